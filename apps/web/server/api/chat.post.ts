@@ -117,14 +117,9 @@ export default defineLazyEventHandler(async () => {
     }
 
     const headers = event.node?.req?.headers ?? {};
-    const headerAmount = headers["x-billing-amount"];
     const headerRef = headers["x-billing-ref-id"];
     const headerTrace = headers["x-trace-id"];
 
-    const rawAmount = body?.billingAmount ?? headerAmount;
-    const amount = rawAmount !== undefined ? Number(rawAmount) : 0;
-    const hasAmount = Number.isFinite(amount) && amount > 0;
-
     const refId =
       (typeof body?.billingRefId === "string" && body.billingRefId) ||
       (typeof headerRef === "string" && headerRef) ||
@@ -136,11 +131,6 @@ export default defineLazyEventHandler(async () => {
       cookie: event.node.req.headers.cookie || "",
     };
 
-    if (hasAmount) {
-      extraHeaders["X-Billing-Amount"] = String(amount);
-      extraHeaders["X-Billing-Ref-Id"] = refId;
-    }
-
     const openai = createOpenAICompatible({
       name: "newapi",
       baseURL: apiUrl.endsWith("/v1") ? apiUrl : `${apiUrl}/v1`,
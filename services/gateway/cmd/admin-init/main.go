@@ -7,8 +7,8 @@ import (
 	"os"
 	"strings"
 
+	"deepspace-shared/model"
 	"deepspace/internal/config"
-	"deepspace/internal/model"
 	"deepspace/internal/pkg/db"
 	"deepspace/internal/repo"
 	"deepspace/internal/service/user"
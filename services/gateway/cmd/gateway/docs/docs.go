@@ -15,7 +15,91 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/admin/billing/topups": {
+        "/admin/billing/adjustments": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "获取人工调账申请",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-计费"
+                ],
+                "summary": "管理员：调账申请列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "组织ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "状态（pending/applied/rejected）",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
             "post": {
                 "security": [
                     {
@@ -25,7 +109,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "管理员给指定用户充值或冲正余额",
+                "description": "增加或扣减用户余额并记录原因；金额超过审批阈值时保存为待审批，由另一名管理员批准后入账",
                 "consumes": [
                     "application/json"
                 ],
@@ -35,21 +119,28 @@ const docTemplate = `{
                 "tags": [
                     "管理-计费"
                 ],
-                "summary": "管理员：系统充值",
+                "summary": "管理员：人工调账",
                 "parameters": [
                     {
-                        "description": "充值信息",
+                        "description": "调账信息",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.adminTopUpRequest"
+                            "$ref": "#/definitions/handlers.adminAdjustmentRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "充值成功",
+                        "description": "已入账",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "202": {
+                        "description": "待审批",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -69,6 +160,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "402": {
+                        "description": "余额不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "403": {
                         "description": "无权限",
                         "schema": {
@@ -83,6 +181,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "422": {
+                        "description": "缺少汇率",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -93,8 +198,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/billing/transactions": {
-            "get": {
+        "/admin/billing/adjustments/{id}/approve": {
+            "post": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -103,7 +208,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取交易流水",
+                "description": "批准待审批的调账并入账，审批人不能是申请人",
                 "consumes": [
                     "application/json"
                 ],
@@ -113,48 +218,27 @@ const docTemplate = `{
                 "tags": [
                     "管理-计费"
                 ],
-                "summary": "管理员：交易流水",
+                "summary": "管理员：批准调账",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "用户ID",
-                        "name": "user_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "类型（hold/capture/release）",
-                        "name": "type",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "开始时间（RFC3339）",
-                        "name": "start",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "结束时间（RFC3339）",
-                        "name": "end",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query"
+                        "description": "调账申请ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "每页数量",
-                        "name": "page_size",
-                        "in": "query"
+                        "description": "审批备注",
+                        "name": "data",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.adminAdjustmentReviewRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功",
+                        "description": "已入账",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -174,8 +258,29 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "402": {
+                        "description": "余额不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "403": {
-                        "description": "无权限",
+                        "description": "无权限或不能审批自己的申请",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "调账申请不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "申请已处理",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -191,8 +296,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/billing/usage": {
-            "get": {
+        "/admin/billing/adjustments/{id}/reject": {
+            "post": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -201,7 +306,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取用量记录",
+                "description": "驳回待审批的调账申请",
                 "consumes": [
                     "application/json"
                 ],
@@ -211,42 +316,27 @@ const docTemplate = `{
                 "tags": [
                     "管理-计费"
                 ],
-                "summary": "管理员：用量记录",
+                "summary": "管理员：驳回调账",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "用户ID",
-                        "name": "user_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "开始时间（RFC3339）",
-                        "name": "start",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "结束时间（RFC3339）",
-                        "name": "end",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query"
+                        "description": "调账申请ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "每页数量",
-                        "name": "page_size",
-                        "in": "query"
+                        "description": "驳回原因",
+                        "name": "data",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.adminAdjustmentReviewRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功",
+                        "description": "已驳回",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -273,6 +363,20 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "404": {
+                        "description": "调账申请不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "申请已处理",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -283,7 +387,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/billing/wallets": {
+        "/admin/billing/events/{ref_id}": {
             "get": {
                 "security": [
                     {
@@ -293,7 +397,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取钱包列表",
+                "description": "按 ref_id 获取关联的预扣、扣款与释放流水，按组织分组",
                 "consumes": [
                     "application/json"
                 ],
@@ -303,24 +407,19 @@ const docTemplate = `{
                 "tags": [
                     "管理-计费"
                 ],
-                "summary": "管理员：钱包列表",
+                "summary": "管理员：计费事件",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "用户ID",
-                        "name": "user_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query"
+                        "type": "string",
+                        "description": "引用ID",
+                        "name": "ref_id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "每页数量",
-                        "name": "page_size",
+                        "description": "组织ID",
+                        "name": "user_id",
                         "in": "query"
                     }
                 ],
@@ -363,7 +462,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/models": {
+        "/admin/billing/fx-rates": {
             "get": {
                 "security": [
                     {
@@ -373,7 +472,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取全部模型列表",
+                "description": "获取汇率列表，rate 表示 1 单位 base_currency 折合多少 quote_currency",
                 "consumes": [
                     "application/json"
                 ],
@@ -381,14 +480,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-模型"
+                    "管理-计费"
                 ],
-                "summary": "管理员：模型列表",
+                "summary": "管理员：汇率列表",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "提供商",
-                        "name": "provider",
+                        "description": "币种（匹配 base 或 quote）",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
                         "in": "query"
                     }
                 ],
@@ -423,7 +534,7 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
+            "put": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -432,7 +543,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "新建模型配置",
+                "description": "按币种对新增或覆盖汇率；反向换算自动使用倒数，无需重复配置",
                 "consumes": [
                     "application/json"
                 ],
@@ -440,23 +551,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-模型"
+                    "管理-计费"
                 ],
-                "summary": "管理员：创建模型",
+                "summary": "管理员：设置汇率",
                 "parameters": [
                     {
-                        "description": "模型数据",
+                        "description": "汇率信息",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.modelCreateRequest"
+                            "$ref": "#/definitions/handlers.adminFXRateRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "创建成功",
+                    "200": {
+                        "description": "设置成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -483,13 +594,6 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "409": {
-                        "description": "模型已存在",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -500,8 +604,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/models/confirm": {
-            "post": {
+        "/admin/billing/fx-rates/{id}": {
+            "delete": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -510,7 +614,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "批量确认模型列表",
+                "description": "删除汇率",
                 "consumes": [
                     "application/json"
                 ],
@@ -518,23 +622,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-模型"
+                    "管理-计费"
                 ],
-                "summary": "管理员：确认模型",
+                "summary": "管理员：删除汇率",
                 "parameters": [
                     {
-                        "description": "模型确认数据",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.modelConfirmRequest"
-                        }
+                        "type": "integer",
+                        "description": "汇率ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "确认成功",
+                    "204": {
+                        "description": "删除成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -561,6 +663,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "404": {
+                        "description": "汇率不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -571,8 +680,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/models/pricing": {
-            "post": {
+        "/admin/billing/invoices": {
+            "get": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -581,7 +690,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "批量更新模型定价",
+                "description": "获取所有组织的月度账单，可按组织与账期筛选",
                 "consumes": [
                     "application/json"
                 ],
@@ -589,23 +698,50 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-模型"
+                    "管理-计费"
                 ],
-                "summary": "管理员：批量定价",
+                "summary": "管理员：月度账单列表",
                 "parameters": [
                     {
-                        "description": "定价数据",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.modelPricingRequest"
-                        }
+                        "type": "integer",
+                        "description": "组织ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "状态（issued）",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "账期起始时间下限（RFC3339）",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "账期起始时间上限（RFC3339）",
+                        "name": "end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功",
+                        "description": "获取成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -642,7 +778,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/models/providers": {
+        "/admin/billing/invoices/{id}": {
             "get": {
                 "security": [
                     {
@@ -652,7 +788,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取全部模型提供商",
+                "description": "获取账单及按模型、项目汇总的明细",
                 "consumes": [
                     "application/json"
                 ],
@@ -660,9 +796,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-模型"
+                    "管理-计费"
+                ],
+                "summary": "管理员：月度账单详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "账单ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "管理员：模型提供商",
                 "responses": {
                     "200": {
                         "description": "获取成功",
@@ -671,67 +816,29 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "401": {
-                        "description": "未登录",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "403": {
-                        "description": "无权限",
+                    "400": {
+                        "description": "请求错误",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "服务内部错误",
+                    "401": {
+                        "description": "未登录",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                }
-            }
-        },
-        "/admin/models/sync": {
-            "post": {
-                "security": [
-                    {
-                        "bearerAuth": []
-                    },
-                    {
-                        "cookieAuth": []
-                    }
-                ],
-                "description": "从上游同步模型列表",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "管理-模型"
-                ],
-                "summary": "管理员：同步上游模型",
-                "responses": {
-                    "200": {
-                        "description": "同步成功",
-                        "schema": {
-                            "$ref": "#/definitions/handlers.modelSyncResponse"
-                        }
                     },
-                    "401": {
-                        "description": "未登录",
+                    "403": {
+                        "description": "无权限",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "403": {
-                        "description": "无权限",
+                    "404": {
+                        "description": "账单不存在",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -747,8 +854,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/models/{id}": {
-            "patch": {
+        "/admin/billing/invoices/{id}/download": {
+            "get": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -757,41 +864,44 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "更新模型配置",
+                "description": "以 HTML 或 PDF 格式下载任意用户的账单",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/pdf",
+                    "text/html"
                 ],
                 "tags": [
-                    "管理-模型"
+                    "管理-计费"
                 ],
-                "summary": "管理员：更新模型",
+                "summary": "管理员：下载月度账单",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "模型ID",
+                        "type": "integer",
+                        "description": "账单ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "模型更新数据",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.modelUpdateRequest"
-                        }
+                        "type": "string",
+                        "description": "格式（pdf/html，默认 pdf）",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "下载方式（attachment/inline）",
+                        "name": "disposition",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功",
+                        "description": "账单文件",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -816,7 +926,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "模型不存在",
+                        "description": "账单不存在",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -832,7 +942,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/plans": {
+        "/admin/billing/reclaimed": {
             "get": {
                 "security": [
                     {
@@ -842,7 +952,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "需要管理员权限",
+                "description": "获取 Worker 自动释放的过期预扣流水及按币种汇总的回收总额",
                 "consumes": [
                     "application/json"
                 ],
@@ -850,9 +960,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-套餐"
+                    "管理-计费"
+                ],
+                "summary": "管理员：过期预扣回收报告",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "组织ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "开始时间（RFC3339）",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束时间（RFC3339）",
+                        "name": "end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
                 ],
-                "summary": "管理员：套餐列表",
                 "responses": {
                     "200": {
                         "description": "获取成功",
@@ -861,6 +1003,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "401": {
                         "description": "未登录",
                         "schema": {
@@ -883,7 +1032,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/billing/refunds": {
             "post": {
                 "security": [
                     {
@@ -893,7 +1044,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "需要管理员权限",
+                "description": "把指定扣款 ref 的已扣金额部分或全部退回余额，累计退款不超过已扣款金额；操作写入审计日志",
                 "consumes": [
                     "application/json"
                 ],
@@ -901,23 +1052,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-套餐"
+                    "管理-计费"
                 ],
-                "summary": "管理员：创建套餐",
+                "summary": "管理员：退款",
                 "parameters": [
                     {
-                        "description": "套餐数据",
+                        "description": "退款信息",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.planCreateRequest"
+                            "$ref": "#/definitions/handlers.adminRefundRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "创建成功",
+                    "200": {
+                        "description": "退款成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -944,6 +1095,27 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "404": {
+                        "description": "扣款不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "引用冲突",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "超过可退金额或缺少汇率",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -954,8 +1126,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/plans/{id}": {
-            "patch": {
+        "/admin/billing/topups": {
+            "post": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -964,7 +1136,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "需要管理员权限",
+                "description": "管理员给指定用户充值或冲正余额；currency 与钱包币种不同时按当前汇率换算",
                 "consumes": [
                     "application/json"
                 ],
@@ -972,30 +1144,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-套餐"
+                    "管理-计费"
                 ],
-                "summary": "管理员：更新套餐",
+                "summary": "管理员：系统充值",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "套餐ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "套餐更新数据",
+                        "description": "充值信息",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.planUpdateRequest"
+                            "$ref": "#/definitions/handlers.adminTopUpRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功",
+                        "description": "充值成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1022,8 +1187,15 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "套餐不存在",
+                    "409": {
+                        "description": "引用冲突",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "缺少汇率",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1039,7 +1211,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/risk/budget-caps": {
+        "/admin/billing/transactions": {
             "get": {
                 "security": [
                     {
@@ -1049,7 +1221,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取预算上限列表",
+                "description": "获取交易流水",
                 "consumes": [
                     "application/json"
                 ],
@@ -1057,20 +1229,44 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-风控"
+                    "管理-计费"
                 ],
-                "summary": "管理员：预算上限列表",
+                "summary": "管理员：交易流水",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "策略ID",
-                        "name": "policy_id",
+                        "description": "组织ID",
+                        "name": "user_id",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "状态（active/disabled）",
-                        "name": "status",
+                        "description": "类型，多个以逗号分隔（hold/capture/release/expire/topup/voucher/voucher_expire/refund/manual_credit/manual_debit/adjustment）",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "引用ID",
+                        "name": "ref_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "被退款的扣款引用ID",
+                        "name": "parent_ref_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "开始时间（RFC3339）",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束时间（RFC3339）",
+                        "name": "end",
                         "in": "query"
                     },
                     {
@@ -1123,8 +1319,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/admin/billing/usage": {
+            "get": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -1133,7 +1331,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "创建预算上限",
+                "description": "获取用量记录，含请求路径、状态码、错误原因、是否流式、上游、上游耗时、首 token 耗时及凭证类型与 User-Agent；失败请求费用为 0",
                 "consumes": [
                     "application/json"
                 ],
@@ -1141,23 +1339,50 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-风控"
+                    "管理-计费"
                 ],
-                "summary": "管理员：创建预算上限",
+                "summary": "管理员：用量记录",
                 "parameters": [
                     {
-                        "description": "预算上限数据",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.budgetCapCreateRequest"
-                        }
+                        "type": "integer",
+                        "description": "发起请求的用户ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "付费组织ID",
+                        "name": "org_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "开始时间（RFC3339）",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束时间（RFC3339）",
+                        "name": "end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "创建成功",
+                    "200": {
+                        "description": "获取成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1194,8 +1419,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/risk/budget-caps/{id}": {
-            "delete": {
+        "/admin/billing/usage/analytics": {
+            "get": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -1204,7 +1429,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "删除预算上限",
+                "description": "按小时或按天（UTC）返回用量时间序列，可按组织、成员、项目、模型分组，并返回整个范围内按费用倒序的前 limit 组汇总。数据来自 Worker 定时累加的预聚合，最近几分钟的用量可能尚未计入；费用按币种分别汇总，project_id 为 0 表示不属于任何项目",
                 "consumes": [
                     "application/json"
                 ],
@@ -1212,21 +1437,68 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-风控"
+                    "管理-计费"
                 ],
-                "summary": "管理员：删除预算上限",
+                "summary": "管理员：用量趋势",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "粒度：hour 或 day，默认 day",
+                        "name": "granularity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "开始时间（RFC3339），默认 hour 为最近 24 小时、day 为最近 30 天；hour 最长 31 天，day 最长 366 天",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束时间（RFC3339），默认当前时间",
+                        "name": "end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "分组维度，逗号分隔：org、user、project、model",
+                        "name": "group_by",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "预算上限ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "付费组织ID",
+                        "name": "org_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "发起请求的用户ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "项目ID，0 为不属于任何项目的请求",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "模型",
+                        "name": "model",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "汇总返回的组数，默认 10，最大 100",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "删除成功",
+                    "200": {
+                        "description": "获取成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1261,8 +1533,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/admin/billing/wallets": {
+            "get": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -1271,7 +1545,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "更新预算上限",
+                "description": "获取钱包列表；钱包归属组织，user_id 为组织ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1279,30 +1553,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-风控"
+                    "管理-计费"
                 ],
-                "summary": "管理员：更新预算上限",
+                "summary": "管理员：钱包列表",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "预算上限ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "组织ID",
+                        "name": "user_id",
+                        "in": "query"
                     },
                     {
-                        "description": "预算上限更新数据",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.budgetCapUpdateRequest"
-                        }
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功",
+                        "description": "获取成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1329,13 +1605,6 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "预算上限不存在",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -1346,8 +1615,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/risk/ip-rules": {
-            "get": {
+        "/admin/billing/wallets/{org_id}/credit-limit": {
+            "put": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -1356,7 +1625,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取 IP 黑白名单",
+                "description": "设置后付费信用额度（钱包币种），余额最低可透支到 -credit_limit；0 表示预付费",
                 "consumes": [
                     "application/json"
                 ],
@@ -1364,44 +1633,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-风控"
+                    "管理-计费"
                 ],
-                "summary": "管理员：IP 规则列表",
+                "summary": "管理员：设置信用额度",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "策略ID",
-                        "name": "policy_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "类型（allow/deny）",
-                        "name": "type",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "状态（active/disabled）",
-                        "name": "status",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query"
+                        "description": "组织ID",
+                        "name": "org_id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "每页数量",
-                        "name": "page_size",
-                        "in": "query"
+                        "description": "信用额度",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.adminWalletCreditLimitRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功",
+                        "description": "设置成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1436,9 +1691,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "security": [
+            }
+        },
+        "/admin/billing/wallets/{org_id}/currency": {
+            "put": {
+                "security": [
                     {
                         "bearerAuth": []
                     },
@@ -1446,7 +1703,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "创建 IP 黑白名单规则",
+                "description": "设置组织钱包的记账币种，钱包余额与冻结金额必须为 0",
                 "consumes": [
                     "application/json"
                 ],
@@ -1454,23 +1711,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-风控"
+                    "管理-计费"
                 ],
-                "summary": "管理员：创建 IP 规则",
+                "summary": "管理员：设置钱包币种",
                 "parameters": [
                     {
-                        "description": "IP 规则数据",
+                        "type": "integer",
+                        "description": "组织ID",
+                        "name": "org_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "币种",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.ipRuleCreateRequest"
+                            "$ref": "#/definitions/handlers.adminWalletCurrencyRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "创建成功",
+                    "200": {
+                        "description": "设置成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1497,6 +1761,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "409": {
+                        "description": "钱包非空",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -1507,8 +1778,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/risk/ip-rules/{id}": {
-            "delete": {
+        "/admin/billing/wallets/{org_id}/status": {
+            "put": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -1517,7 +1788,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "删除 IP 黑白名单规则",
+                "description": "停用后不能发起新的预扣；恢复时清除催缴状态，仍超出信用额度的钱包会被催缴任务重新停用",
                 "consumes": [
                     "application/json"
                 ],
@@ -1525,21 +1796,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-风控"
+                    "管理-计费"
                 ],
-                "summary": "管理员：删除 IP 规则",
+                "summary": "管理员：停用或恢复钱包",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "IP 规则ID",
-                        "name": "id",
+                        "description": "组织ID",
+                        "name": "org_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "状态",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.adminWalletStatusRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "删除成功",
+                    "200": {
+                        "description": "设置成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1574,8 +1854,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/admin/models": {
+            "get": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -1584,7 +1866,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "更新 IP 黑白名单规则",
+                "description": "获取全部模型列表",
                 "consumes": [
                     "application/json"
                 ],
@@ -1592,37 +1874,20 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-风控"
+                    "管理-模型"
                 ],
-                "summary": "管理员：更新 IP 规则",
+                "summary": "管理员：模型列表",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "IP 规则ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "IP 规则更新数据",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.ipRuleUpdateRequest"
-                        }
+                        "type": "string",
+                        "description": "提供商",
+                        "name": "provider",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "请求错误",
+                        "description": "获取成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1642,13 +1907,6 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "IP 规则不存在",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -1657,10 +1915,8 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/admin/risk/policies": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -1669,7 +1925,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取风控策略列表",
+                "description": "新建模型配置",
                 "consumes": [
                     "application/json"
                 ],
@@ -1677,50 +1933,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-风控"
+                    "管理-模型"
                 ],
-                "summary": "管理员：风控策略列表",
+                "summary": "管理员：创建模型",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "作用域（global/user/project）",
-                        "name": "scope",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "用户ID",
-                        "name": "user_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "项目ID",
-                        "name": "project_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "状态（active/disabled）",
-                        "name": "status",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "每页数量",
-                        "name": "page_size",
-                        "in": "query"
+                        "description": "模型数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.modelCreateRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "获取成功",
+                    "201": {
+                        "description": "创建成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1747,6 +1976,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "409": {
+                        "description": "模型已存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -1755,7 +1991,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/models/confirm": {
             "post": {
                 "security": [
                     {
@@ -1765,7 +2003,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "创建风控策略",
+                "description": "批量确认模型列表",
                 "consumes": [
                     "application/json"
                 ],
@@ -1773,23 +2011,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-风控"
+                    "管理-模型"
                 ],
-                "summary": "管理员：创建风控策略",
+                "summary": "管理员：确认模型",
                 "parameters": [
                     {
-                        "description": "策略数据",
+                        "description": "模型确认数据",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.riskPolicyCreateRequest"
+                            "$ref": "#/definitions/handlers.modelConfirmRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "创建成功",
+                    "200": {
+                        "description": "确认成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1826,8 +2064,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/risk/policies/{id}": {
-            "delete": {
+        "/admin/models/pricing": {
+            "post": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -1836,7 +2074,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "删除风控策略",
+                "description": "批量更新模型定价",
                 "consumes": [
                     "application/json"
                 ],
@@ -1844,21 +2082,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-风控"
+                    "管理-模型"
                 ],
-                "summary": "管理员：删除风控策略",
+                "summary": "管理员：批量定价",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "策略ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "定价数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.modelPricingRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "删除成功",
+                    "200": {
+                        "description": "更新成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1893,8 +2133,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/admin/models/providers": {
+            "get": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -1903,7 +2145,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "更新风控策略",
+                "description": "获取全部模型提供商",
                 "consumes": [
                     "application/json"
                 ],
@@ -1911,37 +2153,12 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-风控"
-                ],
-                "summary": "管理员：更新风控策略",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "策略ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "策略更新数据",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.riskPolicyUpdateRequest"
-                        }
-                    }
+                    "管理-模型"
                 ],
+                "summary": "管理员：模型提供商",
                 "responses": {
                     "200": {
-                        "description": "更新成功",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "请求错误",
+                        "description": "获取成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1961,13 +2178,6 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "策略不存在",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -1978,8 +2188,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/risk/rate-limits": {
-            "get": {
+        "/admin/models/sync": {
+            "post": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -1988,7 +2198,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取速率限制列表",
+                "description": "从上游同步模型列表",
                 "consumes": [
                     "application/json"
                 ],
@@ -1996,48 +2206,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-风控"
-                ],
-                "summary": "管理员：速率限制列表",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "策略ID",
-                        "name": "policy_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "状态（active/disabled）",
-                        "name": "status",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "每页数量",
-                        "name": "page_size",
-                        "in": "query"
-                    }
+                    "管理-模型"
                 ],
+                "summary": "管理员：同步上游模型",
                 "responses": {
                     "200": {
-                        "description": "获取成功",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "请求错误",
+                        "description": "同步成功",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/handlers.modelSyncResponse"
                         }
                     },
                     "401": {
@@ -2062,8 +2238,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/admin/models/{id}": {
+            "patch": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -2072,7 +2250,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "创建速率限制",
+                "description": "更新模型配置",
                 "consumes": [
                     "application/json"
                 ],
@@ -2080,23 +2258,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-风控"
+                    "管理-模型"
                 ],
-                "summary": "管理员：创建速率限制",
+                "summary": "管理员：更新模型",
                 "parameters": [
                     {
-                        "description": "速率限制数据",
+                        "type": "string",
+                        "description": "模型ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "模型更新数据",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.rateLimitCreateRequest"
+                            "$ref": "#/definitions/handlers.modelUpdateRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "创建成功",
+                    "200": {
+                        "description": "更新成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -2123,6 +2308,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "404": {
+                        "description": "模型不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -2133,8 +2325,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/risk/rate-limits/{id}": {
-            "delete": {
+        "/admin/orgs": {
+            "get": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -2143,7 +2335,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "删除速率限制",
+                "description": "获取组织列表；组织 ID 即计费接口中钱包、流水、账单的 user_id",
                 "consumes": [
                     "application/json"
                 ],
@@ -2151,21 +2343,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-风控"
+                    "管理-组织"
                 ],
-                "summary": "管理员：删除速率限制",
+                "summary": "管理员：组织列表",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "名称关键字",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否个人组织",
+                        "name": "personal",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "速率限制ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "删除成功",
+                    "200": {
+                        "description": "获取成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -2200,8 +2409,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/admin/orgs/{id}": {
+            "get": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -2210,7 +2421,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "更新速率限制",
+                "description": "获取组织信息及成员",
                 "consumes": [
                     "application/json"
                 ],
@@ -2218,30 +2429,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-风控"
+                    "管理-组织"
                 ],
-                "summary": "管理员：更新速率限制",
+                "summary": "管理员：组织详情",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "速率限制ID",
+                        "description": "组织ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "速率限制更新数据",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.rateLimitUpdateRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功",
+                        "description": "获取成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -2269,7 +2471,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "速率限制不存在",
+                        "description": "组织不存在",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -2285,8 +2487,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/subscriptions": {
-            "post": {
+        "/admin/orgs/{id}/subscription": {
+            "get": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -2295,7 +2497,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "创建用户订阅",
+                "description": "获取组织当前生效订阅",
                 "consumes": [
                     "application/json"
                 ],
@@ -2305,21 +2507,19 @@ const docTemplate = `{
                 "tags": [
                     "管理-订阅"
                 ],
-                "summary": "管理员：创建订阅",
+                "summary": "管理员：获取组织订阅",
                 "parameters": [
                     {
-                        "description": "订阅数据",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.subscriptionCreateRequest"
-                        }
+                        "type": "integer",
+                        "description": "组织ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "创建成功",
+                    "200": {
+                        "description": "获取成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -2347,14 +2547,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "套餐不存在",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "409": {
-                        "description": "已有生效订阅",
+                        "description": "订阅不存在",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -2370,8 +2563,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/subscriptions/{id}": {
-            "patch": {
+        "/admin/pipeline/chains": {
+            "get": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -2380,7 +2573,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "更新订阅状态或时间",
+                "description": "获取按全局、项目或模型配置的前置/后置链路",
                 "consumes": [
                     "application/json"
                 ],
@@ -2388,30 +2581,56 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-订阅"
+                    "管理-Pipeline"
                 ],
-                "summary": "管理员：更新订阅",
+                "summary": "管理员：Pipeline 链路列表",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "作用域（global/project/model）",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "阶段（pre/post）",
+                        "name": "stage",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "订阅ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "项目ID",
+                        "name": "project_id",
+                        "in": "query"
                     },
                     {
-                        "description": "订阅更新数据",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.subscriptionUpdateRequest"
-                        }
+                        "type": "string",
+                        "description": "模型名称",
+                        "name": "model",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "状态（active/disabled）",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功",
+                        "description": "获取成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -2438,13 +2657,6 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "订阅不存在",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -2453,10 +2665,8 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/admin/users": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -2465,7 +2675,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "需要管理员权限",
+                "description": "按全局、项目或模型声明有序的前置/后置步骤；前置链路须包含 auth、policy 与 budget_hold 且以 budget_hold 结尾，后置链路须包含 usage_capture，修改在本实例立即生效、其他实例最迟 30 秒后生效",
                 "consumes": [
                     "application/json"
                 ],
@@ -2473,51 +2683,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-用户"
+                    "管理-Pipeline"
                 ],
-                "summary": "管理员：用户列表",
+                "summary": "管理员：创建 Pipeline 链路",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "每页数量",
-                        "name": "page_size",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "搜索关键字",
-                        "name": "search",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "角色",
-                        "name": "role",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "状态",
-                        "name": "status",
-                        "in": "query"
+                        "description": "链路数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.pipelineChainCreateRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "获取成功",
+                    "201": {
+                        "description": "创建成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "401": {
-                        "description": "未登录",
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -2538,8 +2734,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/admin/pipeline/chains/{id}": {
+            "delete": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -2548,7 +2746,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "需要管理员权限",
+                "description": "删除后对应作用域回落到更宽的链路或默认步骤",
                 "consumes": [
                     "application/json"
                 ],
@@ -2556,23 +2754,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-用户"
+                    "管理-Pipeline"
                 ],
-                "summary": "管理员：创建用户",
+                "summary": "管理员：删除 Pipeline 链路",
                 "parameters": [
                     {
-                        "description": "用户信息",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.createUserRequest"
-                        }
+                        "type": "integer",
+                        "description": "链路ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "创建成功",
+                    "204": {
+                        "description": "删除成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -2607,10 +2803,8 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/admin/users/{id}": {
-            "get": {
+            },
+            "patch": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -2619,7 +2813,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "需要管理员权限",
+                "description": "更新链路的作用域、阶段或步骤顺序；作用域改为 model 或 global 时自动清空 project_id，改为 global 时同时清空 model",
                 "consumes": [
                     "application/json"
                 ],
@@ -2627,21 +2821,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-用户"
+                    "管理-Pipeline"
                 ],
-                "summary": "管理员：获取用户",
+                "summary": "管理员：更新 Pipeline 链路",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "用户ID",
+                        "description": "链路ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "链路更新数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.pipelineChainUpdateRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功",
+                        "description": "更新成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -2669,7 +2872,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "用户不存在",
+                        "description": "链路不存在",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -2683,8 +2886,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/admin/pipeline/steps": {
+            "get": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -2693,7 +2898,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "需要管理员权限",
+                "description": "获取已注册、可用于链路配置的步骤名称、默认步骤及各阶段必须包含的步骤",
                 "consumes": [
                     "application/json"
                 ],
@@ -2701,28 +2906,65 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-用户"
-                ],
-                "summary": "管理员：删除用户",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "用户ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "管理-Pipeline"
                 ],
+                "summary": "管理员：可用 Pipeline 步骤",
                 "responses": {
                     "200": {
-                        "description": "删除成功",
+                        "description": "获取成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "请求错误",
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/plans": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "需要管理员权限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-套餐"
+                ],
+                "summary": "管理员：套餐列表",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -2751,7 +2993,7 @@ const docTemplate = `{
                     }
                 }
             },
-            "patch": {
+            "post": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -2760,7 +3002,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "需要管理员权限",
+                "description": "需要管理员权限。included_tokens 与 included_requests 为通用额度，可同时设置；allowances 为限定模型（scope=model，match 为模型名）或能力（scope=capability）的额度，命中的请求只消耗该额度；overage_multiplier 为超额部分的价格倍率，默认 1；quota_enforcement 为 block 时额度用完后在调用上游前拒绝请求（429），为 overage（默认）时转为钱包计费；grace_period_days 为订阅到期后仍按生效处理的宽限天数（0-90，默认 0）；rollover_tokens / rollover_requests 为通用额度每周期最多结转到下一周期的未用量（限定额度可分别设置），结转额度优先消耗，在下一周期开始 rollover_days 天后失效（0 为随下一周期结束失效）",
                 "consumes": [
                     "application/json"
                 ],
@@ -2768,30 +3010,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-用户"
+                    "管理-套餐"
                 ],
-                "summary": "管理员：更新用户",
+                "summary": "管理员：创建套餐",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "用户ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "用户信息",
+                        "description": "套餐数据",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.updateUserAdminRequest"
+                            "$ref": "#/definitions/handlers.planCreateRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "更新成功",
+                    "201": {
+                        "description": "创建成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -2818,13 +3053,6 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "用户不存在",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -2835,8 +3063,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/users/{id}/subscription": {
-            "get": {
+        "/admin/plans/{id}": {
+            "patch": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -2845,7 +3073,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取用户当前生效订阅",
+                "description": "需要管理员权限；传入 allowances 时替换套餐的全部限定额度",
                 "consumes": [
                     "application/json"
                 ],
@@ -2853,21 +3081,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "管理-订阅"
+                    "管理-套餐"
                 ],
-                "summary": "管理员：获取用户订阅",
+                "summary": "管理员：更新套餐",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "用户ID",
+                        "description": "套餐ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "套餐更新数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.planUpdateRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功",
+                        "description": "更新成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -2895,7 +3132,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "订阅不存在",
+                        "description": "套餐不存在",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -2911,9 +3148,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/login": {
-            "post": {
-                "description": "使用邮箱密码登录并设置登录 Cookie",
+        "/admin/risk/budget-caps": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "获取预算上限列表",
                 "consumes": [
                     "application/json"
                 ],
@@ -2921,23 +3166,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "认证"
+                    "管理-风控"
                 ],
-                "summary": "用户登录",
+                "summary": "管理员：预算上限列表",
                 "parameters": [
                     {
-                        "description": "登录信息",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.loginRequest"
-                        }
+                        "type": "integer",
+                        "description": "策略ID",
+                        "name": "policy_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "状态（active/disabled）",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "登录成功",
+                        "description": "获取成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -2951,7 +3211,14 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "账号或密码错误",
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -2965,11 +3232,17 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/auth/logout": {
+            },
             "post": {
-                "description": "清理登录 Cookie",
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "创建预算上限",
                 "consumes": [
                     "application/json"
                 ],
@@ -2977,21 +3250,4185 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "认证"
+                    "管理-风控"
                 ],
-                "summary": "用户退出登录",
-                "responses": {
-                    "200": {
-                        "description": "退出成功",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    }
+                "summary": "管理员：创建预算上限",
+                "parameters": [
+                    {
+                        "description": "预算上限数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.budgetCapCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "创建成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/risk/budget-caps/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "删除预算上限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-风控"
+                ],
+                "summary": "管理员：删除预算上限",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "预算上限ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "删除成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "更新预算上限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-风控"
+                ],
+                "summary": "管理员：更新预算上限",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "预算上限ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "预算上限更新数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.budgetCapUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "预算上限不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/risk/ip-rules": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "获取 IP 黑白名单",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-风控"
+                ],
+                "summary": "管理员：IP 规则列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "策略ID",
+                        "name": "policy_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "类型（allow/deny）",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "状态（active/disabled）",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "创建 IP 黑白名单规则",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-风控"
+                ],
+                "summary": "管理员：创建 IP 规则",
+                "parameters": [
+                    {
+                        "description": "IP 规则数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ipRuleCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "创建成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/risk/ip-rules/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "删除 IP 黑白名单规则",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-风控"
+                ],
+                "summary": "管理员：删除 IP 规则",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "IP 规则ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "删除成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "更新 IP 黑白名单规则",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-风控"
+                ],
+                "summary": "管理员：更新 IP 规则",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "IP 规则ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "IP 规则更新数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ipRuleUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "IP 规则不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/risk/policies": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "获取风控策略列表",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-风控"
+                ],
+                "summary": "管理员：风控策略列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "作用域（global/user/project）",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "用户ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "状态（active/disabled）",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "创建风控策略",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-风控"
+                ],
+                "summary": "管理员：创建风控策略",
+                "parameters": [
+                    {
+                        "description": "策略数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.riskPolicyCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "创建成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/risk/policies/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "删除风控策略",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-风控"
+                ],
+                "summary": "管理员：删除风控策略",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "策略ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "删除成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "更新风控策略",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-风控"
+                ],
+                "summary": "管理员：更新风控策略",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "策略ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "策略更新数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.riskPolicyUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "策略不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/risk/rate-limits": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "获取速率限制列表",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-风控"
+                ],
+                "summary": "管理员：速率限制列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "策略ID",
+                        "name": "policy_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "状态（active/disabled）",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "创建速率限制",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-风控"
+                ],
+                "summary": "管理员：创建速率限制",
+                "parameters": [
+                    {
+                        "description": "速率限制数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.rateLimitCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "创建成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/risk/rate-limits/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "删除速率限制",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-风控"
+                ],
+                "summary": "管理员：删除速率限制",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "速率限制ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "删除成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "更新速率限制",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-风控"
+                ],
+                "summary": "管理员：更新速率限制",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "速率限制ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "速率限制更新数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.rateLimitUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "速率限制不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/subscriptions": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "为组织创建订阅，user_id 为组织ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-订阅"
+                ],
+                "summary": "管理员：创建订阅",
+                "parameters": [
+                    {
+                        "description": "订阅数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.subscriptionCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "创建成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "套餐不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "已有生效订阅",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/subscriptions/{id}": {
+            "patch": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "更新订阅状态或时间",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-订阅"
+                ],
+                "summary": "管理员：更新订阅",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "订阅ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "订阅更新数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.subscriptionUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "订阅不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/upstreams/health": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "获取各 NewAPI 上游的权重、模型范围、熔断状态与被动健康统计",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-上游"
+                ],
+                "summary": "管理员：上游健康状态",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "需要管理员权限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-用户"
+                ],
+                "summary": "管理员：用户列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "搜索关键字",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "角色",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "状态",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "需要管理员权限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-用户"
+                ],
+                "summary": "管理员：创建用户",
+                "parameters": [
+                    {
+                        "description": "用户信息",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.createUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "创建成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "需要管理员权限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-用户"
+                ],
+                "summary": "管理员：获取用户",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "需要管理员权限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-用户"
+                ],
+                "summary": "管理员：删除用户",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "需要管理员权限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-用户"
+                ],
+                "summary": "管理员：更新用户",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "用户信息",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.updateUserAdminRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/subscription": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "获取用户个人组织当前生效的订阅",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-订阅"
+                ],
+                "summary": "管理员：获取用户订阅",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "订阅不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/vouchers": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "获取代金券列表",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-计费"
+                ],
+                "summary": "管理员：代金券列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "状态（active/disabled）",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "兑换码",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "创建单个或批量生成代金券；max_redemptions 大于 1 时为多次兑换码，每个用户限兑一次",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-计费"
+                ],
+                "summary": "管理员：创建代金券",
+                "parameters": [
+                    {
+                        "description": "代金券信息",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.adminVoucherCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "创建成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "兑换码已存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/vouchers/{id}": {
+            "patch": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "停用/启用代金券或调整兑换次数、过期时间；已兑换的额度不受影响",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-计费"
+                ],
+                "summary": "管理员：更新代金券",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "代金券ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "代金券更新数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.adminVoucherUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "代金券不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/vouchers/{id}/redemptions": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "获取指定代金券的兑换记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "管理-计费"
+                ],
+                "summary": "管理员：代金券兑换记录",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "代金券ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "组织ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "状态（active/expired）",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "使用邮箱密码登录并设置登录 Cookie",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "认证"
+                ],
+                "summary": "用户登录",
+                "parameters": [
+                    {
+                        "description": "登录信息",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "登录成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "账号或密码错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "清理登录 Cookie",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "认证"
+                ],
+                "summary": "用户退出登录",
+                "responses": {
+                    "200": {
+                        "description": "退出成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/me": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "获取当前登录用户的 user_id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "认证"
+                ],
+                "summary": "获取当前用户",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password-reset/confirm": {
+            "post": {
+                "description": "校验重置令牌并更新密码",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "认证"
+                ],
+                "summary": "确认重置密码",
+                "parameters": [
+                    {
+                        "description": "重置确认",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.passwordResetConfirmRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "重置成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "503": {
+                        "description": "服务不可用",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password-reset/request": {
+            "post": {
+                "description": "发送重置密码邮件",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "认证"
+                ],
+                "summary": "申请重置密码",
+                "parameters": [
+                    {
+                        "description": "重置请求",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.passwordResetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "请求成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "503": {
+                        "description": "服务不可用",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "使用邮箱密码注册并设置登录 Cookie",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "认证"
+                ],
+                "summary": "用户注册",
+                "parameters": [
+                    {
+                        "description": "注册信息",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.registerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "创建成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "邮箱已注册",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/billing/capture": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "确认扣减预扣余额",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "扣减余额",
+                "parameters": [
+                    {
+                        "description": "扣减信息",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.billingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "扣减成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "402": {
+                        "description": "余额不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "预扣不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "引用冲突",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "缺少汇率",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/billing/events/{ref_id}": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "按 ref_id 获取关联的预扣、扣款与释放流水",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "计费事件",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "引用ID",
+                        "name": "ref_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "事件不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/billing/hold": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "预扣用户余额",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "预扣余额",
+                "parameters": [
+                    {
+                        "description": "预扣信息",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.billingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "预扣成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "402": {
+                        "description": "余额不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "引用冲突",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "缺少汇率",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/billing/invoices": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "获取当前用户的月度账单，按账期倒序",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "月度账单列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "账期起始时间下限（RFC3339）",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "账期起始时间上限（RFC3339）",
+                        "name": "end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/billing/invoices/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "获取当前用户的账单及按模型、项目汇总的明细",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "月度账单详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "账单ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "账单不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/billing/invoices/{id}/download": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "以 HTML 或 PDF 格式下载当前用户的账单",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/pdf",
+                    "text/html"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "下载月度账单",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "账单ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "格式（pdf/html，默认 pdf）",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "下载方式（attachment/inline）",
+                        "name": "disposition",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "账单文件",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "账单不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/billing/quota": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "返回当前订阅本周期各项额度的已用与剩余量及周期起止时间（没有订阅或套餐不含额度时 quota 为 null），以及按周期倒序的历史用量（含当前周期）。额度中 allowance_id 为 0 的是通用额度，其余为限定模型或能力的额度；included 为 0 的维度不计量；remaining 含未失效的结转额度，rollover 为从上一周期结转的额度、已用量与失效时间",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "套餐额度",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "历史周期数，默认 12，最大 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/billing/release": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "释放已预扣余额",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "释放预扣",
+                "parameters": [
+                    {
+                        "description": "释放信息",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.billingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "释放成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "预扣不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "引用冲突",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "缺少汇率",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/billing/settle": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "按实际金额扣款并自动释放剩余预扣，扣款与释放在同一事务内完成",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "结算预扣",
+                "parameters": [
+                    {
+                        "description": "结算信息（amount 为实际金额）",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.billingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "结算成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "预扣不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "引用冲突或状态不允许",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "缺少汇率",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/billing/subscription": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "获取当前组织生效的套餐订阅，没有订阅时 subscription 为 null",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "当前订阅",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "订阅 /api/plans 中的套餐，从组织钱包扣除首个周期费用（按当前汇率换算为钱包币种）；auto_renew 默认为 true，周期结束时按订阅时的价格自动续费",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "订阅套餐",
+                "parameters": [
+                    {
+                        "description": "套餐",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.subscribeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "订阅成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "402": {
+                        "description": "余额不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "套餐不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "已有生效订阅",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "410": {
+                        "description": "套餐已下架",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "缺少汇率",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/billing/subscription/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "关闭自动续费，订阅在当前周期结束时失效，已付费用不退还",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "取消订阅",
+                "responses": {
+                    "200": {
+                        "description": "取消成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "没有生效订阅",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "订阅由管理员创建",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/billing/subscription/change": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "按钱包币种的日均价格比较：新套餐更贵时立即升级，按当前周期剩余时长补差价（新套餐费用减去原套餐未用部分的抵扣），本周期已用的用量计入新套餐；否则安排在当前周期结束时降级，下一周期用量从零开始；已取消自动续费的订阅须先恢复才能降级",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "变更套餐",
+                "parameters": [
+                    {
+                        "description": "目标套餐",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.changePlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "变更成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "402": {
+                        "description": "余额不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "套餐或订阅不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "套餐未变化、订阅由管理员创建、订阅已到期或已取消自动续费",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "410": {
+                        "description": "套餐已下架",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "撤销尚未生效的降级，续费时继续使用当前套餐",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "撤销降级",
+                "responses": {
+                    "200": {
+                        "description": "撤销成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "没有生效订阅或待生效的降级",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/billing/subscription/resume": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "撤销取消，恢复自动续费；订阅已到期但仍在套餐宽限期内时，Worker 会重新尝试扣款续费",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "恢复自动续费",
+                "responses": {
+                    "200": {
+                        "description": "恢复成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "没有生效订阅",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "订阅由管理员创建",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/billing/topups": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "获取当前用户的充值订单",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "充值订单列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "状态（pending/succeeded/failed）",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "以钱包币种创建充值订单，返回支付渠道的收银台地址；支付完成后由渠道回调入账",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "发起充值",
+                "parameters": [
+                    {
+                        "description": "充值金额",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.topUpCheckoutRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "创建成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "503": {
+                        "description": "未启用自助充值",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/billing/topups/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "获取当前用户的充值订单，用于轮询支付结果",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "充值订单详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "订单ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "订单不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/billing/usage": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "获取当前用户用量明细，每条记录含请求路径、状态码、是否流式、上游、上游耗时与首 token 耗时；失败请求同样记录，费用为 0",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "用量明细",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "开始时间（RFC3339）",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束时间（RFC3339）",
+                        "name": "end",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/billing/usage/analytics": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "按小时或按天（UTC）返回当前组织的用量时间序列，可按成员、项目、模型分组，并返回整个范围内按费用倒序的前 limit 组汇总（用于项目费用、热门模型图表）。数据来自 Worker 定时累加的预聚合，最近几分钟的用量可能尚未计入；费用按币种分别汇总，project_id 为 0 表示不属于任何项目",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "用量趋势",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "粒度：hour 或 day，默认 day",
+                        "name": "granularity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "开始时间（RFC3339），默认 hour 为最近 24 小时、day 为最近 30 天；hour 最长 31 天，day 最长 366 天",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束时间（RFC3339），默认当前时间",
+                        "name": "end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "分组维度，逗号分隔：user、project、model",
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "成员ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "项目ID，0 为不属于任何项目的请求",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "模型",
+                        "name": "model",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "汇总返回的组数，默认 10，最大 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/billing/vouchers": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "获取当前用户的代金券兑换记录，含额度过期时间与已回收金额",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "代金券兑换记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "状态（active/expired）",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/billing/vouchers/redeem": {
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "兑换代金券，额度以 voucher 流水计入钱包，按当前汇率换算为钱包币种",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "兑换代金券",
+                "parameters": [
+                    {
+                        "description": "兑换码",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.voucherRedeemRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "兑换成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "兑换码不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "已兑换或次数已用完",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "410": {
+                        "description": "兑换码已停用或过期",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "缺少汇率",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/billing/wallet": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "获取当前用户钱包与近 24 小时用量",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "获取钱包",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/billing/webhooks/{provider}": {
+            "post": {
+                "description": "支付渠道回调，验签后处理支付结果；重复投递是安全的",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "计费"
+                ],
+                "summary": "支付回调",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "支付渠道",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "处理成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "签名不正确",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "订单不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "回调与订单不一致",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "503": {
+                        "description": "未启用自助充值",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
                 }
             }
         },
-        "/auth/me": {
+        "/conversations": {
             "get": {
                 "security": [
                     {
@@ -3001,7 +7438,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取当前登录用户的 user_id",
+                "description": "获取独立对话列表",
                 "consumes": [
                     "application/json"
                 ],
@@ -3009,9 +7446,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "认证"
+                    "对话"
                 ],
-                "summary": "获取当前用户",
+                "summary": "独立对话列表",
                 "responses": {
                     "200": {
                         "description": "获取成功",
@@ -3026,13 +7463,26 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
-            }
-        },
-        "/auth/password-reset/confirm": {
+            },
             "post": {
-                "description": "校验重置令牌并更新密码",
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "创建独立对话",
                 "consumes": [
                     "application/json"
                 ],
@@ -3040,23 +7490,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "认证"
+                    "对话"
                 ],
-                "summary": "确认重置密码",
+                "summary": "创建独立对话",
                 "parameters": [
                     {
-                        "description": "重置确认",
+                        "description": "对话数据",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.passwordResetConfirmRequest"
+                            "$ref": "#/definitions/handlers.createConversationRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "重置成功",
+                    "201": {
+                        "description": "创建成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -3069,15 +7519,15 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "服务内部错误",
+                    "401": {
+                        "description": "未登录",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "503": {
-                        "description": "服务不可用",
+                    "500": {
+                        "description": "服务内部错误",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -3086,9 +7536,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/password-reset/request": {
-            "post": {
-                "description": "发送重置密码邮件",
+        "/conversations/{conversationId}": {
+            "delete": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "删除指定对话",
                 "consumes": [
                     "application/json"
                 ],
@@ -3096,23 +7554,97 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "认证"
+                    "对话"
                 ],
-                "summary": "申请重置密码",
+                "summary": "删除对话",
                 "parameters": [
                     {
-                        "description": "重置请求",
+                        "type": "integer",
+                        "description": "对话ID",
+                        "name": "conversationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "删除成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "对话不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "更新对话标题",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "对话"
+                ],
+                "summary": "更新对话",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "对话ID",
+                        "name": "conversationId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "对话更新数据",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.passwordResetRequest"
+                            "$ref": "#/definitions/handlers.updateConversationRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "请求成功",
+                        "description": "更新成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -3125,15 +7657,22 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "服务内部错误",
+                    "401": {
+                        "description": "未登录",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "503": {
-                        "description": "服务不可用",
+                    "404": {
+                        "description": "对话不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -3142,9 +7681,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/register": {
-            "post": {
-                "description": "使用邮箱密码注册并设置登录 Cookie",
+        "/conversations/{conversationId}/messages": {
+            "get": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "获取对话消息列表",
                 "consumes": [
                     "application/json"
                 ],
@@ -3152,23 +7699,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "认证"
+                    "对话"
                 ],
-                "summary": "用户注册",
+                "summary": "对话消息列表",
                 "parameters": [
                     {
-                        "description": "注册信息",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.registerRequest"
-                        }
+                        "type": "integer",
+                        "description": "对话ID",
+                        "name": "conversationId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "创建成功",
+                    "200": {
+                        "description": "获取成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -3181,8 +7726,15 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "409": {
-                        "description": "邮箱已注册",
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "对话不存在",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -3196,9 +7748,7 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/billing/capture": {
+            },
             "post": {
                 "security": [
                     {
@@ -3208,7 +7758,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "确认扣减预扣余额",
+                "description": "在对话中追加消息",
                 "consumes": [
                     "application/json"
                 ],
@@ -3216,23 +7766,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "计费"
+                    "对话"
                 ],
-                "summary": "扣减余额",
+                "summary": "创建对话消息",
                 "parameters": [
                     {
-                        "description": "扣减信息",
+                        "type": "integer",
+                        "description": "对话ID",
+                        "name": "conversationId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "消息数据",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.billingRequest"
+                            "$ref": "#/definitions/handlers.createMessageRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "扣减成功",
+                    "201": {
+                        "description": "创建成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -3245,15 +7802,15 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "402": {
-                        "description": "余额不足",
+                    "401": {
+                        "description": "未登录",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "409": {
-                        "description": "引用冲突",
+                    "404": {
+                        "description": "对话不存在",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -3269,7 +7826,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/billing/hold": {
+        "/email/enqueue": {
             "post": {
                 "security": [
                     {
@@ -3279,7 +7836,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "预扣用户余额",
+                "description": "批量邮件入队",
                 "consumes": [
                     "application/json"
                 ],
@@ -3287,23 +7844,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "计费"
+                    "邮件"
                 ],
-                "summary": "预扣余额",
+                "summary": "邮件入队",
                 "parameters": [
                     {
-                        "description": "预扣信息",
+                        "description": "邮件列表",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.billingRequest"
+                            "$ref": "#/definitions/handlers.enqueueEmailRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "预扣成功",
+                        "description": "入队成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -3316,15 +7873,8 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "402": {
-                        "description": "余额不足",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "409": {
-                        "description": "引用冲突",
+                    "401": {
+                        "description": "未登录",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -3340,7 +7890,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/billing/release": {
+        "/email/send": {
             "post": {
                 "security": [
                     {
@@ -3350,7 +7900,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "释放已预扣余额",
+                "description": "立即发送邮件",
                 "consumes": [
                     "application/json"
                 ],
@@ -3358,23 +7908,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "计费"
+                    "邮件"
                 ],
-                "summary": "释放预扣",
+                "summary": "发送邮件",
                 "parameters": [
                     {
-                        "description": "释放信息",
+                        "description": "邮件内容",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.billingRequest"
+                            "$ref": "#/definitions/handlers.sendEmailRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "释放成功",
+                        "description": "发送成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -3387,8 +7937,8 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "409": {
-                        "description": "引用冲突",
+                    "401": {
+                        "description": "未登录",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -3404,7 +7954,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/billing/usage": {
+        "/knowledge-bases": {
             "get": {
                 "security": [
                     {
@@ -3414,7 +7964,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取当前用户用量明细",
+                "description": "获取知识库列表",
                 "consumes": [
                     "application/json"
                 ],
@@ -3422,32 +7972,20 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "计费"
+                    "知识库"
                 ],
-                "summary": "用量明细",
+                "summary": "知识库列表",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "每页数量",
-                        "name": "page_size",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
-                        "description": "开始时间（RFC3339）",
-                        "name": "start",
+                        "description": "范围",
+                        "name": "scope",
                         "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "结束时间（RFC3339）",
-                        "name": "end",
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "project_id",
                         "in": "query"
                     }
                 ],
@@ -3481,10 +8019,8 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/billing/wallet": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -3493,7 +8029,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取当前用户钱包与近 24 小时用量",
+                "description": "创建新的知识库",
                 "consumes": [
                     "application/json"
                 ],
@@ -3501,12 +8037,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "计费"
+                    "知识库"
+                ],
+                "summary": "创建知识库",
+                "parameters": [
+                    {
+                        "description": "知识库数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.createKnowledgeBaseRequest"
+                        }
+                    }
                 ],
-                "summary": "获取钱包",
                 "responses": {
-                    "200": {
-                        "description": "获取成功",
+                    "201": {
+                        "description": "创建成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -3519,6 +8073,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "404": {
+                        "description": "项目不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -3529,7 +8090,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/conversations": {
+        "/knowledge-bases/{id}": {
             "get": {
                 "security": [
                     {
@@ -3539,7 +8100,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取独立对话列表",
+                "description": "获取知识库详情",
                 "consumes": [
                     "application/json"
                 ],
@@ -3547,9 +8108,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "对话"
+                    "知识库"
+                ],
+                "summary": "获取知识库",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "知识库ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "独立对话列表",
                 "responses": {
                     "200": {
                         "description": "获取成功",
@@ -3558,6 +8128,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "401": {
                         "description": "未登录",
                         "schema": {
@@ -3565,6 +8142,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "404": {
+                        "description": "知识库不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -3574,7 +8158,7 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
+            "delete": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -3583,7 +8167,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "创建独立对话",
+                "description": "删除指定知识库",
                 "consumes": [
                     "application/json"
                 ],
@@ -3591,23 +8175,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "对话"
+                    "知识库"
                 ],
-                "summary": "创建独立对话",
+                "summary": "删除知识库",
                 "parameters": [
                     {
-                        "description": "对话数据",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.createConversationRequest"
-                        }
+                        "type": "integer",
+                        "description": "知识库ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "创建成功",
+                    "200": {
+                        "description": "删除成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -3627,6 +8209,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "404": {
+                        "description": "知识库不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -3635,10 +8224,8 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/conversations/{conversationId}": {
-            "delete": {
+            },
+            "patch": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -3647,7 +8234,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "删除指定对话",
+                "description": "更新知识库名称或描述",
                 "consumes": [
                     "application/json"
                 ],
@@ -3655,21 +8242,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "对话"
+                    "知识库"
                 ],
-                "summary": "删除对话",
+                "summary": "更新知识库",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "对话ID",
-                        "name": "conversationId",
+                        "description": "知识库ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "知识库更新数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.updateKnowledgeBaseRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "删除成功",
+                    "200": {
+                        "description": "更新成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -3690,7 +8286,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "对话不存在",
+                        "description": "知识库不存在",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -3704,8 +8300,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/knowledge-bases/{id}/documents": {
+            "get": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -3714,7 +8312,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "更新对话标题",
+                "description": "获取指定知识库的文档列表",
                 "consumes": [
                     "application/json"
                 ],
@@ -3722,51 +8320,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "对话"
+                    "知识库文档"
                 ],
-                "summary": "更新对话",
+                "summary": "知识库文档列表",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "对话ID",
-                        "name": "conversationId",
+                        "description": "知识库ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "对话更新数据",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.updateConversationRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "请求错误",
+                        "description": "获取成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "401": {
-                        "description": "未登录",
+                    "400": {
+                        "description": "请求错误",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "对话不存在",
+                    "401": {
+                        "description": "未登录",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -3780,10 +8362,8 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/conversations/{conversationId}/messages": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -3792,29 +8372,36 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取对话消息列表",
+                "description": "上传文件到指定知识库",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "对话"
+                    "知识库文档"
                 ],
-                "summary": "对话消息列表",
+                "summary": "上传知识库文档",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "对话ID",
-                        "name": "conversationId",
+                        "description": "知识库ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "上传文件",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "获取成功",
+                    "201": {
+                        "description": "上传成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -3835,7 +8422,14 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "对话不存在",
+                        "description": "知识库不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "413": {
+                        "description": "文件过大",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -3849,8 +8443,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/knowledge-bases/{id}/documents/{docId}": {
+            "delete": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -3859,7 +8455,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "在对话中追加消息",
+                "description": "删除指定知识库的文档",
                 "consumes": [
                     "application/json"
                 ],
@@ -3867,30 +8463,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "对话"
+                    "知识库文档"
                 ],
-                "summary": "创建对话消息",
+                "summary": "删除知识库文档",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "对话ID",
-                        "name": "conversationId",
+                        "description": "知识库ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "消息数据",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.createMessageRequest"
-                        }
+                        "type": "integer",
+                        "description": "文档ID",
+                        "name": "docId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "创建成功",
+                    "200": {
+                        "description": "删除成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -3911,7 +8505,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "对话不存在",
+                        "description": "文档不存在",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -3927,8 +8521,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/email/enqueue": {
-            "post": {
+        "/knowledge-bases/{id}/documents/{docId}/download": {
+            "get": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -3937,34 +8531,44 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "批量邮件入队",
+                "description": "下载指定知识库的文档",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "邮件"
+                    "知识库文档"
                 ],
-                "summary": "邮件入队",
+                "summary": "下载知识库文档",
                 "parameters": [
                     {
-                        "description": "邮件列表",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.enqueueEmailRequest"
-                        }
+                        "type": "integer",
+                        "description": "知识库ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "文档ID",
+                        "name": "docId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "下载方式",
+                        "name": "disposition",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "入队成功",
+                        "description": "文件流",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -3981,6 +8585,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "404": {
+                        "description": "文档不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -3991,8 +8602,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/email/send": {
-            "post": {
+        "/models": {
+            "get": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -4001,7 +8612,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "立即发送邮件",
+                "description": "获取可用模型列表",
                 "consumes": [
                     "application/json"
                 ],
@@ -4009,30 +8620,20 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "邮件"
+                    "模型"
                 ],
-                "summary": "发送邮件",
+                "summary": "模型列表",
                 "parameters": [
                     {
-                        "description": "邮件内容",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.sendEmailRequest"
-                        }
+                        "type": "string",
+                        "description": "提供商",
+                        "name": "provider",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "发送成功",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "请求错误",
+                        "description": "获取成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -4055,7 +8656,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/knowledge-bases": {
+        "/models/providers": {
             "get": {
                 "security": [
                     {
@@ -4065,7 +8666,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取知识库列表",
+                "description": "获取可用模型提供商",
                 "consumes": [
                     "application/json"
                 ],
@@ -4073,23 +8674,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "知识库"
-                ],
-                "summary": "知识库列表",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "范围",
-                        "name": "scope",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "项目ID",
-                        "name": "project_id",
-                        "in": "query"
-                    }
+                    "模型"
                 ],
+                "summary": "模型提供商列表",
                 "responses": {
                     "200": {
                         "description": "获取成功",
@@ -4098,13 +8685,6 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "请求错误",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
                     "401": {
                         "description": "未登录",
                         "schema": {
@@ -4120,8 +8700,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/orgs": {
+            "get": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -4130,7 +8712,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "创建新的知识库",
+                "description": "获取当前用户所属的组织及角色，第一项为个人组织；请求其他接口时通过 X-Org-Id 请求头切换组织",
                 "consumes": [
                     "application/json"
                 ],
@@ -4138,30 +8720,12 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "知识库"
-                ],
-                "summary": "创建知识库",
-                "parameters": [
-                    {
-                        "description": "知识库数据",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.createKnowledgeBaseRequest"
-                        }
-                    }
+                    "组织"
                 ],
+                "summary": "我的组织",
                 "responses": {
-                    "201": {
-                        "description": "创建成功",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "请求错误",
+                    "200": {
+                        "description": "获取成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -4174,13 +8738,6 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "项目不存在",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -4189,10 +8746,8 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/knowledge-bases/{id}": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -4201,7 +8756,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取知识库详情",
+                "description": "创建团队组织，创建者成为 owner；组织拥有独立的钱包、项目、知识库与套餐订阅",
                 "consumes": [
                     "application/json"
                 ],
@@ -4209,21 +8764,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "知识库"
+                    "组织"
                 ],
-                "summary": "获取知识库",
+                "summary": "创建组织",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "知识库ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "组织信息",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.orgCreateRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "获取成功",
+                    "201": {
+                        "description": "创建成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -4235,16 +8792,9 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
-                    },
-                    "401": {
-                        "description": "未登录",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "404": {
-                        "description": "知识库不存在",
+                    },
+                    "401": {
+                        "description": "未登录",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -4258,8 +8808,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/orgs/{id}": {
+            "get": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -4268,7 +8820,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "删除指定知识库",
+                "description": "获取组织信息及当前用户的角色",
                 "consumes": [
                     "application/json"
                 ],
@@ -4276,13 +8828,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "知识库"
+                    "组织"
                 ],
-                "summary": "删除知识库",
+                "summary": "组织详情",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "知识库ID",
+                        "description": "组织ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -4290,7 +8842,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "删除成功",
+                        "description": "获取成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -4310,8 +8862,15 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "403": {
+                        "description": "不是组织成员",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "404": {
-                        "description": "知识库不存在",
+                        "description": "组织不存在",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -4335,7 +8894,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "更新知识库名称或描述",
+                "description": "修改组织名称或账单邮箱（owner/admin）；账单邮箱为空时账单与催缴邮件发送给 owner",
                 "consumes": [
                     "application/json"
                 ],
@@ -4343,24 +8902,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "知识库"
+                    "组织"
                 ],
-                "summary": "更新知识库",
+                "summary": "更新组织",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "知识库ID",
+                        "description": "组织ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "知识库更新数据",
+                        "description": "组织信息",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.updateKnowledgeBaseRequest"
+                            "$ref": "#/definitions/handlers.orgUpdateRequest"
                         }
                     }
                 ],
@@ -4386,8 +8945,15 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "404": {
-                        "description": "知识库不存在",
+                        "description": "组织不存在",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -4403,7 +8969,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/knowledge-bases/{id}/documents": {
+        "/orgs/{id}/members": {
             "get": {
                 "security": [
                     {
@@ -4413,7 +8979,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取指定知识库的文档列表",
+                "description": "获取组织成员及角色",
                 "consumes": [
                     "application/json"
                 ],
@@ -4421,13 +8987,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "知识库文档"
+                    "组织"
                 ],
-                "summary": "知识库文档列表",
+                "summary": "组织成员",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "知识库ID",
+                        "description": "组织ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -4455,6 +9021,20 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "403": {
+                        "description": "不是组织成员",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "组织不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -4473,36 +9053,38 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "上传文件到指定知识库",
+                "description": "按邮箱添加已注册用户（owner/admin），角色为 owner/admin/member/billing，默认 member；只有 owner 可以添加 owner，个人组织不能添加成员",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "知识库文档"
+                    "组织"
                 ],
-                "summary": "上传知识库文档",
+                "summary": "添加组织成员",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "知识库ID",
+                        "description": "组织ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "file",
-                        "description": "上传文件",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
+                        "description": "成员信息",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.orgMemberCreateRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "上传成功",
+                        "description": "添加成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -4522,15 +9104,22 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "404": {
-                        "description": "知识库不存在",
+                        "description": "组织或用户不存在",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "413": {
-                        "description": "文件过大",
+                    "409": {
+                        "description": "已是成员",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -4546,7 +9135,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/knowledge-bases/{id}/documents/{docId}": {
+        "/orgs/{id}/members/{user_id}": {
             "delete": {
                 "security": [
                     {
@@ -4556,7 +9145,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "删除指定知识库的文档",
+                "description": "移除成员（owner/admin），成员也可以移除自己以退出组织；admin 不能移除 owner，组织至少保留一名 owner",
                 "consumes": [
                     "application/json"
                 ],
@@ -4564,32 +9153,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "知识库文档"
+                    "组织"
                 ],
-                "summary": "删除知识库文档",
+                "summary": "移除组织成员",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "知识库ID",
+                        "description": "组织ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "文档ID",
-                        "name": "docId",
+                        "description": "成员用户ID",
+                        "name": "user_id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "删除成功",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
+                    "204": {
+                        "description": "移除成功"
                     },
                     "400": {
                         "description": "请求错误",
@@ -4605,8 +9190,22 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "404": {
-                        "description": "文档不存在",
+                        "description": "组织或成员不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "不能移除最后一名 owner",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -4620,10 +9219,8 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/knowledge-bases/{id}/documents/{docId}/download": {
-            "get": {
+            },
+            "patch": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -4632,44 +9229,48 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "下载指定知识库的文档",
+                "description": "修改成员角色（owner/admin）；只有 owner 可以授予或变更 owner，组织至少保留一名 owner",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/octet-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "知识库文档"
+                    "组织"
                 ],
-                "summary": "下载知识库文档",
+                "summary": "修改成员角色",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "知识库ID",
+                        "description": "组织ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "文档ID",
-                        "name": "docId",
+                        "description": "成员用户ID",
+                        "name": "user_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "下载方式",
-                        "name": "disposition",
-                        "in": "query"
+                        "description": "角色",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.orgMemberUpdateRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "文件流",
+                        "description": "更新成功",
                         "schema": {
-                            "type": "file"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
@@ -4686,8 +9287,22 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "404": {
-                        "description": "文档不存在",
+                        "description": "组织或成员不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "不能移除最后一名 owner",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -4703,7 +9318,38 @@ const docTemplate = `{
                 }
             }
         },
-        "/models": {
+        "/plans": {
+            "get": {
+                "description": "获取可用套餐列表",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "套餐"
+                ],
+                "summary": "套餐列表",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/projects": {
             "get": {
                 "security": [
                     {
@@ -4713,7 +9359,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取可用模型列表",
+                "description": "获取当前用户的项目列表",
                 "consumes": [
                     "application/json"
                 ],
@@ -4721,20 +9367,74 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "模型"
+                    "项目"
                 ],
-                "summary": "模型列表",
+                "summary": "项目列表",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未登录",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务内部错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "bearerAuth": []
+                    },
+                    {
+                        "cookieAuth": []
+                    }
+                ],
+                "description": "创建新项目",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "项目"
+                ],
+                "summary": "创建项目",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "提供商",
-                        "name": "provider",
-                        "in": "query"
+                        "description": "项目数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.createProjectRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "获取成功",
+                    "201": {
+                        "description": "创建成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -4757,7 +9457,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/models/providers": {
+        "/projects/stats": {
             "get": {
                 "security": [
                     {
@@ -4767,7 +9467,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取可用模型提供商",
+                "description": "获取项目与资料统计",
                 "consumes": [
                     "application/json"
                 ],
@@ -4775,9 +9475,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "模型"
+                    "项目"
                 ],
-                "summary": "模型提供商列表",
+                "summary": "项目统计",
                 "responses": {
                     "200": {
                         "description": "获取成功",
@@ -4803,38 +9503,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/plans": {
-            "get": {
-                "description": "获取可用套餐列表",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "套餐"
-                ],
-                "summary": "套餐列表",
-                "responses": {
-                    "200": {
-                        "description": "获取成功",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "500": {
-                        "description": "服务内部错误",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    }
-                }
-            }
-        },
-        "/projects": {
+        "/projects/{id}": {
             "get": {
                 "security": [
                     {
@@ -4844,7 +9513,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取当前用户的项目列表",
+                "description": "获取指定项目详情",
                 "consumes": [
                     "application/json"
                 ],
@@ -4854,7 +9523,16 @@ const docTemplate = `{
                 "tags": [
                     "项目"
                 ],
-                "summary": "项目列表",
+                "summary": "获取项目详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "获取成功",
@@ -4863,6 +9541,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "401": {
                         "description": "未登录",
                         "schema": {
@@ -4870,6 +9555,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "404": {
+                        "description": "项目不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -4879,7 +9571,7 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
+            "delete": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -4888,7 +9580,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "创建新项目",
+                "description": "删除指定项目",
                 "consumes": [
                     "application/json"
                 ],
@@ -4898,21 +9590,19 @@ const docTemplate = `{
                 "tags": [
                     "项目"
                 ],
-                "summary": "创建项目",
+                "summary": "删除项目",
                 "parameters": [
                     {
-                        "description": "项目数据",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.createProjectRequest"
-                        }
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "创建成功",
+                    "204": {
+                        "description": "删除成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -4932,6 +9622,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "404": {
+                        "description": "项目不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -4940,10 +9637,8 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/projects/stats": {
-            "get": {
+            },
+            "patch": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -4952,7 +9647,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取项目与资料统计",
+                "description": "更新项目名称或描述",
                 "consumes": [
                     "application/json"
                 ],
@@ -4962,10 +9657,35 @@ const docTemplate = `{
                 "tags": [
                     "项目"
                 ],
-                "summary": "项目统计",
+                "summary": "更新项目",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "项目更新数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.updateProjectRequest"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "获取成功",
+                        "description": "更新成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -4978,6 +9698,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "404": {
+                        "description": "项目不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "服务内部错误",
                         "schema": {
@@ -4988,7 +9715,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/projects/{id}": {
+        "/projects/{id}/budget": {
             "get": {
                 "security": [
                     {
@@ -4998,7 +9725,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "获取指定项目详情",
+                "description": "获取项目本周期的预算额度、已用与剩余金额；已用为周期内项目所有成员的用量",
                 "consumes": [
                     "application/json"
                 ],
@@ -5006,9 +9733,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "项目"
+                    "项目预算"
                 ],
-                "summary": "获取项目详情",
+                "summary": "项目预算",
                 "parameters": [
                     {
                         "type": "integer",
@@ -5041,7 +9768,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "项目不存在",
+                        "description": "项目或预算不存在",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -5056,7 +9783,7 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
+            "put": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -5065,7 +9792,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "删除指定项目",
+                "description": "设置项目每个周期可从组织钱包花费的额度（owner/admin/billing）。cycle 为 daily/weekly/monthly，默认 monthly；enforcement 为 hard（用完后拒绝请求）或 soft（只在响应头 X-Project-Budget-Exceeded 中提示），默认 hard；currency 为空时使用钱包币种",
                 "consumes": [
                     "application/json"
                 ],
@@ -5073,9 +9800,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "项目"
+                    "项目预算"
                 ],
-                "summary": "删除项目",
+                "summary": "设置项目预算",
                 "parameters": [
                     {
                         "type": "integer",
@@ -5083,11 +9810,20 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "预算数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.projectBudgetRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "删除成功",
+                    "200": {
+                        "description": "设置成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -5107,6 +9843,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "404": {
                         "description": "项目不存在",
                         "schema": {
@@ -5123,7 +9866,7 @@ const docTemplate = `{
                     }
                 }
             },
-            "patch": {
+            "delete": {
                 "security": [
                     {
                         "bearerAuth": []
@@ -5132,7 +9875,7 @@ const docTemplate = `{
                         "cookieAuth": []
                     }
                 ],
-                "description": "更新项目名称或描述",
+                "description": "删除项目预算（owner/admin/billing），删除后项目不再单独限额",
                 "consumes": [
                     "application/json"
                 ],
@@ -5140,9 +9883,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "项目"
+                    "项目预算"
                 ],
-                "summary": "更新项目",
+                "summary": "删除项目预算",
                 "parameters": [
                     {
                         "type": "integer",
@@ -5150,24 +9893,11 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "项目更新数据",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.updateProjectRequest"
-                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "更新成功",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
+                    "204": {
+                        "description": "删除成功"
                     },
                     "400": {
                         "description": "请求错误",
@@ -5183,8 +9913,15 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "403": {
+                        "description": "无权限",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "404": {
-                        "description": "项目不存在",
+                        "description": "项目或预算不存在",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -6514,7 +11251,7 @@ const docTemplate = `{
                         }
                     },
                     "402": {
-                        "description": "余额不足",
+                        "description": "余额不足或项目预算已用完",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -6588,7 +11325,7 @@ const docTemplate = `{
                         }
                     },
                     "402": {
-                        "description": "余额不足",
+                        "description": "余额不足或项目预算已用完",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -6662,7 +11399,7 @@ const docTemplate = `{
                         }
                     },
                     "402": {
-                        "description": "余额不足",
+                        "description": "余额不足或项目预算已用完",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -6736,7 +11473,7 @@ const docTemplate = `{
                         }
                     },
                     "402": {
-                        "description": "余额不足",
+                        "description": "余额不足或项目预算已用完",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -6810,7 +11547,7 @@ const docTemplate = `{
                         }
                     },
                     "402": {
-                        "description": "余额不足",
+                        "description": "余额不足或项目预算已用完",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -6832,9 +11569,79 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
+        }
+    },
+    "definitions": {
+        "handlers.adminAdjustmentRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "ref_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type 为 credit（增加余额）或 debit（扣减余额）。",
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.adminAdjustmentReviewRequest": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.adminFXRateRequest": {
+            "type": "object",
+            "properties": {
+                "base_currency": {
+                    "type": "string"
+                },
+                "quote_currency": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "number"
+                }
+            }
+        },
+        "handlers.adminRefundRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "ref_id": {
+                    "description": "RefID 为被退款的扣款引用ID，RefundID 为本次退款的幂等键。",
+                    "type": "string"
+                },
+                "refund_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "handlers.adminTopUpRequest": {
             "type": "object",
             "properties": {
@@ -6856,12 +11663,90 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.adminVoucherCreateRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "code": {
+                    "description": "Code 为空时自动生成；指定 Code 时 Count 只能为 1。",
+                    "type": "string"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "credit_days": {
+                    "description": "CreditDays 为兑换后额度的有效天数，0 表示仅受 ExpiresAt 限制。",
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "max_redemptions": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.adminVoucherUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "max_redemptions": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.adminWalletCreditLimitRequest": {
+            "type": "object",
+            "properties": {
+                "credit_limit": {
+                    "description": "CreditLimit 以钱包币种计，0 表示预付费。",
+                    "type": "number"
+                }
+            }
+        },
+        "handlers.adminWalletCurrencyRequest": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.adminWalletStatusRequest": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "description": "Status 为 active 或 suspended。",
+                    "type": "string"
+                }
+            }
+        },
         "handlers.billingRequest": {
             "type": "object",
             "properties": {
                 "amount": {
                     "type": "number"
                 },
+                "currency": {
+                    "description": "Currency 为空表示与钱包币种相同，否则按当前汇率换算为钱包币种。",
+                    "type": "string"
+                },
                 "metadata": {
                     "type": "object",
                     "additionalProperties": {}
@@ -6919,6 +11804,14 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.changePlanRequest": {
+            "type": "object",
+            "properties": {
+                "plan_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "handlers.createConversationRequest": {
             "type": "object",
             "properties": {
@@ -7239,6 +12132,44 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.orgCreateRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.orgMemberCreateRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.orgMemberUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.orgUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "billing_email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
         "handlers.passwordResetConfirmRequest": {
             "type": "object",
             "properties": {
@@ -7258,12 +12189,108 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.pipelineChainCreateRequest": {
+            "type": "object",
+            "properties": {
+                "model": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "scope": {
+                    "type": "string"
+                },
+                "stage": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.pipelineChainUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "model": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "scope": {
+                    "type": "string"
+                },
+                "stage": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.planAllowanceRequest": {
+            "type": "object",
+            "properties": {
+                "included_requests": {
+                    "type": "integer"
+                },
+                "included_tokens": {
+                    "type": "integer"
+                },
+                "match": {
+                    "type": "string"
+                },
+                "rollover_requests": {
+                    "type": "integer"
+                },
+                "rollover_tokens": {
+                    "type": "integer"
+                },
+                "scope": {
+                    "type": "string"
+                }
+            }
+        },
         "handlers.planCreateRequest": {
             "type": "object",
             "properties": {
+                "allowances": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.planAllowanceRequest"
+                    }
+                },
                 "currency": {
                     "type": "string"
                 },
+                "grace_period_days": {
+                    "type": "integer"
+                },
                 "included_requests": {
                     "type": "integer"
                 },
@@ -7273,12 +12300,27 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
+                "overage_multiplier": {
+                    "type": "number"
+                },
                 "price": {
                     "type": "number"
                 },
+                "quota_enforcement": {
+                    "type": "string"
+                },
                 "reset_interval_days": {
                     "type": "integer"
                 },
+                "rollover_days": {
+                    "type": "integer"
+                },
+                "rollover_requests": {
+                    "type": "integer"
+                },
+                "rollover_tokens": {
+                    "type": "integer"
+                },
                 "status": {
                     "type": "string"
                 }
@@ -7287,9 +12329,18 @@ const docTemplate = `{
         "handlers.planUpdateRequest": {
             "type": "object",
             "properties": {
+                "allowances": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.planAllowanceRequest"
+                    }
+                },
                 "currency": {
                     "type": "string"
                 },
+                "grace_period_days": {
+                    "type": "integer"
+                },
                 "included_requests": {
                     "type": "integer"
                 },
@@ -7299,17 +12350,49 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
+                "overage_multiplier": {
+                    "type": "number"
+                },
                 "price": {
                     "type": "number"
                 },
+                "quota_enforcement": {
+                    "type": "string"
+                },
                 "reset_interval_days": {
                     "type": "integer"
                 },
+                "rollover_days": {
+                    "type": "integer"
+                },
+                "rollover_requests": {
+                    "type": "integer"
+                },
+                "rollover_tokens": {
+                    "type": "integer"
+                },
                 "status": {
                     "type": "string"
                 }
             }
         },
+        "handlers.projectBudgetRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "cycle": {
+                    "type": "string"
+                },
+                "enforcement": {
+                    "type": "string"
+                }
+            }
+        },
         "handlers.rateLimitCreateRequest": {
             "type": "object",
             "properties": {
@@ -7443,6 +12526,17 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.subscribeRequest": {
+            "type": "object",
+            "properties": {
+                "auto_renew": {
+                    "type": "boolean"
+                },
+                "plan_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "handlers.subscriptionCreateRequest": {
             "type": "object",
             "properties": {
@@ -7477,6 +12571,14 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.topUpCheckoutRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                }
+            }
+        },
         "handlers.updateConversationRequest": {
             "type": "object",
             "properties": {
@@ -7624,6 +12726,14 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.voucherRedeemRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
         "newapi.UpstreamModel": {
             "type": "object",
             "properties": {
@@ -7,23 +7,35 @@ import (
 	"deepspace/internal/api"
 	"deepspace/internal/api/middleware"
 	"deepspace/internal/config"
+	"deepspace/internal/integrations/newapi"
+	"deepspace/internal/integrations/payment"
+	"deepspace/internal/pipeline"
+	"deepspace/internal/pipeline/steps"
 	"deepspace/internal/pkg/db"
 	"deepspace/internal/repo"
+	"deepspace/internal/service/adjustment"
 	"deepspace/internal/service/auth"
 	"deepspace/internal/service/billing"
 	"deepspace/internal/service/chat"
 	"deepspace/internal/service/email"
+	"deepspace/internal/service/fx"
+	"deepspace/internal/service/invoice"
 	"deepspace/internal/service/knowledge"
 	modelservice "deepspace/internal/service/model"
+	"deepspace/internal/service/org"
 	"deepspace/internal/service/passwordreset"
+	"deepspace/internal/service/pipelinechain"
 	planservice "deepspace/internal/service/plan"
 	"deepspace/internal/service/project"
+	"deepspace/internal/service/projectbudget"
 	"deepspace/internal/service/projectdocument"
 	"deepspace/internal/service/projectskill"
 	"deepspace/internal/service/projectworkflow"
 	"deepspace/internal/service/risk"
+	"deepspace/internal/service/topup"
 	"deepspace/internal/service/usage"
 	"deepspace/internal/service/user"
+	"deepspace/internal/service/voucher"
 	"strings"
 
 	"github.com/gin-contrib/cors"
@@ -55,10 +67,36 @@ func main() {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
+	fxRateRepo := repo.NewFXRateRepo(dbConn)
+	fxService := fx.New(fxRateRepo)
 	billingRepo := repo.NewBillingRepo(dbConn)
-	billingService := billing.New(dbConn, billingRepo)
+	billingService := billing.New(dbConn, billingRepo, fxService, cfg.BillingDefaultCurrency)
+	paymentProvider, err := payment.New(cfg.PaymentProvider, payment.Options{
+		WebhookSecret:      cfg.PaymentWebhookSecret,
+		CheckoutBaseURL:    cfg.PaymentCheckoutBaseURL,
+		SignatureTolerance: cfg.PaymentWebhookTolerance,
+	})
+	if err != nil {
+		log.Fatalf("Failed to init payment provider: %v", err)
+	}
+	topUpMin, topUpMax, _ := cfg.PaymentTopUpLimits()
+	topUpOrderRepo := repo.NewTopUpOrderRepo(dbConn)
+	topUpService := topup.New(topUpOrderRepo, billingService, paymentProvider, topup.Options{
+		MinAmount: topUpMin,
+		MaxAmount: topUpMax,
+	})
+	approvalThreshold, _ := cfg.BillingApprovalThreshold()
+	auditLogRepo := repo.NewAuditLogRepo(dbConn)
+	adjustmentRepo := repo.NewBillingAdjustmentRepo(dbConn)
+	adjustmentService := adjustment.New(dbConn, adjustmentRepo, auditLogRepo, billingService, fxService, adjustment.Options{
+		ApprovalThreshold: approvalThreshold,
+		ThresholdCurrency: cfg.BillingDefaultCurrency,
+	})
+	voucherRepo := repo.NewVoucherRepo(dbConn)
+	voucherService := voucher.New(dbConn, voucherRepo, billingService)
 	usageRepo := repo.NewUsageRepo(dbConn)
-	usageService := usage.New(usageRepo)
+	usageRollupRepo := repo.NewUsageRollupRepo(dbConn)
+	usageService := usage.New(usageRepo, usageRollupRepo)
 	projectRepo := repo.NewProjectRepo(dbConn)
 	projectService := project.New(projectRepo)
 	projectDocumentRepo := repo.NewProjectDocumentRepo(dbConn)
@@ -81,6 +119,8 @@ func main() {
 	}
 	userAuthService := auth.NewUserAuthService(userRepo, jwtManager)
 	userService := user.New(userRepo, userProfileRepo, userSettingsRepo)
+	orgRepo := repo.NewOrgRepo(dbConn)
+	orgService := org.New(dbConn, orgRepo, userRepo)
 	knowledgeRepo := repo.NewKnowledgeRepo(dbConn)
 	knowledgeService := knowledge.New(knowledgeRepo, projectRepo, cfg.KBStoragePath, cfg.KBMaxUploadBytes(), cfg.KBAllowedMIME)
 	modelRepo := repo.NewModelRepo(dbConn)
@@ -88,12 +128,44 @@ func main() {
 	planRepo := repo.NewPlanRepo(dbConn)
 	planSubscriptionRepo := repo.NewPlanSubscriptionRepo(dbConn)
 	planUsageRepo := repo.NewPlanUsageRepo(dbConn)
-	planService := planservice.New(planRepo, planSubscriptionRepo, planUsageRepo)
+	planService := planservice.New(dbConn, planRepo, planSubscriptionRepo, planUsageRepo, billingService)
 	riskPolicyRepo := repo.NewRiskPolicyRepo(dbConn)
 	riskRateRepo := repo.NewRateLimitRepo(dbConn)
 	riskIPRepo := repo.NewIPRuleRepo(dbConn)
 	riskBudgetRepo := repo.NewBudgetCapRepo(dbConn)
 	riskService := risk.New(riskPolicyRepo, riskRateRepo, riskIPRepo, riskBudgetRepo)
+	projectBudgetRepo := repo.NewProjectBudgetRepo(dbConn)
+	projectBudgetService := projectbudget.New(projectBudgetRepo, projectRepo, usageService, fxService, billingService)
+	// NewAPI 上游池，由 newapi_call 步骤与管理端上游接口共用
+	upstreams, _ := cfg.NewAPIUpstreams()
+	upstreamOptions := make([]newapi.UpstreamOptions, 0, len(upstreams))
+	for _, item := range upstreams {
+		upstreamOptions = append(upstreamOptions, newapi.UpstreamOptions{
+			Name:    item.Name,
+			BaseURL: item.BaseURL,
+			APIKey:  item.APIKey,
+			Weight:  item.Weight,
+			Models:  item.Models,
+		})
+	}
+	upstreamPool := newapi.NewPool(upstreamOptions, newapi.BreakerOptions{
+		FailureThreshold: cfg.NewAPIBreakerFailures,
+		Cooldown:         cfg.NewAPIBreakerCooldown,
+	})
+	stepRegistry := pipeline.NewRegistry()
+	stepRegistry.MustRegister(
+		steps.NewAuth(),
+		steps.NewPolicy(riskService, usageService, fxService, projectBudgetService, planService),
+		steps.NewBudgetHold(billingService, cfg.BillingEstimateOutputTokens),
+		steps.NewNewAPICall(upstreamPool, steps.RetryPolicy{
+			MaxRetries: cfg.NewAPIMaxRetries,
+			BaseDelay:  cfg.NewAPIRetryBaseDelay,
+			MaxDelay:   cfg.NewAPIRetryMaxDelay,
+		}),
+		steps.NewUsageCapture(billingService, usageService, planService),
+	)
+	pipelineChainRepo := repo.NewPipelineChainRepo(dbConn)
+	pipelineChainService := pipelinechain.New(pipelineChainRepo, stepRegistry)
 	emailService, err := email.New(cfg)
 	if err != nil {
 		log.Fatalf("Failed to init email service: %v", err)
@@ -102,6 +174,8 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to init password reset service: %v", err)
 	}
+	invoiceRepo := repo.NewInvoiceRepo(dbConn)
+	invoiceService := invoice.New(invoiceRepo, emailService)
 
 	r := gin.New()
 	r.Use(gin.Recovery())
@@ -128,7 +202,7 @@ func main() {
 	r.Use(cors.Default())
 
 	// Setup Routes
-	api.SetupRoutes(r, cfg, billingService, usageService, projectService, chatService, emailService, knowledgeService, modelService, planService, projectDocumentService, projectSkillService, projectWorkflowService, userAuthService, passwordResetService, userService, riskService, jwtManager)
+	api.SetupRoutes(r, cfg, billingService, fxService, adjustmentService, topUpService, voucherService, invoiceService, usageService, projectService, chatService, emailService, knowledgeService, modelService, planService, projectDocumentService, projectSkillService, projectWorkflowService, projectBudgetService, userAuthService, passwordResetService, userService, orgService, riskService, pipelineChainService, upstreamPool, jwtManager)
 
 	log.Printf("Gateway running on port %s", cfg.Port)
 	if err := r.Run(":" + cfg.Port); err != nil {
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"deepspace-shared/money"
+	"deepspace/internal/service/adjustment"
+	"deepspace/internal/service/billing"
+	"deepspace/internal/service/fx"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminAdjustmentHandler struct {
+	svc *adjustment.Service
+}
+
+func NewAdminAdjustmentHandler(svc *adjustment.Service) *AdminAdjustmentHandler {
+	return &AdminAdjustmentHandler{svc: svc}
+}
+
+type adminRefundRequest struct {
+	UserID int64 `json:"user_id"`
+	// RefID 为被退款的扣款引用ID，RefundID 为本次退款的幂等键。
+	RefID    string       `json:"ref_id"`
+	RefundID string       `json:"refund_id"`
+	Amount   money.Amount `json:"amount" swaggertype:"number"`
+	Currency string       `json:"currency"`
+	Reason   string       `json:"reason"`
+}
+
+type adminAdjustmentRequest struct {
+	UserID int64 `json:"user_id"`
+	// Type 为 credit（增加余额）或 debit（扣减余额）。
+	Type     string       `json:"type"`
+	Amount   money.Amount `json:"amount" swaggertype:"number"`
+	Currency string       `json:"currency"`
+	Reason   string       `json:"reason"`
+	RefID    string       `json:"ref_id"`
+}
+
+type adminAdjustmentReviewRequest struct {
+	Note string `json:"note"`
+}
+
+// Refund godoc
+// @Summary 管理员：退款
+// @Description 把指定扣款 ref 的已扣金额部分或全部退回余额，累计退款不超过已扣款金额；操作写入审计日志
+// @Tags 管理-计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param data body adminRefundRequest true "退款信息"
+// @Success 200 {object} map[string]interface{} "退款成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 404 {object} map[string]interface{} "扣款不存在"
+// @Failure 409 {object} map[string]interface{} "引用冲突"
+// @Failure 422 {object} map[string]interface{} "超过可退金额或缺少汇率"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/billing/refunds [post]
+func (h *AdminAdjustmentHandler) Refund(c *gin.Context) {
+	if h == nil || h.svc == nil {
+		respondInternal(c, "调账服务未配置")
+		return
+	}
+
+	var req adminRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数不正确"})
+		return
+	}
+	if req.UserID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "用户ID不正确"})
+		return
+	}
+	adminID, _ := getUserID(c)
+
+	result, err := h.svc.Refund(c.Request.Context(), adjustment.RefundInput{
+		UserID:   req.UserID,
+		RefID:    req.RefID,
+		RefundID: req.RefundID,
+		Amount:   req.Amount,
+		Currency: req.Currency,
+		Reason:   req.Reason,
+		AdminID:  adminID,
+		TraceID:  getTraceID(c),
+	})
+	if err != nil {
+		handleAdjustmentError(c, err, "退款失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Adjustments godoc
+// @Summary 管理员：调账申请列表
+// @Description 获取人工调账申请
+// @Tags 管理-计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param user_id query int false "组织ID"
+// @Param status query string false "状态（pending/applied/rejected）"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/billing/adjustments [get]
+func (h *AdminAdjustmentHandler) Adjustments(c *gin.Context) {
+	if h == nil || h.svc == nil {
+		respondInternal(c, "调账服务未配置")
+		return
+	}
+
+	userID, err := parseOptionalInt64(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "用户ID不正确"})
+		return
+	}
+
+	page := parseIntQueryAdmin(c, "page", 1)
+	pageSize := parseIntQueryAdmin(c, "page_size", 20)
+
+	items, total, err := h.svc.List(c.Request.Context(), adjustment.ListInput{
+		UserID:   userID,
+		Status:   c.Query("status"),
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		respondInternal(c, "获取调账申请失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":     items,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
+
+// CreateAdjustment godoc
+// @Summary 管理员：人工调账
+// @Description 增加或扣减用户余额并记录原因；金额超过审批阈值时保存为待审批，由另一名管理员批准后入账
+// @Tags 管理-计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param data body adminAdjustmentRequest true "调账信息"
+// @Success 200 {object} map[string]interface{} "已入账"
+// @Success 202 {object} map[string]interface{} "待审批"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 402 {object} map[string]interface{} "余额不足"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 409 {object} map[string]interface{} "引用冲突"
+// @Failure 422 {object} map[string]interface{} "缺少汇率"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/billing/adjustments [post]
+func (h *AdminAdjustmentHandler) CreateAdjustment(c *gin.Context) {
+	if h == nil || h.svc == nil {
+		respondInternal(c, "调账服务未配置")
+		return
+	}
+
+	var req adminAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数不正确"})
+		return
+	}
+	if req.UserID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "用户ID不正确"})
+		return
+	}
+	adminID, _ := getUserID(c)
+
+	result, err := h.svc.Request(c.Request.Context(), adjustment.RequestInput{
+		UserID:   req.UserID,
+		Type:     req.Type,
+		Amount:   req.Amount,
+		Currency: req.Currency,
+		Reason:   req.Reason,
+		RefID:    strings.TrimSpace(req.RefID),
+		AdminID:  adminID,
+		TraceID:  getTraceID(c),
+	})
+	if err != nil {
+		handleAdjustmentError(c, err, "调账失败")
+		return
+	}
+
+	status := http.StatusOK
+	if result.Adjustment.Status == adjustment.StatusPending {
+		status = http.StatusAccepted
+	}
+	c.JSON(status, result)
+}
+
+// ApproveAdjustment godoc
+// @Summary 管理员：批准调账
+// @Description 批准待审批的调账并入账，审批人不能是申请人
+// @Tags 管理-计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "调账申请ID"
+// @Param data body adminAdjustmentReviewRequest false "审批备注"
+// @Success 200 {object} map[string]interface{} "已入账"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 402 {object} map[string]interface{} "余额不足"
+// @Failure 403 {object} map[string]interface{} "无权限或不能审批自己的申请"
+// @Failure 404 {object} map[string]interface{} "调账申请不存在"
+// @Failure 409 {object} map[string]interface{} "申请已处理"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/billing/adjustments/{id}/approve [post]
+func (h *AdminAdjustmentHandler) ApproveAdjustment(c *gin.Context) {
+	h.review(c, (*adjustment.Service).Approve, "审批调账失败")
+}
+
+// RejectAdjustment godoc
+// @Summary 管理员：驳回调账
+// @Description 驳回待审批的调账申请
+// @Tags 管理-计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "调账申请ID"
+// @Param data body adminAdjustmentReviewRequest false "驳回原因"
+// @Success 200 {object} map[string]interface{} "已驳回"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 404 {object} map[string]interface{} "调账申请不存在"
+// @Failure 409 {object} map[string]interface{} "申请已处理"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/billing/adjustments/{id}/reject [post]
+func (h *AdminAdjustmentHandler) RejectAdjustment(c *gin.Context) {
+	h.review(c, (*adjustment.Service).Reject, "驳回调账失败")
+}
+
+func (h *AdminAdjustmentHandler) review(c *gin.Context, op func(svc *adjustment.Service, ctx context.Context, id int64, input adjustment.ReviewInput) (*adjustment.AdjustmentResult, error), fallback string) {
+	if h == nil || h.svc == nil {
+		respondInternal(c, "调账服务未配置")
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "调账申请ID不正确"})
+		return
+	}
+	var req adminAdjustmentReviewRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数不正确"})
+			return
+		}
+	}
+	adminID, _ := getUserID(c)
+
+	result, err := op(h.svc, c.Request.Context(), id, adjustment.ReviewInput{
+		AdminID: adminID,
+		Note:    req.Note,
+		TraceID: getTraceID(c),
+	})
+	if err != nil {
+		handleAdjustmentError(c, err, fallback)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func handleAdjustmentError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case adjustment.ErrInvalidType:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "调账类型不正确"})
+	case adjustment.ErrInvalidAmount, billing.ErrInvalidAmount:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "金额不正确"})
+	case adjustment.ErrReasonRequired:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "原因不能为空"})
+	case adjustment.ErrRefIDRequired:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ref_id 与 refund_id 不能为空"})
+	case adjustment.ErrRefConflict, billing.ErrRefConflict:
+		c.JSON(http.StatusConflict, gin.H{"error": "ref_id 冲突"})
+	case adjustment.ErrAdjustmentNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "调账申请不存在"})
+	case adjustment.ErrAdjustmentNotPending:
+		c.JSON(http.StatusConflict, gin.H{"error": "调账申请已处理"})
+	case adjustment.ErrSelfApproval:
+		c.JSON(http.StatusForbidden, gin.H{"error": "不能审批自己发起的调账"})
+	case billing.ErrHoldNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "扣款不存在"})
+	case billing.ErrRefundExceedsCapture:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "退款金额超过可退金额"})
+	case billing.ErrInsufficientBalance:
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": "余额不足"})
+	case fx.ErrInvalidCurrency:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "币种不正确"})
+	case fx.ErrRateNotFound:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "未配置对应汇率"})
+	default:
+		respondInternal(c, fallback)
+	}
+}
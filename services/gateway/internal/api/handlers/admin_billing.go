@@ -7,7 +7,9 @@ import (
 	"strings"
 	"time"
 
+	"deepspace-shared/money"
 	"deepspace/internal/service/billing"
+	"deepspace/internal/service/fx"
 	"deepspace/internal/service/usage"
 
 	"github.com/gin-gonic/gin"
@@ -16,10 +18,11 @@ import (
 type AdminBillingHandler struct {
 	billingSvc *billing.Service
 	usageSvc   *usage.Service
+	fxSvc      *fx.Service
 }
 
-func NewAdminBillingHandler(billingSvc *billing.Service, usageSvc *usage.Service) *AdminBillingHandler {
-	return &AdminBillingHandler{billingSvc: billingSvc, usageSvc: usageSvc}
+func NewAdminBillingHandler(billingSvc *billing.Service, usageSvc *usage.Service, fxSvc *fx.Service) *AdminBillingHandler {
+	return &AdminBillingHandler{billingSvc: billingSvc, usageSvc: usageSvc, fxSvc: fxSvc}
 }
 
 type adminWalletUser struct {
@@ -30,30 +33,63 @@ type adminWalletUser struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type adminWalletOrg struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Personal bool   `json:"personal"`
+}
+
+// adminWalletItem 的 User 为钱包所属组织的 owner。
 type adminWalletItem struct {
+	Org           adminWalletOrg  `json:"org"`
 	User          adminWalletUser `json:"user"`
-	Balance       float64         `json:"balance"`
-	FrozenBalance float64         `json:"frozen_balance"`
+	Balance       money.Amount    `json:"balance" swaggertype:"number"`
+	FrozenBalance money.Amount    `json:"frozen_balance" swaggertype:"number"`
+	Currency      string          `json:"currency"`
+	CreditLimit   money.Amount    `json:"credit_limit" swaggertype:"number"`
+	SuspendedAt   *time.Time      `json:"suspended_at"`
+	SuspendReason string          `json:"suspend_reason"`
+	DunningStage  int             `json:"dunning_stage"`
 	UpdatedAt     time.Time       `json:"updated_at"`
 }
 
 type adminTopUpRequest struct {
 	UserID   int64          `json:"user_id"`
-	Amount   float64        `json:"amount"`
+	Amount   money.Amount   `json:"amount" swaggertype:"number"`
 	Currency string         `json:"currency"`
 	RefID    string         `json:"ref_id"`
 	Metadata map[string]any `json:"metadata"`
 }
 
+type adminWalletCurrencyRequest struct {
+	Currency string `json:"currency"`
+}
+
+type adminWalletCreditLimitRequest struct {
+	// CreditLimit 以钱包币种计，0 表示预付费。
+	CreditLimit money.Amount `json:"credit_limit" swaggertype:"number"`
+}
+
+type adminWalletStatusRequest struct {
+	// Status 为 active 或 suspended。
+	Status string `json:"status"`
+}
+
+type adminFXRateRequest struct {
+	BaseCurrency  string     `json:"base_currency"`
+	QuoteCurrency string     `json:"quote_currency"`
+	Rate          money.Rate `json:"rate" swaggertype:"number"`
+}
+
 // Wallets godoc
 // @Summary 管理员：钱包列表
-// @Description 获取钱包列表
+// @Description 获取钱包列表；钱包归属组织，user_id 为组织ID
 // @Tags 管理-计费
 // @Accept json
 // @Produce json
 // @Security bearerAuth
 // @Security cookieAuth
-// @Param user_id query int false "用户ID"
+// @Param user_id query int false "组织ID"
 // @Param page query int false "页码"
 // @Param page_size query int false "每页数量"
 // @Success 200 {object} map[string]interface{} "获取成功"
@@ -90,8 +126,13 @@ func (h *AdminBillingHandler) Wallets(c *gin.Context) {
 	result := make([]adminWalletItem, 0, len(items))
 	for _, item := range items {
 		result = append(result, adminWalletItem{
+			Org: adminWalletOrg{
+				ID:       item.UserID,
+				Name:     item.OrgName,
+				Personal: item.OrgPersonal,
+			},
 			User: adminWalletUser{
-				ID:        item.UserID,
+				ID:        item.OwnerID,
 				Email:     item.Email,
 				Status:    item.Status,
 				Role:      item.Role,
@@ -99,6 +140,11 @@ func (h *AdminBillingHandler) Wallets(c *gin.Context) {
 			},
 			Balance:       item.Balance,
 			FrozenBalance: item.FrozenBalance,
+			Currency:      item.Currency,
+			CreditLimit:   item.CreditLimit,
+			SuspendedAt:   item.SuspendedAt,
+			SuspendReason: item.SuspendReason,
+			DunningStage:  item.DunningStage,
 			UpdatedAt:     item.UpdatedAt,
 		})
 	}
@@ -119,8 +165,10 @@ func (h *AdminBillingHandler) Wallets(c *gin.Context) {
 // @Produce json
 // @Security bearerAuth
 // @Security cookieAuth
-// @Param user_id query int false "用户ID"
-// @Param type query string false "类型（hold/capture/release）"
+// @Param user_id query int false "组织ID"
+// @Param type query string false "类型，多个以逗号分隔（hold/capture/release/expire/topup/voucher/voucher_expire/refund/manual_credit/manual_debit/adjustment）"
+// @Param ref_id query string false "引用ID"
+// @Param parent_ref_id query string false "被退款的扣款引用ID"
 // @Param start query string false "开始时间（RFC3339）"
 // @Param end query string false "结束时间（RFC3339）"
 // @Param page query int false "页码"
@@ -149,17 +197,31 @@ func (h *AdminBillingHandler) Transactions(c *gin.Context) {
 		return
 	}
 
+	var types []string
+	for _, value := range strings.Split(c.Query("type"), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !billing.IsTransactionType(value) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "类型不正确"})
+			return
+		}
+		types = append(types, value)
+	}
+
 	page := parseIntQueryAdmin(c, "page", 1)
 	pageSize := parseIntQueryAdmin(c, "page_size", 20)
-	typeFilter := strings.TrimSpace(c.Query("type"))
 
 	items, total, err := h.billingSvc.ListTransactions(c.Request.Context(), billing.TransactionListInput{
-		UserID:   userID,
-		Type:     typeFilter,
-		Start:    start,
-		End:      end,
-		Page:     page,
-		PageSize: pageSize,
+		UserID:      userID,
+		Types:       types,
+		RefID:       strings.TrimSpace(c.Query("ref_id")),
+		ParentRefID: strings.TrimSpace(c.Query("parent_ref_id")),
+		Start:       start,
+		End:         end,
+		Page:        page,
+		PageSize:    pageSize,
 	})
 	if err != nil {
 		respondInternal(c, "获取流水失败")
@@ -174,15 +236,105 @@ func (h *AdminBillingHandler) Transactions(c *gin.Context) {
 	})
 }
 
+// Event godoc
+// @Summary 管理员：计费事件
+// @Description 按 ref_id 获取关联的预扣、扣款与释放流水，按组织分组
+// @Tags 管理-计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param ref_id path string true "引用ID"
+// @Param user_id query int false "组织ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/billing/events/{ref_id} [get]
+func (h *AdminBillingHandler) Event(c *gin.Context) {
+	if h == nil || h.billingSvc == nil {
+		respondInternal(c, "计费服务未配置")
+		return
+	}
+
+	userID, err := parseOptionalInt64(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "用户ID不正确"})
+		return
+	}
+
+	items, err := h.billingSvc.ListBillingEvents(c.Request.Context(), c.Param("ref_id"), userID)
+	if err != nil {
+		respondInternal(c, "获取计费事件失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// Reclaimed godoc
+// @Summary 管理员：过期预扣回收报告
+// @Description 获取 Worker 自动释放的过期预扣流水及按币种汇总的回收总额
+// @Tags 管理-计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param user_id query int false "组织ID"
+// @Param start query string false "开始时间（RFC3339）"
+// @Param end query string false "结束时间（RFC3339）"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/billing/reclaimed [get]
+func (h *AdminBillingHandler) Reclaimed(c *gin.Context) {
+	if h == nil || h.billingSvc == nil {
+		respondInternal(c, "计费服务未配置")
+		return
+	}
+
+	userID, err := parseOptionalInt64(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "用户ID不正确"})
+		return
+	}
+
+	start, end, err := parseTimeRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "时间范围不正确"})
+		return
+	}
+
+	report, err := h.billingSvc.ListReclaimed(c.Request.Context(), billing.TransactionListInput{
+		UserID:   userID,
+		Start:    start,
+		End:      end,
+		Page:     parseIntQueryAdmin(c, "page", 1),
+		PageSize: parseIntQueryAdmin(c, "page_size", 20),
+	})
+	if err != nil {
+		respondInternal(c, "获取回收报告失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
 // Usage godoc
 // @Summary 管理员：用量记录
-// @Description 获取用量记录
+// @Description 获取用量记录，含请求路径、状态码、错误原因、是否流式、上游、上游耗时、首 token 耗时及凭证类型与 User-Agent；失败请求费用为 0
 // @Tags 管理-计费
 // @Accept json
 // @Produce json
 // @Security bearerAuth
 // @Security cookieAuth
-// @Param user_id query int false "用户ID"
+// @Param user_id query int false "发起请求的用户ID"
+// @Param org_id query int false "付费组织ID"
 // @Param start query string false "开始时间（RFC3339）"
 // @Param end query string false "结束时间（RFC3339）"
 // @Param page query int false "页码"
@@ -204,6 +356,11 @@ func (h *AdminBillingHandler) Usage(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "用户ID不正确"})
 		return
 	}
+	orgID, err := parseOptionalInt64(c.Query("org_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "组织ID不正确"})
+		return
+	}
 
 	start, end, err := parseTimeRange(c)
 	if err != nil {
@@ -216,6 +373,7 @@ func (h *AdminBillingHandler) Usage(c *gin.Context) {
 
 	items, total, err := h.usageSvc.ListAdmin(c.Request.Context(), usage.AdminListInput{
 		UserID:   userID,
+		OrgID:    orgID,
 		Start:    start,
 		End:      end,
 		Page:     page,
@@ -234,9 +392,87 @@ func (h *AdminBillingHandler) Usage(c *gin.Context) {
 	})
 }
 
+// UsageAnalytics godoc
+// @Summary 管理员：用量趋势
+// @Description 按小时或按天（UTC）返回用量时间序列，可按组织、成员、项目、模型分组，并返回整个范围内按费用倒序的前 limit 组汇总。数据来自 Worker 定时累加的预聚合，最近几分钟的用量可能尚未计入；费用按币种分别汇总，project_id 为 0 表示不属于任何项目
+// @Tags 管理-计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param granularity query string false "粒度：hour 或 day，默认 day"
+// @Param start query string false "开始时间（RFC3339），默认 hour 为最近 24 小时、day 为最近 30 天；hour 最长 31 天，day 最长 366 天"
+// @Param end query string false "结束时间（RFC3339），默认当前时间"
+// @Param group_by query string false "分组维度，逗号分隔：org、user、project、model"
+// @Param org_id query int false "付费组织ID"
+// @Param user_id query int false "发起请求的用户ID"
+// @Param project_id query int false "项目ID，0 为不属于任何项目的请求"
+// @Param model query string false "模型"
+// @Param limit query int false "汇总返回的组数，默认 10，最大 100"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/billing/usage/analytics [get]
+func (h *AdminBillingHandler) UsageAnalytics(c *gin.Context) {
+	if h == nil || h.usageSvc == nil {
+		respondInternal(c, "用量服务未配置")
+		return
+	}
+
+	orgID, err := parseOptionalInt64(c.Query("org_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "组织ID不正确"})
+		return
+	}
+	userID, err := parseOptionalInt64(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "用户ID不正确"})
+		return
+	}
+	projectID, err := parseOptionalInt64(c.Query("project_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "项目ID不正确"})
+		return
+	}
+	start, end, err := parseTimeRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "时间范围不正确"})
+		return
+	}
+
+	result, err := h.usageSvc.Analytics(c.Request.Context(), usage.AnalyticsInput{
+		Granularity: c.Query("granularity"),
+		OrgID:       orgID,
+		UserID:      userID,
+		ProjectID:   projectID,
+		Model:       c.Query("model"),
+		Start:       start,
+		End:         end,
+		GroupBy:     strings.Split(c.Query("group_by"), ","),
+		Limit:       parseIntQueryAdmin(c, "limit", 10),
+	})
+	if err != nil {
+		switch err {
+		case usage.ErrInvalidGranularity:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "粒度不正确"})
+		case usage.ErrInvalidGroupBy:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "分组维度不正确"})
+		case usage.ErrInvalidTimeRange:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "时间范围不正确"})
+		default:
+			respondInternal(c, "获取用量趋势失败")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // TopUp godoc
 // @Summary 管理员：系统充值
-// @Description 管理员给指定用户充值或冲正余额
+// @Description 管理员给指定用户充值或冲正余额；currency 与钱包币种不同时按当前汇率换算
 // @Tags 管理-计费
 // @Accept json
 // @Produce json
@@ -248,6 +484,7 @@ func (h *AdminBillingHandler) Usage(c *gin.Context) {
 // @Failure 401 {object} map[string]interface{} "未登录"
 // @Failure 403 {object} map[string]interface{} "无权限"
 // @Failure 409 {object} map[string]interface{} "引用冲突"
+// @Failure 422 {object} map[string]interface{} "缺少汇率"
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /admin/billing/topups [post]
 func (h *AdminBillingHandler) TopUp(c *gin.Context) {
@@ -280,6 +517,10 @@ func (h *AdminBillingHandler) TopUp(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "金额不正确"})
 		case billing.ErrRefConflict:
 			c.JSON(http.StatusConflict, gin.H{"error": "ref_id 冲突"})
+		case fx.ErrInvalidCurrency:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "币种不正确"})
+		case fx.ErrRateNotFound:
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "未配置对应汇率"})
 		default:
 			respondInternal(c, "充值失败")
 		}
@@ -289,6 +530,287 @@ func (h *AdminBillingHandler) TopUp(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+// SetWalletCurrency godoc
+// @Summary 管理员：设置钱包币种
+// @Description 设置组织钱包的记账币种，钱包余额与冻结金额必须为 0
+// @Tags 管理-计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param org_id path int true "组织ID"
+// @Param data body adminWalletCurrencyRequest true "币种"
+// @Success 200 {object} map[string]interface{} "设置成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 409 {object} map[string]interface{} "钱包非空"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/billing/wallets/{org_id}/currency [put]
+func (h *AdminBillingHandler) SetWalletCurrency(c *gin.Context) {
+	if h == nil || h.billingSvc == nil {
+		respondInternal(c, "计费服务未配置")
+		return
+	}
+
+	orgID, err := strconv.ParseInt(c.Param("org_id"), 10, 64)
+	if err != nil || orgID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "组织ID不正确"})
+		return
+	}
+
+	var req adminWalletCurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数不正确"})
+		return
+	}
+
+	wallet, err := h.billingSvc.SetWalletCurrency(c.Request.Context(), orgID, req.Currency)
+	if err != nil {
+		switch err {
+		case fx.ErrInvalidCurrency:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "币种不正确"})
+		case billing.ErrWalletNotEmpty:
+			c.JSON(http.StatusConflict, gin.H{"error": "钱包余额或冻结金额不为 0，不能切换币种"})
+		default:
+			respondInternal(c, "设置钱包币种失败")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, wallet)
+}
+
+// SetWalletCreditLimit godoc
+// @Summary 管理员：设置信用额度
+// @Description 设置后付费信用额度（钱包币种），余额最低可透支到 -credit_limit；0 表示预付费
+// @Tags 管理-计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param org_id path int true "组织ID"
+// @Param data body adminWalletCreditLimitRequest true "信用额度"
+// @Success 200 {object} map[string]interface{} "设置成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/billing/wallets/{org_id}/credit-limit [put]
+func (h *AdminBillingHandler) SetWalletCreditLimit(c *gin.Context) {
+	if h == nil || h.billingSvc == nil {
+		respondInternal(c, "计费服务未配置")
+		return
+	}
+
+	orgID, err := strconv.ParseInt(c.Param("org_id"), 10, 64)
+	if err != nil || orgID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "组织ID不正确"})
+		return
+	}
+
+	var req adminWalletCreditLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数不正确"})
+		return
+	}
+
+	wallet, err := h.billingSvc.SetCreditLimit(c.Request.Context(), orgID, req.CreditLimit)
+	if err != nil {
+		switch err {
+		case billing.ErrInvalidCreditLimit:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "信用额度不正确"})
+		default:
+			respondInternal(c, "设置信用额度失败")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, wallet)
+}
+
+// SetWalletStatus godoc
+// @Summary 管理员：停用或恢复钱包
+// @Description 停用后不能发起新的预扣；恢复时清除催缴状态，仍超出信用额度的钱包会被催缴任务重新停用
+// @Tags 管理-计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param org_id path int true "组织ID"
+// @Param data body adminWalletStatusRequest true "状态"
+// @Success 200 {object} map[string]interface{} "设置成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/billing/wallets/{org_id}/status [put]
+func (h *AdminBillingHandler) SetWalletStatus(c *gin.Context) {
+	if h == nil || h.billingSvc == nil {
+		respondInternal(c, "计费服务未配置")
+		return
+	}
+
+	orgID, err := strconv.ParseInt(c.Param("org_id"), 10, 64)
+	if err != nil || orgID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "组织ID不正确"})
+		return
+	}
+
+	var req adminWalletStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数不正确"})
+		return
+	}
+	var suspended bool
+	switch strings.ToLower(strings.TrimSpace(req.Status)) {
+	case "active":
+	case "suspended":
+		suspended = true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "状态不正确"})
+		return
+	}
+
+	wallet, err := h.billingSvc.SetSuspended(c.Request.Context(), orgID, suspended)
+	if err != nil {
+		respondInternal(c, "设置钱包状态失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, wallet)
+}
+
+// FXRates godoc
+// @Summary 管理员：汇率列表
+// @Description 获取汇率列表，rate 表示 1 单位 base_currency 折合多少 quote_currency
+// @Tags 管理-计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param currency query string false "币种（匹配 base 或 quote）"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/billing/fx-rates [get]
+func (h *AdminBillingHandler) FXRates(c *gin.Context) {
+	if h == nil || h.fxSvc == nil {
+		respondInternal(c, "汇率服务未配置")
+		return
+	}
+
+	page := parseIntQueryAdmin(c, "page", 1)
+	pageSize := parseIntQueryAdmin(c, "page_size", 20)
+
+	items, total, err := h.fxSvc.ListRates(c.Request.Context(), fx.ListInput{
+		Currency: c.Query("currency"),
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		respondInternal(c, "获取汇率失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":     items,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
+
+// UpsertFXRate godoc
+// @Summary 管理员：设置汇率
+// @Description 按币种对新增或覆盖汇率；反向换算自动使用倒数，无需重复配置
+// @Tags 管理-计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param data body adminFXRateRequest true "汇率信息"
+// @Success 200 {object} map[string]interface{} "设置成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/billing/fx-rates [put]
+func (h *AdminBillingHandler) UpsertFXRate(c *gin.Context) {
+	if h == nil || h.fxSvc == nil {
+		respondInternal(c, "汇率服务未配置")
+		return
+	}
+
+	var req adminFXRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数不正确"})
+		return
+	}
+
+	item, err := h.fxSvc.UpsertRate(c.Request.Context(), fx.RateInput{
+		BaseCurrency:  req.BaseCurrency,
+		QuoteCurrency: req.QuoteCurrency,
+		Rate:          req.Rate,
+	})
+	if err != nil {
+		switch err {
+		case fx.ErrInvalidCurrency:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "币种不正确"})
+		case fx.ErrInvalidRate:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "汇率必须大于 0"})
+		default:
+			respondInternal(c, "设置汇率失败")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// DeleteFXRate godoc
+// @Summary 管理员：删除汇率
+// @Description 删除汇率
+// @Tags 管理-计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "汇率ID"
+// @Success 204 {object} map[string]interface{} "删除成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 404 {object} map[string]interface{} "汇率不存在"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/billing/fx-rates/{id} [delete]
+func (h *AdminBillingHandler) DeleteFXRate(c *gin.Context) {
+	if h == nil || h.fxSvc == nil {
+		respondInternal(c, "汇率服务未配置")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "汇率ID不正确"})
+		return
+	}
+
+	deleted, err := h.fxSvc.DeleteRate(c.Request.Context(), id)
+	if err != nil {
+		respondInternal(c, "删除汇率失败")
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "汇率不存在"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
 func parseOptionalInt64(value string) (*int64, error) {
 	value = strings.TrimSpace(value)
 	if value == "" {
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"deepspace/internal/service/invoice"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminInvoiceHandler struct {
+	svc *invoice.Service
+}
+
+func NewAdminInvoiceHandler(svc *invoice.Service) *AdminInvoiceHandler {
+	return &AdminInvoiceHandler{svc: svc}
+}
+
+// List godoc
+// @Summary 管理员：月度账单列表
+// @Description 获取所有组织的月度账单，可按组织与账期筛选
+// @Tags 管理-计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param user_id query int false "组织ID"
+// @Param status query string false "状态（issued）"
+// @Param start query string false "账期起始时间下限（RFC3339）"
+// @Param end query string false "账期起始时间上限（RFC3339）"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/billing/invoices [get]
+func (h *AdminInvoiceHandler) List(c *gin.Context) {
+	if h == nil || h.svc == nil {
+		respondInternal(c, "账单服务未配置")
+		return
+	}
+
+	userID, err := parseOptionalInt64(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "用户ID不正确"})
+		return
+	}
+	start, end, err := parseTimeRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "时间范围不正确"})
+		return
+	}
+
+	page := parseIntQueryAdmin(c, "page", 1)
+	pageSize := parseIntQueryAdmin(c, "page_size", 20)
+
+	items, total, err := h.svc.List(c.Request.Context(), invoice.ListInput{
+		UserID:   userID,
+		Status:   c.Query("status"),
+		Start:    start,
+		End:      end,
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		respondInternal(c, "获取账单失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":     items,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
+
+// Get godoc
+// @Summary 管理员：月度账单详情
+// @Description 获取账单及按模型、项目汇总的明细
+// @Tags 管理-计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "账单ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 404 {object} map[string]interface{} "账单不存在"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/billing/invoices/{id} [get]
+func (h *AdminInvoiceHandler) Get(c *gin.Context) {
+	if h == nil || h.svc == nil {
+		respondInternal(c, "账单服务未配置")
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "账单ID不正确"})
+		return
+	}
+
+	detail, err := h.svc.Get(c.Request.Context(), id, nil)
+	if err != nil {
+		handleInvoiceError(c, err, "获取账单失败")
+		return
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+// Download godoc
+// @Summary 管理员：下载月度账单
+// @Description 以 HTML 或 PDF 格式下载任意用户的账单
+// @Tags 管理-计费
+// @Accept json
+// @Produce application/pdf
+// @Produce text/html
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "账单ID"
+// @Param format query string false "格式（pdf/html，默认 pdf）"
+// @Param disposition query string false "下载方式（attachment/inline）"
+// @Success 200 {file} file "账单文件"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 404 {object} map[string]interface{} "账单不存在"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/billing/invoices/{id}/download [get]
+func (h *AdminInvoiceHandler) Download(c *gin.Context) {
+	if h == nil || h.svc == nil {
+		respondInternal(c, "账单服务未配置")
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "账单ID不正确"})
+		return
+	}
+
+	detail, err := h.svc.Get(c.Request.Context(), id, nil)
+	if err != nil {
+		handleInvoiceError(c, err, "获取账单失败")
+		return
+	}
+	doc, err := h.svc.Render(detail, c.Query("format"))
+	if err != nil {
+		handleInvoiceError(c, err, "渲染账单失败")
+		return
+	}
+	writeInvoiceDocument(c, doc)
+}
+
+func handleInvoiceError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case invoice.ErrInvoiceNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "账单不存在"})
+	case invoice.ErrInvalidFormat:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "账单格式不正确"})
+	default:
+		respondInternal(c, fallback)
+	}
+}
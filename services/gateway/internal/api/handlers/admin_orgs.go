@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"deepspace/internal/service/org"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminOrgHandler struct {
+	svc *org.Service
+}
+
+func NewAdminOrgHandler(svc *org.Service) *AdminOrgHandler {
+	return &AdminOrgHandler{svc: svc}
+}
+
+// List godoc
+// @Summary 管理员：组织列表
+// @Description 获取组织列表；组织 ID 即计费接口中钱包、流水、账单的 user_id
+// @Tags 管理-组织
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param search query string false "名称关键字"
+// @Param personal query bool false "是否个人组织"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/orgs [get]
+func (h *AdminOrgHandler) List(c *gin.Context) {
+	if h == nil || h.svc == nil {
+		respondInternal(c, "组织服务未配置")
+		return
+	}
+
+	var personal *bool
+	if value := c.Query("personal"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "personal 参数不正确"})
+			return
+		}
+		personal = &parsed
+	}
+
+	page := parseIntQueryAdmin(c, "page", 1)
+	pageSize := parseIntQueryAdmin(c, "page_size", 20)
+
+	items, total, err := h.svc.List(c.Request.Context(), org.ListInput{
+		Search:   c.Query("search"),
+		Personal: personal,
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		respondInternal(c, "获取组织失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":     items,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
+
+// Get godoc
+// @Summary 管理员：组织详情
+// @Description 获取组织信息及成员
+// @Tags 管理-组织
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "组织ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 404 {object} map[string]interface{} "组织不存在"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/orgs/{id} [get]
+func (h *AdminOrgHandler) Get(c *gin.Context) {
+	if h == nil || h.svc == nil {
+		respondInternal(c, "组织服务未配置")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "组织ID不正确"})
+		return
+	}
+
+	item, members, err := h.svc.AdminGet(c.Request.Context(), id)
+	if err != nil {
+		handleOrgError(c, err, "获取组织失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"organization": item, "members": members})
+}
+
+func handleOrgError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case org.ErrOrgNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "组织不存在"})
+	default:
+		respondInternal(c, fallback)
+	}
+}
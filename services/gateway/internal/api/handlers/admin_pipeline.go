@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"deepspace/internal/repo"
+	"deepspace/internal/service/pipelinechain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminPipelineHandler struct {
+	svc *pipelinechain.Service
+}
+
+func NewAdminPipelineHandler(svc *pipelinechain.Service) *AdminPipelineHandler {
+	return &AdminPipelineHandler{svc: svc}
+}
+
+type pipelineChainCreateRequest struct {
+	Name      string   `json:"name"`
+	Scope     string   `json:"scope"`
+	Stage     string   `json:"stage"`
+	ProjectID *int64   `json:"project_id"`
+	Model     *string  `json:"model"`
+	Steps     []string `json:"steps"`
+	Status    string   `json:"status"`
+	Priority  int      `json:"priority"`
+}
+
+type pipelineChainUpdateRequest struct {
+	Name      *string   `json:"name"`
+	Scope     *string   `json:"scope"`
+	Stage     *string   `json:"stage"`
+	ProjectID *int64    `json:"project_id"`
+	Model     *string   `json:"model"`
+	Steps     *[]string `json:"steps"`
+	Status    *string   `json:"status"`
+	Priority  *int      `json:"priority"`
+}
+
+// ListSteps godoc
+// @Summary 管理员：可用 Pipeline 步骤
+// @Description 获取已注册、可用于链路配置的步骤名称、默认步骤及各阶段必须包含的步骤
+// @Tags 管理-Pipeline
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/pipeline/steps [get]
+func (h *AdminPipelineHandler) ListSteps(c *gin.Context) {
+	if h == nil || h.svc == nil {
+		respondInternal(c, "Pipeline 服务未配置")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"items":        h.svc.AvailableSteps(),
+		"default_pre":  pipelinechain.DefaultPreSteps,
+		"default_post": pipelinechain.DefaultPostSteps,
+		"required":     pipelinechain.RequiredSteps,
+	})
+}
+
+// ListChains godoc
+// @Summary 管理员：Pipeline 链路列表
+// @Description 获取按全局、项目或模型配置的前置/后置链路
+// @Tags 管理-Pipeline
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param scope query string false "作用域（global/project/model）"
+// @Param stage query string false "阶段（pre/post）"
+// @Param project_id query int false "项目ID"
+// @Param model query string false "模型名称"
+// @Param status query string false "状态（active/disabled）"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/pipeline/chains [get]
+func (h *AdminPipelineHandler) ListChains(c *gin.Context) {
+	if h == nil || h.svc == nil {
+		respondInternal(c, "Pipeline 服务未配置")
+		return
+	}
+	projectID, err := parseOptionalInt64(c.Query("project_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "项目ID不正确"})
+		return
+	}
+	page := parseIntQueryAdmin(c, "page", 1)
+	pageSize := parseIntQueryAdmin(c, "page_size", 20)
+
+	items, total, err := h.svc.List(c.Request.Context(), repo.PipelineChainFilter{
+		Scope:     strings.TrimSpace(c.Query("scope")),
+		Stage:     strings.TrimSpace(c.Query("stage")),
+		ProjectID: projectID,
+		Model:     strings.TrimSpace(c.Query("model")),
+		Status:    strings.TrimSpace(c.Query("status")),
+		Limit:     pageSize,
+		Offset:    (page - 1) * pageSize,
+	})
+	if err != nil {
+		respondInternal(c, "获取 Pipeline 链路失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":     items,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
+
+// CreateChain godoc
+// @Summary 管理员：创建 Pipeline 链路
+// @Description 按全局、项目或模型声明有序的前置/后置步骤；前置链路须包含 auth、policy 与 budget_hold 且以 budget_hold 结尾，后置链路须包含 usage_capture，修改在本实例立即生效、其他实例最迟 30 秒后生效
+// @Tags 管理-Pipeline
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param data body pipelineChainCreateRequest true "链路数据"
+// @Success 201 {object} map[string]interface{} "创建成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/pipeline/chains [post]
+func (h *AdminPipelineHandler) CreateChain(c *gin.Context) {
+	if h == nil || h.svc == nil {
+		respondInternal(c, "Pipeline 服务未配置")
+		return
+	}
+
+	var req pipelineChainCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数不正确"})
+		return
+	}
+
+	item, err := h.svc.Create(c.Request.Context(), pipelinechain.ChainCreateInput{
+		Name:      strings.TrimSpace(req.Name),
+		Scope:     strings.TrimSpace(req.Scope),
+		Stage:     strings.TrimSpace(req.Stage),
+		ProjectID: req.ProjectID,
+		Model:     req.Model,
+		Steps:     req.Steps,
+		Status:    strings.TrimSpace(req.Status),
+		Priority:  req.Priority,
+	})
+	if err != nil {
+		handlePipelineChainError(c, err, "创建 Pipeline 链路失败")
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// UpdateChain godoc
+// @Summary 管理员：更新 Pipeline 链路
+// @Description 更新链路的作用域、阶段或步骤顺序；作用域改为 model 或 global 时自动清空 project_id，改为 global 时同时清空 model
+// @Tags 管理-Pipeline
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "链路ID"
+// @Param data body pipelineChainUpdateRequest true "链路更新数据"
+// @Success 200 {object} map[string]interface{} "更新成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 404 {object} map[string]interface{} "链路不存在"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/pipeline/chains/{id} [patch]
+func (h *AdminPipelineHandler) UpdateChain(c *gin.Context) {
+	if h == nil || h.svc == nil {
+		respondInternal(c, "Pipeline 服务未配置")
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "链路ID不正确"})
+		return
+	}
+
+	var req pipelineChainUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数不正确"})
+		return
+	}
+
+	item, err := h.svc.Update(c.Request.Context(), id, pipelinechain.ChainUpdateInput{
+		Name:      req.Name,
+		Scope:     req.Scope,
+		Stage:     req.Stage,
+		ProjectID: req.ProjectID,
+		Model:     req.Model,
+		Steps:     req.Steps,
+		Status:    req.Status,
+		Priority:  req.Priority,
+	})
+	if err != nil {
+		handlePipelineChainError(c, err, "更新 Pipeline 链路失败")
+		return
+	}
+	if item == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "链路不存在"})
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// DeleteChain godoc
+// @Summary 管理员：删除 Pipeline 链路
+// @Description 删除后对应作用域回落到更宽的链路或默认步骤
+// @Tags 管理-Pipeline
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "链路ID"
+// @Success 204 {object} map[string]interface{} "删除成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/pipeline/chains/{id} [delete]
+func (h *AdminPipelineHandler) DeleteChain(c *gin.Context) {
+	if h == nil || h.svc == nil {
+		respondInternal(c, "Pipeline 服务未配置")
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "链路ID不正确"})
+		return
+	}
+	if _, err := h.svc.Delete(c.Request.Context(), id); err != nil {
+		handlePipelineChainError(c, err, "删除 Pipeline 链路失败")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func handlePipelineChainError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case pipelinechain.ErrInvalidName:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "名称不正确"})
+	case pipelinechain.ErrInvalidScope:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "作用域不正确"})
+	case pipelinechain.ErrInvalidStage:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "阶段不正确"})
+	case pipelinechain.ErrInvalidStatus:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "状态不正确"})
+	case pipelinechain.ErrInvalidSteps:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "步骤不正确"})
+	case pipelinechain.ErrRequiredStepMissing:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "前置链路须包含 auth、policy 与 budget_hold，后置链路须包含 usage_capture"})
+	case pipelinechain.ErrHoldStepNotLast:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "budget_hold 须为前置链路的最后一步"})
+	case pipelinechain.ErrChainNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "链路不存在"})
+	default:
+		respondInternal(c, fallback)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"strings"
 
+	"deepspace-shared/money"
 	"deepspace/internal/repo"
 	"deepspace/internal/service/risk"
 
@@ -68,18 +69,18 @@ type ipRuleUpdateRequest struct {
 }
 
 type budgetCapCreateRequest struct {
-	PolicyID int64   `json:"policy_id"`
-	Cycle    string  `json:"cycle"`
-	MaxCost  float64 `json:"max_cost"`
-	Currency string  `json:"currency"`
-	Status   string  `json:"status"`
+	PolicyID int64        `json:"policy_id"`
+	Cycle    string       `json:"cycle"`
+	MaxCost  money.Amount `json:"max_cost" swaggertype:"number"`
+	Currency string       `json:"currency"`
+	Status   string       `json:"status"`
 }
 
 type budgetCapUpdateRequest struct {
-	Cycle    *string  `json:"cycle"`
-	MaxCost  *float64 `json:"max_cost"`
-	Currency *string  `json:"currency"`
-	Status   *string  `json:"status"`
+	Cycle    *string       `json:"cycle"`
+	MaxCost  *money.Amount `json:"max_cost" swaggertype:"number"`
+	Currency *string       `json:"currency"`
+	Status   *string       `json:"status"`
 }
 
 // ListPolicies godoc
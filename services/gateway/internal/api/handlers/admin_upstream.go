@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"deepspace/internal/integrations/newapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminUpstreamHandler struct {
+	pool *newapi.Pool
+}
+
+func NewAdminUpstreamHandler(pool *newapi.Pool) *AdminUpstreamHandler {
+	return &AdminUpstreamHandler{pool: pool}
+}
+
+// Health godoc
+// @Summary 管理员：上游健康状态
+// @Description 获取各 NewAPI 上游的权重、模型范围、熔断状态与被动健康统计
+// @Tags 管理-上游
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/upstreams/health [get]
+func (h *AdminUpstreamHandler) Health(c *gin.Context) {
+	if h == nil || h.pool == nil {
+		respondInternal(c, "上游未配置")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": h.pool.Snapshot()})
+}
@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"deepspace-shared/money"
+	"deepspace/internal/service/voucher"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminVoucherHandler struct {
+	svc *voucher.Service
+}
+
+func NewAdminVoucherHandler(svc *voucher.Service) *AdminVoucherHandler {
+	return &AdminVoucherHandler{svc: svc}
+}
+
+type adminVoucherCreateRequest struct {
+	// Code 为空时自动生成；指定 Code 时 Count 只能为 1。
+	Code           string       `json:"code"`
+	Count          int          `json:"count"`
+	Amount         money.Amount `json:"amount" swaggertype:"number"`
+	Currency       string       `json:"currency"`
+	MaxRedemptions int          `json:"max_redemptions"`
+	// CreditDays 为兑换后额度的有效天数，0 表示仅受 ExpiresAt 限制。
+	CreditDays int     `json:"credit_days"`
+	ExpiresAt  *string `json:"expires_at"`
+	Note       string  `json:"note"`
+}
+
+type adminVoucherUpdateRequest struct {
+	Status         *string `json:"status"`
+	MaxRedemptions *int    `json:"max_redemptions"`
+	ExpiresAt      *string `json:"expires_at"`
+	Note           *string `json:"note"`
+}
+
+// List godoc
+// @Summary 管理员：代金券列表
+// @Description 获取代金券列表
+// @Tags 管理-计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param status query string false "状态（active/disabled）"
+// @Param code query string false "兑换码"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/vouchers [get]
+func (h *AdminVoucherHandler) List(c *gin.Context) {
+	if h == nil || h.svc == nil {
+		respondInternal(c, "代金券服务未配置")
+		return
+	}
+
+	page := parseIntQueryAdmin(c, "page", 1)
+	pageSize := parseIntQueryAdmin(c, "page_size", 20)
+
+	items, total, err := h.svc.List(c.Request.Context(), voucher.ListInput{
+		Status:   c.Query("status"),
+		Code:     c.Query("code"),
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		respondInternal(c, "获取代金券失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":     items,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
+
+// Create godoc
+// @Summary 管理员：创建代金券
+// @Description 创建单个或批量生成代金券；max_redemptions 大于 1 时为多次兑换码，每个用户限兑一次
+// @Tags 管理-计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param data body adminVoucherCreateRequest true "代金券信息"
+// @Success 201 {object} map[string]interface{} "创建成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 409 {object} map[string]interface{} "兑换码已存在"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/vouchers [post]
+func (h *AdminVoucherHandler) Create(c *gin.Context) {
+	if h == nil || h.svc == nil {
+		respondInternal(c, "代金券服务未配置")
+		return
+	}
+
+	var req adminVoucherCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数不正确"})
+		return
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresAt != nil && strings.TrimSpace(*req.ExpiresAt) != "" {
+		value, err := parseRFC3339(*req.ExpiresAt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "过期时间不正确"})
+			return
+		}
+		expiresAt = &value
+	}
+
+	var createdBy *int64
+	if value, ok := getUserID(c); ok {
+		createdBy = &value
+	}
+
+	items, err := h.svc.Create(c.Request.Context(), voucher.CreateInput{
+		Code:           req.Code,
+		Count:          req.Count,
+		Amount:         req.Amount,
+		Currency:       req.Currency,
+		MaxRedemptions: req.MaxRedemptions,
+		CreditDays:     req.CreditDays,
+		ExpiresAt:      expiresAt,
+		Note:           req.Note,
+		CreatedBy:      createdBy,
+	})
+	if err != nil {
+		handleVoucherError(c, err, "创建代金券失败")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"items": items})
+}
+
+// Update godoc
+// @Summary 管理员：更新代金券
+// @Description 停用/启用代金券或调整兑换次数、过期时间；已兑换的额度不受影响
+// @Tags 管理-计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "代金券ID"
+// @Param data body adminVoucherUpdateRequest true "代金券更新数据"
+// @Success 200 {object} map[string]interface{} "更新成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 404 {object} map[string]interface{} "代金券不存在"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/vouchers/{id} [patch]
+func (h *AdminVoucherHandler) Update(c *gin.Context) {
+	if h == nil || h.svc == nil {
+		respondInternal(c, "代金券服务未配置")
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "代金券ID不正确"})
+		return
+	}
+	var req adminVoucherUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数不正确"})
+		return
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresAt != nil {
+		value, err := parseRFC3339(*req.ExpiresAt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "过期时间不正确"})
+			return
+		}
+		expiresAt = &value
+	}
+
+	item, err := h.svc.Update(c.Request.Context(), id, voucher.UpdateInput{
+		Status:         req.Status,
+		MaxRedemptions: req.MaxRedemptions,
+		ExpiresAt:      expiresAt,
+		Note:           req.Note,
+	})
+	if err != nil {
+		handleVoucherError(c, err, "更新代金券失败")
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// Redemptions godoc
+// @Summary 管理员：代金券兑换记录
+// @Description 获取指定代金券的兑换记录
+// @Tags 管理-计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "代金券ID"
+// @Param user_id query int false "组织ID"
+// @Param status query string false "状态（active/expired）"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/vouchers/{id}/redemptions [get]
+func (h *AdminVoucherHandler) Redemptions(c *gin.Context) {
+	if h == nil || h.svc == nil {
+		respondInternal(c, "代金券服务未配置")
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "代金券ID不正确"})
+		return
+	}
+	userID, err := parseOptionalInt64(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "用户ID不正确"})
+		return
+	}
+
+	page := parseIntQueryAdmin(c, "page", 1)
+	pageSize := parseIntQueryAdmin(c, "page_size", 20)
+
+	items, total, err := h.svc.ListRedemptions(c.Request.Context(), voucher.RedemptionListInput{
+		VoucherID: &id,
+		UserID:    userID,
+		Status:    c.Query("status"),
+		Page:      page,
+		PageSize:  pageSize,
+	})
+	if err != nil {
+		respondInternal(c, "获取兑换记录失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":     items,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
+
+func handleVoucherError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case voucher.ErrInvalidCode:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "兑换码格式不正确"})
+	case voucher.ErrInvalidAmount:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "金额不正确"})
+	case voucher.ErrInvalidCurrency:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "币种不正确"})
+	case voucher.ErrInvalidMaxRedemptions:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "兑换次数不正确"})
+	case voucher.ErrInvalidCount:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "生成数量不正确"})
+	case voucher.ErrInvalidStatus:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "状态不正确"})
+	case voucher.ErrInvalidExpiry:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "过期时间不正确"})
+	case voucher.ErrCodeExists:
+		c.JSON(http.StatusConflict, gin.H{"error": "兑换码已存在"})
+	case voucher.ErrVoucherNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "代金券不存在"})
+	default:
+		respondInternal(c, fallback)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"strings"
 
+	"deepspace-shared/money"
 	"deepspace/internal/service/billing"
+	"deepspace/internal/service/fx"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,7 +21,9 @@ func NewBillingHandler(svc *billing.Service) *BillingHandler {
 }
 
 type billingRequest struct {
-	Amount   float64        `json:"amount"`
+	Amount money.Amount `json:"amount" swaggertype:"number"`
+	// Currency 为空表示与钱包币种相同，否则按当前汇率换算为钱包币种。
+	Currency string         `json:"currency"`
 	RefID    string         `json:"ref_id"`
 	Metadata map[string]any `json:"metadata"`
 }
@@ -37,6 +41,7 @@ type billingRequest struct {
 // @Failure 400 {object} map[string]interface{} "请求错误"
 // @Failure 402 {object} map[string]interface{} "余额不足"
 // @Failure 409 {object} map[string]interface{} "引用冲突"
+// @Failure 422 {object} map[string]interface{} "缺少汇率"
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /billing/hold [post]
 func (h *BillingHandler) Hold(c *gin.Context) {
@@ -55,7 +60,9 @@ func (h *BillingHandler) Hold(c *gin.Context) {
 // @Success 200 {object} map[string]interface{} "扣减成功"
 // @Failure 400 {object} map[string]interface{} "请求错误"
 // @Failure 402 {object} map[string]interface{} "余额不足"
+// @Failure 404 {object} map[string]interface{} "预扣不存在"
 // @Failure 409 {object} map[string]interface{} "引用冲突"
+// @Failure 422 {object} map[string]interface{} "缺少汇率"
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /billing/capture [post]
 func (h *BillingHandler) Capture(c *gin.Context) {
@@ -73,17 +80,97 @@ func (h *BillingHandler) Capture(c *gin.Context) {
 // @Param data body billingRequest true "释放信息"
 // @Success 200 {object} map[string]interface{} "释放成功"
 // @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 404 {object} map[string]interface{} "预扣不存在"
 // @Failure 409 {object} map[string]interface{} "引用冲突"
+// @Failure 422 {object} map[string]interface{} "缺少汇率"
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /billing/release [post]
 func (h *BillingHandler) Release(c *gin.Context) {
 	h.handle(c, h.svc.Release)
 }
 
-func (h *BillingHandler) handle(c *gin.Context, op func(ctx context.Context, orgID int64, amount float64, refID string, metadata map[string]any) (*billing.HoldResult, error)) {
-	userID, ok := getUserID(c)
+// Settle godoc
+// @Summary 结算预扣
+// @Description 按实际金额扣款并自动释放剩余预扣，扣款与释放在同一事务内完成
+// @Tags 计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param data body billingRequest true "结算信息（amount 为实际金额）"
+// @Success 200 {object} map[string]interface{} "结算成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 404 {object} map[string]interface{} "预扣不存在"
+// @Failure 409 {object} map[string]interface{} "引用冲突或状态不允许"
+// @Failure 422 {object} map[string]interface{} "缺少汇率"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /billing/settle [post]
+func (h *BillingHandler) Settle(c *gin.Context) {
+	orgID, ok := getOrgID(c)
+	if !ok {
+		respondInternal(c, "org_id 缺失")
+		return
+	}
+
+	var req billingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	refID := strings.TrimSpace(req.RefID)
+	if refID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ref_id is required"})
+		return
+	}
+
+	result, err := h.svc.Settle(c.Request.Context(), orgID, req.Amount, req.Currency, refID, req.Metadata)
+	if err != nil {
+		respondBillingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Event godoc
+// @Summary 计费事件
+// @Description 按 ref_id 获取关联的预扣、扣款与释放流水
+// @Tags 计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param ref_id path string true "引用ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 404 {object} map[string]interface{} "事件不存在"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /billing/events/{ref_id} [get]
+func (h *BillingHandler) Event(c *gin.Context) {
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
+		return
+	}
+
+	events, err := h.svc.ListBillingEvents(c.Request.Context(), c.Param("ref_id"), &orgID)
+	if err != nil {
+		respondInternal(c, "failed to load billing event")
+		return
+	}
+	if len(events) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "billing event not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, events[0])
+}
+
+func (h *BillingHandler) handle(c *gin.Context, op func(ctx context.Context, orgID int64, amount money.Amount, currency string, refID string, metadata map[string]any) (*billing.HoldResult, error)) {
+	orgID, ok := getOrgID(c)
+	if !ok {
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -102,7 +189,7 @@ func (h *BillingHandler) handle(c *gin.Context, op func(ctx context.Context, org
 		req.Metadata = map[string]any{}
 	}
 
-	result, err := op(c.Request.Context(), userID, req.Amount, refID, req.Metadata)
+	result, err := op(c.Request.Context(), orgID, req.Amount, req.Currency, refID, req.Metadata)
 	if err != nil {
 		respondBillingError(c, err)
 		return
@@ -119,12 +206,27 @@ func respondBillingError(c *gin.Context, err error) {
 	case billing.ErrInsufficientBalance:
 		c.JSON(http.StatusPaymentRequired, gin.H{"error": "insufficient balance"})
 		return
+	case billing.ErrWalletSuspended:
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": "wallet suspended"})
+		return
 	case billing.ErrInsufficientFrozen:
 		c.JSON(http.StatusPaymentRequired, gin.H{"error": "insufficient frozen balance"})
 		return
 	case billing.ErrRefConflict:
 		c.JSON(http.StatusConflict, gin.H{"error": "ref_id conflict"})
 		return
+	case billing.ErrInvalidRefTransition:
+		c.JSON(http.StatusConflict, gin.H{"error": "invalid ref state"})
+		return
+	case billing.ErrHoldNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "hold not found"})
+		return
+	case fx.ErrInvalidCurrency:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid currency"})
+		return
+	case fx.ErrRateNotFound:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "fx rate not available"})
+		return
 	default:
 		respondInternal(c, "billing failed")
 	}
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"deepspace-shared/money"
 	"deepspace/internal/service/billing"
 	"deepspace/internal/service/usage"
 
@@ -36,27 +39,33 @@ func NewBillingViewHandler(billingSvc *billing.Service, usageSvc *usage.Service)
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /billing/wallet [get]
 func (h *BillingViewHandler) Wallet(c *gin.Context) {
-	userID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
-	wallet, err := h.billingSvc.GetWallet(c.Request.Context(), userID)
+	wallet, err := h.billingSvc.GetWallet(c.Request.Context(), orgID)
 	if err != nil {
 		respondInternal(c, "failed to load wallet")
 		return
 	}
 
-	usage24h := float64(0)
+	var usage24h money.Amount
 	if h.usageSvc != nil {
 		end := time.Now().UTC()
 		start := end.Add(-24 * time.Hour)
-		total, err := h.usageSvc.SumCost(c.Request.Context(), userID, &start, &end)
+		costs, err := h.usageSvc.SumCostByCurrency(c.Request.Context(), orgID, &start, &end)
 		if err != nil {
 			respondInternal(c, "failed to load usage")
 			return
 		}
+		// 用量按记录币种分别汇总，换算为钱包币种展示。
+		total, err := h.billingSvc.SumInCurrency(c.Request.Context(), costs, wallet.Currency)
+		if err != nil {
+			respondBillingError(c, err)
+			return
+		}
 		usage24h = total
 	}
 
@@ -68,7 +77,7 @@ func (h *BillingViewHandler) Wallet(c *gin.Context) {
 
 // Usage godoc
 // @Summary 用量明细
-// @Description 获取当前用户用量明细
+// @Description 获取当前用户用量明细，每条记录含请求路径、状态码、是否流式、上游、上游耗时与首 token 耗时；失败请求同样记录，费用为 0
 // @Tags 计费
 // @Accept json
 // @Produce json
@@ -88,9 +97,9 @@ func (h *BillingViewHandler) Usage(c *gin.Context) {
 		respondInternal(c, "usage service unavailable")
 		return
 	}
-	userID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -123,7 +132,7 @@ func (h *BillingViewHandler) Usage(c *gin.Context) {
 	}
 
 	items, total, err := h.usageSvc.List(c.Request.Context(), usage.ListInput{
-		UserID:   userID,
+		OrgID:    orgID,
 		Start:    start,
 		End:      end,
 		Page:     page,
@@ -142,6 +151,82 @@ func (h *BillingViewHandler) Usage(c *gin.Context) {
 	})
 }
 
+// UsageAnalytics godoc
+// @Summary 用量趋势
+// @Description 按小时或按天（UTC）返回当前组织的用量时间序列，可按成员、项目、模型分组，并返回整个范围内按费用倒序的前 limit 组汇总（用于项目费用、热门模型图表）。数据来自 Worker 定时累加的预聚合，最近几分钟的用量可能尚未计入；费用按币种分别汇总，project_id 为 0 表示不属于任何项目
+// @Tags 计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param granularity query string false "粒度：hour 或 day，默认 day"
+// @Param start query string false "开始时间（RFC3339），默认 hour 为最近 24 小时、day 为最近 30 天；hour 最长 31 天，day 最长 366 天"
+// @Param end query string false "结束时间（RFC3339），默认当前时间"
+// @Param group_by query string false "分组维度，逗号分隔：user、project、model"
+// @Param user_id query int false "成员ID"
+// @Param project_id query int false "项目ID，0 为不属于任何项目的请求"
+// @Param model query string false "模型"
+// @Param limit query int false "汇总返回的组数，默认 10，最大 100"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /billing/usage/analytics [get]
+func (h *BillingViewHandler) UsageAnalytics(c *gin.Context) {
+	if h.usageSvc == nil {
+		respondInternal(c, "usage service unavailable")
+		return
+	}
+	orgID, ok := getOrgID(c)
+	if !ok {
+		respondInternal(c, "org_id 缺失")
+		return
+	}
+
+	userID, err := parseOptionalInt64(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return
+	}
+	projectID, err := parseOptionalInt64(c.Query("project_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project_id"})
+		return
+	}
+	start, end, err := parseTimeRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid time range"})
+		return
+	}
+
+	result, err := h.usageSvc.Analytics(c.Request.Context(), usage.AnalyticsInput{
+		Granularity: c.Query("granularity"),
+		OrgID:       &orgID,
+		UserID:      userID,
+		ProjectID:   projectID,
+		Model:       c.Query("model"),
+		Start:       start,
+		End:         end,
+		GroupBy:     strings.Split(c.Query("group_by"), ","),
+		Limit:       parseIntQuery(c, "limit", 10),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, usage.ErrInvalidGranularity):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid granularity"})
+		case errors.Is(err, usage.ErrInvalidGroupBy):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group_by"})
+		case errors.Is(err, usage.ErrInvalidTimeRange):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid time range"})
+		default:
+			respondInternal(c, "failed to get usage analytics")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 func parseIntQuery(c *gin.Context, key string, fallback int) int {
 	value := c.Query(key)
 	if value == "" {
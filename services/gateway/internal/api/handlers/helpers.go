@@ -41,6 +41,25 @@ func getUserID(c *gin.Context) (int64, bool) {
 	return castToInt64(value)
 }
 
+// getOrgID 返回 OrgContext 解析出的当前组织 ID。
+func getOrgID(c *gin.Context) (int64, bool) {
+	value, ok := c.Get("org_id")
+	if !ok {
+		return 0, false
+	}
+
+	return castToInt64(value)
+}
+
+func getTraceID(c *gin.Context) string {
+	value, ok := c.Get("trace_id")
+	if !ok {
+		return ""
+	}
+	traceID, _ := value.(string)
+	return traceID
+}
+
 func respondInternal(c *gin.Context, message string) {
 	traceID, ok := c.Get("trace_id")
 	if ok {
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"deepspace/internal/service/invoice"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvoiceHandler struct {
+	svc *invoice.Service
+}
+
+func NewInvoiceHandler(svc *invoice.Service) *InvoiceHandler {
+	return &InvoiceHandler{svc: svc}
+}
+
+// List godoc
+// @Summary 月度账单列表
+// @Description 获取当前用户的月度账单，按账期倒序
+// @Tags 计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param start query string false "账期起始时间下限（RFC3339）"
+// @Param end query string false "账期起始时间上限（RFC3339）"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /billing/invoices [get]
+func (h *InvoiceHandler) List(c *gin.Context) {
+	orgID, ok := getOrgID(c)
+	if !ok {
+		respondInternal(c, "org_id 缺失")
+		return
+	}
+	start, end, err := parseTimeRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid time range"})
+		return
+	}
+
+	page := parseIntQuery(c, "page", 1)
+	pageSize := parseIntQuery(c, "page_size", 20)
+
+	items, total, err := h.svc.List(c.Request.Context(), invoice.ListInput{
+		UserID:   &orgID,
+		Start:    start,
+		End:      end,
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		respondInternal(c, "failed to list invoices")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":     items,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
+
+// Get godoc
+// @Summary 月度账单详情
+// @Description 获取当前用户的账单及按模型、项目汇总的明细
+// @Tags 计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "账单ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 404 {object} map[string]interface{} "账单不存在"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /billing/invoices/{id} [get]
+func (h *InvoiceHandler) Get(c *gin.Context) {
+	orgID, ok := getOrgID(c)
+	if !ok {
+		respondInternal(c, "org_id 缺失")
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice id"})
+		return
+	}
+
+	detail, err := h.svc.Get(c.Request.Context(), id, &orgID)
+	if err != nil {
+		respondInvoiceError(c, err, "failed to get invoice")
+		return
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+// Download godoc
+// @Summary 下载月度账单
+// @Description 以 HTML 或 PDF 格式下载当前用户的账单
+// @Tags 计费
+// @Accept json
+// @Produce application/pdf
+// @Produce text/html
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "账单ID"
+// @Param format query string false "格式（pdf/html，默认 pdf）"
+// @Param disposition query string false "下载方式（attachment/inline）"
+// @Success 200 {file} file "账单文件"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 404 {object} map[string]interface{} "账单不存在"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /billing/invoices/{id}/download [get]
+func (h *InvoiceHandler) Download(c *gin.Context) {
+	orgID, ok := getOrgID(c)
+	if !ok {
+		respondInternal(c, "org_id 缺失")
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice id"})
+		return
+	}
+
+	detail, err := h.svc.Get(c.Request.Context(), id, &orgID)
+	if err != nil {
+		respondInvoiceError(c, err, "failed to get invoice")
+		return
+	}
+	doc, err := h.svc.Render(detail, c.Query("format"))
+	if err != nil {
+		respondInvoiceError(c, err, "failed to render invoice")
+		return
+	}
+	writeInvoiceDocument(c, doc)
+}
+
+// writeInvoiceDocument 默认以附件下载，disposition=inline 时在浏览器中直接打开。
+func writeInvoiceDocument(c *gin.Context, doc *invoice.Document) {
+	disposition := "attachment"
+	if strings.ToLower(strings.TrimSpace(c.Query("disposition"))) == "inline" {
+		disposition = "inline"
+	}
+	c.Header("Content-Disposition", disposition+"; filename=\""+doc.Filename+"\"")
+	c.Data(http.StatusOK, doc.ContentType, doc.Body)
+}
+
+func respondInvoiceError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, invoice.ErrInvoiceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "invoice not found"})
+	case errors.Is(err, invoice.ErrInvalidFormat):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice format"})
+	default:
+		respondInternal(c, fallback)
+	}
+}
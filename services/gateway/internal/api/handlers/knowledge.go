@@ -34,9 +34,9 @@ func NewKnowledgeHandler(svc *knowledge.Service) *KnowledgeHandler {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /knowledge-bases [get]
 func (h *KnowledgeHandler) ListBases(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -87,9 +87,9 @@ type createKnowledgeBaseRequest struct {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /knowledge-bases [post]
 func (h *KnowledgeHandler) CreateBase(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -132,9 +132,9 @@ func (h *KnowledgeHandler) CreateBase(c *gin.Context) {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /knowledge-bases/{id} [get]
 func (h *KnowledgeHandler) GetBase(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -179,9 +179,9 @@ type updateKnowledgeBaseRequest struct {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /knowledge-bases/{id} [patch]
 func (h *KnowledgeHandler) UpdateBase(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -230,9 +230,9 @@ func (h *KnowledgeHandler) UpdateBase(c *gin.Context) {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /knowledge-bases/{id} [delete]
 func (h *KnowledgeHandler) DeleteBase(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -270,9 +270,9 @@ func (h *KnowledgeHandler) DeleteBase(c *gin.Context) {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /knowledge-bases/{id}/documents [get]
 func (h *KnowledgeHandler) ListDocuments(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -309,9 +309,9 @@ func (h *KnowledgeHandler) ListDocuments(c *gin.Context) {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /knowledge-bases/{id}/documents [post]
 func (h *KnowledgeHandler) CreateDocument(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -391,9 +391,9 @@ func (h *KnowledgeHandler) CreateDocument(c *gin.Context) {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /knowledge-bases/{id}/documents/{docId} [delete]
 func (h *KnowledgeHandler) DeleteDocument(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -440,9 +440,9 @@ func (h *KnowledgeHandler) DeleteDocument(c *gin.Context) {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /knowledge-bases/{id}/documents/{docId}/download [get]
 func (h *KnowledgeHandler) DownloadDocument(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 
+	"deepspace-shared/money"
 	"deepspace/internal/integrations/newapi"
 	modelservice "deepspace/internal/service/model"
 
@@ -54,8 +55,8 @@ func (h *ModelHandler) Sync(c *gin.Context) {
 type modelCreateRequest struct {
 	Name         string         `json:"name"`
 	Provider     string         `json:"provider"`
-	PriceInput   float64        `json:"price_input"`
-	PriceOutput  float64        `json:"price_output"`
+	PriceInput   money.Amount   `json:"price_input" swaggertype:"number"`
+	PriceOutput  money.Amount   `json:"price_output" swaggertype:"number"`
 	Currency     string         `json:"currency"`
 	Capabilities []string       `json:"capabilities"`
 	Status       string         `json:"status"`
@@ -110,8 +111,8 @@ func (h *ModelHandler) Create(c *gin.Context) {
 
 type modelUpdateRequest struct {
 	Provider     *string         `json:"provider"`
-	PriceInput   *float64        `json:"price_input"`
-	PriceOutput  *float64        `json:"price_output"`
+	PriceInput   *money.Amount   `json:"price_input" swaggertype:"number"`
+	PriceOutput  *money.Amount   `json:"price_output" swaggertype:"number"`
 	Currency     *string         `json:"currency"`
 	Capabilities *[]string       `json:"capabilities"`
 	Status       *string         `json:"status"`
@@ -129,12 +130,12 @@ type modelConfirmRequest struct {
 }
 
 type modelPricingItem struct {
-	ID           string    `json:"id"`
-	PriceInput   *float64  `json:"price_input"`
-	PriceOutput  *float64  `json:"price_output"`
-	Currency     *string   `json:"currency"`
-	Status       *string   `json:"status"`
-	Capabilities *[]string `json:"capabilities"`
+	ID           string        `json:"id"`
+	PriceInput   *money.Amount `json:"price_input" swaggertype:"number"`
+	PriceOutput  *money.Amount `json:"price_output" swaggertype:"number"`
+	Currency     *string       `json:"currency"`
+	Status       *string       `json:"status"`
+	Capabilities *[]string     `json:"capabilities"`
 }
 
 type modelPricingRequest struct {
@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"deepspace/internal/service/org"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrgHandler struct {
+	svc *org.Service
+}
+
+func NewOrgHandler(svc *org.Service) *OrgHandler {
+	return &OrgHandler{svc: svc}
+}
+
+type orgCreateRequest struct {
+	Name string `json:"name"`
+}
+
+type orgUpdateRequest struct {
+	Name         *string `json:"name"`
+	BillingEmail *string `json:"billing_email"`
+}
+
+type orgMemberCreateRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+type orgMemberUpdateRequest struct {
+	Role string `json:"role"`
+}
+
+// List godoc
+// @Summary 我的组织
+// @Description 获取当前用户所属的组织及角色，第一项为个人组织；请求其他接口时通过 X-Org-Id 请求头切换组织
+// @Tags 组织
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /orgs [get]
+func (h *OrgHandler) List(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondInternal(c, "user_id 缺失")
+		return
+	}
+
+	items, err := h.svc.ListForUser(c.Request.Context(), userID)
+	if err != nil {
+		respondInternal(c, "failed to list organizations")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// Create godoc
+// @Summary 创建组织
+// @Description 创建团队组织，创建者成为 owner；组织拥有独立的钱包、项目、知识库与套餐订阅
+// @Tags 组织
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param data body orgCreateRequest true "组织信息"
+// @Success 201 {object} map[string]interface{} "创建成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /orgs [post]
+func (h *OrgHandler) Create(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondInternal(c, "user_id 缺失")
+		return
+	}
+
+	var req orgCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	item, err := h.svc.Create(c.Request.Context(), userID, req.Name)
+	if err != nil {
+		respondOrgError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// Get godoc
+// @Summary 组织详情
+// @Description 获取组织信息及当前用户的角色
+// @Tags 组织
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "组织ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "不是组织成员"
+// @Failure 404 {object} map[string]interface{} "组织不存在"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /orgs/{id} [get]
+func (h *OrgHandler) Get(c *gin.Context) {
+	userID, orgID, ok := orgPathIDs(c)
+	if !ok {
+		return
+	}
+
+	current, err := h.svc.Get(c.Request.Context(), userID, orgID)
+	if err != nil {
+		respondOrgError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"organization": current.Org, "role": current.Role})
+}
+
+// Update godoc
+// @Summary 更新组织
+// @Description 修改组织名称或账单邮箱（owner/admin）；账单邮箱为空时账单与催缴邮件发送给 owner
+// @Tags 组织
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "组织ID"
+// @Param data body orgUpdateRequest true "组织信息"
+// @Success 200 {object} map[string]interface{} "更新成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 404 {object} map[string]interface{} "组织不存在"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /orgs/{id} [patch]
+func (h *OrgHandler) Update(c *gin.Context) {
+	userID, orgID, ok := orgPathIDs(c)
+	if !ok {
+		return
+	}
+
+	var req orgUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	item, err := h.svc.Update(c.Request.Context(), userID, orgID, org.UpdateInput{
+		Name:         req.Name,
+		BillingEmail: req.BillingEmail,
+	})
+	if err != nil {
+		respondOrgError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// Members godoc
+// @Summary 组织成员
+// @Description 获取组织成员及角色
+// @Tags 组织
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "组织ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "不是组织成员"
+// @Failure 404 {object} map[string]interface{} "组织不存在"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /orgs/{id}/members [get]
+func (h *OrgHandler) Members(c *gin.Context) {
+	userID, orgID, ok := orgPathIDs(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.svc.ListMembers(c.Request.Context(), userID, orgID)
+	if err != nil {
+		respondOrgError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// AddMember godoc
+// @Summary 添加组织成员
+// @Description 按邮箱添加已注册用户（owner/admin），角色为 owner/admin/member/billing，默认 member；只有 owner 可以添加 owner，个人组织不能添加成员
+// @Tags 组织
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "组织ID"
+// @Param data body orgMemberCreateRequest true "成员信息"
+// @Success 201 {object} map[string]interface{} "添加成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 404 {object} map[string]interface{} "组织或用户不存在"
+// @Failure 409 {object} map[string]interface{} "已是成员"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /orgs/{id}/members [post]
+func (h *OrgHandler) AddMember(c *gin.Context) {
+	userID, orgID, ok := orgPathIDs(c)
+	if !ok {
+		return
+	}
+
+	var req orgMemberCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	item, err := h.svc.AddMember(c.Request.Context(), userID, orgID, req.Email, req.Role)
+	if err != nil {
+		respondOrgError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// UpdateMember godoc
+// @Summary 修改成员角色
+// @Description 修改成员角色（owner/admin）；只有 owner 可以授予或变更 owner，组织至少保留一名 owner
+// @Tags 组织
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "组织ID"
+// @Param user_id path int true "成员用户ID"
+// @Param data body orgMemberUpdateRequest true "角色"
+// @Success 200 {object} map[string]interface{} "更新成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 404 {object} map[string]interface{} "组织或成员不存在"
+// @Failure 409 {object} map[string]interface{} "不能移除最后一名 owner"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /orgs/{id}/members/{user_id} [patch]
+func (h *OrgHandler) UpdateMember(c *gin.Context) {
+	userID, orgID, ok := orgPathIDs(c)
+	if !ok {
+		return
+	}
+	memberID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil || memberID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var req orgMemberUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	if err := h.svc.UpdateMember(c.Request.Context(), userID, orgID, memberID, req.Role); err != nil {
+		respondOrgError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user_id": memberID, "role": req.Role})
+}
+
+// RemoveMember godoc
+// @Summary 移除组织成员
+// @Description 移除成员（owner/admin），成员也可以移除自己以退出组织；admin 不能移除 owner，组织至少保留一名 owner
+// @Tags 组织
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "组织ID"
+// @Param user_id path int true "成员用户ID"
+// @Success 204 "移除成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 404 {object} map[string]interface{} "组织或成员不存在"
+// @Failure 409 {object} map[string]interface{} "不能移除最后一名 owner"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /orgs/{id}/members/{user_id} [delete]
+func (h *OrgHandler) RemoveMember(c *gin.Context) {
+	userID, orgID, ok := orgPathIDs(c)
+	if !ok {
+		return
+	}
+	memberID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil || memberID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	if err := h.svc.RemoveMember(c.Request.Context(), userID, orgID, memberID); err != nil {
+		respondOrgError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// orgPathIDs 读取当前用户与路径中的组织 ID，失败时已写入响应。
+func orgPathIDs(c *gin.Context) (int64, int64, bool) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondInternal(c, "user_id 缺失")
+		return 0, 0, false
+	}
+	orgID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orgID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return 0, 0, false
+	}
+	return userID, orgID, true
+}
+
+func respondOrgError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, org.ErrInvalidName):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization name"})
+	case errors.Is(err, org.ErrInvalidEmail):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid billing email"})
+	case errors.Is(err, org.ErrInvalidRole):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role"})
+	case errors.Is(err, org.ErrOrgNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+	case errors.Is(err, org.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	case errors.Is(err, org.ErrMemberNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "member not found"})
+	case errors.Is(err, org.ErrNotMember):
+		c.JSON(http.StatusForbidden, gin.H{"error": "not a member of organization"})
+	case errors.Is(err, org.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "permission denied"})
+	case errors.Is(err, org.ErrPersonalOrg):
+		c.JSON(http.StatusForbidden, gin.H{"error": "personal organization members cannot be changed"})
+	case errors.Is(err, org.ErrAlreadyMember):
+		c.JSON(http.StatusConflict, gin.H{"error": "user is already a member"})
+	case errors.Is(err, org.ErrLastOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": "organization must keep at least one owner"})
+	default:
+		respondInternal(c, "organization operation failed")
+	}
+}
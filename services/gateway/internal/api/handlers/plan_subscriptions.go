@@ -1,22 +1,25 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"deepspace/internal/service/org"
 	planservice "deepspace/internal/service/plan"
 
 	"github.com/gin-gonic/gin"
 )
 
 type PlanSubscriptionHandler struct {
-	svc *planservice.Service
+	svc  *planservice.Service
+	orgs *org.Service
 }
 
-func NewPlanSubscriptionHandler(svc *planservice.Service) *PlanSubscriptionHandler {
-	return &PlanSubscriptionHandler{svc: svc}
+func NewPlanSubscriptionHandler(svc *planservice.Service, orgs *org.Service) *PlanSubscriptionHandler {
+	return &PlanSubscriptionHandler{svc: svc, orgs: orgs}
 }
 
 type subscriptionCreateRequest struct {
@@ -35,7 +38,7 @@ type subscriptionUpdateRequest struct {
 
 // Create godoc
 // @Summary 管理员：创建订阅
-// @Description 创建用户订阅
+// @Description 为组织创建订阅，user_id 为组织ID
 // @Tags 管理-订阅
 // @Accept json
 // @Produce json
@@ -77,19 +80,13 @@ func (h *PlanSubscriptionHandler) Create(c *gin.Context) {
 		endAt = &value
 	}
 
-	userID := req.UserID
-	if userID == 0 {
-		if value, ok := getUserID(c); ok {
-			userID = value
-		}
-	}
-	if userID == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "用户ID不正确"})
+	if req.UserID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "组织ID不正确"})
 		return
 	}
 
 	item, err := h.svc.CreateSubscription(c.Request.Context(), planservice.SubscriptionCreateInput{
-		UserID:  userID,
+		UserID:  req.UserID,
 		PlanID:  req.PlanID,
 		Status:  strings.TrimSpace(req.Status),
 		StartAt: startAt,
@@ -176,8 +173,49 @@ func (h *PlanSubscriptionHandler) Update(c *gin.Context) {
 }
 
 // GetOrgActive godoc
+// @Summary 管理员：获取组织订阅
+// @Description 获取组织当前生效订阅
+// @Tags 管理-订阅
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "组织ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 404 {object} map[string]interface{} "订阅不存在"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /admin/orgs/{id}/subscription [get]
+func (h *PlanSubscriptionHandler) GetOrgActive(c *gin.Context) {
+	if h == nil || h.svc == nil {
+		respondInternal(c, "订阅服务未配置")
+		return
+	}
+
+	orgID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orgID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "组织ID不正确"})
+		return
+	}
+
+	item, err := h.svc.GetActiveSubscription(c.Request.Context(), orgID, time.Now().UTC())
+	if err != nil {
+		respondInternal(c, "获取订阅失败")
+		return
+	}
+	if item == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "订阅不存在"})
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// GetUserActive godoc
 // @Summary 管理员：获取用户订阅
-// @Description 获取用户当前生效订阅
+// @Description 获取用户个人组织当前生效的订阅
 // @Tags 管理-订阅
 // @Accept json
 // @Produce json
@@ -191,8 +229,8 @@ func (h *PlanSubscriptionHandler) Update(c *gin.Context) {
 // @Failure 404 {object} map[string]interface{} "订阅不存在"
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /admin/users/{id}/subscription [get]
-func (h *PlanSubscriptionHandler) GetOrgActive(c *gin.Context) {
-	if h == nil || h.svc == nil {
+func (h *PlanSubscriptionHandler) GetUserActive(c *gin.Context) {
+	if h == nil || h.svc == nil || h.orgs == nil {
 		respondInternal(c, "订阅服务未配置")
 		return
 	}
@@ -203,7 +241,17 @@ func (h *PlanSubscriptionHandler) GetOrgActive(c *gin.Context) {
 		return
 	}
 
-	item, err := h.svc.GetActiveSubscription(c.Request.Context(), userID, time.Now().UTC())
+	personal, err := h.orgs.AdminGetPersonal(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, org.ErrOrgNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "订阅不存在"})
+			return
+		}
+		respondInternal(c, "获取订阅失败")
+		return
+	}
+
+	item, err := h.svc.GetActiveSubscription(c.Request.Context(), personal.ID, time.Now().UTC())
 	if err != nil {
 		respondInternal(c, "获取订阅失败")
 		return
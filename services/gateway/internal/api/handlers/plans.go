@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"strings"
 
+	"deepspace-shared/money"
 	planservice "deepspace/internal/service/plan"
 
 	"github.com/gin-gonic/gin"
@@ -19,23 +20,46 @@ func NewPlanHandler(svc *planservice.Service) *PlanHandler {
 }
 
 type planCreateRequest struct {
-	Name              string  `json:"name"`
-	Status            string  `json:"status"`
-	IncludedTokens    int64   `json:"included_tokens"`
-	IncludedRequests  int64   `json:"included_requests"`
-	ResetIntervalDays int     `json:"reset_interval_days"`
-	Price             float64 `json:"price"`
-	Currency          string  `json:"currency"`
+	Name              string                 `json:"name"`
+	Status            string                 `json:"status"`
+	IncludedTokens    int64                  `json:"included_tokens"`
+	IncludedRequests  int64                  `json:"included_requests"`
+	ResetIntervalDays int                    `json:"reset_interval_days"`
+	Price             money.Amount           `json:"price" swaggertype:"number"`
+	Currency          string                 `json:"currency"`
+	OverageMultiplier money.Rate             `json:"overage_multiplier" swaggertype:"number"`
+	QuotaEnforcement  string                 `json:"quota_enforcement"`
+	GracePeriodDays   int                    `json:"grace_period_days"`
+	RolloverTokens    int64                  `json:"rollover_tokens"`
+	RolloverRequests  int64                  `json:"rollover_requests"`
+	RolloverDays      int                    `json:"rollover_days"`
+	Allowances        []planAllowanceRequest `json:"allowances"`
 }
 
 type planUpdateRequest struct {
-	Name              *string  `json:"name"`
-	Status            *string  `json:"status"`
-	IncludedTokens    *int64   `json:"included_tokens"`
-	IncludedRequests  *int64   `json:"included_requests"`
-	ResetIntervalDays *int     `json:"reset_interval_days"`
-	Price             *float64 `json:"price"`
-	Currency          *string  `json:"currency"`
+	Name              *string                 `json:"name"`
+	Status            *string                 `json:"status"`
+	IncludedTokens    *int64                  `json:"included_tokens"`
+	IncludedRequests  *int64                  `json:"included_requests"`
+	ResetIntervalDays *int                    `json:"reset_interval_days"`
+	Price             *money.Amount           `json:"price" swaggertype:"number"`
+	Currency          *string                 `json:"currency"`
+	OverageMultiplier *money.Rate             `json:"overage_multiplier" swaggertype:"number"`
+	QuotaEnforcement  *string                 `json:"quota_enforcement"`
+	GracePeriodDays   *int                    `json:"grace_period_days"`
+	RolloverTokens    *int64                  `json:"rollover_tokens"`
+	RolloverRequests  *int64                  `json:"rollover_requests"`
+	RolloverDays      *int                    `json:"rollover_days"`
+	Allowances        *[]planAllowanceRequest `json:"allowances"`
+}
+
+type planAllowanceRequest struct {
+	Scope            string `json:"scope"`
+	Match            string `json:"match"`
+	IncludedTokens   int64  `json:"included_tokens"`
+	IncludedRequests int64  `json:"included_requests"`
+	RolloverTokens   int64  `json:"rollover_tokens"`
+	RolloverRequests int64  `json:"rollover_requests"`
 }
 
 // ListPublic godoc
@@ -92,7 +116,7 @@ func (h *PlanHandler) List(c *gin.Context) {
 
 // Create godoc
 // @Summary 管理员：创建套餐
-// @Description 需要管理员权限
+// @Description 需要管理员权限。included_tokens 与 included_requests 为通用额度，可同时设置；allowances 为限定模型（scope=model，match 为模型名）或能力（scope=capability）的额度，命中的请求只消耗该额度；overage_multiplier 为超额部分的价格倍率，默认 1；quota_enforcement 为 block 时额度用完后在调用上游前拒绝请求（429），为 overage（默认）时转为钱包计费；grace_period_days 为订阅到期后仍按生效处理的宽限天数（0-90，默认 0）；rollover_tokens / rollover_requests 为通用额度每周期最多结转到下一周期的未用量（限定额度可分别设置），结转额度优先消耗，在下一周期开始 rollover_days 天后失效（0 为随下一周期结束失效）
 // @Tags 管理-套餐
 // @Accept json
 // @Produce json
@@ -125,6 +149,13 @@ func (h *PlanHandler) Create(c *gin.Context) {
 		ResetIntervalDays: req.ResetIntervalDays,
 		Price:             req.Price,
 		Currency:          strings.TrimSpace(req.Currency),
+		OverageMultiplier: req.OverageMultiplier,
+		QuotaEnforcement:  req.QuotaEnforcement,
+		GracePeriodDays:   req.GracePeriodDays,
+		RolloverTokens:    req.RolloverTokens,
+		RolloverRequests:  req.RolloverRequests,
+		RolloverDays:      req.RolloverDays,
+		Allowances:        planAllowanceInputs(req.Allowances),
 	})
 	if err != nil {
 		handlePlanError(c, err)
@@ -136,7 +167,7 @@ func (h *PlanHandler) Create(c *gin.Context) {
 
 // Update godoc
 // @Summary 管理员：更新套餐
-// @Description 需要管理员权限
+// @Description 需要管理员权限；传入 allowances 时替换套餐的全部限定额度
 // @Tags 管理-套餐
 // @Accept json
 // @Produce json
@@ -169,6 +200,12 @@ func (h *PlanHandler) Update(c *gin.Context) {
 		return
 	}
 
+	var allowances *[]planservice.AllowanceInput
+	if req.Allowances != nil {
+		inputs := planAllowanceInputs(*req.Allowances)
+		allowances = &inputs
+	}
+
 	item, err := h.svc.UpdatePlan(c.Request.Context(), id, planservice.PlanUpdateInput{
 		Name:              req.Name,
 		Status:            req.Status,
@@ -177,6 +214,13 @@ func (h *PlanHandler) Update(c *gin.Context) {
 		ResetIntervalDays: req.ResetIntervalDays,
 		Price:             req.Price,
 		Currency:          req.Currency,
+		OverageMultiplier: req.OverageMultiplier,
+		QuotaEnforcement:  req.QuotaEnforcement,
+		GracePeriodDays:   req.GracePeriodDays,
+		RolloverTokens:    req.RolloverTokens,
+		RolloverRequests:  req.RolloverRequests,
+		RolloverDays:      req.RolloverDays,
+		Allowances:        allowances,
 	})
 	if err != nil {
 		handlePlanError(c, err)
@@ -203,9 +247,34 @@ func handlePlanError(c *gin.Context, err error) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "套餐价格不正确"})
 	case planservice.ErrInvalidPlanCurrency:
 		c.JSON(http.StatusBadRequest, gin.H{"error": "价格单位不正确"})
+	case planservice.ErrInvalidPlanAllowance:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "限定额度不正确"})
+	case planservice.ErrInvalidOverageMultiplier:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "超额倍率不正确"})
+	case planservice.ErrInvalidQuotaEnforcement:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "额度用完后的处理方式不正确"})
+	case planservice.ErrInvalidGracePeriod:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "宽限期不正确"})
+	case planservice.ErrInvalidPlanRollover:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "额度结转设置不正确"})
 	case planservice.ErrPlanNotFound:
 		c.JSON(http.StatusNotFound, gin.H{"error": "套餐不存在"})
 	default:
 		respondInternal(c, "套餐操作失败")
 	}
 }
+
+func planAllowanceInputs(items []planAllowanceRequest) []planservice.AllowanceInput {
+	result := make([]planservice.AllowanceInput, 0, len(items))
+	for _, item := range items {
+		result = append(result, planservice.AllowanceInput{
+			Scope:            item.Scope,
+			Match:            item.Match,
+			IncludedTokens:   item.IncludedTokens,
+			IncludedRequests: item.IncludedRequests,
+			RolloverTokens:   item.RolloverTokens,
+			RolloverRequests: item.RolloverRequests,
+		})
+	}
+	return result
+}
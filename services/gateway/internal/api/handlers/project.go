@@ -33,9 +33,9 @@ func NewProjectHandler(svc *project.Service, knowledgeSvc *knowledge.Service) *P
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /projects [get]
 func (h *ProjectHandler) List(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -64,9 +64,9 @@ func (h *ProjectHandler) List(c *gin.Context) {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /projects/{id} [get]
 func (h *ProjectHandler) Get(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -110,9 +110,9 @@ type createProjectRequest struct {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /projects [post]
 func (h *ProjectHandler) Create(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -154,9 +154,9 @@ type updateProjectRequest struct {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /projects/{id} [patch]
 func (h *ProjectHandler) Update(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -210,9 +210,9 @@ func (h *ProjectHandler) Update(c *gin.Context) {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /projects/{id} [delete]
 func (h *ProjectHandler) Delete(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -248,9 +248,9 @@ func (h *ProjectHandler) Delete(c *gin.Context) {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /projects/stats [get]
 func (h *ProjectHandler) Stats(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
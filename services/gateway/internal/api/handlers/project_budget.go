@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"deepspace-shared/money"
+	"deepspace/internal/service/projectbudget"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProjectBudgetHandler struct {
+	svc *projectbudget.Service
+}
+
+func NewProjectBudgetHandler(svc *projectbudget.Service) *ProjectBudgetHandler {
+	return &ProjectBudgetHandler{svc: svc}
+}
+
+type projectBudgetRequest struct {
+	Cycle       string       `json:"cycle"`
+	Amount      money.Amount `json:"amount" swaggertype:"number"`
+	Currency    string       `json:"currency"`
+	Enforcement string       `json:"enforcement"`
+}
+
+// Get godoc
+// @Summary 项目预算
+// @Description 获取项目本周期的预算额度、已用与剩余金额；已用为周期内项目所有成员的用量
+// @Tags 项目预算
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "项目ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 404 {object} map[string]interface{} "项目或预算不存在"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /projects/{id}/budget [get]
+func (h *ProjectBudgetHandler) Get(c *gin.Context) {
+	orgID, projectID, ok := projectBudgetIDs(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.svc.Get(c.Request.Context(), orgID, projectID)
+	if err != nil {
+		respondProjectBudgetError(c, err, "failed to get project budget")
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// Set godoc
+// @Summary 设置项目预算
+// @Description 设置项目每个周期可从组织钱包花费的额度（owner/admin/billing）。cycle 为 daily/weekly/monthly，默认 monthly；enforcement 为 hard（用完后拒绝请求）或 soft（只在响应头 X-Project-Budget-Exceeded 中提示），默认 hard；currency 为空时使用钱包币种
+// @Tags 项目预算
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "项目ID"
+// @Param data body projectBudgetRequest true "预算数据"
+// @Success 200 {object} map[string]interface{} "设置成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 404 {object} map[string]interface{} "项目不存在"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /projects/{id}/budget [put]
+func (h *ProjectBudgetHandler) Set(c *gin.Context) {
+	orgID, projectID, ok := projectBudgetIDs(c)
+	if !ok {
+		return
+	}
+
+	var req projectBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	item, err := h.svc.Set(c.Request.Context(), orgID, projectID, projectbudget.SetInput{
+		Cycle:       req.Cycle,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Enforcement: req.Enforcement,
+	})
+	if err != nil {
+		respondProjectBudgetError(c, err, "failed to set project budget")
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// Delete godoc
+// @Summary 删除项目预算
+// @Description 删除项目预算（owner/admin/billing），删除后项目不再单独限额
+// @Tags 项目预算
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "项目ID"
+// @Success 204 "删除成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 403 {object} map[string]interface{} "无权限"
+// @Failure 404 {object} map[string]interface{} "项目或预算不存在"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /projects/{id}/budget [delete]
+func (h *ProjectBudgetHandler) Delete(c *gin.Context) {
+	orgID, projectID, ok := projectBudgetIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Delete(c.Request.Context(), orgID, projectID); err != nil {
+		respondProjectBudgetError(c, err, "failed to delete project budget")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func projectBudgetIDs(c *gin.Context) (int64, int64, bool) {
+	orgID, ok := getOrgID(c)
+	if !ok {
+		respondInternal(c, "org_id 缺失")
+		return 0, 0, false
+	}
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || projectID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return 0, 0, false
+	}
+	return orgID, projectID, true
+}
+
+func respondProjectBudgetError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, projectbudget.ErrInvalidCycle):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cycle"})
+	case errors.Is(err, projectbudget.ErrInvalidAmount):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid amount"})
+	case errors.Is(err, projectbudget.ErrInvalidCurrency):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid currency"})
+	case errors.Is(err, projectbudget.ErrInvalidEnforcement):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid enforcement"})
+	case errors.Is(err, projectbudget.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+	case errors.Is(err, projectbudget.ErrBudgetNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "project budget not found"})
+	default:
+		respondInternal(c, fallback)
+	}
+}
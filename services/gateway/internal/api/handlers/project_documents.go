@@ -32,9 +32,9 @@ func NewProjectDocumentHandler(svc *projectdocument.Service) *ProjectDocumentHan
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /projects/{id}/documents [get]
 func (h *ProjectDocumentHandler) List(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -75,9 +75,9 @@ type createDocumentRequest struct {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /projects/{id}/documents [post]
 func (h *ProjectDocumentHandler) Create(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -119,9 +119,9 @@ func (h *ProjectDocumentHandler) Create(c *gin.Context) {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /projects/{id}/documents/{docId} [get]
 func (h *ProjectDocumentHandler) Get(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -174,9 +174,9 @@ type updateDocumentRequest struct {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /projects/{id}/documents/{docId} [patch]
 func (h *ProjectDocumentHandler) Update(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -237,9 +237,9 @@ func (h *ProjectDocumentHandler) Update(c *gin.Context) {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /projects/{id}/documents/{docId} [delete]
 func (h *ProjectDocumentHandler) Delete(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
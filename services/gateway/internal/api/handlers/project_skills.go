@@ -32,9 +32,9 @@ func NewProjectSkillHandler(svc *projectskill.Service) *ProjectSkillHandler {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /projects/{id}/skills [get]
 func (h *ProjectSkillHandler) List(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -75,9 +75,9 @@ type createSkillRequest struct {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /projects/{id}/skills [post]
 func (h *ProjectSkillHandler) Create(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -130,9 +130,9 @@ type updateSkillRequest struct {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /projects/{id}/skills/{skillId} [patch]
 func (h *ProjectSkillHandler) Update(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -193,9 +193,9 @@ func (h *ProjectSkillHandler) Update(c *gin.Context) {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /projects/{id}/skills/{skillId} [delete]
 func (h *ProjectSkillHandler) Delete(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
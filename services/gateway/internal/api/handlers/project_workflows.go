@@ -34,9 +34,9 @@ func NewProjectWorkflowHandler(svc *projectworkflow.Service) *ProjectWorkflowHan
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /projects/{id}/workflows [get]
 func (h *ProjectWorkflowHandler) List(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -77,9 +77,9 @@ type createWorkflowRequest struct {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /projects/{id}/workflows [post]
 func (h *ProjectWorkflowHandler) Create(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -138,9 +138,9 @@ type updateWorkflowRequest struct {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /projects/{id}/workflows/{workflowId} [patch]
 func (h *ProjectWorkflowHandler) Update(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -212,9 +212,9 @@ func (h *ProjectWorkflowHandler) Update(c *gin.Context) {
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /projects/{id}/workflows/{workflowId} [delete]
 func (h *ProjectWorkflowHandler) Delete(c *gin.Context) {
-	orgID, ok := getUserID(c)
+	orgID, ok := getOrgID(c)
 	if !ok {
-		respondInternal(c, "user_id 缺失")
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
@@ -2,41 +2,47 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
-	"deepspace/internal/integrations/newapi"
 	"deepspace/internal/pipeline"
 	"deepspace/internal/pipeline/steps"
 	"deepspace/internal/service/billing"
 	modelservice "deepspace/internal/service/model"
+	"deepspace/internal/service/pipelinechain"
 	planservice "deepspace/internal/service/plan"
-	"deepspace/internal/service/risk"
-	"deepspace/internal/service/usage"
+	"deepspace/internal/service/projectbudget"
 
 	"github.com/gin-gonic/gin"
 )
 
 const (
-	billingAmountHeader = "X-Billing-Amount"
-	billingRefHeader    = "X-Billing-Ref-Id"
+	projectBudgetRemainingHeader = "X-Project-Budget-Remaining"
+	projectBudgetCurrencyHeader  = "X-Project-Budget-Currency"
+	projectBudgetExceededHeader  = "X-Project-Budget-Exceeded"
+
+	planQuotaRemainingTokensHeader   = "X-Plan-Quota-Remaining-Tokens"
+	planQuotaRemainingRequestsHeader = "X-Plan-Quota-Remaining-Requests"
+
+	// postChainTimeout 限制后置链路（结算、用量记录）在客户端断开后的最长执行时间。
+	postChainTimeout = 30 * time.Second
 )
 
 type ProxyHandler struct {
 	billing *billing.Service
-	usage   *usage.Service
-	newapi  *newapi.Client
 	model   *modelservice.Service
-	plan    *planservice.Service
-	risk    *risk.Service
+	chains  *pipelinechain.Service
 }
 
-func NewProxyHandler(billingSvc *billing.Service, usageSvc *usage.Service, riskSvc *risk.Service, newapiClient *newapi.Client, modelSvc *modelservice.Service, planSvc *planservice.Service) *ProxyHandler {
-	return &ProxyHandler{billing: billingSvc, usage: usageSvc, risk: riskSvc, newapi: newapiClient, model: modelSvc, plan: planSvc}
+func NewProxyHandler(billingSvc *billing.Service, modelSvc *modelservice.Service, chainSvc *pipelinechain.Service) *ProxyHandler {
+	return &ProxyHandler{billing: billingSvc, model: modelSvc, chains: chainSvc}
 }
 
 // Handle godoc
@@ -52,7 +58,7 @@ func NewProxyHandler(billingSvc *billing.Service, usageSvc *usage.Service, riskS
 // @Failure 400 {object} map[string]interface{} "请求错误"
 // @Failure 404 {object} map[string]interface{} "接口不存在"
 // @Failure 401 {object} map[string]interface{} "未登录"
-// @Failure 402 {object} map[string]interface{} "余额不足"
+// @Failure 402 {object} map[string]interface{} "余额不足或项目预算已用完"
 // @Failure 500 {object} map[string]interface{} "服务内部错误"
 // @Router /v1/{path} [get]
 // @Router /v1/{path} [post]
@@ -60,8 +66,8 @@ func NewProxyHandler(billingSvc *billing.Service, usageSvc *usage.Service, riskS
 // @Router /v1/{path} [patch]
 // @Router /v1/{path} [delete]
 func (h *ProxyHandler) Handle(c *gin.Context) {
-	if h.newapi == nil {
-		respondInternal(c, "newapi client not configured")
+	if h.chains == nil {
+		respondInternal(c, "pipeline not configured")
 		return
 	}
 
@@ -70,45 +76,29 @@ func (h *ProxyHandler) Handle(c *gin.Context) {
 		respondInternal(c, "user_id 缺失")
 		return
 	}
-
-	if isModelListRequest(c) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	orgID, ok := getOrgID(c)
+	if !ok {
+		respondInternal(c, "org_id 缺失")
 		return
 	}
 
-	// If billing is enabled but no amount is provided, still guard zero-balance usage.
-	if h.billing != nil {
-		wallet, err := h.billing.GetWallet(c.Request.Context(), userID)
-		if err != nil {
-			respondInternal(c, "failed to load wallet")
-			return
-		}
-		if wallet != nil && wallet.Balance <= 0 {
-			c.JSON(http.StatusPaymentRequired, gin.H{"error": "insufficient balance"})
-			return
-		}
-	}
-
-	amount, hasAmount, err := parseBillingAmount(c)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid billing amount"})
+	if isModelListRequest(c) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
 	}
 
-	modelName := peekModelFromBody(c)
+	rawBody := readRequestBody(c)
+	modelName := peekModel(rawBody)
 
 	state := pipeline.NewState()
 	state.UserID = userID
-	state.CostAmount = amount
+	state.OrgID = orgID
+	state.RequestBody = rawBody
+	state.Method = c.Request.Method
+	state.Path = c.Request.URL.RequestURI()
+	state.RequestHeader = c.Request.Header.Clone()
+	state.ResponseWriter = c.Writer
 	state.Model = modelName
-	if hasAmount {
-		state.Meta["billing_amount_provided"] = true
-	}
-	state.RefID = resolveRefID(c, hasAmount)
-	if state.RefID == "" && hasAmount {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ref_id is required for billing"})
-		return
-	}
 	modelName = strings.TrimSpace(modelName)
 	if modelName == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "model is required"})
@@ -127,6 +117,7 @@ func (h *ProxyHandler) Handle(c *gin.Context) {
 		state.Meta["price_input"] = item.PriceInput
 		state.Meta["price_output"] = item.PriceOutput
 		state.Meta["currency"] = item.Currency
+		state.Meta["capabilities"] = item.Capabilities
 	}
 	if value, ok := c.Get("trace_id"); ok {
 		if v, ok := value.(string); ok {
@@ -134,6 +125,10 @@ func (h *ProxyHandler) Handle(c *gin.Context) {
 		}
 	}
 	state.Meta["client_ip"] = c.ClientIP()
+	state.Meta["user_agent"] = c.Request.UserAgent()
+	if value, ok := c.Get("auth_method"); ok {
+		state.Meta["auth_method"] = value
+	}
 	if value, ok := c.Get("project_id"); ok {
 		switch v := value.(type) {
 		case int64:
@@ -156,16 +151,19 @@ func (h *ProxyHandler) Handle(c *gin.Context) {
 			}
 		}
 	}
-	if state.RefID == "" && state.TraceID != "" {
-		state.RefID = state.TraceID
+	// 费用由网关按模型单价估算与结算，不接受客户端指定的金额；预扣与结算以 trace_id 作为 ref。
+	state.RefID = state.TraceID
+
+	chain, err := h.chains.Resolve(c.Request.Context(), state.ProjectID, state.Model)
+	if err != nil {
+		respondInternal(c, "failed to resolve pipeline")
+		return
 	}
 
-	pre := pipeline.New(
-		steps.NewAuth(),
-		steps.NewPolicy(h.risk, h.usage),
-		steps.NewBudgetHold(h.billing),
-	)
-	if err := pre.Run(c.Request.Context(), state); err != nil {
+	err = chain.Pre.Run(c.Request.Context(), state)
+	writeProjectBudgetHeaders(c, state)
+	writePlanQuotaHeaders(c, state)
+	if err != nil {
 		switch {
 		case errors.Is(err, steps.ErrRiskIPDenied):
 			c.JSON(http.StatusForbidden, gin.H{"error": "IP 已被限制"})
@@ -173,24 +171,60 @@ func (h *ProxyHandler) Handle(c *gin.Context) {
 			c.JSON(http.StatusTooManyRequests, gin.H{"error": "请求过于频繁"})
 		case errors.Is(err, steps.ErrRiskBudgetExceeded):
 			c.JSON(http.StatusPaymentRequired, gin.H{"error": "预算已超限"})
+		case errors.Is(err, steps.ErrProjectBudgetExceeded):
+			c.JSON(http.StatusPaymentRequired, gin.H{"error": "项目预算已用完"})
+		case errors.Is(err, steps.ErrPlanQuotaExhausted):
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "套餐额度已用完"})
 		default:
 			respondBillingError(c, err)
 		}
 		return
 	}
 
-	h.newapi.Proxy(c)
-	state.StatusCode = c.Writer.Status()
-	if usage, ok := newapi.GetUsageFromContext(c); ok {
-		state.UsagePromptTokens = usage.PromptTokens
-		state.UsageCompletionTokens = usage.CompletionTokens
-		state.UsageTotalTokens = usage.TotalTokens
+	// 上游调用由注册表中的 newapi_call 步骤完成，失败时仍需执行后置链路释放预扣，因此不放在前置链路中。
+	if err := chain.Call.Run(c.Request.Context(), state); err != nil {
+		state.Error = err
+		// 已开始向客户端写出时只能中断，否则按网关错误返回并交由后置步骤释放预扣。
+		if !c.Writer.Written() {
+			state.StatusCode = http.StatusBadGateway
+			c.JSON(http.StatusBadGateway, gin.H{"error": "upstream unavailable"})
+		}
+	}
+
+	// 客户端断开（如流式中途关闭）会取消请求上下文，结算与用量记录改用不随请求取消、带超时的上下文，
+	// 否则扣款失败后预扣会被回收任务释放，用量不再计费。
+	postCtx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), postChainTimeout)
+	defer cancel()
+	if err := chain.Post.Run(postCtx, state); err != nil {
+		log.Printf("post pipeline failed trace=%s ref=%s: %v", state.TraceID, state.RefID, err)
 	}
+}
 
-	post := pipeline.New(
-		steps.NewUsageCapture(h.billing, h.usage, h.plan),
-	)
-	_ = post.Run(c.Request.Context(), state)
+// writeProjectBudgetHeaders 在响应头中返回项目本周期剩余预算；soft 预算超额时仍放行，只通过 X-Project-Budget-Exceeded 提示。
+func writeProjectBudgetHeaders(c *gin.Context, state *pipeline.State) {
+	status, ok := state.Meta[steps.ProjectBudgetMetaKey].(*projectbudget.Status)
+	if !ok || status == nil {
+		return
+	}
+	c.Header(projectBudgetRemainingHeader, status.Remaining.String())
+	c.Header(projectBudgetCurrencyHeader, status.Currency)
+	if status.Exceeded {
+		c.Header(projectBudgetExceededHeader, "true")
+	}
+}
+
+// writePlanQuotaHeaders 在响应头中返回请求所匹配的套餐额度在调用前的剩余量，只输出计量的维度。
+func writePlanQuotaHeaders(c *gin.Context, state *pipeline.State) {
+	check, ok := state.Meta[steps.PlanQuotaMetaKey].(*planservice.QuotaCheck)
+	if !ok || check == nil {
+		return
+	}
+	if check.Allowance.IncludedTokens > 0 {
+		c.Header(planQuotaRemainingTokensHeader, strconv.FormatInt(check.Allowance.RemainingTokens, 10))
+	}
+	if check.Allowance.IncludedRequests > 0 {
+		c.Header(planQuotaRemainingRequestsHeader, strconv.FormatInt(check.Allowance.RemainingRequests, 10))
+	}
 }
 
 func isModelListRequest(c *gin.Context) bool {
@@ -207,47 +241,25 @@ func isModelListRequest(c *gin.Context) bool {
 	return path == "/models" || strings.HasSuffix(path, "/models")
 }
 
-func parseBillingAmount(c *gin.Context) (float64, bool, error) {
-	value := strings.TrimSpace(c.GetHeader(billingAmountHeader))
-	if value == "" {
-		return 0, false, nil
+func readRequestBody(c *gin.Context) []byte {
+	if c.Request.Body == nil {
+		return nil
 	}
 
-	amount, err := strconv.ParseFloat(value, 64)
+	raw, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		return 0, true, err
+		return nil
 	}
-	return amount, true, nil
-}
 
-func resolveRefID(c *gin.Context, hasAmount bool) string {
-	if !hasAmount {
-		return ""
-	}
-	refID := strings.TrimSpace(c.GetHeader(billingRefHeader))
-	if refID != "" {
-		return refID
-	}
-	if trace, ok := c.Get("trace_id"); ok {
-		if v, ok := trace.(string); ok {
-			return v
-		}
-	}
-	return ""
+	c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+	return raw
 }
 
-func peekModelFromBody(c *gin.Context) string {
-	if c.Request.Body == nil {
+func peekModel(raw []byte) string {
+	if len(raw) == 0 {
 		return ""
 	}
 
-	raw, err := io.ReadAll(c.Request.Body)
-	if err != nil {
-		return ""
-	}
-
-	c.Request.Body = io.NopCloser(bytes.NewReader(raw))
-
 	var payload struct {
 		Model string `json:"model"`
 	}
@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	planservice "deepspace/internal/service/plan"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SubscriptionHandler struct {
+	svc *planservice.Service
+}
+
+func NewSubscriptionHandler(svc *planservice.Service) *SubscriptionHandler {
+	return &SubscriptionHandler{svc: svc}
+}
+
+type subscribeRequest struct {
+	PlanID    int64 `json:"plan_id"`
+	AutoRenew *bool `json:"auto_renew"`
+}
+
+// Get godoc
+// @Summary 当前订阅
+// @Description 获取当前组织生效的套餐订阅，没有订阅时 subscription 为 null
+// @Tags 计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /billing/subscription [get]
+func (h *SubscriptionHandler) Get(c *gin.Context) {
+	orgID, ok := getOrgID(c)
+	if !ok {
+		respondInternal(c, "org_id 缺失")
+		return
+	}
+
+	item, err := h.svc.GetSubscriptionView(c.Request.Context(), orgID)
+	if err != nil {
+		respondInternal(c, "failed to get subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscription": item})
+}
+
+// Subscribe godoc
+// @Summary 订阅套餐
+// @Description 订阅 /api/plans 中的套餐，从组织钱包扣除首个周期费用（按当前汇率换算为钱包币种）；auto_renew 默认为 true，周期结束时按订阅时的价格自动续费
+// @Tags 计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param data body subscribeRequest true "套餐"
+// @Success 201 {object} map[string]interface{} "订阅成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 402 {object} map[string]interface{} "余额不足"
+// @Failure 404 {object} map[string]interface{} "套餐不存在"
+// @Failure 409 {object} map[string]interface{} "已有生效订阅"
+// @Failure 410 {object} map[string]interface{} "套餐已下架"
+// @Failure 422 {object} map[string]interface{} "缺少汇率"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /billing/subscription [post]
+func (h *SubscriptionHandler) Subscribe(c *gin.Context) {
+	orgID, ok := getOrgID(c)
+	if !ok {
+		respondInternal(c, "org_id 缺失")
+		return
+	}
+
+	var req subscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	autoRenew := true
+	if req.AutoRenew != nil {
+		autoRenew = *req.AutoRenew
+	}
+
+	item, err := h.svc.Subscribe(c.Request.Context(), orgID, req.PlanID, autoRenew)
+	if err != nil {
+		respondSubscriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// Cancel godoc
+// @Summary 取消订阅
+// @Description 关闭自动续费，订阅在当前周期结束时失效，已付费用不退还
+// @Tags 计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Success 200 {object} map[string]interface{} "取消成功"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 404 {object} map[string]interface{} "没有生效订阅"
+// @Failure 409 {object} map[string]interface{} "订阅由管理员创建"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /billing/subscription/cancel [post]
+func (h *SubscriptionHandler) Cancel(c *gin.Context) {
+	orgID, ok := getOrgID(c)
+	if !ok {
+		respondInternal(c, "org_id 缺失")
+		return
+	}
+
+	item, err := h.svc.CancelSubscription(c.Request.Context(), orgID)
+	if err != nil {
+		respondSubscriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// Resume godoc
+// @Summary 恢复自动续费
+// @Description 撤销取消，恢复自动续费；订阅已到期但仍在套餐宽限期内时，Worker 会重新尝试扣款续费
+// @Tags 计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Success 200 {object} map[string]interface{} "恢复成功"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 404 {object} map[string]interface{} "没有生效订阅"
+// @Failure 409 {object} map[string]interface{} "订阅由管理员创建"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /billing/subscription/resume [post]
+func (h *SubscriptionHandler) Resume(c *gin.Context) {
+	orgID, ok := getOrgID(c)
+	if !ok {
+		respondInternal(c, "org_id 缺失")
+		return
+	}
+
+	item, err := h.svc.ResumeSubscription(c.Request.Context(), orgID)
+	if err != nil {
+		respondSubscriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+type changePlanRequest struct {
+	PlanID int64 `json:"plan_id"`
+}
+
+// Change godoc
+// @Summary 变更套餐
+// @Description 按钱包币种的日均价格比较：新套餐更贵时立即升级，按当前周期剩余时长补差价（新套餐费用减去原套餐未用部分的抵扣），本周期已用的用量计入新套餐；否则安排在当前周期结束时降级，下一周期用量从零开始；已取消自动续费的订阅须先恢复才能降级
+// @Tags 计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param data body changePlanRequest true "目标套餐"
+// @Success 200 {object} map[string]interface{} "变更成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 402 {object} map[string]interface{} "余额不足"
+// @Failure 404 {object} map[string]interface{} "套餐或订阅不存在"
+// @Failure 409 {object} map[string]interface{} "套餐未变化、订阅由管理员创建、订阅已到期或已取消自动续费"
+// @Failure 410 {object} map[string]interface{} "套餐已下架"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /billing/subscription/change [post]
+func (h *SubscriptionHandler) Change(c *gin.Context) {
+	orgID, ok := getOrgID(c)
+	if !ok {
+		respondInternal(c, "org_id 缺失")
+		return
+	}
+
+	var req changePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	item, err := h.svc.ChangePlan(c.Request.Context(), orgID, req.PlanID)
+	if err != nil {
+		respondSubscriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// CancelChange godoc
+// @Summary 撤销降级
+// @Description 撤销尚未生效的降级，续费时继续使用当前套餐
+// @Tags 计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Success 200 {object} map[string]interface{} "撤销成功"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 404 {object} map[string]interface{} "没有生效订阅或待生效的降级"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /billing/subscription/change [delete]
+func (h *SubscriptionHandler) CancelChange(c *gin.Context) {
+	orgID, ok := getOrgID(c)
+	if !ok {
+		respondInternal(c, "org_id 缺失")
+		return
+	}
+
+	item, err := h.svc.CancelScheduledChange(c.Request.Context(), orgID)
+	if err != nil {
+		respondSubscriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// Quota godoc
+// @Summary 套餐额度
+// @Description 返回当前订阅本周期各项额度的已用与剩余量及周期起止时间（没有订阅或套餐不含额度时 quota 为 null），以及按周期倒序的历史用量（含当前周期）。额度中 allowance_id 为 0 的是通用额度，其余为限定模型或能力的额度；included 为 0 的维度不计量；remaining 含未失效的结转额度，rollover 为从上一周期结转的额度、已用量与失效时间
+// @Tags 计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param limit query int false "历史周期数，默认 12，最大 100"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /billing/quota [get]
+func (h *SubscriptionHandler) Quota(c *gin.Context) {
+	orgID, ok := getOrgID(c)
+	if !ok {
+		respondInternal(c, "org_id 缺失")
+		return
+	}
+	limit := parseIntQuery(c, "limit", 12)
+	if limit <= 0 || limit > 100 {
+		limit = 12
+	}
+
+	quota, _, err := h.svc.GetActivePlanQuota(c.Request.Context(), orgID, time.Now().UTC())
+	if err != nil && !errors.Is(err, planservice.ErrPlanNotFound) {
+		respondInternal(c, "failed to get quota")
+		return
+	}
+	history, err := h.svc.QuotaHistory(c.Request.Context(), orgID, limit)
+	if err != nil {
+		respondInternal(c, "failed to get quota history")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quota": quota, "history": history})
+}
+
+func respondSubscriptionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, planservice.ErrPlanNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "plan not found"})
+	case errors.Is(err, planservice.ErrPlanUnavailable):
+		c.JSON(http.StatusGone, gin.H{"error": "plan unavailable"})
+	case errors.Is(err, planservice.ErrActiveSubscriptionExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "active subscription exists"})
+	case errors.Is(err, planservice.ErrSubscriptionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "no active subscription"})
+	case errors.Is(err, planservice.ErrNotSelfService):
+		c.JSON(http.StatusConflict, gin.H{"error": "subscription is managed by an administrator"})
+	case errors.Is(err, planservice.ErrPlanUnchanged):
+		c.JSON(http.StatusConflict, gin.H{"error": "already subscribed to this plan"})
+	case errors.Is(err, planservice.ErrSubscriptionCurrencyChanged):
+		c.JSON(http.StatusConflict, gin.H{"error": "wallet currency differs from subscription"})
+	case errors.Is(err, planservice.ErrNoScheduledChange):
+		c.JSON(http.StatusNotFound, gin.H{"error": "no scheduled plan change"})
+	case errors.Is(err, planservice.ErrSubscriptionEnded):
+		c.JSON(http.StatusConflict, gin.H{"error": "subscription has ended, resume auto-renew to continue"})
+	case errors.Is(err, planservice.ErrSubscriptionCanceled):
+		c.JSON(http.StatusConflict, gin.H{"error": "subscription is canceled, resume auto-renew to schedule a downgrade"})
+	default:
+		respondBillingError(c, err)
+	}
+}
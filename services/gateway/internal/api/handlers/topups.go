@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"deepspace-shared/money"
+	"deepspace/internal/integrations/payment"
+	"deepspace/internal/service/billing"
+	"deepspace/internal/service/topup"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBodyBytes 限制支付回调请求体大小。
+const maxWebhookBodyBytes = 1 << 20
+
+type TopUpHandler struct {
+	svc *topup.Service
+}
+
+func NewTopUpHandler(svc *topup.Service) *TopUpHandler {
+	return &TopUpHandler{svc: svc}
+}
+
+type topUpCheckoutRequest struct {
+	Amount money.Amount `json:"amount" swaggertype:"number"`
+}
+
+// Create godoc
+// @Summary 发起充值
+// @Description 以钱包币种创建充值订单，返回支付渠道的收银台地址；支付完成后由渠道回调入账
+// @Tags 计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param data body topUpCheckoutRequest true "充值金额"
+// @Success 201 {object} map[string]interface{} "创建成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Failure 503 {object} map[string]interface{} "未启用自助充值"
+// @Router /billing/topups [post]
+func (h *TopUpHandler) Create(c *gin.Context) {
+	orgID, ok := getOrgID(c)
+	if !ok {
+		respondInternal(c, "org_id 缺失")
+		return
+	}
+
+	var req topUpCheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	item, err := h.svc.CreateCheckout(c.Request.Context(), orgID, req.Amount)
+	if err != nil {
+		respondTopUpError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// List godoc
+// @Summary 充值订单列表
+// @Description 获取当前用户的充值订单
+// @Tags 计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param status query string false "状态（pending/succeeded/failed）"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /billing/topups [get]
+func (h *TopUpHandler) List(c *gin.Context) {
+	orgID, ok := getOrgID(c)
+	if !ok {
+		respondInternal(c, "org_id 缺失")
+		return
+	}
+
+	page := parseIntQuery(c, "page", 1)
+	pageSize := parseIntQuery(c, "page_size", 20)
+
+	items, total, err := h.svc.List(c.Request.Context(), topup.ListInput{
+		UserID:   &orgID,
+		Status:   c.Query("status"),
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		respondInternal(c, "failed to list top-ups")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":     items,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
+
+// Get godoc
+// @Summary 充值订单详情
+// @Description 获取当前用户的充值订单，用于轮询支付结果
+// @Tags 计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param id path int true "订单ID"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 404 {object} map[string]interface{} "订单不存在"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /billing/topups/{id} [get]
+func (h *TopUpHandler) Get(c *gin.Context) {
+	orgID, ok := getOrgID(c)
+	if !ok {
+		respondInternal(c, "org_id 缺失")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	item, err := h.svc.Get(c.Request.Context(), orgID, id)
+	if err != nil {
+		respondInternal(c, "failed to load top-up")
+		return
+	}
+	if item == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "top-up not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// Webhook godoc
+// @Summary 支付回调
+// @Description 支付渠道回调，验签后处理支付结果；重复投递是安全的
+// @Tags 计费
+// @Accept json
+// @Produce json
+// @Param provider path string true "支付渠道"
+// @Success 200 {object} map[string]interface{} "处理成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "签名不正确"
+// @Failure 404 {object} map[string]interface{} "订单不存在"
+// @Failure 409 {object} map[string]interface{} "回调与订单不一致"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Failure 503 {object} map[string]interface{} "未启用自助充值"
+// @Router /billing/webhooks/{provider} [post]
+func (h *TopUpHandler) Webhook(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBodyBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	item, err := h.svc.HandleWebhook(c.Request.Context(), c.Param("provider"), c.Request.Header, body)
+	if err != nil {
+		respondTopUpError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"received": true, "status": item.Status})
+}
+
+func respondTopUpError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, topup.ErrProviderNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "top-up unavailable"})
+	case errors.Is(err, topup.ErrInvalidAmount):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid amount"})
+	case errors.Is(err, payment.ErrInvalidSignature):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid signature"})
+	case errors.Is(err, payment.ErrInvalidEvent):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event"})
+	case errors.Is(err, topup.ErrOrderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "top-up not found"})
+	case errors.Is(err, topup.ErrEventMismatch), errors.Is(err, billing.ErrRefConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": "event does not match top-up"})
+	default:
+		respondInternal(c, "top-up failed")
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"deepspace/internal/service/billing"
+	"deepspace/internal/service/voucher"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VoucherHandler struct {
+	svc *voucher.Service
+}
+
+func NewVoucherHandler(svc *voucher.Service) *VoucherHandler {
+	return &VoucherHandler{svc: svc}
+}
+
+type voucherRedeemRequest struct {
+	Code string `json:"code"`
+}
+
+// Redeem godoc
+// @Summary 兑换代金券
+// @Description 兑换代金券，额度以 voucher 流水计入钱包，按当前汇率换算为钱包币种
+// @Tags 计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param data body voucherRedeemRequest true "兑换码"
+// @Success 200 {object} map[string]interface{} "兑换成功"
+// @Failure 400 {object} map[string]interface{} "请求错误"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 404 {object} map[string]interface{} "兑换码不存在"
+// @Failure 409 {object} map[string]interface{} "已兑换或次数已用完"
+// @Failure 410 {object} map[string]interface{} "兑换码已停用或过期"
+// @Failure 422 {object} map[string]interface{} "缺少汇率"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /billing/vouchers/redeem [post]
+func (h *VoucherHandler) Redeem(c *gin.Context) {
+	orgID, ok := getOrgID(c)
+	if !ok {
+		respondInternal(c, "org_id 缺失")
+		return
+	}
+
+	var req voucherRedeemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	result, err := h.svc.Redeem(c.Request.Context(), orgID, req.Code)
+	if err != nil {
+		respondVoucherError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// List godoc
+// @Summary 代金券兑换记录
+// @Description 获取当前用户的代金券兑换记录，含额度过期时间与已回收金额
+// @Tags 计费
+// @Accept json
+// @Produce json
+// @Security bearerAuth
+// @Security cookieAuth
+// @Param status query string false "状态（active/expired）"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} map[string]interface{} "获取成功"
+// @Failure 401 {object} map[string]interface{} "未登录"
+// @Failure 500 {object} map[string]interface{} "服务内部错误"
+// @Router /billing/vouchers [get]
+func (h *VoucherHandler) List(c *gin.Context) {
+	orgID, ok := getOrgID(c)
+	if !ok {
+		respondInternal(c, "org_id 缺失")
+		return
+	}
+
+	page := parseIntQuery(c, "page", 1)
+	pageSize := parseIntQuery(c, "page_size", 20)
+
+	items, total, err := h.svc.ListRedemptions(c.Request.Context(), voucher.RedemptionListInput{
+		UserID:   &orgID,
+		Status:   c.Query("status"),
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		respondInternal(c, "failed to list vouchers")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":     items,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
+
+func respondVoucherError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, voucher.ErrInvalidCode):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid voucher code"})
+	case errors.Is(err, voucher.ErrVoucherNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "voucher not found"})
+	case errors.Is(err, voucher.ErrAlreadyRedeemed):
+		c.JSON(http.StatusConflict, gin.H{"error": "voucher already redeemed"})
+	case errors.Is(err, voucher.ErrVoucherExhausted):
+		c.JSON(http.StatusConflict, gin.H{"error": "voucher fully redeemed"})
+	case errors.Is(err, voucher.ErrVoucherUnavailable):
+		c.JSON(http.StatusGone, gin.H{"error": "voucher disabled or expired"})
+	case errors.Is(err, billing.ErrRefConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": "voucher already redeemed"})
+	default:
+		respondBillingError(c, err)
+	}
+}
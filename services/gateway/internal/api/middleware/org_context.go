@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"deepspace/internal/service/org"
+
+	"github.com/gin-gonic/gin"
+)
+
+const orgIDHeader = "X-Org-Id"
+
+// OrgContext 按 X-Org-Id 请求头确定本次请求使用的组织，
+// 未指定时使用用户的个人组织；请求者不是该组织成员时拒绝请求。
+func OrgContext(orgSvc *org.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		orgID := int64(0)
+		if value := strings.TrimSpace(c.GetHeader(orgIDHeader)); value != "" {
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || parsed <= 0 {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+				return
+			}
+			orgID = parsed
+		}
+
+		current, err := orgSvc.Resolve(c.Request.Context(), userID.(int64), orgID)
+		if err != nil {
+			switch {
+			case errors.Is(err, org.ErrOrgNotFound):
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+			case errors.Is(err, org.ErrNotMember):
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "not a member of organization"})
+			case errors.Is(err, org.ErrUserUnavailable):
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
+			default:
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve organization"})
+			}
+			return
+		}
+
+		c.Set("org_id", current.Org.ID)
+		c.Set("org_role", current.Role)
+		c.Next()
+	}
+}
+
+// RequireOrgRole 要求请求者在当前组织中的角色属于 roles，需在 OrgContext 之后使用。
+func RequireOrgRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("org_role")
+		if !slices.Contains(roles, role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "permission denied"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -23,9 +23,11 @@ func UserAuth(jwtManager *auth.JWTManager) gin.HandlerFunc {
 			return
 		}
 
+		authMethod := "cookie"
 		token, err := c.Cookie(jwtManager.CookieName)
 		if err != nil || token == "" {
 			// Fallback for non-browser clients (optional).
+			authMethod = "bearer"
 			if authHeader := c.GetHeader("Authorization"); authHeader != "" {
 				parts := strings.SplitN(authHeader, " ", 2)
 				if len(parts) == 2 && strings.EqualFold(parts[0], "Bearer") {
@@ -59,7 +61,7 @@ func UserAuth(jwtManager *auth.JWTManager) gin.HandlerFunc {
 		}
 
 		c.Set("user_id", claims.UserID)
-		c.Set("org_id", claims.UserID)
+		c.Set("auth_method", authMethod)
 		c.Next()
 	}
 }
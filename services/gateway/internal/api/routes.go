@@ -7,21 +7,29 @@ import (
 	"deepspace/internal/api/middleware"
 	"deepspace/internal/config"
 	"deepspace/internal/integrations/newapi"
+	"deepspace/internal/service/adjustment"
 	"deepspace/internal/service/auth"
 	"deepspace/internal/service/billing"
 	"deepspace/internal/service/chat"
 	"deepspace/internal/service/email"
+	"deepspace/internal/service/fx"
+	"deepspace/internal/service/invoice"
 	"deepspace/internal/service/knowledge"
 	modelservice "deepspace/internal/service/model"
+	"deepspace/internal/service/org"
 	"deepspace/internal/service/passwordreset"
+	"deepspace/internal/service/pipelinechain"
 	planservice "deepspace/internal/service/plan"
 	"deepspace/internal/service/project"
+	"deepspace/internal/service/projectbudget"
 	"deepspace/internal/service/projectdocument"
 	"deepspace/internal/service/projectskill"
 	"deepspace/internal/service/projectworkflow"
 	"deepspace/internal/service/risk"
+	"deepspace/internal/service/topup"
 	"deepspace/internal/service/usage"
 	"deepspace/internal/service/user"
+	"deepspace/internal/service/voucher"
 
 	"github.com/gin-gonic/gin"
 	swaggerfiles "github.com/swaggo/files"
@@ -32,6 +40,11 @@ func SetupRoutes(
 	r *gin.Engine,
 	cfg *config.Config,
 	billingService *billing.Service,
+	fxService *fx.Service,
+	adjustmentService *adjustment.Service,
+	topUpService *topup.Service,
+	voucherService *voucher.Service,
+	invoiceService *invoice.Service,
 	usageService *usage.Service,
 	projectService *project.Service,
 	chatService *chat.Service,
@@ -42,10 +55,14 @@ func SetupRoutes(
 	projectDocumentService *projectdocument.Service,
 	projectSkillService *projectskill.Service,
 	projectWorkflowService *projectworkflow.Service,
+	projectBudgetService *projectbudget.Service,
 	authService *auth.UserAuthService,
 	passwordResetService *passwordreset.Service,
 	userService *user.Service,
+	orgService *org.Service,
 	riskService *risk.Service,
+	pipelineChainService *pipelinechain.Service,
+	upstreamPool *newapi.Pool,
 	jwtManager *auth.JWTManager,
 ) {
 	// Health check
@@ -56,27 +73,40 @@ func SetupRoutes(
 	// Swagger 文档入口
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
-	// NewAPI Integration
-	newAPIClient := newapi.NewClient(cfg.NewAPIBaseURL, cfg.NewAPIKey)
-
 	billingHandler := handlers.NewBillingHandler(billingService)
 	billingViewHandler := handlers.NewBillingViewHandler(billingService, usageService)
-	adminBillingHandler := handlers.NewAdminBillingHandler(billingService, usageService)
-	proxyHandler := handlers.NewProxyHandler(billingService, usageService, riskService, newAPIClient, modelService, planService)
+	topUpHandler := handlers.NewTopUpHandler(topUpService)
+	voucherHandler := handlers.NewVoucherHandler(voucherService)
+	invoiceHandler := handlers.NewInvoiceHandler(invoiceService)
+	adminBillingHandler := handlers.NewAdminBillingHandler(billingService, usageService, fxService)
+	proxyHandler := handlers.NewProxyHandler(billingService, modelService, pipelineChainService)
 	projectHandler := handlers.NewProjectHandler(projectService, knowledgeService)
 	chatHandler := handlers.NewChatSessionHandler(chatService)
 	emailHandler := handlers.NewEmailHandler(emailService)
 	knowledgeHandler := handlers.NewKnowledgeHandler(knowledgeService)
-	modelHandler := handlers.NewModelHandler(modelService, newAPIClient)
+	modelHandler := handlers.NewModelHandler(modelService, upstreamPool.Primary())
 	planHandler := handlers.NewPlanHandler(planService)
-	planSubscriptionHandler := handlers.NewPlanSubscriptionHandler(planService)
+	planSubscriptionHandler := handlers.NewPlanSubscriptionHandler(planService, orgService)
+	subscriptionHandler := handlers.NewSubscriptionHandler(planService)
 	projectDocumentHandler := handlers.NewProjectDocumentHandler(projectDocumentService)
 	projectSkillHandler := handlers.NewProjectSkillHandler(projectSkillService)
 	projectWorkflowHandler := handlers.NewProjectWorkflowHandler(projectWorkflowService)
+	projectBudgetHandler := handlers.NewProjectBudgetHandler(projectBudgetService)
 	authHandler := handlers.NewAuthHandler(authService, jwtManager)
 	passwordResetHandler := handlers.NewPasswordResetHandler(passwordResetService)
 	userHandler := handlers.NewUserHandler(userService, authService)
 	adminRiskHandler := handlers.NewAdminRiskHandler(riskService)
+	adminUpstreamHandler := handlers.NewAdminUpstreamHandler(upstreamPool)
+	adminPipelineHandler := handlers.NewAdminPipelineHandler(pipelineChainService)
+	adminVoucherHandler := handlers.NewAdminVoucherHandler(voucherService)
+	adminAdjustmentHandler := handlers.NewAdminAdjustmentHandler(adjustmentService)
+	adminInvoiceHandler := handlers.NewAdminInvoiceHandler(invoiceService)
+	orgHandler := handlers.NewOrgHandler(orgService)
+	adminOrgHandler := handlers.NewAdminOrgHandler(orgService)
+
+	// 组织内角色：billing 只能管理充值、账单等财务事项，不能修改项目与知识库，也不能调用模型。
+	editor := middleware.RequireOrgRole(org.RoleOwner, org.RoleAdmin, org.RoleMember)
+	finance := middleware.RequireOrgRole(org.RoleOwner, org.RoleAdmin, org.RoleBilling)
 	api := r.Group("/api")
 	{
 		api.POST("/auth/register", authHandler.Register)
@@ -86,28 +116,41 @@ func SetupRoutes(
 		api.POST("/auth/password-reset/request", passwordResetHandler.RequestPasswordReset)
 		api.POST("/auth/password-reset/confirm", passwordResetHandler.ConfirmPasswordReset)
 		api.GET("/plans", planHandler.ListPublic)
+		api.POST("/billing/webhooks/:provider", topUpHandler.Webhook)
 
 		protected := api.Group("")
-		protected.Use(middleware.UserAuth(jwtManager))
+		protected.Use(middleware.UserAuth(jwtManager), middleware.OrgContext(orgService))
+		protected.GET("/orgs", orgHandler.List)
+		protected.POST("/orgs", orgHandler.Create)
+		protected.GET("/orgs/:id", orgHandler.Get)
+		protected.PATCH("/orgs/:id", orgHandler.Update)
+		protected.GET("/orgs/:id/members", orgHandler.Members)
+		protected.POST("/orgs/:id/members", orgHandler.AddMember)
+		protected.PATCH("/orgs/:id/members/:user_id", orgHandler.UpdateMember)
+		protected.DELETE("/orgs/:id/members/:user_id", orgHandler.RemoveMember)
+
 		protected.GET("/projects", projectHandler.List)
-		protected.POST("/projects", projectHandler.Create)
+		protected.POST("/projects", editor, projectHandler.Create)
 		protected.GET("/projects/stats", projectHandler.Stats)
 		protected.GET("/projects/:id", projectHandler.Get)
-		protected.PATCH("/projects/:id", projectHandler.Update)
-		protected.DELETE("/projects/:id", projectHandler.Delete)
+		protected.PATCH("/projects/:id", editor, projectHandler.Update)
+		protected.DELETE("/projects/:id", editor, projectHandler.Delete)
 		protected.GET("/projects/:id/documents", projectDocumentHandler.List)
-		protected.POST("/projects/:id/documents", projectDocumentHandler.Create)
+		protected.POST("/projects/:id/documents", editor, projectDocumentHandler.Create)
 		protected.GET("/projects/:id/documents/:docId", projectDocumentHandler.Get)
-		protected.PATCH("/projects/:id/documents/:docId", projectDocumentHandler.Update)
-		protected.DELETE("/projects/:id/documents/:docId", projectDocumentHandler.Delete)
+		protected.PATCH("/projects/:id/documents/:docId", editor, projectDocumentHandler.Update)
+		protected.DELETE("/projects/:id/documents/:docId", editor, projectDocumentHandler.Delete)
 		protected.GET("/projects/:id/skills", projectSkillHandler.List)
-		protected.POST("/projects/:id/skills", projectSkillHandler.Create)
-		protected.PATCH("/projects/:id/skills/:skillId", projectSkillHandler.Update)
-		protected.DELETE("/projects/:id/skills/:skillId", projectSkillHandler.Delete)
+		protected.POST("/projects/:id/skills", editor, projectSkillHandler.Create)
+		protected.PATCH("/projects/:id/skills/:skillId", editor, projectSkillHandler.Update)
+		protected.DELETE("/projects/:id/skills/:skillId", editor, projectSkillHandler.Delete)
 		protected.GET("/projects/:id/workflows", projectWorkflowHandler.List)
-		protected.POST("/projects/:id/workflows", projectWorkflowHandler.Create)
-		protected.PATCH("/projects/:id/workflows/:workflowId", projectWorkflowHandler.Update)
-		protected.DELETE("/projects/:id/workflows/:workflowId", projectWorkflowHandler.Delete)
+		protected.POST("/projects/:id/workflows", editor, projectWorkflowHandler.Create)
+		protected.PATCH("/projects/:id/workflows/:workflowId", editor, projectWorkflowHandler.Update)
+		protected.DELETE("/projects/:id/workflows/:workflowId", editor, projectWorkflowHandler.Delete)
+		protected.GET("/projects/:id/budget", projectBudgetHandler.Get)
+		protected.PUT("/projects/:id/budget", finance, projectBudgetHandler.Set)
+		protected.DELETE("/projects/:id/budget", finance, projectBudgetHandler.Delete)
 		protected.GET("/projects/:id/conversations", chatHandler.ListConversations)
 		protected.POST("/projects/:id/conversations", chatHandler.CreateConversation)
 		protected.GET("/conversations", chatHandler.ListStandaloneConversations)
@@ -118,20 +161,38 @@ func SetupRoutes(
 		protected.DELETE("/conversations/:conversationId", chatHandler.DeleteConversation)
 
 		protected.GET("/knowledge-bases", knowledgeHandler.ListBases)
-		protected.POST("/knowledge-bases", knowledgeHandler.CreateBase)
+		protected.POST("/knowledge-bases", editor, knowledgeHandler.CreateBase)
 		protected.GET("/knowledge-bases/:id", knowledgeHandler.GetBase)
-		protected.PATCH("/knowledge-bases/:id", knowledgeHandler.UpdateBase)
-		protected.DELETE("/knowledge-bases/:id", knowledgeHandler.DeleteBase)
+		protected.PATCH("/knowledge-bases/:id", editor, knowledgeHandler.UpdateBase)
+		protected.DELETE("/knowledge-bases/:id", editor, knowledgeHandler.DeleteBase)
 		protected.GET("/knowledge-bases/:id/documents", knowledgeHandler.ListDocuments)
-		protected.POST("/knowledge-bases/:id/documents", knowledgeHandler.CreateDocument)
-		protected.DELETE("/knowledge-bases/:id/documents/:docId", knowledgeHandler.DeleteDocument)
+		protected.POST("/knowledge-bases/:id/documents", editor, knowledgeHandler.CreateDocument)
+		protected.DELETE("/knowledge-bases/:id/documents/:docId", editor, knowledgeHandler.DeleteDocument)
 		protected.GET("/knowledge-bases/:id/documents/:docId/download", knowledgeHandler.DownloadDocument)
 
-		protected.POST("/billing/hold", billingHandler.Hold)
-		protected.POST("/billing/capture", billingHandler.Capture)
-		protected.POST("/billing/release", billingHandler.Release)
+		protected.POST("/billing/hold", editor, billingHandler.Hold)
+		protected.POST("/billing/capture", editor, billingHandler.Capture)
+		protected.POST("/billing/release", editor, billingHandler.Release)
+		protected.POST("/billing/settle", editor, billingHandler.Settle)
+		protected.GET("/billing/events/:ref_id", billingHandler.Event)
 		protected.GET("/billing/wallet", billingViewHandler.Wallet)
 		protected.GET("/billing/usage", billingViewHandler.Usage)
+		protected.GET("/billing/usage/analytics", billingViewHandler.UsageAnalytics)
+		protected.POST("/billing/topups", finance, topUpHandler.Create)
+		protected.GET("/billing/topups", finance, topUpHandler.List)
+		protected.GET("/billing/topups/:id", finance, topUpHandler.Get)
+		protected.POST("/billing/vouchers/redeem", finance, voucherHandler.Redeem)
+		protected.GET("/billing/subscription", subscriptionHandler.Get)
+		protected.GET("/billing/quota", subscriptionHandler.Quota)
+		protected.POST("/billing/subscription", finance, subscriptionHandler.Subscribe)
+		protected.POST("/billing/subscription/cancel", finance, subscriptionHandler.Cancel)
+		protected.POST("/billing/subscription/resume", finance, subscriptionHandler.Resume)
+		protected.POST("/billing/subscription/change", finance, subscriptionHandler.Change)
+		protected.DELETE("/billing/subscription/change", finance, subscriptionHandler.CancelChange)
+		protected.GET("/billing/vouchers", finance, voucherHandler.List)
+		protected.GET("/billing/invoices", finance, invoiceHandler.List)
+		protected.GET("/billing/invoices/:id", finance, invoiceHandler.Get)
+		protected.GET("/billing/invoices/:id/download", finance, invoiceHandler.Download)
 
 		protected.GET("/users/me", userHandler.GetMe)
 		protected.PATCH("/users/me", userHandler.UpdateMe)
@@ -148,6 +209,10 @@ func SetupRoutes(
 			admin.GET("/users", userHandler.List)
 			admin.POST("/users", userHandler.Create)
 			admin.GET("/users/:id", userHandler.Get)
+			admin.GET("/orgs", adminOrgHandler.List)
+			admin.GET("/orgs/:id", adminOrgHandler.Get)
+			admin.GET("/orgs/:id/subscription", planSubscriptionHandler.GetOrgActive)
+			admin.GET("/users/:id/subscription", planSubscriptionHandler.GetUserActive)
 			admin.PATCH("/users/:id", userHandler.Update)
 			admin.DELETE("/users/:id", userHandler.Delete)
 
@@ -163,11 +228,31 @@ func SetupRoutes(
 			admin.PATCH("/plans/:id", planHandler.Update)
 			admin.GET("/billing/wallets", adminBillingHandler.Wallets)
 			admin.GET("/billing/transactions", adminBillingHandler.Transactions)
+			admin.GET("/billing/events/:ref_id", adminBillingHandler.Event)
+			admin.GET("/billing/reclaimed", adminBillingHandler.Reclaimed)
 			admin.GET("/billing/usage", adminBillingHandler.Usage)
+			admin.GET("/billing/usage/analytics", adminBillingHandler.UsageAnalytics)
 			admin.POST("/billing/topups", adminBillingHandler.TopUp)
+			admin.PUT("/billing/wallets/:org_id/currency", adminBillingHandler.SetWalletCurrency)
+			admin.PUT("/billing/wallets/:org_id/credit-limit", adminBillingHandler.SetWalletCreditLimit)
+			admin.PUT("/billing/wallets/:org_id/status", adminBillingHandler.SetWalletStatus)
+			admin.GET("/billing/fx-rates", adminBillingHandler.FXRates)
+			admin.PUT("/billing/fx-rates", adminBillingHandler.UpsertFXRate)
+			admin.DELETE("/billing/fx-rates/:id", adminBillingHandler.DeleteFXRate)
+			admin.POST("/billing/refunds", adminAdjustmentHandler.Refund)
+			admin.GET("/billing/adjustments", adminAdjustmentHandler.Adjustments)
+			admin.POST("/billing/adjustments", adminAdjustmentHandler.CreateAdjustment)
+			admin.POST("/billing/adjustments/:id/approve", adminAdjustmentHandler.ApproveAdjustment)
+			admin.POST("/billing/adjustments/:id/reject", adminAdjustmentHandler.RejectAdjustment)
+			admin.GET("/billing/invoices", adminInvoiceHandler.List)
+			admin.GET("/billing/invoices/:id", adminInvoiceHandler.Get)
+			admin.GET("/billing/invoices/:id/download", adminInvoiceHandler.Download)
+			admin.GET("/vouchers", adminVoucherHandler.List)
+			admin.POST("/vouchers", adminVoucherHandler.Create)
+			admin.PATCH("/vouchers/:id", adminVoucherHandler.Update)
+			admin.GET("/vouchers/:id/redemptions", adminVoucherHandler.Redemptions)
 			admin.POST("/subscriptions", planSubscriptionHandler.Create)
 			admin.PATCH("/subscriptions/:id", planSubscriptionHandler.Update)
-			admin.GET("/users/:id/subscription", planSubscriptionHandler.GetOrgActive)
 			admin.GET("/risk/policies", adminRiskHandler.ListPolicies)
 			admin.POST("/risk/policies", adminRiskHandler.CreatePolicy)
 			admin.PATCH("/risk/policies/:id", adminRiskHandler.UpdatePolicy)
@@ -184,6 +269,12 @@ func SetupRoutes(
 			admin.POST("/risk/budget-caps", adminRiskHandler.CreateBudgetCap)
 			admin.PATCH("/risk/budget-caps/:id", adminRiskHandler.UpdateBudgetCap)
 			admin.DELETE("/risk/budget-caps/:id", adminRiskHandler.DeleteBudgetCap)
+			admin.GET("/pipeline/steps", adminPipelineHandler.ListSteps)
+			admin.GET("/pipeline/chains", adminPipelineHandler.ListChains)
+			admin.POST("/pipeline/chains", adminPipelineHandler.CreateChain)
+			admin.PATCH("/pipeline/chains/:id", adminPipelineHandler.UpdateChain)
+			admin.DELETE("/pipeline/chains/:id", adminPipelineHandler.DeleteChain)
+			admin.GET("/upstreams/health", adminUpstreamHandler.Health)
 		}
 	}
 
@@ -192,7 +283,7 @@ func SetupRoutes(
 	// This covers /v1/chat/completions, /v1/models, etc.
 	v1 := r.Group("/v1")
 	{
-		v1.Use(middleware.UserAuth(jwtManager))
+		v1.Use(middleware.UserAuth(jwtManager), middleware.OrgContext(orgService), editor)
 		// Use Any to match all methods (GET, POST, etc.)
 		// /*path will capture the rest of the path
 		v1.Any("/*path", proxyHandler.Handle)
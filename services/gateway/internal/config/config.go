@@ -1,19 +1,40 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
+	"deepspace-shared/money"
+
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Port          string
-	NewAPIBaseURL string
-	NewAPIKey     string
+	Port                  string
+	NewAPIBaseURL         string
+	NewAPIKey             string
+	NewAPIMaxRetries      int
+	NewAPIRetryBaseDelay  time.Duration
+	NewAPIRetryMaxDelay   time.Duration
+	NewAPIUpstreamsRaw    string
+	NewAPIBreakerFailures int
+	NewAPIBreakerCooldown time.Duration
+
+	BillingEstimateOutputTokens int
+	BillingDefaultCurrency      string
+	// BillingApprovalThresholdRaw 以默认币种计价，人工调账超过该金额需要第二名管理员审批。
+	BillingApprovalThresholdRaw string
+
+	PaymentProvider         string
+	PaymentWebhookSecret    string
+	PaymentCheckoutBaseURL  string
+	PaymentWebhookTolerance time.Duration
+	PaymentTopUpMinRaw      string
+	PaymentTopUpMaxRaw      string
 
 	DBHost         string
 	DBPort         string
@@ -58,9 +79,26 @@ func Load() *Config {
 	_ = godotenv.Load()
 
 	return &Config{
-		Port:          getEnv("PORT", "8080"),
-		NewAPIBaseURL: getEnv("NEWAPI_BASE_URL", "http://localhost:3000"),
-		NewAPIKey:     getEnv("NEWAPI_API_KEY", ""),
+		Port:                  getEnv("PORT", "8080"),
+		NewAPIBaseURL:         getEnv("NEWAPI_BASE_URL", "http://localhost:3000"),
+		NewAPIKey:             getEnv("NEWAPI_API_KEY", ""),
+		NewAPIMaxRetries:      getEnvInt("NEWAPI_MAX_RETRIES", 2),
+		NewAPIRetryBaseDelay:  time.Duration(getEnvInt("NEWAPI_RETRY_BASE_MS", 500)) * time.Millisecond,
+		NewAPIRetryMaxDelay:   time.Duration(getEnvInt("NEWAPI_RETRY_MAX_MS", 10000)) * time.Millisecond,
+		NewAPIUpstreamsRaw:    getEnv("NEWAPI_UPSTREAMS", ""),
+		NewAPIBreakerFailures: getEnvInt("NEWAPI_BREAKER_FAILURES", 5),
+		NewAPIBreakerCooldown: time.Duration(getEnvInt("NEWAPI_BREAKER_COOLDOWN_SECONDS", 30)) * time.Second,
+
+		BillingEstimateOutputTokens: getEnvInt("BILLING_ESTIMATE_OUTPUT_TOKENS", 4096),
+		BillingDefaultCurrency:      strings.ToUpper(strings.TrimSpace(getEnv("BILLING_DEFAULT_CURRENCY", "CNY"))),
+		BillingApprovalThresholdRaw: getEnv("BILLING_ADJUSTMENT_APPROVAL_THRESHOLD", "1000"),
+
+		PaymentProvider:         strings.ToLower(strings.TrimSpace(getEnv("PAYMENT_PROVIDER", ""))),
+		PaymentWebhookSecret:    getEnv("PAYMENT_WEBHOOK_SECRET", ""),
+		PaymentCheckoutBaseURL:  getEnv("PAYMENT_CHECKOUT_BASE_URL", ""),
+		PaymentWebhookTolerance: time.Duration(getEnvInt("PAYMENT_WEBHOOK_TOLERANCE_SECONDS", 300)) * time.Second,
+		PaymentTopUpMinRaw:      getEnv("PAYMENT_TOPUP_MIN", "1"),
+		PaymentTopUpMaxRaw:      getEnv("PAYMENT_TOPUP_MAX", "100000"),
 
 		DBHost:         getEnv("DB_HOST", "localhost"),
 		DBPort:         getEnv("DB_PORT", "5432"),
@@ -136,8 +174,37 @@ func (c *Config) Validate() error {
 	if strings.TrimSpace(c.Port) == "" {
 		return fmt.Errorf("PORT is required")
 	}
-	if strings.TrimSpace(c.NewAPIBaseURL) == "" {
-		return fmt.Errorf("NEWAPI_BASE_URL is required")
+	if _, err := c.NewAPIUpstreams(); err != nil {
+		return err
+	}
+	if c.NewAPIMaxRetries < 0 {
+		return fmt.Errorf("NEWAPI_MAX_RETRIES must not be negative")
+	}
+	if c.NewAPIBreakerFailures <= 0 {
+		return fmt.Errorf("NEWAPI_BREAKER_FAILURES must be positive")
+	}
+	if c.NewAPIBreakerCooldown <= 0 {
+		return fmt.Errorf("NEWAPI_BREAKER_COOLDOWN_SECONDS must be positive")
+	}
+	if c.BillingEstimateOutputTokens <= 0 {
+		return fmt.Errorf("BILLING_ESTIMATE_OUTPUT_TOKENS must be positive")
+	}
+	if !isCurrencyCode(c.BillingDefaultCurrency) {
+		return fmt.Errorf("BILLING_DEFAULT_CURRENCY must be a 3-letter currency code")
+	}
+	if _, err := c.BillingApprovalThreshold(); err != nil {
+		return err
+	}
+	if c.PaymentProvider != "" {
+		if strings.TrimSpace(c.PaymentWebhookSecret) == "" {
+			return fmt.Errorf("PAYMENT_WEBHOOK_SECRET is required when PAYMENT_PROVIDER is set")
+		}
+		if c.PaymentWebhookTolerance <= 0 {
+			return fmt.Errorf("PAYMENT_WEBHOOK_TOLERANCE_SECONDS must be positive")
+		}
+		if _, _, err := c.PaymentTopUpLimits(); err != nil {
+			return err
+		}
 	}
 	if strings.TrimSpace(c.DBHost) == "" {
 		return fmt.Errorf("DB_HOST is required")
@@ -207,3 +274,90 @@ func parseCommaList(value string) []string {
 func (c *Config) KBMaxUploadBytes() int64 {
 	return int64(c.KBMaxUploadMB) * 1024 * 1024
 }
+
+// UpstreamConfig 描述一个 NewAPI 实例，Models 为空表示可服务所有模型。
+type UpstreamConfig struct {
+	Name    string   `json:"name"`
+	BaseURL string   `json:"base_url"`
+	APIKey  string   `json:"api_key"`
+	Weight  int      `json:"weight"`
+	Models  []string `json:"models"`
+}
+
+// NewAPIUpstreams 解析 NEWAPI_UPSTREAMS（JSON 数组）；未配置时回落到 NEWAPI_BASE_URL 单实例。
+func (c *Config) NewAPIUpstreams() ([]UpstreamConfig, error) {
+	raw := strings.TrimSpace(c.NewAPIUpstreamsRaw)
+	if raw == "" {
+		if strings.TrimSpace(c.NewAPIBaseURL) == "" {
+			return nil, fmt.Errorf("NEWAPI_BASE_URL is required")
+		}
+		return []UpstreamConfig{{
+			Name:    "default",
+			BaseURL: c.NewAPIBaseURL,
+			APIKey:  c.NewAPIKey,
+			Weight:  1,
+		}}, nil
+	}
+	var items []UpstreamConfig
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		return nil, fmt.Errorf("NEWAPI_UPSTREAMS is invalid: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("NEWAPI_UPSTREAMS must not be empty")
+	}
+	names := map[string]bool{}
+	for i := range items {
+		items[i].Name = strings.TrimSpace(items[i].Name)
+		if items[i].Name == "" {
+			items[i].Name = fmt.Sprintf("upstream-%d", i+1)
+		}
+		if names[items[i].Name] {
+			return nil, fmt.Errorf("NEWAPI_UPSTREAMS has duplicated name %q", items[i].Name)
+		}
+		names[items[i].Name] = true
+		if strings.TrimSpace(items[i].BaseURL) == "" {
+			return nil, fmt.Errorf("NEWAPI_UPSTREAMS[%d].base_url is required", i)
+		}
+		if items[i].Weight < 0 {
+			return nil, fmt.Errorf("NEWAPI_UPSTREAMS[%d].weight must not be negative", i)
+		}
+		if items[i].APIKey == "" {
+			items[i].APIKey = c.NewAPIKey
+		}
+	}
+	return items, nil
+}
+
+// PaymentTopUpLimits 返回自助充值的单笔金额范围（钱包币种）。
+func (c *Config) PaymentTopUpLimits() (money.Amount, money.Amount, error) {
+	minAmount, err := money.Parse(c.PaymentTopUpMinRaw)
+	if err != nil || minAmount <= 0 {
+		return 0, 0, fmt.Errorf("PAYMENT_TOPUP_MIN must be a positive amount")
+	}
+	maxAmount, err := money.Parse(c.PaymentTopUpMaxRaw)
+	if err != nil || maxAmount < minAmount {
+		return 0, 0, fmt.Errorf("PAYMENT_TOPUP_MAX must be an amount not less than PAYMENT_TOPUP_MIN")
+	}
+	return minAmount, maxAmount, nil
+}
+
+// BillingApprovalThreshold 返回人工调账的审批阈值（默认币种），0 表示所有调账都需要审批。
+func (c *Config) BillingApprovalThreshold() (money.Amount, error) {
+	threshold, err := money.Parse(c.BillingApprovalThresholdRaw)
+	if err != nil || threshold < 0 {
+		return 0, fmt.Errorf("BILLING_ADJUSTMENT_APPROVAL_THRESHOLD must be a non-negative amount")
+	}
+	return threshold, nil
+}
+
+func isCurrencyCode(value string) bool {
+	if len(value) != 3 {
+		return false
+	}
+	for _, r := range value {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
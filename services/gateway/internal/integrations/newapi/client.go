@@ -2,20 +2,19 @@ package newapi
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
-	"net/http/httputil"
-	"net/url"
 	"strings"
 	"time"
-
-	"github.com/gin-gonic/gin"
 )
 
 type Client struct {
 	BaseURL string
 	APIKey  string
+	HTTP    *http.Client
 }
 
 type ParsedUsage struct {
@@ -24,77 +23,93 @@ type ParsedUsage struct {
 	TotalTokens      int
 }
 
-const usageContextKey = "newapi_usage"
+// 流式响应可能持续很久，因此不设置整体超时，只限制等待响应头的时间。
+const defaultResponseHeaderTimeout = 120 * time.Second
+
+// 逐跳头部不应转发。
+var hopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
 
 func NewClient(baseURL, apiKey string) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = defaultResponseHeaderTimeout
 	return &Client{
 		BaseURL: baseURL,
 		APIKey:  apiKey,
+		HTTP:    &http.Client{Transport: transport},
 	}
 }
 
-// ProxyRequestHandler creates a reverse proxy to NewAPI
-func (c *Client) ProxyRequestHandler() gin.HandlerFunc {
-	return func(ctx *gin.Context) {
-		c.Proxy(ctx)
+// Send 向 NewAPI 发起一次请求，调用方负责关闭响应体。
+func (c *Client) Send(ctx context.Context, method, path string, header http.Header, body []byte) (*http.Response, error) {
+	if c == nil {
+		return nil, fmt.Errorf("client missing")
 	}
-}
-
-// Proxy forwards the current request to NewAPI.
-func (c *Client) Proxy(ctx *gin.Context) {
-	remote, err := url.Parse(c.BaseURL)
+	target := strings.TrimRight(c.BaseURL, "/") + path
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid NewAPI Base URL"})
-		ctx.Abort()
-		return
+		return nil, err
 	}
-
-	// Improve streaming behavior for SSE responses.
-	ctx.Writer.Header().Set("X-Accel-Buffering", "no")
-
-	proxy := httputil.NewSingleHostReverseProxy(remote)
-	proxy.FlushInterval = 100 * time.Millisecond
-	proxy.ModifyResponse = func(resp *http.Response) error {
-		contentType := strings.ToLower(resp.Header.Get("Content-Type"))
-		isSSE := strings.Contains(contentType, "text/event-stream")
-		resp.Body = &usageCaptureReadCloser{
-			rc:    resp.Body,
-			isSSE: isSSE,
-			onUsage: func(usage ParsedUsage) {
-				ctx.Set(usageContextKey, usage)
-			},
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
 		}
-		return nil
 	}
+	removeHopHeaders(req.Header)
+	req.Header.Del("Cookie")
+	req.Header.Del("Content-Length")
+	// 交由 Transport 处理压缩，保证能从明文响应中解析 usage。
+	req.Header.Del("Accept-Encoding")
+	req.ContentLength = int64(len(body))
 
-	originalDirector := proxy.Director
-	proxy.Director = func(req *http.Request) {
-		originalDirector(req)
-		req.Host = remote.Host
-		req.URL.Scheme = remote.Scheme
-		req.URL.Host = remote.Host
+	// Gateway -> NewAPI 优先使用配置的高权限 Key，未配置时沿用请求自带的凭证。
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
 
-		// If NewAPIKey is configured, use it.
-		// Otherwise, we might rely on the incoming request's key (if valid).
-		// But typically Gateway -> NewAPI uses a high-privilege key or a specific token.
-		if c.APIKey != "" {
-			req.Header.Set("Authorization", "Bearer "+c.APIKey)
-		}
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
 	}
+	return client.Do(req)
+}
 
-	proxy.ServeHTTP(ctx.Writer, ctx.Request)
+// CopyResponseHeader 复制上游响应头，跳过逐跳头部。
+func CopyResponseHeader(dst, src http.Header) {
+	for key, values := range src {
+		for _, value := range values {
+			dst.Add(key, value)
+		}
+	}
+	removeHopHeaders(dst)
 }
 
-func GetUsageFromContext(ctx *gin.Context) (ParsedUsage, bool) {
-	if ctx == nil {
-		return ParsedUsage{}, false
+// IsEventStream 判断响应是否为 SSE。
+func IsEventStream(resp *http.Response) bool {
+	if resp == nil {
+		return false
 	}
-	value, ok := ctx.Get(usageContextKey)
-	if !ok {
-		return ParsedUsage{}, false
+	return strings.Contains(strings.ToLower(resp.Header.Get("Content-Type")), "text/event-stream")
+}
+
+// WrapUsage 包装响应体，在读取过程中解析 usage 并回调。
+func WrapUsage(rc io.ReadCloser, isSSE bool, onUsage func(ParsedUsage)) io.ReadCloser {
+	return &usageCaptureReadCloser{rc: rc, isSSE: isSSE, onUsage: onUsage}
+}
+
+func removeHopHeaders(header http.Header) {
+	for _, key := range hopHeaders {
+		header.Del(key)
 	}
-	usage, ok := value.(ParsedUsage)
-	return usage, ok
 }
 
 type usageCaptureReadCloser struct {
@@ -0,0 +1,311 @@
+package newapi
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+type UpstreamOptions struct {
+	Name    string
+	BaseURL string
+	APIKey  string
+	Weight  int
+	Models  []string
+}
+
+type BreakerOptions struct {
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+// Upstream 是一个 NewAPI 实例，附带被动健康统计与熔断状态。
+type Upstream struct {
+	Name   string
+	Weight int
+	Models []string
+	Client *Client
+
+	mu                  sync.Mutex
+	breaker             BreakerOptions
+	state               string
+	consecutiveFailures int
+	trialInFlight       bool
+	openUntil           time.Time
+	totalRequests       int64
+	totalFailures       int64
+	lastError           string
+	lastFailureAt       *time.Time
+	lastSuccessAt       *time.Time
+}
+
+type UpstreamHealth struct {
+	Name                string     `json:"name"`
+	BaseURL             string     `json:"base_url"`
+	Weight              int        `json:"weight"`
+	Models              []string   `json:"models"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	TotalRequests       int64      `json:"total_requests"`
+	TotalFailures       int64      `json:"total_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastFailureAt       *time.Time `json:"last_failure_at,omitempty"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	OpenUntil           *time.Time `json:"open_until,omitempty"`
+}
+
+type Pool struct {
+	upstreams []*Upstream
+}
+
+func NewPool(items []UpstreamOptions, breaker BreakerOptions) *Pool {
+	if breaker.FailureThreshold <= 0 {
+		breaker.FailureThreshold = 5
+	}
+	if breaker.Cooldown <= 0 {
+		breaker.Cooldown = 30 * time.Second
+	}
+	pool := &Pool{upstreams: make([]*Upstream, 0, len(items))}
+	for i, item := range items {
+		name := strings.TrimSpace(item.Name)
+		if name == "" {
+			name = fmt.Sprintf("upstream-%d", i+1)
+		}
+		weight := item.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		pool.upstreams = append(pool.upstreams, &Upstream{
+			Name:    name,
+			Weight:  weight,
+			Models:  normalizeModels(item.Models),
+			Client:  NewClient(item.BaseURL, item.APIKey),
+			breaker: breaker,
+			state:   BreakerClosed,
+		})
+	}
+	return pool
+}
+
+// Primary 返回第一个上游，用于模型同步等管理类请求。
+func (p *Pool) Primary() *Client {
+	if p == nil || len(p.upstreams) == 0 {
+		return nil
+	}
+	return p.upstreams[0].Client
+}
+
+// Candidates 返回可服务该模型的上游：健康实例按权重随机排序在前，
+// 冷却结束待探测的实例其次；若全部熔断，则按最早恢复时间兜底返回。
+func (p *Pool) Candidates(model string) []*Upstream {
+	if p == nil {
+		return nil
+	}
+	now := time.Now()
+	var healthy, probing, open []*Upstream
+	for _, item := range p.upstreams {
+		if !item.serves(model) {
+			continue
+		}
+		switch item.currentState(now) {
+		case BreakerClosed:
+			healthy = append(healthy, item)
+		case BreakerHalfOpen:
+			probing = append(probing, item)
+		default:
+			open = append(open, item)
+		}
+	}
+	result := weightedShuffle(healthy)
+	result = append(result, probing...)
+	if len(result) == 0 {
+		sortByOpenUntil(open)
+		result = open
+	}
+	return result
+}
+
+func (p *Pool) Snapshot() []UpstreamHealth {
+	if p == nil {
+		return []UpstreamHealth{}
+	}
+	now := time.Now()
+	result := make([]UpstreamHealth, 0, len(p.upstreams))
+	for _, item := range p.upstreams {
+		result = append(result, item.health(now))
+	}
+	return result
+}
+
+// Acquire 在发起请求前调用；半开状态只允许一个探测请求同时进行。
+func (u *Upstream) Acquire() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	switch u.stateLocked(time.Now()) {
+	case BreakerClosed:
+		return true
+	case BreakerHalfOpen:
+		if u.trialInFlight {
+			return false
+		}
+		u.trialInFlight = true
+		return true
+	default:
+		// 全部熔断时的兜底请求同样作为一次探测。
+		if u.trialInFlight {
+			return false
+		}
+		u.trialInFlight = true
+		return true
+	}
+}
+
+// ReportSuccess 记录一次成功响应并闭合熔断器。
+func (u *Upstream) ReportSuccess() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	now := time.Now()
+	u.totalRequests++
+	u.consecutiveFailures = 0
+	u.trialInFlight = false
+	u.state = BreakerClosed
+	u.lastSuccessAt = &now
+}
+
+// ReportFailure 记录网络错误或 5xx；连续失败达到阈值或探测失败时打开熔断器。
+func (u *Upstream) ReportFailure(reason string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	now := time.Now()
+	u.totalRequests++
+	u.totalFailures++
+	u.consecutiveFailures++
+	u.lastError = reason
+	u.lastFailureAt = &now
+	wasProbe := u.trialInFlight
+	u.trialInFlight = false
+	if wasProbe || u.consecutiveFailures >= u.breaker.FailureThreshold {
+		u.state = BreakerOpen
+		u.openUntil = now.Add(u.breaker.Cooldown)
+	}
+}
+
+// ReportNeutral 用于 429 等既不代表实例故障也不代表恢复的响应，仅释放探测名额。
+func (u *Upstream) ReportNeutral() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.totalRequests++
+	u.trialInFlight = false
+}
+
+func (u *Upstream) serves(model string) bool {
+	if len(u.Models) == 0 {
+		return true
+	}
+	model = strings.ToLower(strings.TrimSpace(model))
+	for _, pattern := range u.Models {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(model, prefix) {
+				return true
+			}
+			continue
+		}
+		if pattern == model {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *Upstream) currentState(now time.Time) string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.stateLocked(now)
+}
+
+func (u *Upstream) stateLocked(now time.Time) string {
+	if u.state == BreakerOpen && !now.Before(u.openUntil) {
+		u.state = BreakerHalfOpen
+	}
+	return u.state
+}
+
+func (u *Upstream) health(now time.Time) UpstreamHealth {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	item := UpstreamHealth{
+		Name:                u.Name,
+		BaseURL:             u.Client.BaseURL,
+		Weight:              u.Weight,
+		Models:              u.Models,
+		State:               u.stateLocked(now),
+		ConsecutiveFailures: u.consecutiveFailures,
+		TotalRequests:       u.totalRequests,
+		TotalFailures:       u.totalFailures,
+		LastError:           u.lastError,
+		LastFailureAt:       u.lastFailureAt,
+		LastSuccessAt:       u.lastSuccessAt,
+	}
+	if item.State == BreakerOpen {
+		openUntil := u.openUntil
+		item.OpenUntil = &openUntil
+	}
+	if item.Models == nil {
+		item.Models = []string{}
+	}
+	return item
+}
+
+func (u *Upstream) openUntilTime() time.Time {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.openUntil
+}
+
+func weightedShuffle(items []*Upstream) []*Upstream {
+	pending := append([]*Upstream(nil), items...)
+	result := make([]*Upstream, 0, len(items))
+	for len(pending) > 0 {
+		total := 0
+		for _, item := range pending {
+			total += item.Weight
+		}
+		pick := rand.IntN(total)
+		for i, item := range pending {
+			pick -= item.Weight
+			if pick < 0 {
+				result = append(result, item)
+				pending = append(pending[:i], pending[i+1:]...)
+				break
+			}
+		}
+	}
+	return result
+}
+
+func sortByOpenUntil(items []*Upstream) {
+	for i := 1; i < len(items); i++ {
+		for j := i; j > 0 && items[j].openUntilTime().Before(items[j-1].openUntilTime()); j-- {
+			items[j], items[j-1] = items[j-1], items[j]
+		}
+	}
+}
+
+func normalizeModels(items []string) []string {
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
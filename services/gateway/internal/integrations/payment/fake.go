@@ -0,0 +1,145 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"deepspace-shared/money"
+)
+
+const (
+	FakeProviderName = "fake"
+	// FakeSignatureHeader 的格式为 "t=<unix 秒>,v1=<hex(HMAC-SHA256(secret, "<t>.<body>"))>"。
+	FakeSignatureHeader = "X-Fake-Signature"
+)
+
+// Fake 是本地开发与测试用的支付渠道：不对接真实收银台，由调用方按约定签名后投递回调。
+type Fake struct {
+	secret    []byte
+	baseURL   string
+	tolerance time.Duration
+}
+
+type fakeWebhookPayload struct {
+	PaymentID string       `json:"payment_id"`
+	Status    string       `json:"status"`
+	Amount    money.Amount `json:"amount"`
+	Currency  string       `json:"currency"`
+}
+
+func NewFake(opts Options) *Fake {
+	baseURL := strings.TrimRight(strings.TrimSpace(opts.CheckoutBaseURL), "/")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080/billing/checkout"
+	}
+	tolerance := opts.SignatureTolerance
+	if tolerance <= 0 {
+		tolerance = 5 * time.Minute
+	}
+	return &Fake{secret: []byte(opts.WebhookSecret), baseURL: baseURL, tolerance: tolerance}
+}
+
+func (p *Fake) Name() string {
+	return FakeProviderName
+}
+
+func (p *Fake) CreateCheckout(ctx context.Context, req CheckoutRequest) (*Checkout, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	paymentID := "fake_pay_" + hex.EncodeToString(buf)
+	query := url.Values{}
+	query.Set("payment_id", paymentID)
+	query.Set("amount", req.Amount.String())
+	query.Set("currency", req.Currency)
+	return &Checkout{
+		PaymentID:   paymentID,
+		CheckoutURL: p.baseURL + "?" + query.Encode(),
+	}, nil
+}
+
+func (p *Fake) ParseWebhook(header http.Header, body []byte) (*Event, error) {
+	if len(p.secret) == 0 {
+		return nil, ErrInvalidSignature
+	}
+	timestamp, signature, ok := parseFakeSignature(header.Get(FakeSignatureHeader))
+	if !ok {
+		return nil, ErrInvalidSignature
+	}
+	age := time.Since(time.Unix(timestamp, 0))
+	if age > p.tolerance || age < -p.tolerance {
+		return nil, ErrInvalidSignature
+	}
+	expected, err := hex.DecodeString(p.Sign(timestamp, body))
+	if err != nil || !hmac.Equal(expected, signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	var payload fakeWebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, ErrInvalidEvent
+	}
+	payload.PaymentID = strings.TrimSpace(payload.PaymentID)
+	if payload.PaymentID == "" {
+		return nil, ErrInvalidEvent
+	}
+	switch payload.Status {
+	case StatusSucceeded, StatusFailed:
+	default:
+		return nil, ErrInvalidEvent
+	}
+	return &Event{
+		PaymentID: payload.PaymentID,
+		Status:    payload.Status,
+		Amount:    payload.Amount,
+		Currency:  strings.ToUpper(strings.TrimSpace(payload.Currency)),
+	}, nil
+}
+
+// Sign 返回 body 在 timestamp 时刻的十六进制签名，供本地模拟回调使用。
+func (p *Fake) Sign(timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, p.secret)
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func parseFakeSignature(value string) (int64, []byte, bool) {
+	var timestamp int64
+	var signature []byte
+	for _, part := range strings.Split(value, ",") {
+		key, val, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "t":
+			parsed, err := strconv.ParseInt(val, 10, 64)
+			if err != nil {
+				return 0, nil, false
+			}
+			timestamp = parsed
+		case "v1":
+			decoded, err := hex.DecodeString(val)
+			if err != nil {
+				return 0, nil, false
+			}
+			signature = decoded
+		}
+	}
+	if timestamp == 0 || len(signature) == 0 {
+		return 0, nil, false
+	}
+	return timestamp, signature, true
+}
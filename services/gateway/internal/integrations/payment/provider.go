@@ -0,0 +1,67 @@
+// Package payment 定义支付渠道接口：渠道负责创建收银台和校验回调签名，入账由计费服务完成。
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"deepspace-shared/money"
+)
+
+var (
+	ErrUnknownProvider  = errors.New("unknown payment provider")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrInvalidEvent     = errors.New("invalid webhook event")
+)
+
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+type CheckoutRequest struct {
+	UserID   int64
+	Amount   money.Amount
+	Currency string
+}
+
+type Checkout struct {
+	PaymentID   string
+	CheckoutURL string
+}
+
+// Event 是验签后的支付结果通知。
+type Event struct {
+	PaymentID string
+	Status    string
+	Amount    money.Amount
+	Currency  string
+}
+
+type Provider interface {
+	Name() string
+	CreateCheckout(ctx context.Context, req CheckoutRequest) (*Checkout, error)
+	// ParseWebhook 校验签名并解析回调，签名不正确时返回 ErrInvalidSignature。
+	ParseWebhook(header http.Header, body []byte) (*Event, error)
+}
+
+type Options struct {
+	WebhookSecret      string
+	CheckoutBaseURL    string
+	SignatureTolerance time.Duration
+}
+
+// New 按名称创建支付渠道；name 为空表示未启用自助充值，返回 nil。
+func New(name string, opts Options) (Provider, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "":
+		return nil, nil
+	case FakeProviderName:
+		return NewFake(opts), nil
+	default:
+		return nil, ErrUnknownProvider
+	}
+}
@@ -0,0 +1,101 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrStepNotFound   = errors.New("pipeline step not found")
+	ErrStepDuplicated = errors.New("pipeline step already registered")
+)
+
+// Registry 按名称登记可用的步骤，链路配置只引用步骤名称。
+type Registry struct {
+	mu    sync.RWMutex
+	steps map[string]Step
+}
+
+func NewRegistry() *Registry {
+	return &Registry{steps: map[string]Step{}}
+}
+
+func (r *Registry) Register(step Step) error {
+	if step == nil {
+		return fmt.Errorf("step missing")
+	}
+	name := normalizeStepName(step.Name())
+	if name == "" {
+		return fmt.Errorf("step name missing")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.steps[name]; ok {
+		return fmt.Errorf("%w: %s", ErrStepDuplicated, name)
+	}
+	r.steps[name] = step
+	return nil
+}
+
+// MustRegister 用于启动阶段注册内置步骤，重复注册视为编程错误。
+func (r *Registry) MustRegister(steps ...Step) {
+	for _, step := range steps {
+		if err := r.Register(step); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (r *Registry) Get(name string) (Step, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	step, ok := r.steps[normalizeStepName(name)]
+	return step, ok
+}
+
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.steps))
+	for name := range r.steps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate 校验步骤名称均已注册，且同一链路内不重复。
+func (r *Registry) Validate(names []string) error {
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		key := normalizeStepName(name)
+		if _, ok := r.Get(key); !ok {
+			return fmt.Errorf("%w: %s", ErrStepNotFound, name)
+		}
+		if _, ok := seen[key]; ok {
+			return fmt.Errorf("%w: %s", ErrStepDuplicated, name)
+		}
+		seen[key] = struct{}{}
+	}
+	return nil
+}
+
+// Build 按顺序组装链路。
+func (r *Registry) Build(names []string) (*Pipeline, error) {
+	if err := r.Validate(names); err != nil {
+		return nil, err
+	}
+	steps := make([]Step, 0, len(names))
+	for _, name := range names {
+		step, _ := r.Get(name)
+		steps = append(steps, step)
+	}
+	return New(steps...), nil
+}
+
+func normalizeStepName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
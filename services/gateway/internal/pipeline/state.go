@@ -1,13 +1,30 @@
 package pipeline
 
+import (
+	"net/http"
+	"time"
+
+	"deepspace-shared/money"
+)
+
 type State struct {
 	RequestBody           []byte
 	ResponseBody          []byte
+	Method                string
+	Path                  string
+	RequestHeader         http.Header
+	ResponseWriter        http.ResponseWriter
+	Streamed              bool
+	Upstream              string
+	Latency               time.Duration
+	FirstTokenDelay       time.Duration
 	TraceID               string
 	UserID                int64
+	OrgID                 int64
 	ProjectID             *int64
 	Model                 string
-	CostAmount            float64
+	CostAmount            money.Amount
+	HoldAmount            money.Amount
 	UsagePromptTokens     int
 	UsageCompletionTokens int
 	UsageTotalTokens      int
@@ -3,29 +3,88 @@ package steps
 import (
 	"context"
 
+	"deepspace-shared/money"
 	"deepspace/internal/pipeline"
+	"deepspace/internal/pkg/tokenizer"
 	"deepspace/internal/service/billing"
+	planservice "deepspace/internal/service/plan"
 )
 
 type BudgetHold struct {
-	billing *billing.Service
+	billing             *billing.Service
+	defaultOutputTokens int
 }
 
-func NewBudgetHold(billingSvc *billing.Service) *BudgetHold {
-	return &BudgetHold{billing: billingSvc}
+// NewBudgetHold 创建预扣步骤；defaultOutputTokens 用于请求未指定 max_tokens 时估算输出。
+func NewBudgetHold(billingSvc *billing.Service, defaultOutputTokens int) *BudgetHold {
+	return &BudgetHold{billing: billingSvc, defaultOutputTokens: defaultOutputTokens}
 }
 
 func (s *BudgetHold) Name() string {
 	return "budget_hold"
 }
 
+// Run 在调用上游前按 prompt token 估算、max_tokens 与模型单价估算费用并预扣。
+// 匹配到套餐额度时只预扣预估用量超出剩余额度的部分（乘以超额倍率），预估用量完全落在剩余额度内时不预扣。
+// 额度在结算时才记账，并发请求可能按同一剩余额度判断，实际超出的部分由用量结算步骤从余额补扣。
+// 不预扣时仍拒绝停用的钱包；除非预估用量完全由套餐额度承担，余额（含信用额度）耗尽的钱包也会被拒绝。
 func (s *BudgetHold) Run(ctx context.Context, state *pipeline.State) error {
 	if s.billing == nil {
 		return nil
 	}
-	if state.CostAmount <= 0 || state.RefID == "" {
+
+	estimate := tokenizer.CountRequest(state.RequestBody)
+	if estimate.MaxTokens <= 0 {
+		estimate.MaxTokens = s.defaultOutputTokens
+	}
+	amount := estimateCost(state, estimate)
+	metadata := billingMetadata(state)
+	metadata["estimated"] = true
+	metadata["estimated_prompt_tokens"] = estimate.PromptTokens
+	metadata["estimated_max_tokens"] = estimate.MaxTokens
+	covered := false
+	if check, ok := state.Meta[PlanQuotaMetaKey].(*planservice.QuotaCheck); ok && check != nil {
+		overage := check.EstimateOverage(int64(estimate.PromptTokens+estimate.MaxTokens), 1)
+		amount = overage.BillableCost(amount)
+		metadata["estimated_token_overage"] = overage.TokenOverage
+		metadata["estimated_request_overage"] = overage.RequestOverage
+		covered = overage.TokenOverage == 0 && overage.RequestOverage == 0
+	}
+	if amount <= 0 || state.RefID == "" {
+		return s.checkSpendable(ctx, state.OrgID, covered)
+	}
+
+	if _, err := s.billing.Hold(ctx, state.OrgID, amount, costCurrency(state), state.RefID, metadata); err != nil {
+		return err
+	}
+	state.HoldAmount = amount
+	return nil
+}
+
+// checkSpendable 在不预扣时检查钱包能否发起请求；covered 表示请求由套餐额度承担，此时只拒绝停用的钱包。
+func (s *BudgetHold) checkSpendable(ctx context.Context, orgID int64, covered bool) error {
+	wallet, err := s.billing.GetWallet(ctx, orgID)
+	if err != nil || wallet == nil {
+		return err
+	}
+	if covered {
+		if wallet.SuspendedAt != nil {
+			return billing.ErrWalletSuspended
+		}
 		return nil
 	}
-	_, err := s.billing.Hold(ctx, state.UserID, state.CostAmount, state.RefID, map[string]any{"source": "pipeline"})
-	return err
+	return billing.CheckSpendable(wallet)
+}
+
+// estimateCost 向上取整到钱包精度，保证预扣不少于估算值且重放时金额一致。
+func estimateCost(state *pipeline.State, estimate tokenizer.Request) money.Amount {
+	priceInput := getMetaAmount(state.Meta, "price_input")
+	priceOutput := getMetaAmount(state.Meta, "price_output")
+	if priceInput <= 0 && priceOutput <= 0 {
+		return 0
+	}
+	return money.PerMillion(money.RoundUp,
+		money.TokenPrice{Price: priceInput, Tokens: int64(estimate.PromptTokens)},
+		money.TokenPrice{Price: priceOutput, Tokens: int64(estimate.MaxTokens)},
+	)
 }
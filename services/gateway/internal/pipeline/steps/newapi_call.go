@@ -2,14 +2,51 @@ package steps
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"deepspace/internal/integrations/newapi"
 	"deepspace/internal/pipeline"
 )
 
-type NewAPICall struct{}
+var (
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
 
-func NewNewAPICall() *NewAPICall {
-	return &NewAPICall{}
+	errNoUpstream        = errors.New("no upstream serves model")
+	errBreakerOpen       = errors.New("upstream circuit open")
+	errStreamInterrupted = errors.New("upstream stream interrupted")
+)
+
+// 响应体最多留存这么多字节供后置步骤使用，超出部分仍会透传给客户端。
+const maxCapturedResponseBytes = 4 << 20
+
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+type NewAPICall struct {
+	pool  *newapi.Pool
+	retry RetryPolicy
+}
+
+func NewNewAPICall(pool *newapi.Pool, retry RetryPolicy) *NewAPICall {
+	if retry.MaxRetries < 0 {
+		retry.MaxRetries = 0
+	}
+	if retry.BaseDelay <= 0 {
+		retry.BaseDelay = 500 * time.Millisecond
+	}
+	if retry.MaxDelay < retry.BaseDelay {
+		retry.MaxDelay = retry.BaseDelay
+	}
+	return &NewAPICall{pool: pool, retry: retry}
 }
 
 func (s *NewAPICall) Name() string {
@@ -17,6 +54,234 @@ func (s *NewAPICall) Name() string {
 }
 
 func (s *NewAPICall) Run(ctx context.Context, state *pipeline.State) error {
-	// Placeholder for direct call integration.
+	if s == nil || s.pool == nil || state == nil {
+		return ErrUpstreamUnavailable
+	}
+
+	// 耗时从首次发送算起，包含重试与读取响应体，供用量记录排查慢请求。
+	start := time.Now()
+	defer func() {
+		state.Latency = time.Since(start)
+	}()
+
+	resp, upstream, err := s.send(ctx, state)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	state.Upstream = upstream.Name
+	state.StatusCode = resp.StatusCode
+	state.Streamed = newapi.IsEventStream(resp)
+	var src io.ReadCloser = resp.Body
+	if state.Streamed {
+		src = &firstByteReader{ReadCloser: resp.Body, start: start, state: state}
+	}
+	body := newapi.WrapUsage(src, state.Streamed, func(usage newapi.ParsedUsage) {
+		state.UsagePromptTokens = usage.PromptTokens
+		state.UsageCompletionTokens = usage.CompletionTokens
+		state.UsageTotalTokens = usage.TotalTokens
+	})
+
+	if state.ResponseWriter == nil {
+		raw, err := io.ReadAll(io.LimitReader(body, maxCapturedResponseBytes))
+		state.ResponseBody = raw
+		if err != nil {
+			err = errors.Join(errStreamInterrupted, err)
+		}
+		reportOutcome(ctx, upstream, resp.StatusCode, err)
+		return err
+	}
+	err = relay(state, resp, body)
+	reportOutcome(ctx, upstream, resp.StatusCode, err)
+	return err
+}
+
+// send 在尚未向客户端写出任何字节之前，对网络错误、429 与 5xx 进行重试：
+// 优先切换到下一个候选上游，所有候选都尝试过一轮后再按退避等待。
+func (s *NewAPICall) send(ctx context.Context, state *pipeline.State) (*http.Response, *newapi.Upstream, error) {
+	method := state.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	candidates := s.pool.Candidates(state.Model)
+	if len(candidates) == 0 {
+		return nil, nil, errors.Join(ErrUpstreamUnavailable, errNoUpstream)
+	}
+	maxAttempts := s.retry.MaxRetries + 1
+	if maxAttempts < len(candidates) {
+		maxAttempts = len(candidates)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		upstream := candidates[attempt%len(candidates)]
+		round := attempt / len(candidates)
+		endOfRound := (attempt+1)%len(candidates) == 0
+		if !upstream.Acquire() {
+			lastErr = errBreakerOpen
+			continue
+		}
+
+		resp, err := upstream.Client.Send(ctx, method, state.Path, state.RequestHeader, state.RequestBody)
+		if err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				upstream.ReportNeutral()
+				break
+			}
+			upstream.ReportFailure(err.Error())
+			// 最后一次尝试失败后直接返回，不再等待退避。
+			if endOfRound && attempt < maxAttempts-1 && !sleepContext(ctx, s.backoff(round)) {
+				break
+			}
+			continue
+		}
+		if !isRetryableStatus(resp.StatusCode) || attempt == maxAttempts-1 {
+			return resp, upstream, nil
+		}
+		var wait time.Duration
+		if endOfRound {
+			delay, ok := s.retryDelay(resp, round)
+			if !ok {
+				return resp, upstream, nil
+			}
+			wait = delay
+		}
+		reportOutcome(ctx, upstream, resp.StatusCode, nil)
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+		lastErr = fmt.Errorf("upstream %s returned HTTP %d", upstream.Name, resp.StatusCode)
+		if !sleepContext(ctx, wait) {
+			return nil, nil, ctx.Err()
+		}
+	}
+	if lastErr == nil {
+		lastErr = ctx.Err()
+	}
+	return nil, nil, errors.Join(ErrUpstreamUnavailable, lastErr)
+}
+
+func (s *NewAPICall) backoff(attempt int) time.Duration {
+	delay := s.retry.BaseDelay << attempt
+	if delay <= 0 || delay > s.retry.MaxDelay {
+		return s.retry.MaxDelay
+	}
+	return delay
+}
+
+// retryDelay 优先遵循 Retry-After，若其要求的等待超过上限则放弃重试，直接返回上游响应。
+func (s *NewAPICall) retryDelay(resp *http.Response, attempt int) (time.Duration, bool) {
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return s.backoff(attempt), true
+	}
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		wait = time.Until(at)
+	} else {
+		return s.backoff(attempt), true
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	if wait > s.retry.MaxDelay {
+		return 0, false
+	}
+	return wait, true
+}
+
+// firstByteReader 记录流式响应读到首个字节的耗时，作为首 token 时间。
+type firstByteReader struct {
+	io.ReadCloser
+	start time.Time
+	state *pipeline.State
+	seen  bool
+}
+
+func (r *firstByteReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 && !r.seen {
+		r.seen = true
+		r.state.FirstTokenDelay = time.Since(r.start)
+	}
+	return n, err
+}
+
+func relay(state *pipeline.State, resp *http.Response, body io.Reader) error {
+	w := state.ResponseWriter
+	newapi.CopyResponseHeader(w.Header(), resp.Header)
+	if state.Streamed {
+		// 关闭反向代理缓冲，保证 SSE 及时到达客户端。
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.Header().Del("Content-Length")
+	}
+	w.WriteHeader(resp.StatusCode)
+
+	flusher, _ := w.(http.Flusher)
+	if state.Streamed && flusher != nil {
+		flusher.Flush()
+	}
+	captured := make([]byte, 0, 4096)
+	buf := make([]byte, 32<<10)
+	for {
+		n, readErr := body.Read(buf)
+		if n > 0 {
+			if len(captured) < maxCapturedResponseBytes {
+				remain := maxCapturedResponseBytes - len(captured)
+				captured = append(captured, buf[:min(n, remain)]...)
+			}
+			if _, err := w.Write(buf[:n]); err != nil {
+				state.ResponseBody = captured
+				return err
+			}
+			if state.Streamed && flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			state.ResponseBody = captured
+			return errors.Join(errStreamInterrupted, readErr)
+		}
+	}
+	state.ResponseBody = captured
 	return nil
 }
+
+// reportOutcome 把一次请求的结果计入上游的被动健康统计。客户端主动断开、
+// 写回客户端失败与 429 都不视为上游故障。
+func reportOutcome(ctx context.Context, upstream *newapi.Upstream, status int, err error) {
+	switch {
+	case err != nil && errors.Is(err, errStreamInterrupted) && ctx.Err() == nil:
+		upstream.ReportFailure(err.Error())
+	case status >= 500:
+		upstream.ReportFailure(fmt.Sprintf("HTTP %d", status))
+	case status == http.StatusTooManyRequests || ctx.Err() != nil:
+		upstream.ReportNeutral()
+	default:
+		upstream.ReportSuccess()
+	}
+}
+
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
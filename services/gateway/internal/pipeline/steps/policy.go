@@ -7,9 +7,12 @@ import (
 	"strings"
 	"time"
 
-	"deepspace/internal/model"
+	"deepspace-shared/model"
 	"deepspace/internal/pipeline"
 	"deepspace/internal/repo"
+	"deepspace/internal/service/fx"
+	planservice "deepspace/internal/service/plan"
+	"deepspace/internal/service/projectbudget"
 	"deepspace/internal/service/risk"
 	"deepspace/internal/service/usage"
 )
@@ -18,15 +21,31 @@ var (
 	ErrRiskIPDenied       = errors.New("risk ip denied")
 	ErrRiskRateLimited    = errors.New("risk rate limited")
 	ErrRiskBudgetExceeded = errors.New("risk budget exceeded")
+	// ErrProjectBudgetExceeded 表示项目本周期的 hard 预算已用完。
+	ErrProjectBudgetExceeded = errors.New("project budget exceeded")
+	// ErrPlanQuotaExhausted 表示请求匹配的套餐额度已用完且套餐设置为 block。
+	ErrPlanQuotaExhausted = errors.New("plan quota exhausted")
+)
+
+const (
+	// ProjectBudgetMetaKey 是策略步骤写入 State.Meta 的项目预算状态（*projectbudget.Status）。
+	ProjectBudgetMetaKey = "project_budget"
+	// PlanQuotaMetaKey 是策略步骤写入 State.Meta 的套餐额度检查结果（*planservice.QuotaCheck）。
+	PlanQuotaMetaKey = "plan_quota"
 )
 
 type Policy struct {
-	risk  *risk.Service
-	usage *usage.Service
+	risk    *risk.Service
+	usage   *usage.Service
+	fx      *fx.Service
+	budgets *projectbudget.Service
+	plans   *planservice.Service
 }
 
-func NewPolicy(riskSvc *risk.Service, usageSvc *usage.Service) *Policy {
-	return &Policy{risk: riskSvc, usage: usageSvc}
+// NewPolicy 创建策略步骤；fxSvc 用于把不同币种的用量换算为预算上限的币种，
+// budgetSvc 用于校验项目在组织钱包中的预算额度，planSvc 用于在调用上游前检查套餐额度。
+func NewPolicy(riskSvc *risk.Service, usageSvc *usage.Service, fxSvc *fx.Service, budgetSvc *projectbudget.Service, planSvc *planservice.Service) *Policy {
+	return &Policy{risk: riskSvc, usage: usageSvc, fx: fxSvc, budgets: budgetSvc, plans: planSvc}
 }
 
 func (s *Policy) Name() string {
@@ -34,29 +53,35 @@ func (s *Policy) Name() string {
 }
 
 func (s *Policy) Run(ctx context.Context, state *pipeline.State) error {
-	if s == nil || s.risk == nil || state == nil {
+	if s == nil || state == nil {
 		return nil
 	}
 	if state.UserID <= 0 {
 		return nil
 	}
 
-	policy, err := s.resolvePolicy(ctx, state)
-	if err != nil || policy == nil {
-		return err
+	if s.risk != nil {
+		policy, err := s.resolvePolicy(ctx, state)
+		if err != nil {
+			return err
+		}
+		if policy != nil {
+			if err := s.applyIPRules(ctx, state, policy.ID); err != nil {
+				return err
+			}
+			if err := s.applyRateLimits(ctx, state, policy.ID); err != nil {
+				return err
+			}
+			if err := s.applyBudgetCaps(ctx, state, policy.ID); err != nil {
+				return err
+			}
+		}
 	}
 
-	if err := s.applyIPRules(ctx, state, policy.ID); err != nil {
-		return err
-	}
-	if err := s.applyRateLimits(ctx, state, policy.ID); err != nil {
-		return err
-	}
-	if err := s.applyBudgetCaps(ctx, state, policy.ID); err != nil {
+	if err := s.applyProjectBudget(ctx, state); err != nil {
 		return err
 	}
-
-	return nil
+	return s.applyPlanQuota(ctx, state)
 }
 
 func (s *Policy) resolvePolicy(ctx context.Context, state *pipeline.State) (*model.RiskPolicy, error) {
@@ -235,9 +260,6 @@ func (s *Policy) applyBudgetCaps(ctx context.Context, state *pipeline.State, pol
 		if !ok {
 			continue
 		}
-		if !currencyMatch(cap.Currency, metaCurrency) {
-			continue
-		}
 		agg, err := s.usage.AggregateByScope(ctx, usage.AggregateInput{
 			UserID:    state.UserID,
 			ProjectID: state.ProjectID,
@@ -247,7 +269,15 @@ func (s *Policy) applyBudgetCaps(ctx context.Context, state *pipeline.State, pol
 		if err != nil {
 			return err
 		}
-		if cap.MaxCost > 0 && agg.TotalCost >= cap.MaxCost {
+		if cap.MaxCost <= 0 {
+			continue
+		}
+		// 用量按记录币种分别汇总，逐项换算为上限币种后再比较。
+		spent, err := s.fx.Sum(ctx, agg.Costs, capCurrency(cap.Currency, metaCurrency))
+		if err != nil {
+			return err
+		}
+		if spent >= cap.MaxCost {
 			return ErrRiskBudgetExceeded
 		}
 	}
@@ -255,6 +285,40 @@ func (s *Policy) applyBudgetCaps(ctx context.Context, state *pipeline.State, pol
 	return nil
 }
 
+// applyProjectBudget 校验项目本周期的预算，并把状态写入 Meta 供响应头展示剩余额度。
+func (s *Policy) applyProjectBudget(ctx context.Context, state *pipeline.State) error {
+	if s.budgets == nil || state.ProjectID == nil || state.OrgID <= 0 {
+		return nil
+	}
+	status, err := s.budgets.Check(ctx, state.OrgID, *state.ProjectID, time.Now().UTC())
+	if err != nil || status == nil {
+		return err
+	}
+	state.Meta[ProjectBudgetMetaKey] = status
+	if status.Blocks() {
+		return ErrProjectBudgetExceeded
+	}
+	return nil
+}
+
+// applyPlanQuota 检查请求匹配的套餐额度：额度用完且套餐为 block 时拒绝请求；
+// 额度未用完时把结果写入 Meta，预扣步骤据此跳过钱包预扣。
+// 无论客户端是否携带计费请求头都会检查，否则 block 套餐可通过指定金额绕过额度限制。
+func (s *Policy) applyPlanQuota(ctx context.Context, state *pipeline.State) error {
+	if s.plans == nil || state.OrgID <= 0 {
+		return nil
+	}
+	check, err := s.plans.CheckQuota(ctx, state.OrgID, time.Now().UTC(), state.Model, getMetaStrings(state.Meta, "capabilities"))
+	if err != nil || check == nil {
+		return err
+	}
+	state.Meta[PlanQuotaMetaKey] = check
+	if check.Blocks() {
+		return ErrPlanQuotaExhausted
+	}
+	return nil
+}
+
 func matchIPRule(clientIP net.IP, rule model.IPRule) bool {
 	if clientIP == nil {
 		return false
@@ -291,15 +355,15 @@ func resolveCycleStart(cycle string, now time.Time) (time.Time, bool) {
 	}
 }
 
-func currencyMatch(capCurrency, metaCurrency string) bool {
-	value := strings.ToUpper(strings.TrimSpace(capCurrency))
-	if value == "" {
-		return true
+// capCurrency 返回预算上限的币种；未设置时按本次请求的模型币种，仍为空则为 CNY。
+func capCurrency(capCurrency, metaCurrency string) string {
+	if value := fx.NormalizeCurrency(capCurrency); value != "" {
+		return value
 	}
-	if metaCurrency == "" {
-		return true
+	if value := fx.NormalizeCurrency(metaCurrency); value != "" {
+		return value
 	}
-	return value == metaCurrency
+	return "CNY"
 }
 
 func getMetaString(meta map[string]any, key string) string {
@@ -3,8 +3,14 @@ package steps
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
 	"time"
 
+	"deepspace-shared/money"
 	"deepspace/internal/pipeline"
 	"deepspace/internal/service/billing"
 	planservice "deepspace/internal/service/plan"
@@ -26,78 +32,128 @@ func (s *UsageCapture) Name() string {
 }
 
 func (s *UsageCapture) Run(ctx context.Context, state *pipeline.State) error {
-	if !hasProvidedBillingAmount(state) && state.CostAmount <= 0 {
-		state.CostAmount = calculateCostFromUsage(state)
-	}
+	state.CostAmount = calculateCostFromUsage(state)
 
-	if !hasProvidedBillingAmount(state) && state.StatusCode >= 200 && state.StatusCode < 400 && s.plan != nil {
-		result, err := s.plan.ApplyQuota(ctx, state.UserID, time.Now().UTC(), int64(state.UsageTotalTokens), 1)
-		if err == nil && result != nil && result.Applied {
-			if result.Type == "request" {
-				if result.Overage == 0 {
-					state.CostAmount = 0
-				}
-			} else if result.Type == "token" {
-				totalTokens := int64(state.UsageTotalTokens)
-				if totalTokens > 0 {
-					if result.Overage == 0 {
-						state.CostAmount = 0
-					} else if result.Overage < totalTokens {
-						ratio := float64(result.Overage) / float64(totalTokens)
-						state.CostAmount = state.CostAmount * ratio
-					}
-				}
-			}
+	var quotaErr error
+	if state.StatusCode >= 200 && state.StatusCode < 400 && s.plan != nil {
+		result, err := s.plan.ApplyQuota(ctx, state.OrgID, time.Now().UTC(), planservice.QuotaUsage{
+			Model:        state.Model,
+			Capabilities: getMetaStrings(state.Meta, "capabilities"),
+			Tokens:       int64(state.UsageTotalTokens),
+			Requests:     1,
+		})
+		if err != nil {
+			// 额度未能记账时按完整费用计费，并在用量记录的 BillingError 中留下原因以便核对。
+			quotaErr = fmt.Errorf("apply plan quota: %w", err)
+			log.Printf("plan quota apply failed org=%d ref=%s: %v", state.OrgID, state.RefID, err)
+		} else if result != nil && result.Applied {
+			// 额度内的用量不计费，超出部分按比例并乘以套餐超额倍率计费。
+			state.CostAmount = result.BillableCost(state.CostAmount)
 		}
 	}
 
-	if state.RefID != "" && state.CostAmount > 0 && s.billing != nil {
-		if state.StatusCode >= 200 && state.StatusCode < 400 {
-			if !hasProvidedBillingAmount(state) {
-				if _, err := s.billing.Hold(ctx, state.UserID, state.CostAmount, state.RefID, map[string]any{"source": "pipeline"}); err != nil {
-					return nil
-				}
+	// 钱包与套餐归属组织，按 OrgID 扣费；用量记录同时保存发起请求的成员。
+	// 用量记录保存实际扣款的钱包币种金额；未经过计费时保留模型币种下的费用。
+	cost, currency := state.CostAmount, costCurrency(state)
+	succeeded := state.StatusCode >= 200 && state.StatusCode < 400
+	var billingErr error
+	if state.RefID != "" && s.billing != nil {
+		if state.HoldAmount > 0 {
+			// 按实际费用结算预扣，失败请求全额释放。
+			var actual money.Amount
+			if succeeded {
+				actual = state.CostAmount
+			}
+			if result, err := s.billing.Settle(ctx, state.OrgID, actual, currency, state.RefID, billingMetadata(state)); err != nil {
+				billingErr = fmt.Errorf("settle: %w", err)
+			} else {
+				cost, currency = result.Actual, result.Currency
+			}
+		} else if succeeded && state.CostAmount > 0 {
+			// 链路未配置预扣时，事后补一次 hold+capture。
+			if _, err := s.billing.Hold(ctx, state.OrgID, state.CostAmount, currency, state.RefID, billingMetadata(state)); err != nil {
+				billingErr = fmt.Errorf("hold: %w", err)
+			} else if result, err := s.billing.Capture(ctx, state.OrgID, state.CostAmount, currency, state.RefID, billingMetadata(state)); err != nil {
+				billingErr = fmt.Errorf("capture: %w", err)
+			} else {
+				cost, currency = result.Transaction.Amount, result.Transaction.Currency
 			}
-			_, _ = s.billing.Capture(ctx, state.UserID, state.CostAmount, state.RefID, map[string]any{"source": "pipeline"})
-		} else if hasProvidedBillingAmount(state) {
-			_, _ = s.billing.Release(ctx, state.UserID, state.CostAmount, state.RefID, map[string]any{"source": "pipeline"})
 		}
 	}
+	// 扣款失败时用量记录的费用为 0，并在 BillingError 中记录原因，避免账单与聚合计入未入账的费用。
+	if billingErr != nil {
+		log.Printf("usage billing failed org=%d ref=%s cost=%s: %v", state.OrgID, state.RefID, state.CostAmount, billingErr)
+		cost = 0
+	}
+
+	// 失败请求同样留下用量记录便于排查，但不计费。
+	if !succeeded {
+		cost = 0
+	}
 
+	var recordErr error
 	if s.usage != nil {
-		_ = s.usage.Record(ctx, usage.RecordInput{
+		path, _, _ := strings.Cut(state.Path, "?")
+		errMessage := ""
+		if state.Error != nil {
+			errMessage = state.Error.Error()
+		}
+		authMethod, _ := state.Meta["auth_method"].(string)
+		userAgent, _ := state.Meta["user_agent"].(string)
+		billingErrMessage := ""
+		if err := errors.Join(quotaErr, billingErr); err != nil {
+			billingErrMessage = err.Error()
+		}
+		recordErr = s.usage.Record(ctx, usage.RecordInput{
 			UserID:           state.UserID,
+			OrgID:            state.OrgID,
 			ProjectID:        state.ProjectID,
 			Model:            state.Model,
 			PromptTokens:     state.UsagePromptTokens,
 			CompletionTokens: state.UsageCompletionTokens,
 			TotalTokens:      state.UsageTotalTokens,
-			Cost:             state.CostAmount,
+			Cost:             cost,
+			Currency:         currency,
 			TraceID:          state.TraceID,
+			Path:             path,
+			StatusCode:       state.StatusCode,
+			Error:            errMessage,
+			Streamed:         state.Streamed,
+			Upstream:         state.Upstream,
+			Latency:          state.Latency,
+			FirstTokenDelay:  state.FirstTokenDelay,
+			AuthMethod:       authMethod,
+			UserAgent:        userAgent,
+			BillingError:     billingErrMessage,
 		})
+		if recordErr != nil {
+			recordErr = fmt.Errorf("record usage: %w", recordErr)
+		}
 	}
 
-	return nil
+	return errors.Join(quotaErr, billingErr, recordErr)
 }
 
-func calculateCostFromUsage(state *pipeline.State) float64 {
+// calculateCostFromUsage 按模型单价（每百万 token）计算实际费用，结果四舍五入到钱包精度。
+func calculateCostFromUsage(state *pipeline.State) money.Amount {
 	if state == nil {
 		return 0
 	}
 	if state.UsagePromptTokens <= 0 && state.UsageCompletionTokens <= 0 {
 		return 0
 	}
-	priceInput := getMetaFloat(state.Meta, "price_input")
-	priceOutput := getMetaFloat(state.Meta, "price_output")
+	priceInput := getMetaAmount(state.Meta, "price_input")
+	priceOutput := getMetaAmount(state.Meta, "price_output")
 	if priceInput <= 0 && priceOutput <= 0 {
 		return 0
 	}
-	promptCost := (float64(state.UsagePromptTokens) / 1_000_000) * priceInput
-	completionCost := (float64(state.UsageCompletionTokens) / 1_000_000) * priceOutput
-	return promptCost + completionCost
+	return money.PerMillion(money.RoundHalfUp,
+		money.TokenPrice{Price: priceInput, Tokens: int64(max(state.UsagePromptTokens, 0))},
+		money.TokenPrice{Price: priceOutput, Tokens: int64(max(state.UsageCompletionTokens, 0))},
+	)
 }
 
-func getMetaFloat(meta map[string]any, key string) float64 {
+func getMetaAmount(meta map[string]any, key string) money.Amount {
 	if meta == nil {
 		return 0
 	}
@@ -106,16 +162,26 @@ func getMetaFloat(meta map[string]any, key string) float64 {
 		return 0
 	}
 	switch v := value.(type) {
-	case float64:
+	case money.Amount:
 		return v
-	case float32:
-		return float64(v)
 	case int:
-		return float64(v)
+		return money.FromInt(int64(v))
 	case int64:
-		return float64(v)
+		return money.FromInt(v)
+	case float64:
+		parsed, err := money.Parse(strconv.FormatFloat(v, 'f', -1, 64))
+		if err != nil {
+			return 0
+		}
+		return parsed
+	case string:
+		parsed, err := money.Parse(v)
+		if err != nil {
+			return 0
+		}
+		return parsed
 	case json.Number:
-		parsed, err := v.Float64()
+		parsed, err := money.Parse(v.String())
 		if err != nil {
 			return 0
 		}
@@ -125,14 +191,41 @@ func getMetaFloat(meta map[string]any, key string) float64 {
 	}
 }
 
-func hasProvidedBillingAmount(state *pipeline.State) bool {
-	if state == nil || state.Meta == nil {
-		return false
+func getMetaStrings(meta map[string]any, key string) []string {
+	if meta == nil {
+		return nil
 	}
-	value, ok := state.Meta["billing_amount_provided"]
-	if !ok {
-		return false
+	switch v := meta[key].(type) {
+	case []string:
+		return v
+	case []any:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if text, ok := item.(string); ok {
+				result = append(result, text)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// billingMetadata 返回链路写入流水的公共 metadata；trace_id 供对账关联用量记录。
+func billingMetadata(state *pipeline.State) map[string]any {
+	// 组织钱包的流水记录发起请求的成员，便于按成员追溯扣费。
+	metadata := map[string]any{"source": "pipeline", "user_id": state.UserID}
+	if state.TraceID != "" {
+		metadata["trace_id"] = state.TraceID
+	}
+	return metadata
+}
+
+// costCurrency 返回 CostAmount 的币种，即模型定价币种。
+func costCurrency(state *pipeline.State) string {
+	if state.Meta == nil {
+		return ""
 	}
-	provided, ok := value.(bool)
-	return ok && provided
+	currency, _ := state.Meta["currency"].(string)
+	return currency
 }
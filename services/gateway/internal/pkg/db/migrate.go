@@ -1,8 +1,10 @@
 package db
 
 import (
+	"strings"
+
+	"deepspace-shared/model"
 	"deepspace/internal/config"
-	"deepspace/internal/model"
 
 	"gorm.io/gorm"
 )
@@ -18,17 +20,26 @@ func AutoMigrate(db *gorm.DB, cfg *config.Config) error {
 		}
 	}
 
-	return db.AutoMigrate(
+	if err := mergeDuplicatePlanUsages(db); err != nil {
+		return err
+	}
+	if err := db.AutoMigrate(
 		&model.User{},
 		&model.UserProfile{},
 		&model.UserSettings{},
+		&model.Organization{},
+		&model.Membership{},
 		&model.Project{},
 		&model.ProjectDocument{},
 		&model.ProjectSkill{},
 		&model.ProjectWorkflow{},
+		&model.ProjectBudget{},
 		&model.Wallet{},
 		&model.Transaction{},
+		&model.BillingRef{},
 		&model.UsageRecord{},
+		&model.UsageRollup{},
+		&model.UsageRollupCursor{},
 		&model.Conversation{},
 		&model.Message{},
 		&model.AuditLog{},
@@ -36,13 +47,105 @@ func AutoMigrate(db *gorm.DB, cfg *config.Config) error {
 		&model.KnowledgeDocument{},
 		&model.Model{},
 		&model.Plan{},
+		&model.PlanAllowance{},
 		&model.PlanSubscription{},
 		&model.PlanUsage{},
 		&model.RiskPolicy{},
 		&model.RateLimit{},
 		&model.IPRule{},
 		&model.BudgetCap{},
-	)
+		&model.FXRate{},
+		&model.TopUpOrder{},
+		&model.BillingAdjustment{},
+		&model.Voucher{},
+		&model.VoucherRedemption{},
+		&model.Invoice{},
+		&model.InvoiceLine{},
+		&model.PipelineChain{},
+	); err != nil {
+		return err
+	}
+	if err := dropLegacyIndexes(db); err != nil {
+		return err
+	}
+	return migratePersonalOrgs(db)
+}
+
+// migratePersonalOrgs 为还没有个人组织的用户创建 ID 与用户 ID 相同的个人组织，
+// 使原先以用户 ID 保存在 user_id 列中的项目、钱包等数据自动归属该组织。
+// ID 已被其他组织占用的用户跳过，登录时再按序列分配新的个人组织。
+func migratePersonalOrgs(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			INSERT INTO organizations (id, name, personal, owner_id, billing_email, created_at, updated_at)
+			SELECT u.id, u.email, true, u.id, '', NOW(), NOW()
+			FROM users u
+			WHERE NOT EXISTS (SELECT 1 FROM organizations o WHERE o.owner_id = u.id AND o.personal)
+				AND NOT EXISTS (SELECT 1 FROM organizations o WHERE o.id = u.id)`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`
+			INSERT INTO memberships (org_id, user_id, role, created_at, updated_at)
+			SELECT o.id, o.owner_id, 'owner', NOW(), NOW()
+			FROM organizations o
+			WHERE o.personal
+				AND NOT EXISTS (SELECT 1 FROM memberships m WHERE m.org_id = o.id AND m.user_id = o.owner_id)`).Error; err != nil {
+			return err
+		}
+		// 显式写入 ID 后序列不会前进，需要与现有最大 ID 对齐。
+		if err := tx.Exec(`SELECT setval(pg_get_serial_sequence('organizations', 'id'), GREATEST((SELECT COALESCE(MAX(id), 0) FROM organizations), 1))`).Error; err != nil {
+			return err
+		}
+		// 组织上线前的用量记录由用户本人付费，归属其个人组织。
+		return tx.Exec(`
+			UPDATE usage_records SET org_id = o.id
+			FROM organizations o
+			WHERE usage_records.org_id = 0 AND o.owner_id = usage_records.user_id AND o.personal`).Error
+	})
+}
+
+// mergeDuplicatePlanUsages 在建立 (subscription_id, allowance_id, period_start) 唯一索引前合并并发写入产生的重复用量记录：
+// 用量累加到 ID 最小的记录，其余记录删除。结转用量对重复记录同时累加，保留最小 ID 记录上的值即可。
+func mergeDuplicatePlanUsages(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&model.PlanUsage{}) {
+		return nil
+	}
+	// 旧表还没有 allowance_id 列时所有记录都是通用额度的用量。
+	key := []string{"subscription_id", "period_start"}
+	if db.Migrator().HasColumn(&model.PlanUsage{}, "AllowanceID") {
+		key = append(key, "allowance_id")
+	}
+	match := make([]string, 0, len(key))
+	for _, column := range key {
+		match = append(match, "p."+column+" = k."+column)
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			UPDATE plan_usages SET used_tokens = d.used_tokens, used_requests = d.used_requests
+			FROM (
+				SELECT MIN(id) AS id, SUM(used_tokens) AS used_tokens, SUM(used_requests) AS used_requests
+				FROM plan_usages
+				GROUP BY ` + strings.Join(key, ", ") + `
+				HAVING COUNT(*) > 1
+			) d
+			WHERE plan_usages.id = d.id`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			DELETE FROM plan_usages p USING plan_usages k
+			WHERE ` + strings.Join(match, " AND ") + ` AND p.id > k.id`).Error
+	})
+}
+
+// dropLegacyIndexes 清理已被替换的旧索引，避免与新约束冲突。
+func dropLegacyIndexes(db *gorm.DB) error {
+	// 流水幂等键由 (user_id, ref_id) 改为 (user_id, ref_id, type)，旧唯一索引会阻止同一 ref 的 capture/release。
+	if db.Migrator().HasIndex(&model.Transaction{}, "idx_transactions_user_ref") {
+		if err := db.Migrator().DropIndex(&model.Transaction{}, "idx_transactions_user_ref"); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func dropAll(db *gorm.DB) error {
@@ -50,13 +153,19 @@ func dropAll(db *gorm.DB) error {
 		&model.User{},
 		&model.UserProfile{},
 		&model.UserSettings{},
+		&model.Membership{},
+		&model.Organization{},
 		&model.Project{},
 		&model.ProjectDocument{},
 		&model.ProjectSkill{},
 		&model.ProjectWorkflow{},
+		&model.ProjectBudget{},
 		&model.Wallet{},
 		&model.Transaction{},
+		&model.BillingRef{},
 		&model.UsageRecord{},
+		&model.UsageRollup{},
+		&model.UsageRollupCursor{},
 		&model.Conversation{},
 		&model.Message{},
 		&model.AuditLog{},
@@ -65,10 +174,19 @@ func dropAll(db *gorm.DB) error {
 		&model.Model{},
 		&model.PlanSubscription{},
 		&model.PlanUsage{},
+		&model.PlanAllowance{},
 		&model.Plan{},
 		&model.RiskPolicy{},
 		&model.RateLimit{},
 		&model.IPRule{},
 		&model.BudgetCap{},
+		&model.FXRate{},
+		&model.TopUpOrder{},
+		&model.BillingAdjustment{},
+		&model.VoucherRedemption{},
+		&model.Voucher{},
+		&model.InvoiceLine{},
+		&model.Invoice{},
+		&model.PipelineChain{},
 	)
 }
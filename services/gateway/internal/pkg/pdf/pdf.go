@@ -0,0 +1,159 @@
+// Package pdf 生成只包含文本与直线的简单 PDF 文档，用于账单等可打印单据。
+// 文本统一使用阅读器内置的 STSong-Light（Adobe-GB1）字体并以 GBK 编码写入，
+// 不嵌入字体文件；GBK 无法表示的字符输出为 "?"。坐标以点为单位，原点在页面左上角。
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// A4 纸张尺寸（点）。
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// asciiWidths 是 STSong-Light 中 0x20~0x7E 对应 CID 1~95 的字宽（千分之一 em），其余字符为全角 1000。
+var asciiWidths = [95]int{
+	207, 270, 342, 467, 462, 797, 710, 239, 374, 374, 423, 605, 238, 375, 238, 334,
+	462, 462, 462, 462, 462, 462, 462, 462, 462, 462, 238, 238, 605, 605, 605, 344,
+	748, 684, 560, 695, 739, 563, 511, 729, 793, 318, 312, 666, 526, 896, 758, 772,
+	544, 772, 628, 465, 607, 753, 711, 972, 647, 620, 607, 374, 333, 374, 606, 500,
+	239, 417, 503, 427, 529, 415, 264, 444, 518, 241, 230, 495, 228, 793, 527, 524,
+	524, 504, 338, 336, 277, 517, 450, 652, 466, 452, 407, 370, 258, 370, 605,
+}
+
+type Document struct {
+	pages []*bytes.Buffer
+}
+
+func New() *Document {
+	return &Document{}
+}
+
+// AddPage 新建一页，之后的绘制都写入该页。
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+// Text 以 (x, y) 为左上角绘制单行文本。
+func (d *Document) Text(x, y, size float64, s string) {
+	page := d.current()
+	fmt.Fprintf(page, "BT /F1 %s Tf %s %s Td <%X> Tj ET\n",
+		num(size), num(x), num(PageHeight-y-size), encode(s))
+}
+
+// TextRight 绘制右端对齐到 x 的单行文本。
+func (d *Document) TextRight(x, y, size float64, s string) {
+	d.Text(x-TextWidth(s, size), y, size, s)
+}
+
+// Line 绘制一条直线。
+func (d *Document) Line(x1, y1, x2, y2, width float64) {
+	page := d.current()
+	fmt.Fprintf(page, "%s w %s %s m %s %s l S\n",
+		num(width), num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+// TextWidth 返回文本按 size 字号绘制时的宽度。
+func TextWidth(s string, size float64) float64 {
+	units := 0
+	raw := encode(s)
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		if c >= 0x20 && c <= 0x7E {
+			units += asciiWidths[c-0x20]
+			continue
+		}
+		if c >= 0x81 {
+			i++
+		}
+		units += 1000
+	}
+	return float64(units) * size / 1000
+}
+
+// Bytes 输出完整的 PDF 文件；没有页面时输出一张空白页。
+func (d *Document) Bytes() []byte {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// 对象编号：1 Catalog、2 Pages、3~5 字体，之后每页依次为 Page 与内容流。
+	const firstPage = 6
+	kids := bytes.Buffer{}
+	for i := range d.pages {
+		fmt.Fprintf(&kids, "%d 0 R ", firstPage+i*2)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", bytes.TrimSpace(kids.Bytes()), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light-GBK-EUC-H /Encoding /GBK-EUC-H /DescendantFonts [4 0 R] >>")
+	widths := bytes.Buffer{}
+	for i, w := range asciiWidths {
+		if i > 0 {
+			widths.WriteByte(' ')
+		}
+		widths.WriteString(strconv.Itoa(w))
+	}
+	object(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light /CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> /FontDescriptor 5 0 R /DW 1000 /W [1 [%s]] >>", widths.String()))
+	object("<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] /ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			num(PageWidth), num(PageHeight), firstPage+i*2+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+func (d *Document) current() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// encode 把文本转为 GBK 字节，无法编码的字符替换为 "?"。
+func encode(s string) []byte {
+	encoder := simplifiedchinese.GBK.NewEncoder()
+	var out []byte
+	for _, r := range s {
+		if r < 0x80 {
+			if r < 0x20 {
+				r = ' '
+			}
+			out = append(out, byte(r))
+			continue
+		}
+		encoded, err := encoder.Bytes([]byte(string(r)))
+		if err != nil {
+			out = append(out, '?')
+			continue
+		}
+		out = append(out, encoded...)
+	}
+	return out
+}
+
+func num(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
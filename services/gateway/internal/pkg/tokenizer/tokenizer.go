@@ -0,0 +1,143 @@
+package tokenizer
+
+import (
+	"encoding/json"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 每条消息的固定开销（角色、分隔符等），与 OpenAI 聊天格式的计数方式接近。
+const (
+	messageOverheadTokens = 4
+	replyPrimingTokens    = 3
+)
+
+// Count 估算一段文本的 token 数：CJK 字符按 1 个 token 计，
+// 其余连续字符约 4 个字符折算 1 个 token，标点单独计数。
+// 结果用于预扣额度，宁可略高也不低估。
+func Count(text string) int {
+	if text == "" {
+		return 0
+	}
+	tokens := 0
+	run := 0
+	flush := func() {
+		if run > 0 {
+			tokens += (run + 3) / 4
+			run = 0
+		}
+	}
+	for len(text) > 0 {
+		r, size := utf8.DecodeRuneInString(text)
+		text = text[size:]
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case isCJK(r):
+			flush()
+			tokens++
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			flush()
+			tokens++
+		default:
+			run++
+		}
+	}
+	flush()
+	return tokens
+}
+
+// Request 是从 OpenAI 兼容请求体中提取的计数结果。
+type Request struct {
+	PromptTokens int
+	MaxTokens    int
+}
+
+// CountRequest 统计聊天、补全、Responses 与 Embeddings 请求的输入 token，
+// 并读取 max_tokens / max_completion_tokens / max_output_tokens。
+func CountRequest(raw []byte) Request {
+	var body struct {
+		Messages            []message       `json:"messages"`
+		Prompt              json.RawMessage `json:"prompt"`
+		Input               json.RawMessage `json:"input"`
+		Instructions        string          `json:"instructions"`
+		System              json.RawMessage `json:"system"`
+		Tools               json.RawMessage `json:"tools"`
+		MaxTokens           int             `json:"max_tokens"`
+		MaxCompletionTokens int             `json:"max_completion_tokens"`
+		MaxOutputTokens     int             `json:"max_output_tokens"`
+	}
+	if len(raw) == 0 || json.Unmarshal(raw, &body) != nil {
+		return Request{}
+	}
+
+	result := Request{}
+	for _, item := range body.Messages {
+		result.PromptTokens += messageOverheadTokens + Count(item.Role) + Count(item.Name) + countContent(item.Content)
+	}
+	if len(body.Messages) > 0 {
+		result.PromptTokens += replyPrimingTokens
+	}
+	result.PromptTokens += countContent(body.Prompt)
+	result.PromptTokens += countContent(body.Input)
+	result.PromptTokens += countContent(body.System)
+	result.PromptTokens += Count(body.Instructions)
+	if len(body.Tools) > 0 {
+		result.PromptTokens += Count(string(body.Tools))
+	}
+
+	switch {
+	case body.MaxCompletionTokens > 0:
+		result.MaxTokens = body.MaxCompletionTokens
+	case body.MaxOutputTokens > 0:
+		result.MaxTokens = body.MaxOutputTokens
+	case body.MaxTokens > 0:
+		result.MaxTokens = body.MaxTokens
+	}
+	return result
+}
+
+type message struct {
+	Role    string          `json:"role"`
+	Name    string          `json:"name"`
+	Content json.RawMessage `json:"content"`
+}
+
+// countContent 兼容字符串、字符串数组、内容分片数组（{type,text}）以及嵌套消息。
+func countContent(raw json.RawMessage) int {
+	if len(raw) == 0 {
+		return 0
+	}
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return Count(text)
+	}
+	// 已分词的 prompt（token id 数组）每个元素即 1 个 token。
+	var id json.Number
+	if json.Unmarshal(raw, &id) == nil {
+		return 1
+	}
+	var items []json.RawMessage
+	if json.Unmarshal(raw, &items) == nil {
+		total := 0
+		for _, item := range items {
+			total += countContent(item)
+		}
+		return total
+	}
+	var part struct {
+		Text    string          `json:"text"`
+		Content json.RawMessage `json:"content"`
+	}
+	if json.Unmarshal(raw, &part) == nil {
+		return Count(part.Text) + countContent(part.Content)
+	}
+	return 0
+}
+
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
@@ -0,0 +1,25 @@
+package repo
+
+import (
+	"context"
+
+	"deepspace-shared/model"
+
+	"gorm.io/gorm"
+)
+
+type AuditLogRepo struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepo(db *gorm.DB) *AuditLogRepo {
+	return &AuditLogRepo{db: db}
+}
+
+func (r *AuditLogRepo) WithTx(tx *gorm.DB) *AuditLogRepo {
+	return &AuditLogRepo{db: tx}
+}
+
+func (r *AuditLogRepo) Create(ctx context.Context, item *model.AuditLog) error {
+	return r.db.WithContext(ctx).Create(item).Error
+}
@@ -0,0 +1,112 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"deepspace-shared/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BillingAdjustmentRepo struct {
+	db *gorm.DB
+}
+
+func NewBillingAdjustmentRepo(db *gorm.DB) *BillingAdjustmentRepo {
+	return &BillingAdjustmentRepo{db: db}
+}
+
+func (r *BillingAdjustmentRepo) WithTx(tx *gorm.DB) *BillingAdjustmentRepo {
+	return &BillingAdjustmentRepo{db: tx}
+}
+
+type BillingAdjustmentFilter struct {
+	UserID *int64
+	Status string
+	Limit  int
+	Offset int
+}
+
+func (r *BillingAdjustmentRepo) Create(ctx context.Context, item *model.BillingAdjustment) error {
+	return r.db.WithContext(ctx).Create(item).Error
+}
+
+func (r *BillingAdjustmentRepo) GetByID(ctx context.Context, id int64) (*model.BillingAdjustment, error) {
+	var item model.BillingAdjustment
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetByIDForUpdate 加行锁读取，避免同一申请被并发审批两次。
+func (r *BillingAdjustmentRepo) GetByIDForUpdate(ctx context.Context, id int64) (*model.BillingAdjustment, error) {
+	var item model.BillingAdjustment
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *BillingAdjustmentRepo) GetByRefID(ctx context.Context, refID string) (*model.BillingAdjustment, error) {
+	var item model.BillingAdjustment
+	err := r.db.WithContext(ctx).
+		Where("ref_id = ?", refID).
+		First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *BillingAdjustmentRepo) Update(ctx context.Context, id int64, updates map[string]any) (*model.BillingAdjustment, error) {
+	if len(updates) == 0 {
+		return r.GetByID(ctx, id)
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&model.BillingAdjustment{}).
+		Where("id = ?", id).
+		Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *BillingAdjustmentRepo) List(ctx context.Context, filter BillingAdjustmentFilter) ([]model.BillingAdjustment, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.BillingAdjustment{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []model.BillingAdjustment
+	if err := query.Order("created_at DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
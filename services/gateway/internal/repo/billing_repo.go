@@ -5,7 +5,8 @@ import (
 	"errors"
 	"time"
 
-	"deepspace/internal/model"
+	"deepspace-shared/model"
+	"deepspace-shared/money"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -52,8 +53,8 @@ func (r *BillingRepo) GetWallet(ctx context.Context, userID int64) (*model.Walle
 	return &w, nil
 }
 
-func (r *BillingRepo) CreateWallet(ctx context.Context, userID int64) (*model.Wallet, error) {
-	wallet := model.Wallet{UserID: userID, Balance: 0, FrozenBalance: 0}
+func (r *BillingRepo) CreateWallet(ctx context.Context, userID int64, currency string) (*model.Wallet, error) {
+	wallet := model.Wallet{UserID: userID, Balance: 0, FrozenBalance: 0, Currency: currency}
 	err := r.db.WithContext(ctx).
 		Clauses(clause.OnConflict{DoNothing: true}).
 		Create(&wallet).Error
@@ -63,7 +64,7 @@ func (r *BillingRepo) CreateWallet(ctx context.Context, userID int64) (*model.Wa
 	return r.GetWalletForUpdate(ctx, userID)
 }
 
-func (r *BillingRepo) UpdateWallet(ctx context.Context, userID int64, balance, frozen float64) error {
+func (r *BillingRepo) UpdateWallet(ctx context.Context, userID int64, balance, frozen money.Amount) error {
 	return r.db.WithContext(ctx).
 		Model(&model.Wallet{}).
 		Where("user_id = ?", userID).
@@ -73,10 +74,26 @@ func (r *BillingRepo) UpdateWallet(ctx context.Context, userID int64, balance, f
 		}).Error
 }
 
-func (r *BillingRepo) GetTransactionByRef(ctx context.Context, refID string) (*model.Transaction, error) {
+// UpdateWalletFields 更新信用额度、停用状态等非余额字段；余额变动仍通过 UpdateWallet。
+func (r *BillingRepo) UpdateWalletFields(ctx context.Context, userID int64, updates map[string]any) error {
+	return r.db.WithContext(ctx).
+		Model(&model.Wallet{}).
+		Where("user_id = ?", userID).
+		Updates(updates).Error
+}
+
+func (r *BillingRepo) UpdateWalletCurrency(ctx context.Context, userID int64, currency string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.Wallet{}).
+		Where("user_id = ?", userID).
+		Update("currency", currency).Error
+}
+
+// GetTransactionByRef 按 ref_id 与类型查找流水；同一 ref 下 hold/capture/release 各自独立幂等。
+func (r *BillingRepo) GetTransactionByRef(ctx context.Context, userID int64, refID, typ string) (*model.Transaction, error) {
 	var t model.Transaction
 	err := r.db.WithContext(ctx).
-		Where("ref_id = ?", refID).
+		Where("user_id = ? AND ref_id = ? AND type = ?", userID, refID, typ).
 		First(&t).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -87,30 +104,82 @@ func (r *BillingRepo) GetTransactionByRef(ctx context.Context, refID string) (*m
 	return &t, nil
 }
 
-func (r *BillingRepo) CreateTransaction(ctx context.Context, userID int64, typ string, amount float64, refID string, metadata []byte) (*model.Transaction, error) {
-	tr := model.Transaction{
-		UserID:   userID,
-		Type:     typ,
-		Amount:   amount,
-		RefID:    refID,
-		Metadata: metadata,
+func (r *BillingRepo) GetRefForUpdate(ctx context.Context, userID int64, refID string) (*model.BillingRef, error) {
+	var item model.BillingRef
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND ref_id = ?", userID, refID).
+		First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
 	}
+	return &item, nil
+}
 
-	if err := r.db.WithContext(ctx).Create(&tr).Error; err != nil {
+func (r *BillingRepo) CreateRef(ctx context.Context, item *model.BillingRef) error {
+	return r.db.WithContext(ctx).Create(item).Error
+}
+
+func (r *BillingRepo) UpdateRef(ctx context.Context, id int64, updates map[string]any) error {
+	return r.db.WithContext(ctx).
+		Model(&model.BillingRef{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+func (r *BillingRepo) ListRefs(ctx context.Context, refID string, userID *int64) ([]model.BillingRef, error) {
+	query := r.db.WithContext(ctx).Where("ref_id = ?", refID)
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	var items []model.BillingRef
+	if err := query.Find(&items).Error; err != nil {
 		return nil, err
 	}
-	return &tr, nil
+	return items, nil
 }
 
+// ListTransactionsByRef 返回同一 ref_id 下的全部流水及关联到该 ref 的退款，按创建顺序排列。
+func (r *BillingRepo) ListTransactionsByRef(ctx context.Context, refID string, userID *int64) ([]model.Transaction, error) {
+	query := r.db.WithContext(ctx).Where("(ref_id = ? OR parent_ref_id = ?)", refID, refID)
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	var items []model.Transaction
+	if err := query.Order("user_id ASC, id ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *BillingRepo) CreateTransaction(ctx context.Context, tr *model.Transaction) (*model.Transaction, error) {
+	if err := r.db.WithContext(ctx).Create(tr).Error; err != nil {
+		return nil, err
+	}
+	return tr, nil
+}
+
+// WalletWithUser 的 UserID 为钱包所属组织，Email 等用户字段为组织 owner 的信息。
 type WalletWithUser struct {
-	UserID        int64     `json:"user_id"`
-	Balance       float64   `json:"balance"`
-	FrozenBalance float64   `json:"frozen_balance"`
-	UpdatedAt     time.Time `json:"updated_at"`
-	Email         string    `json:"email"`
-	Status        string    `json:"status"`
-	Role          string    `json:"role"`
-	UserCreatedAt time.Time `json:"user_created_at"`
+	UserID        int64        `json:"user_id"`
+	OrgName       string       `json:"org_name"`
+	OrgPersonal   bool         `json:"org_personal"`
+	OwnerID       int64        `json:"owner_id"`
+	Balance       money.Amount `json:"balance"`
+	FrozenBalance money.Amount `json:"frozen_balance"`
+	Currency      string       `json:"currency"`
+	CreditLimit   money.Amount `json:"credit_limit"`
+	SuspendedAt   *time.Time   `json:"suspended_at"`
+	SuspendReason string       `json:"suspend_reason"`
+	DunningStage  int          `json:"dunning_stage"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+	Email         string       `json:"email"`
+	Status        string       `json:"status"`
+	Role          string       `json:"role"`
+	UserCreatedAt time.Time    `json:"user_created_at"`
 }
 
 type WalletListFilter struct {
@@ -122,8 +191,9 @@ type WalletListFilter struct {
 func (r *BillingRepo) ListWallets(ctx context.Context, filter WalletListFilter) ([]WalletWithUser, int64, error) {
 	query := r.db.WithContext(ctx).
 		Table("wallets").
-		Select("wallets.user_id, wallets.balance, wallets.frozen_balance, wallets.updated_at, users.email, users.status, users.role, users.created_at AS user_created_at").
-		Joins("JOIN users ON users.id = wallets.user_id")
+		Select("wallets.user_id, COALESCE(organizations.name, '') AS org_name, COALESCE(organizations.personal, false) AS org_personal, users.id AS owner_id, wallets.balance, wallets.frozen_balance, wallets.currency, wallets.credit_limit, wallets.suspended_at, wallets.suspend_reason, wallets.dunning_stage, wallets.updated_at, users.email, users.status, users.role, users.created_at AS user_created_at").
+		Joins("LEFT JOIN organizations ON organizations.id = wallets.user_id").
+		Joins("JOIN users ON users.id = COALESCE(organizations.owner_id, wallets.user_id)")
 	if filter.UserID != nil {
 		query = query.Where("wallets.user_id = ?", *filter.UserID)
 	}
@@ -142,28 +212,18 @@ func (r *BillingRepo) ListWallets(ctx context.Context, filter WalletListFilter)
 }
 
 type TransactionListFilter struct {
-	UserID *int64
-	Type   string
-	Start  *time.Time
-	End    *time.Time
-	Limit  int
-	Offset int
+	UserID      *int64
+	Types       []string
+	RefID       string
+	ParentRefID string
+	Start       *time.Time
+	End         *time.Time
+	Limit       int
+	Offset      int
 }
 
 func (r *BillingRepo) ListTransactions(ctx context.Context, filter TransactionListFilter) ([]model.Transaction, int64, error) {
-	query := r.db.WithContext(ctx).Model(&model.Transaction{})
-	if filter.UserID != nil {
-		query = query.Where("user_id = ?", *filter.UserID)
-	}
-	if filter.Type != "" {
-		query = query.Where("type = ?", filter.Type)
-	}
-	if filter.Start != nil {
-		query = query.Where("created_at >= ?", *filter.Start)
-	}
-	if filter.End != nil {
-		query = query.Where("created_at < ?", *filter.End)
-	}
+	query := r.transactionQuery(ctx, filter)
 
 	var total int64
 	if err := query.Count(&total).Error; err != nil {
@@ -177,3 +237,45 @@ func (r *BillingRepo) ListTransactions(ctx context.Context, filter TransactionLi
 
 	return items, total, nil
 }
+
+// SumTransactionsByCurrency 按币种汇总符合条件的流水金额，忽略分页参数。
+func (r *BillingRepo) SumTransactionsByCurrency(ctx context.Context, filter TransactionListFilter) (map[string]money.Amount, error) {
+	var rows []struct {
+		Currency string
+		Total    money.Amount
+	}
+	if err := r.transactionQuery(ctx, filter).
+		Select("currency, COALESCE(SUM(amount), 0) AS total").
+		Group("currency").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	totals := make(map[string]money.Amount, len(rows))
+	for _, row := range rows {
+		totals[row.Currency] += row.Total
+	}
+	return totals, nil
+}
+
+func (r *BillingRepo) transactionQuery(ctx context.Context, filter TransactionListFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&model.Transaction{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if len(filter.Types) > 0 {
+		query = query.Where("type IN ?", filter.Types)
+	}
+	if filter.RefID != "" {
+		query = query.Where("ref_id = ?", filter.RefID)
+	}
+	if filter.ParentRefID != "" {
+		query = query.Where("parent_ref_id = ?", filter.ParentRefID)
+	}
+	if filter.Start != nil {
+		query = query.Where("created_at >= ?", *filter.Start)
+	}
+	if filter.End != nil {
+		query = query.Where("created_at < ?", *filter.End)
+	}
+	return query
+}
@@ -5,7 +5,7 @@ import (
 	"errors"
 	"time"
 
-	"deepspace/internal/model"
+	"deepspace-shared/model"
 
 	"gorm.io/gorm"
 )
@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"deepspace-shared/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FXRateRepo struct {
+	db *gorm.DB
+}
+
+func NewFXRateRepo(db *gorm.DB) *FXRateRepo {
+	return &FXRateRepo{db: db}
+}
+
+type FXRateFilter struct {
+	Currency string
+	Limit    int
+	Offset   int
+}
+
+// Upsert 按币种对写入汇率，已存在时覆盖。
+func (r *FXRateRepo) Upsert(ctx context.Context, item *model.FXRate) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "base_currency"}, {Name: "quote_currency"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+		}).
+		Create(item).Error
+}
+
+func (r *FXRateRepo) Delete(ctx context.Context, id int64) (bool, error) {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.FXRate{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *FXRateRepo) GetPair(ctx context.Context, base, quote string) (*model.FXRate, error) {
+	var item model.FXRate
+	err := r.db.WithContext(ctx).
+		Where("base_currency = ? AND quote_currency = ?", base, quote).
+		First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *FXRateRepo) List(ctx context.Context, filter FXRateFilter) ([]model.FXRate, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.FXRate{})
+	if filter.Currency != "" {
+		query = query.Where("base_currency = ? OR quote_currency = ?", filter.Currency, filter.Currency)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []model.FXRate
+	if err := query.Order("base_currency ASC, quote_currency ASC").Limit(filter.Limit).Offset(filter.Offset).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
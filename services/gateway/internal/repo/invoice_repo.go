@@ -0,0 +1,85 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"deepspace-shared/model"
+
+	"gorm.io/gorm"
+)
+
+type InvoiceRepo struct {
+	db *gorm.DB
+}
+
+func NewInvoiceRepo(db *gorm.DB) *InvoiceRepo {
+	return &InvoiceRepo{db: db}
+}
+
+func (r *InvoiceRepo) WithTx(tx *gorm.DB) *InvoiceRepo {
+	return &InvoiceRepo{db: tx}
+}
+
+type InvoiceFilter struct {
+	UserID *int64
+	Status string
+	Start  *time.Time
+	End    *time.Time
+	Limit  int
+	Offset int
+}
+
+func (r *InvoiceRepo) GetByID(ctx context.Context, id int64) (*model.Invoice, error) {
+	var item model.Invoice
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// List 按账期倒序返回账单；Start/End 按账期起始时间筛选。
+func (r *InvoiceRepo) List(ctx context.Context, filter InvoiceFilter) ([]model.Invoice, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.Invoice{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Start != nil {
+		query = query.Where("period_start >= ?", *filter.Start)
+	}
+	if filter.End != nil {
+		query = query.Where("period_start < ?", *filter.End)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []model.Invoice
+	if err := query.Order("period_start DESC, id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (r *InvoiceRepo) ListLines(ctx context.Context, invoiceID int64) ([]model.InvoiceLine, error) {
+	var items []model.InvoiceLine
+	if err := r.db.WithContext(ctx).
+		Where("invoice_id = ?", invoiceID).
+		Order("amount DESC, id ASC").
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
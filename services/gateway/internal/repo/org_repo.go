@@ -0,0 +1,255 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"deepspace-shared/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrgRepo struct {
+	db *gorm.DB
+}
+
+func NewOrgRepo(db *gorm.DB) *OrgRepo {
+	return &OrgRepo{db: db}
+}
+
+func (r *OrgRepo) WithTx(tx *gorm.DB) *OrgRepo {
+	return &OrgRepo{db: tx}
+}
+
+// OrgWithRole 是用户所属组织及其在组织中的角色。
+type OrgWithRole struct {
+	ID           int64     `json:"id"`
+	Name         string    `json:"name"`
+	Personal     bool      `json:"personal"`
+	OwnerID      int64     `json:"owner_id"`
+	BillingEmail string    `json:"billing_email"`
+	Role         string    `json:"role"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// MemberWithUser 是组织成员及其用户信息。
+type MemberWithUser struct {
+	UserID    int64     `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OrgFilter struct {
+	Search   string
+	Personal *bool
+	Limit    int
+	Offset   int
+}
+
+func (r *OrgRepo) GetByID(ctx context.Context, id int64) (*model.Organization, error) {
+	var item model.Organization
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetByIDForUpdate 加行锁读取组织，串行化同一组织的成员变更。
+func (r *OrgRepo) GetByIDForUpdate(ctx context.Context, id int64) (*model.Organization, error) {
+	var item model.Organization
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *OrgRepo) GetPersonal(ctx context.Context, userID int64) (*model.Organization, error) {
+	var item model.Organization
+	err := r.db.WithContext(ctx).
+		Where("owner_id = ? AND personal = ?", userID, true).
+		Order("id ASC").
+		First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Create 创建组织并把 OwnerID 对应的用户加入为 owner。
+func (r *OrgRepo) Create(ctx context.Context, item *model.Organization) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createWithOwner(tx, item)
+	})
+}
+
+// EnsurePersonal 返回用户的个人组织，不存在时以邮箱为名创建；用户不存在时返回 nil。
+// 已存在时直接返回；只有需要创建时才锁定用户行并复查，避免并发请求重复创建。
+func (r *OrgRepo) EnsurePersonal(ctx context.Context, userID int64) (*model.Organization, error) {
+	result, err := r.GetPersonal(ctx, userID)
+	if err != nil || result != nil {
+		return result, err
+	}
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", userID).
+			First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		existing, err := r.WithTx(tx).GetPersonal(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			result = existing
+			return nil
+		}
+		item := &model.Organization{Name: user.Email, Personal: true, OwnerID: userID}
+		if err := createWithOwner(tx, item); err != nil {
+			return err
+		}
+		result = item
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func createWithOwner(tx *gorm.DB, item *model.Organization) error {
+	if err := tx.Create(item).Error; err != nil {
+		return err
+	}
+	return tx.Create(&model.Membership{
+		OrgID:  item.ID,
+		UserID: item.OwnerID,
+		Role:   "owner",
+	}).Error
+}
+
+func (r *OrgRepo) Update(ctx context.Context, id int64, updates map[string]any) (*model.Organization, error) {
+	if len(updates) == 0 {
+		return r.GetByID(ctx, id)
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&model.Organization{}).
+		Where("id = ?", id).
+		Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *OrgRepo) ListForUser(ctx context.Context, userID int64) ([]OrgWithRole, error) {
+	var items []OrgWithRole
+	if err := r.db.WithContext(ctx).
+		Table("memberships").
+		Select("organizations.id, organizations.name, organizations.personal, organizations.owner_id, organizations.billing_email, memberships.role, organizations.created_at").
+		Joins("JOIN organizations ON organizations.id = memberships.org_id").
+		Where("memberships.user_id = ?", userID).
+		Order("organizations.personal DESC, organizations.id ASC").
+		Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *OrgRepo) List(ctx context.Context, filter OrgFilter) ([]model.Organization, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.Organization{})
+	if filter.Search != "" {
+		query = query.Where("name ILIKE ?", "%"+filter.Search+"%")
+	}
+	if filter.Personal != nil {
+		query = query.Where("personal = ?", *filter.Personal)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []model.Organization
+	if err := query.Order("id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (r *OrgRepo) GetMembership(ctx context.Context, orgID, userID int64) (*model.Membership, error) {
+	var item model.Membership
+	err := r.db.WithContext(ctx).
+		Where("org_id = ? AND user_id = ?", orgID, userID).
+		First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *OrgRepo) ListMembers(ctx context.Context, orgID int64) ([]MemberWithUser, error) {
+	var items []MemberWithUser
+	if err := r.db.WithContext(ctx).
+		Table("memberships").
+		Select("memberships.user_id, users.email, memberships.role, memberships.created_at").
+		Joins("JOIN users ON users.id = memberships.user_id").
+		Where("memberships.org_id = ?", orgID).
+		Order("memberships.id ASC").
+		Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *OrgRepo) CreateMembership(ctx context.Context, item *model.Membership) error {
+	return r.db.WithContext(ctx).Create(item).Error
+}
+
+func (r *OrgRepo) UpdateMembershipRole(ctx context.Context, orgID, userID int64, role string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.Membership{}).
+		Where("org_id = ? AND user_id = ?", orgID, userID).
+		Update("role", role).Error
+}
+
+func (r *OrgRepo) DeleteMembership(ctx context.Context, orgID, userID int64) error {
+	return r.db.WithContext(ctx).
+		Where("org_id = ? AND user_id = ?", orgID, userID).
+		Delete(&model.Membership{}).Error
+}
+
+func (r *OrgRepo) CountOwners(ctx context.Context, orgID int64) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&model.Membership{}).
+		Where("org_id = ? AND role = ?", orgID, "owner").
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
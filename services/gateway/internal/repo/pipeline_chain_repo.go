@@ -0,0 +1,107 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"deepspace-shared/model"
+
+	"gorm.io/gorm"
+)
+
+type PipelineChainRepo struct {
+	db *gorm.DB
+}
+
+func NewPipelineChainRepo(db *gorm.DB) *PipelineChainRepo {
+	return &PipelineChainRepo{db: db}
+}
+
+type PipelineChainFilter struct {
+	Scope     string
+	Stage     string
+	ProjectID *int64
+	Model     string
+	Status    string
+	Limit     int
+	Offset    int
+}
+
+func (r *PipelineChainRepo) Create(ctx context.Context, item *model.PipelineChain) error {
+	return r.db.WithContext(ctx).Create(item).Error
+}
+
+func (r *PipelineChainRepo) Update(ctx context.Context, id int64, updates map[string]any) (*model.PipelineChain, error) {
+	if len(updates) == 0 {
+		return r.GetByID(ctx, id)
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&model.PipelineChain{}).
+		Where("id = ?", id).
+		Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *PipelineChainRepo) Delete(ctx context.Context, id int64) (bool, error) {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.PipelineChain{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *PipelineChainRepo) GetByID(ctx context.Context, id int64) (*model.PipelineChain, error) {
+	var item model.PipelineChain
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *PipelineChainRepo) List(ctx context.Context, filter PipelineChainFilter) ([]model.PipelineChain, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.PipelineChain{})
+	if filter.Scope != "" {
+		query = query.Where("scope = ?", filter.Scope)
+	}
+	if filter.Stage != "" {
+		query = query.Where("stage = ?", filter.Stage)
+	}
+	if filter.ProjectID != nil {
+		query = query.Where("project_id = ?", *filter.ProjectID)
+	}
+	if filter.Model != "" {
+		query = query.Where("model = ?", filter.Model)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []model.PipelineChain
+	if err := query.Order("priority ASC, id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// ListActive 取出全部启用链路，由服务层缓存并按作用域与优先级挑选。
+func (r *PipelineChainRepo) ListActive(ctx context.Context) ([]model.PipelineChain, error) {
+	var items []model.PipelineChain
+	if err := r.db.WithContext(ctx).
+		Where("status = ?", "active").
+		Order("priority ASC, id DESC").
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -3,8 +3,9 @@ package repo
 import (
 	"context"
 	"errors"
+	"strings"
 
-	"deepspace/internal/model"
+	"deepspace-shared/model"
 
 	"gorm.io/gorm"
 )
@@ -17,6 +18,10 @@ func NewPlanRepo(db *gorm.DB) *PlanRepo {
 	return &PlanRepo{db: db}
 }
 
+func (r *PlanRepo) WithTx(tx *gorm.DB) *PlanRepo {
+	return &PlanRepo{db: tx}
+}
+
 func (r *PlanRepo) Create(ctx context.Context, plan *model.Plan) error {
 	return r.db.WithContext(ctx).Create(plan).Error
 }
@@ -37,6 +42,7 @@ func (r *PlanRepo) Update(ctx context.Context, id int64, updates map[string]any)
 func (r *PlanRepo) GetByID(ctx context.Context, id int64) (*model.Plan, error) {
 	var plan model.Plan
 	err := r.db.WithContext(ctx).
+		Preload("Allowances", orderAllowances).
 		Where("id = ?", id).
 		First(&plan).Error
 	if err != nil {
@@ -51,9 +57,71 @@ func (r *PlanRepo) GetByID(ctx context.Context, id int64) (*model.Plan, error) {
 func (r *PlanRepo) List(ctx context.Context) ([]model.Plan, error) {
 	var plans []model.Plan
 	if err := r.db.WithContext(ctx).
+		Preload("Allowances", orderAllowances).
 		Order("created_at DESC").
 		Find(&plans).Error; err != nil {
 		return nil, err
 	}
 	return plans, nil
 }
+
+// ReplaceAllowances 用 items 替换套餐的全部限定额度：按 scope 与忽略大小写的 match 原地更新已有额度并保留其 ID，
+// 新增的额度插入，不再出现的额度删除。PlanUsage 按 AllowanceID 关联额度，保留 ID 才不会在修改套餐时清空周期内用量。
+// 不自带事务，需要与套餐更新一起提交时由调用方传入事务。
+func (r *PlanRepo) ReplaceAllowances(ctx context.Context, planID int64, items []model.PlanAllowance) error {
+	var existing []model.PlanAllowance
+	if err := r.db.WithContext(ctx).
+		Where("plan_id = ?", planID).
+		Find(&existing).Error; err != nil {
+		return err
+	}
+	byKey := make(map[string]model.PlanAllowance, len(existing))
+	for _, item := range existing {
+		byKey[allowanceKey(item)] = item
+	}
+
+	for i := range items {
+		items[i].PlanID = planID
+		key := allowanceKey(items[i])
+		current, ok := byKey[key]
+		if !ok {
+			if err := r.db.WithContext(ctx).Create(&items[i]).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		delete(byKey, key)
+		items[i].ID = current.ID
+		if err := r.db.WithContext(ctx).
+			Model(&model.PlanAllowance{}).
+			Where("id = ?", current.ID).
+			Updates(map[string]any{
+				"match":             items[i].Match,
+				"included_tokens":   items[i].IncludedTokens,
+				"included_requests": items[i].IncludedRequests,
+				"rollover_tokens":   items[i].RolloverTokens,
+				"rollover_requests": items[i].RolloverRequests,
+			}).Error; err != nil {
+			return err
+		}
+	}
+
+	if len(byKey) == 0 {
+		return nil
+	}
+	removed := make([]int64, 0, len(byKey))
+	for _, item := range byKey {
+		removed = append(removed, item.ID)
+	}
+	return r.db.WithContext(ctx).
+		Where("plan_id = ? AND id IN ?", planID, removed).
+		Delete(&model.PlanAllowance{}).Error
+}
+
+func allowanceKey(item model.PlanAllowance) string {
+	return item.Scope + ":" + strings.ToLower(item.Match)
+}
+
+func orderAllowances(db *gorm.DB) *gorm.DB {
+	return db.Order("id ASC")
+}
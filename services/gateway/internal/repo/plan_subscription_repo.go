@@ -5,9 +5,10 @@ import (
 	"errors"
 	"time"
 
-	"deepspace/internal/model"
+	"deepspace-shared/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PlanSubscriptionRepo struct {
@@ -18,6 +19,10 @@ func NewPlanSubscriptionRepo(db *gorm.DB) *PlanSubscriptionRepo {
 	return &PlanSubscriptionRepo{db: db}
 }
 
+func (r *PlanSubscriptionRepo) WithTx(tx *gorm.DB) *PlanSubscriptionRepo {
+	return &PlanSubscriptionRepo{db: tx}
+}
+
 func (r *PlanSubscriptionRepo) Create(ctx context.Context, item *model.PlanSubscription) error {
 	return r.db.WithContext(ctx).Create(item).Error
 }
@@ -49,6 +54,22 @@ func (r *PlanSubscriptionRepo) GetByID(ctx context.Context, id int64) (*model.Pl
 	return &item, nil
 }
 
+// GetByIDForUpdate 加行锁读取订阅，串行化同一订阅的周期初始化。
+func (r *PlanSubscriptionRepo) GetByIDForUpdate(ctx context.Context, id int64) (*model.PlanSubscription, error) {
+	var item model.PlanSubscription
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
 func (r *PlanSubscriptionRepo) ListByOrg(ctx context.Context, orgID int64) ([]model.PlanSubscription, error) {
 	var items []model.PlanSubscription
 	if err := r.db.WithContext(ctx).
@@ -60,14 +81,16 @@ func (r *PlanSubscriptionRepo) ListByOrg(ctx context.Context, orgID int64) ([]mo
 	return items, nil
 }
 
+// GetActiveByOrg 返回组织在 now 生效的订阅，已过 EndAt 但仍在套餐宽限期内的订阅也视为生效。
 func (r *PlanSubscriptionRepo) GetActiveByOrg(ctx context.Context, orgID int64, now time.Time) (*model.PlanSubscription, error) {
 	var item model.PlanSubscription
 	err := r.db.WithContext(ctx).
-		Where("user_id = ?", orgID).
-		Where("status = ?", "active").
-		Where("start_at <= ?", now).
-		Where("end_at IS NULL OR end_at > ?", now).
-		Order("start_at DESC").
+		Joins("LEFT JOIN plans ON plans.id = plan_subscriptions.plan_id").
+		Where("plan_subscriptions.user_id = ?", orgID).
+		Where("plan_subscriptions.status = ?", "active").
+		Where("plan_subscriptions.start_at <= ?", now).
+		Where("plan_subscriptions.end_at IS NULL OR plan_subscriptions.end_at + make_interval(days => COALESCE(plans.grace_period_days, 0)) > ?", now).
+		Order("plan_subscriptions.start_at DESC").
 		First(&item).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
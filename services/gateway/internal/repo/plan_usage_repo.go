@@ -5,9 +5,10 @@ import (
 	"errors"
 	"time"
 
-	"deepspace/internal/model"
+	"deepspace-shared/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PlanUsageRepo struct {
@@ -18,17 +19,15 @@ func NewPlanUsageRepo(db *gorm.DB) *PlanUsageRepo {
 	return &PlanUsageRepo{db: db}
 }
 
-func (r *PlanUsageRepo) GetBySubscriptionPeriod(ctx context.Context, subscriptionID int64, periodStart time.Time, periodEnd *time.Time) (*model.PlanUsage, error) {
+func (r *PlanUsageRepo) WithTx(tx *gorm.DB) *PlanUsageRepo {
+	return &PlanUsageRepo{db: tx}
+}
+
+func (r *PlanUsageRepo) GetBySubscriptionPeriod(ctx context.Context, subscriptionID, allowanceID int64, periodStart time.Time, periodEnd *time.Time) (*model.PlanUsage, error) {
 	var item model.PlanUsage
-	query := r.db.WithContext(ctx).
-		Where("subscription_id = ?", subscriptionID).
-		Where("period_start = ?", periodStart)
-	if periodEnd == nil {
-		query = query.Where("period_end IS NULL")
-	} else {
-		query = query.Where("period_end = ?", *periodEnd)
-	}
-	err := query.First(&item).Error
+	err := r.periodQuery(ctx, subscriptionID, periodStart, periodEnd).
+		Where("allowance_id = ?", allowanceID).
+		First(&item).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -38,40 +37,120 @@ func (r *PlanUsageRepo) GetBySubscriptionPeriod(ctx context.Context, subscriptio
 	return &item, nil
 }
 
-func (r *PlanUsageRepo) AddUsage(ctx context.Context, subscriptionID, userID int64, periodStart time.Time, periodEnd *time.Time, tokenDelta, requestDelta int64) (*model.PlanUsage, error) {
+// ListBySubscriptionPeriod 返回订阅某个周期内通用额度与各限定额度的用量。
+func (r *PlanUsageRepo) ListBySubscriptionPeriod(ctx context.Context, subscriptionID int64, periodStart time.Time, periodEnd *time.Time) ([]model.PlanUsage, error) {
+	var items []model.PlanUsage
+	if err := r.periodQuery(ctx, subscriptionID, periodStart, periodEnd).
+		Order("allowance_id ASC").
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// AddUsage 原子地累加订阅某个周期某项额度的用量并返回累加后的记录，记录不存在时创建。
+// 按 (subscription_id, allowance_id, period_start) 唯一索引 upsert，并发请求不会产生重复记录或丢失累加。
+func (r *PlanUsageRepo) AddUsage(ctx context.Context, subscriptionID, allowanceID, userID int64, periodStart time.Time, periodEnd *time.Time, tokenDelta, requestDelta int64) (*model.PlanUsage, error) {
 	if tokenDelta == 0 && requestDelta == 0 {
-		return r.GetBySubscriptionPeriod(ctx, subscriptionID, periodStart, periodEnd)
+		return r.GetBySubscriptionPeriod(ctx, subscriptionID, allowanceID, periodStart, periodEnd)
 	}
+	return r.upsertUsage(ctx, subscriptionID, allowanceID, userID, periodStart, periodEnd, tokenDelta, requestDelta)
+}
 
-	item, err := r.GetBySubscriptionPeriod(ctx, subscriptionID, periodStart, periodEnd)
-	if err != nil {
-		return nil, err
+// LockUsage 返回订阅某个周期某项额度的用量记录并在当前事务中锁定该行，记录不存在时创建。
+func (r *PlanUsageRepo) LockUsage(ctx context.Context, subscriptionID, allowanceID, userID int64, periodStart time.Time, periodEnd *time.Time) (*model.PlanUsage, error) {
+	return r.upsertUsage(ctx, subscriptionID, allowanceID, userID, periodStart, periodEnd, 0, 0)
+}
+
+// upsertUsage 插入或累加用量；ON CONFLICT DO UPDATE 会锁定冲突行，事务内后续读取看到的是累加后的值。
+func (r *PlanUsageRepo) upsertUsage(ctx context.Context, subscriptionID, allowanceID, userID int64, periodStart time.Time, periodEnd *time.Time, tokenDelta, requestDelta int64) (*model.PlanUsage, error) {
+	item := &model.PlanUsage{
+		SubscriptionID: subscriptionID,
+		UserID:         userID,
+		AllowanceID:    allowanceID,
+		PeriodStart:    periodStart,
+		PeriodEnd:      periodEnd,
+		UsedTokens:     tokenDelta,
+		UsedRequests:   requestDelta,
 	}
-	if item == nil {
-		item = &model.PlanUsage{
-			SubscriptionID: subscriptionID,
-			UserID:         userID,
-			PeriodStart:    periodStart,
-			PeriodEnd:      periodEnd,
-			UsedTokens:     tokenDelta,
-			UsedRequests:   requestDelta,
-		}
-		if err := r.db.WithContext(ctx).Create(item).Error; err != nil {
-			return nil, err
-		}
-		return item, nil
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "subscription_id"}, {Name: "allowance_id"}, {Name: "period_start"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"used_tokens":   gorm.Expr("plan_usages.used_tokens + EXCLUDED.used_tokens"),
+				"used_requests": gorm.Expr("plan_usages.used_requests + EXCLUDED.used_requests"),
+				"updated_at":    gorm.Expr("EXCLUDED.updated_at"),
+			}),
+		}, clause.Returning{}).
+		Create(item).Error; err != nil {
+		return nil, err
 	}
+	return item, nil
+}
+
+func (r *PlanUsageRepo) Create(ctx context.Context, item *model.PlanUsage) error {
+	return r.db.WithContext(ctx).Create(item).Error
+}
 
+// AddRolloverUsage 把用量记入订阅某个周期某项额度的结转部分，该周期的用量记录须已存在。
+func (r *PlanUsageRepo) AddRolloverUsage(ctx context.Context, subscriptionID, allowanceID int64, periodStart time.Time, periodEnd *time.Time, tokenDelta, requestDelta int64) error {
 	updates := map[string]any{}
 	if tokenDelta != 0 {
-		updates["used_tokens"] = gorm.Expr("used_tokens + ?", tokenDelta)
+		updates["rollover_used_tokens"] = gorm.Expr("rollover_used_tokens + ?", tokenDelta)
 	}
 	if requestDelta != 0 {
-		updates["used_requests"] = gorm.Expr("used_requests + ?", requestDelta)
+		updates["rollover_used_requests"] = gorm.Expr("rollover_used_requests + ?", requestDelta)
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	return r.periodQuery(ctx, subscriptionID, periodStart, periodEnd).
+		Model(&model.PlanUsage{}).
+		Where("allowance_id = ?", allowanceID).
+		Updates(updates).Error
+}
+
+func (r *PlanUsageRepo) periodQuery(ctx context.Context, subscriptionID int64, periodStart time.Time, periodEnd *time.Time) *gorm.DB {
+	query := r.db.WithContext(ctx).
+		Where("subscription_id = ?", subscriptionID).
+		Where("period_start = ?", periodStart)
+	if periodEnd == nil {
+		return query.Where("period_end IS NULL")
 	}
-	if err := r.db.WithContext(ctx).Model(&model.PlanUsage{}).Where("id = ?", item.ID).Updates(updates).Error; err != nil {
+	return query.Where("period_end = ?", *periodEnd)
+}
+
+// PlanUsagePeriod 是组织某个订阅周期的标识。
+type PlanUsagePeriod struct {
+	SubscriptionID int64
+	PeriodStart    time.Time
+	PeriodEnd      *time.Time
+}
+
+// ListPeriodsByOrg 按周期开始时间倒序返回组织最近 limit 个有用量记录的订阅周期。
+func (r *PlanUsageRepo) ListPeriodsByOrg(ctx context.Context, orgID int64, limit int) ([]PlanUsagePeriod, error) {
+	var items []PlanUsagePeriod
+	if err := r.db.WithContext(ctx).
+		Model(&model.PlanUsage{}).
+		Select("subscription_id, period_start, period_end").
+		Where("user_id = ?", orgID).
+		Group("subscription_id, period_start, period_end").
+		Order("period_start DESC, subscription_id DESC").
+		Limit(limit).
+		Scan(&items).Error; err != nil {
 		return nil, err
 	}
+	return items, nil
+}
 
-	return r.GetBySubscriptionPeriod(ctx, subscriptionID, periodStart, periodEnd)
+// ListByOrgSince 返回组织从 since 起开始的所有周期用量。
+func (r *PlanUsageRepo) ListByOrgSince(ctx context.Context, orgID int64, since time.Time) ([]model.PlanUsage, error) {
+	var items []model.PlanUsage
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND period_start >= ?", orgID, since).
+		Order("period_start DESC, allowance_id ASC").
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
 }
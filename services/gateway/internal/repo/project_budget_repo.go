@@ -0,0 +1,53 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"deepspace-shared/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProjectBudgetRepo struct {
+	db *gorm.DB
+}
+
+func NewProjectBudgetRepo(db *gorm.DB) *ProjectBudgetRepo {
+	return &ProjectBudgetRepo{db: db}
+}
+
+func (r *ProjectBudgetRepo) Get(ctx context.Context, orgID, projectID int64) (*model.ProjectBudget, error) {
+	var item model.ProjectBudget
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND project_id = ?", orgID, projectID).
+		First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Upsert 按 project_id 写入项目预算，已存在时覆盖周期、额度、币种与执行方式。
+func (r *ProjectBudgetRepo) Upsert(ctx context.Context, item *model.ProjectBudget) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "project_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"user_id", "cycle", "amount", "currency", "enforcement", "updated_at"}),
+		}).
+		Create(item).Error
+}
+
+func (r *ProjectBudgetRepo) Delete(ctx context.Context, orgID, projectID int64) (bool, error) {
+	tx := r.db.WithContext(ctx).
+		Where("user_id = ? AND project_id = ?", orgID, projectID).
+		Delete(&model.ProjectBudget{})
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+	return tx.RowsAffected > 0, nil
+}
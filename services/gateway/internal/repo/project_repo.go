@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 
-	"deepspace/internal/model"
+	"deepspace-shared/model"
 
 	"gorm.io/gorm"
 )
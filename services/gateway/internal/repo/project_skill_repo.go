@@ -3,7 +3,7 @@ package repo
 import (
 	"context"
 
-	"deepspace/internal/model"
+	"deepspace-shared/model"
 
 	"gorm.io/gorm"
 )
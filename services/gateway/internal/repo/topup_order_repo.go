@@ -0,0 +1,98 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"deepspace-shared/model"
+
+	"gorm.io/gorm"
+)
+
+type TopUpOrderRepo struct {
+	db *gorm.DB
+}
+
+func NewTopUpOrderRepo(db *gorm.DB) *TopUpOrderRepo {
+	return &TopUpOrderRepo{db: db}
+}
+
+type TopUpOrderFilter struct {
+	UserID *int64
+	Status string
+	Limit  int
+	Offset int
+}
+
+func (r *TopUpOrderRepo) Create(ctx context.Context, item *model.TopUpOrder) error {
+	return r.db.WithContext(ctx).Create(item).Error
+}
+
+func (r *TopUpOrderRepo) GetByID(ctx context.Context, id int64) (*model.TopUpOrder, error) {
+	var item model.TopUpOrder
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *TopUpOrderRepo) GetByPayment(ctx context.Context, provider, paymentID string) (*model.TopUpOrder, error) {
+	var item model.TopUpOrder
+	err := r.db.WithContext(ctx).
+		Where("provider = ? AND payment_id = ?", provider, paymentID).
+		First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// MarkPaid 将订单置为 succeeded；已成功的订单保持原支付时间。
+func (r *TopUpOrderRepo) MarkPaid(ctx context.Context, id int64, paidAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&model.TopUpOrder{}).
+		Where("id = ? AND status <> ?", id, "succeeded").
+		Updates(map[string]any{
+			"status":  "succeeded",
+			"paid_at": paidAt,
+		}).Error
+}
+
+// MarkFailed 仅将 pending 订单置为 failed，避免乱序回调覆盖已成功的订单。
+func (r *TopUpOrderRepo) MarkFailed(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.TopUpOrder{}).
+		Where("id = ? AND status = ?", id, "pending").
+		Update("status", "failed").Error
+}
+
+func (r *TopUpOrderRepo) List(ctx context.Context, filter TopUpOrderFilter) ([]model.TopUpOrder, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.TopUpOrder{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []model.TopUpOrder
+	if err := query.Order("created_at DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
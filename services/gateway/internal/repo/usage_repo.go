@@ -4,7 +4,8 @@ import (
 	"context"
 	"time"
 
-	"deepspace/internal/model"
+	"deepspace-shared/model"
+	"deepspace-shared/money"
 
 	"gorm.io/gorm"
 )
@@ -22,7 +23,7 @@ func (r *UsageRepo) Create(ctx context.Context, record model.UsageRecord) error
 }
 
 type UsageListFilter struct {
-	UserID int64
+	OrgID  int64
 	Start  *time.Time
 	End    *time.Time
 	Limit  int
@@ -31,7 +32,7 @@ type UsageListFilter struct {
 
 func (r *UsageRepo) List(ctx context.Context, filter UsageListFilter) ([]model.UsageRecord, error) {
 	query := r.db.WithContext(ctx).
-		Where("user_id = ?", filter.UserID)
+		Where("org_id = ?", filter.OrgID)
 	if filter.Start != nil {
 		query = query.Where("created_at >= ?", *filter.Start)
 	}
@@ -50,10 +51,10 @@ func (r *UsageRepo) List(ctx context.Context, filter UsageListFilter) ([]model.U
 	return records, nil
 }
 
-func (r *UsageRepo) Count(ctx context.Context, userID int64, start, end *time.Time) (int64, error) {
+func (r *UsageRepo) Count(ctx context.Context, orgID int64, start, end *time.Time) (int64, error) {
 	query := r.db.WithContext(ctx).
 		Model(&model.UsageRecord{}).
-		Where("user_id = ?", userID)
+		Where("org_id = ?", orgID)
 	if start != nil {
 		query = query.Where("created_at >= ?", *start)
 	}
@@ -67,38 +68,54 @@ func (r *UsageRepo) Count(ctx context.Context, userID int64, start, end *time.Ti
 	return count, nil
 }
 
-func (r *UsageRepo) SumCost(ctx context.Context, userID int64, start, end *time.Time) (float64, error) {
+// SumCostByCurrency 按币种汇总费用。
+func (r *UsageRepo) SumCostByCurrency(ctx context.Context, orgID int64, start, end *time.Time) (map[string]money.Amount, error) {
 	query := r.db.WithContext(ctx).
 		Model(&model.UsageRecord{}).
-		Where("user_id = ?", userID)
+		Where("org_id = ?", orgID)
 	if start != nil {
 		query = query.Where("created_at >= ?", *start)
 	}
 	if end != nil {
 		query = query.Where("created_at < ?", *end)
 	}
-	var total float64
-	if err := query.Select("COALESCE(SUM(cost), 0)").Scan(&total).Error; err != nil {
-		return 0, err
+	return sumCostByCurrency(query)
+}
+
+func sumCostByCurrency(query *gorm.DB) (map[string]money.Amount, error) {
+	var rows []struct {
+		Currency string
+		Total    money.Amount
+	}
+	if err := query.Select("currency, COALESCE(SUM(cost), 0) AS total").Group("currency").Scan(&rows).Error; err != nil {
+		return nil, err
 	}
-	return total, nil
+	costs := make(map[string]money.Amount, len(rows))
+	for _, row := range rows {
+		costs[row.Currency] += row.Total
+	}
+	return costs, nil
 }
 
 type AdminUsageListFilter struct {
 	UserID *int64
+	OrgID  *int64
 	Start  *time.Time
 	End    *time.Time
 	Limit  int
 	Offset int
 }
 
+// UsageAggregate 汇总用量；费用按记录币种分别累计，由调用方换算。
 type UsageAggregate struct {
 	TotalTokens int64
-	TotalCost   float64
+	Costs       map[string]money.Amount
 }
 
+// UsageAggregateFilter 的 OrgID 非 0 时按付费组织汇总，否则按发起请求的用户汇总。
 type UsageAggregateFilter struct {
 	UserID    int64
+	OrgID     int64
 	ProjectID *int64
 	Start     *time.Time
 	End       *time.Time
@@ -109,6 +126,9 @@ func (r *UsageRepo) ListAdmin(ctx context.Context, filter AdminUsageListFilter)
 	if filter.UserID != nil {
 		query = query.Where("user_id = ?", *filter.UserID)
 	}
+	if filter.OrgID != nil {
+		query = query.Where("org_id = ?", *filter.OrgID)
+	}
 	if filter.Start != nil {
 		query = query.Where("created_at >= ?", *filter.Start)
 	}
@@ -128,6 +148,9 @@ func (r *UsageRepo) CountAdmin(ctx context.Context, filter AdminUsageListFilter)
 	if filter.UserID != nil {
 		query = query.Where("user_id = ?", *filter.UserID)
 	}
+	if filter.OrgID != nil {
+		query = query.Where("org_id = ?", *filter.OrgID)
+	}
 	if filter.Start != nil {
 		query = query.Where("created_at >= ?", *filter.Start)
 	}
@@ -142,9 +165,7 @@ func (r *UsageRepo) CountAdmin(ctx context.Context, filter AdminUsageListFilter)
 }
 
 func (r *UsageRepo) CountByScope(ctx context.Context, filter UsageAggregateFilter) (int64, error) {
-	query := r.db.WithContext(ctx).
-		Model(&model.UsageRecord{}).
-		Where("user_id = ?", filter.UserID)
+	query := scopeUsageOwner(r.db.WithContext(ctx).Model(&model.UsageRecord{}), filter)
 	if filter.ProjectID != nil {
 		query = query.Where("project_id = ?", *filter.ProjectID)
 	}
@@ -162,9 +183,7 @@ func (r *UsageRepo) CountByScope(ctx context.Context, filter UsageAggregateFilte
 }
 
 func (r *UsageRepo) AggregateByScope(ctx context.Context, filter UsageAggregateFilter) (UsageAggregate, error) {
-	query := r.db.WithContext(ctx).
-		Model(&model.UsageRecord{}).
-		Where("user_id = ?", filter.UserID)
+	query := scopeUsageOwner(r.db.WithContext(ctx).Model(&model.UsageRecord{}), filter)
 	if filter.ProjectID != nil {
 		query = query.Where("project_id = ?", *filter.ProjectID)
 	}
@@ -175,8 +194,20 @@ func (r *UsageRepo) AggregateByScope(ctx context.Context, filter UsageAggregateF
 		query = query.Where("created_at < ?", *filter.End)
 	}
 	var result UsageAggregate
-	if err := query.Select("COALESCE(SUM(total_tokens), 0) AS total_tokens, COALESCE(SUM(cost), 0) AS total_cost").Scan(&result).Error; err != nil {
+	if err := query.Session(&gorm.Session{}).Select("COALESCE(SUM(total_tokens), 0)").Scan(&result.TotalTokens).Error; err != nil {
+		return UsageAggregate{}, err
+	}
+	costs, err := sumCostByCurrency(query)
+	if err != nil {
 		return UsageAggregate{}, err
 	}
+	result.Costs = costs
 	return result, nil
 }
+
+func scopeUsageOwner(query *gorm.DB, filter UsageAggregateFilter) *gorm.DB {
+	if filter.OrgID > 0 {
+		return query.Where("org_id = ?", filter.OrgID)
+	}
+	return query.Where("user_id = ?", filter.UserID)
+}
@@ -0,0 +1,158 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"deepspace-shared/model"
+	"deepspace-shared/money"
+
+	"gorm.io/gorm"
+)
+
+// UsageRollupCursorName 是 Worker 累加 usage_rollups 时使用的游标名。
+const UsageRollupCursorName = model.UsageRollupCursorName
+
+type UsageRollupRepo struct {
+	db *gorm.DB
+}
+
+func NewUsageRollupRepo(db *gorm.DB) *UsageRollupRepo {
+	return &UsageRollupRepo{db: db}
+}
+
+// UsageRollupFilter 查询 [Start, End) 内的聚合；GroupBy 为调用方校验过的维度列名。
+type UsageRollupFilter struct {
+	Granularity string
+	OrgID       *int64
+	UserID      *int64
+	ProjectID   *int64
+	Model       string
+	Start       time.Time
+	End         time.Time
+	GroupBy     []string
+}
+
+// UsageRollupRow 是按维度与币种汇总后的一行，未参与分组的维度为零值。
+type UsageRollupRow struct {
+	BucketStart      time.Time
+	OrgID            int64
+	UserID           int64
+	ProjectID        int64
+	Model            string
+	Currency         string
+	Requests         int64
+	FailedRequests   int64
+	PromptTokens     int64
+	CompletionTokens int64
+	TotalTokens      int64
+	Cost             money.Amount
+	LatencyMsSum     int64
+	LatencyCount     int64
+	FirstTokenMsSum  int64
+	FirstTokenCount  int64
+}
+
+const usageRollupSums = `COALESCE(SUM(requests), 0) AS requests,
+	COALESCE(SUM(failed_requests), 0) AS failed_requests,
+	COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens,
+	COALESCE(SUM(completion_tokens), 0) AS completion_tokens,
+	COALESCE(SUM(total_tokens), 0) AS total_tokens,
+	COALESCE(SUM(cost), 0) AS cost,
+	COALESCE(SUM(latency_ms_sum), 0) AS latency_ms_sum,
+	COALESCE(SUM(latency_count), 0) AS latency_count,
+	COALESCE(SUM(first_token_ms_sum), 0) AS first_token_ms_sum,
+	COALESCE(SUM(first_token_count), 0) AS first_token_count`
+
+// Series 按时间桶、GroupBy 维度与币种汇总，按时间升序返回。
+func (r *UsageRollupRepo) Series(ctx context.Context, filter UsageRollupFilter) ([]UsageRollupRow, error) {
+	columns := strings.Join(append(append([]string{"bucket_start"}, filter.GroupBy...), "currency"), ", ")
+	var rows []UsageRollupRow
+	if err := r.scoped(ctx, filter).
+		Select(columns + ", " + usageRollupSums).
+		Group(columns).
+		Order(columns).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Totals 按 GroupBy 维度与币种汇总整个时间范围，按费用、请求数倒序返回前 limit 行。
+func (r *UsageRollupRepo) Totals(ctx context.Context, filter UsageRollupFilter, limit int) ([]UsageRollupRow, error) {
+	columns := strings.Join(append(append([]string{}, filter.GroupBy...), "currency"), ", ")
+	var rows []UsageRollupRow
+	if err := r.scoped(ctx, filter).
+		Select(columns + ", " + usageRollupSums).
+		Group(columns).
+		Order("cost DESC, requests DESC, " + columns).
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *UsageRollupRepo) scoped(ctx context.Context, filter UsageRollupFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).
+		Model(&model.UsageRollup{}).
+		Where("granularity = ? AND bucket_start >= ? AND bucket_start < ?", filter.Granularity, filter.Start, filter.End)
+	if filter.OrgID != nil {
+		query = query.Where("org_id = ?", *filter.OrgID)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.ProjectID != nil {
+		query = query.Where("project_id = ?", *filter.ProjectID)
+	}
+	if filter.Model != "" {
+		query = query.Where("model = ?", filter.Model)
+	}
+	return query
+}
+
+// AggregateSince 汇总 filter.Start 至今的用量，Start 须按天（UTC）对齐且不能为空。
+// 已累加的记录读 usage_rollups 的天聚合，游标之后尚未累加的记录读 usage_records；
+// 两部分在同一条语句中查询，共享快照，Worker 并发推进游标时不会重复或遗漏。
+func (r *UsageRollupRepo) AggregateSince(ctx context.Context, filter UsageAggregateFilter) (UsageAggregate, error) {
+	ownerColumn, ownerID := "user_id", filter.UserID
+	if filter.OrgID > 0 {
+		ownerColumn, ownerID = "org_id", filter.OrgID
+	}
+	rollupWhere := "granularity = ? AND bucket_start >= ? AND " + ownerColumn + " = ?"
+	recordWhere := "u.id > c.last_id AND u.created_at >= ? AND u." + ownerColumn + " = ?"
+	rollupArgs := []any{"day", *filter.Start, ownerID}
+	recordArgs := []any{*filter.Start, ownerID}
+	if filter.ProjectID != nil {
+		rollupWhere += " AND project_id = ?"
+		recordWhere += " AND COALESCE(u.project_id, 0) = ?"
+		rollupArgs = append(rollupArgs, *filter.ProjectID)
+		recordArgs = append(recordArgs, *filter.ProjectID)
+	}
+
+	var rows []struct {
+		Currency    string
+		TotalTokens int64
+		Total       money.Amount
+	}
+	if err := r.db.WithContext(ctx).Raw(`SELECT currency, COALESCE(SUM(total_tokens), 0) AS total_tokens, COALESCE(SUM(cost), 0) AS total FROM (
+			SELECT currency, total_tokens, cost FROM usage_rollups WHERE `+rollupWhere+`
+			UNION ALL
+			SELECT COALESCE(u.currency, ''), u.total_tokens, u.cost
+			FROM usage_records u
+			CROSS JOIN (SELECT COALESCE(MAX(last_id), 0) AS last_id FROM usage_rollup_cursors WHERE name = ?) AS c
+			WHERE `+recordWhere+`
+		) AS spend GROUP BY currency`,
+		append(append(rollupArgs, UsageRollupCursorName), recordArgs...)...).
+		Scan(&rows).Error; err != nil {
+		return UsageAggregate{}, err
+	}
+	result := UsageAggregate{Costs: make(map[string]money.Amount, len(rows))}
+	for _, row := range rows {
+		result.TotalTokens += row.TotalTokens
+		result.Costs[row.Currency] += row.Total
+	}
+	return result, nil
+}
@@ -0,0 +1,155 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"deepspace-shared/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VoucherRepo struct {
+	db *gorm.DB
+}
+
+func NewVoucherRepo(db *gorm.DB) *VoucherRepo {
+	return &VoucherRepo{db: db}
+}
+
+func (r *VoucherRepo) WithTx(tx *gorm.DB) *VoucherRepo {
+	return &VoucherRepo{db: tx}
+}
+
+type VoucherFilter struct {
+	Status string
+	Code   string
+	Limit  int
+	Offset int
+}
+
+type VoucherRedemptionFilter struct {
+	VoucherID *int64
+	UserID    *int64
+	Status    string
+	Limit     int
+	Offset    int
+}
+
+func (r *VoucherRepo) CreateBatch(ctx context.Context, items []model.Voucher) error {
+	return r.db.WithContext(ctx).Create(&items).Error
+}
+
+func (r *VoucherRepo) GetByID(ctx context.Context, id int64) (*model.Voucher, error) {
+	var item model.Voucher
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetByCodeForUpdate 按兑换码加行锁读取，串行化同一代金券的并发兑换。
+func (r *VoucherRepo) GetByCodeForUpdate(ctx context.Context, code string) (*model.Voucher, error) {
+	var item model.Voucher
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code = ?", code).
+		First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *VoucherRepo) Update(ctx context.Context, id int64, updates map[string]any) (*model.Voucher, error) {
+	if len(updates) == 0 {
+		return r.GetByID(ctx, id)
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&model.Voucher{}).
+		Where("id = ?", id).
+		Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *VoucherRepo) IncrementRedeemed(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.Voucher{}).
+		Where("id = ?", id).
+		Update("redeemed_count", gorm.Expr("redeemed_count + 1")).Error
+}
+
+func (r *VoucherRepo) List(ctx context.Context, filter VoucherFilter) ([]model.Voucher, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.Voucher{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Code != "" {
+		query = query.Where("code = ?", filter.Code)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []model.Voucher
+	if err := query.Order("created_at DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (r *VoucherRepo) GetRedemption(ctx context.Context, voucherID, userID int64) (*model.VoucherRedemption, error) {
+	var item model.VoucherRedemption
+	err := r.db.WithContext(ctx).
+		Where("voucher_id = ? AND user_id = ?", voucherID, userID).
+		First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *VoucherRepo) CreateRedemption(ctx context.Context, item *model.VoucherRedemption) error {
+	return r.db.WithContext(ctx).Create(item).Error
+}
+
+func (r *VoucherRepo) ListRedemptions(ctx context.Context, filter VoucherRedemptionFilter) ([]model.VoucherRedemption, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.VoucherRedemption{})
+	if filter.VoucherID != nil {
+		query = query.Where("voucher_id = ?", *filter.VoucherID)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []model.VoucherRedemption
+	if err := query.Order("created_at DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
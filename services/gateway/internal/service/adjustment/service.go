@@ -0,0 +1,433 @@
+package adjustment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"deepspace-shared/model"
+	"deepspace-shared/money"
+	"deepspace/internal/repo"
+	"deepspace/internal/service/billing"
+	"deepspace/internal/service/fx"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidType          = errors.New("invalid adjustment type")
+	ErrInvalidAmount        = errors.New("invalid adjustment amount")
+	ErrReasonRequired       = errors.New("reason is required")
+	ErrRefIDRequired        = errors.New("ref_id is required")
+	ErrRefConflict          = errors.New("ref_id already used with different adjustment")
+	ErrAdjustmentNotFound   = errors.New("adjustment not found")
+	ErrAdjustmentNotPending = errors.New("adjustment is not pending")
+	// ErrSelfApproval 表示审批人与申请人相同，大额调账必须由另一名管理员审批。
+	ErrSelfApproval = errors.New("adjustment must be approved by another admin")
+)
+
+const (
+	StatusPending  = "pending"
+	StatusApplied  = "applied"
+	StatusRejected = "rejected"
+)
+
+// 审计日志 action，与 Worker 的 billing.reconcile.adjustment 同一命名空间。
+const (
+	ActionRefund            = "billing.refund"
+	ActionAdjustmentRequest = "billing.adjustment.request"
+	ActionAdjustmentApply   = "billing.adjustment.apply"
+	ActionAdjustmentApprove = "billing.adjustment.approve"
+	ActionAdjustmentReject  = "billing.adjustment.reject"
+)
+
+// Options.ApprovalThreshold 以 ThresholdCurrency 计价；调账金额换算后超过阈值时需要第二名管理员审批，
+// 阈值为 0 时所有调账都需要审批。
+type Options struct {
+	ApprovalThreshold money.Amount
+	ThresholdCurrency string
+}
+
+type Service struct {
+	db      *gorm.DB
+	repo    *repo.BillingAdjustmentRepo
+	audit   *repo.AuditLogRepo
+	billing *billing.Service
+	fx      *fx.Service
+	opts    Options
+}
+
+func New(db *gorm.DB, repo *repo.BillingAdjustmentRepo, auditRepo *repo.AuditLogRepo, billingSvc *billing.Service, fxSvc *fx.Service, opts Options) *Service {
+	return &Service{db: db, repo: repo, audit: auditRepo, billing: billingSvc, fx: fxSvc, opts: opts}
+}
+
+// RequestInput.Type 为 credit 或 debit；RefID 为空时自动生成，重复提交相同 RefID 返回已有申请。
+type RequestInput struct {
+	UserID   int64
+	Type     string
+	Amount   money.Amount
+	Currency string
+	Reason   string
+	RefID    string
+	AdminID  int64
+	TraceID  string
+}
+
+type ReviewInput struct {
+	AdminID int64
+	Note    string
+	TraceID string
+}
+
+// RefundInput.RefID 为被退款的扣款 ref，RefundID 为本次退款的幂等键。
+type RefundInput struct {
+	UserID   int64
+	RefID    string
+	RefundID string
+	Amount   money.Amount
+	Currency string
+	Reason   string
+	AdminID  int64
+	TraceID  string
+}
+
+type ListInput struct {
+	UserID   *int64
+	Status   string
+	Page     int
+	PageSize int
+}
+
+type AdjustmentResult struct {
+	Adjustment  *model.BillingAdjustment `json:"adjustment"`
+	Wallet      *model.Wallet            `json:"wallet,omitempty"`
+	Transaction *model.Transaction       `json:"transaction,omitempty"`
+}
+
+// Refund 退回已扣款金额并写审计日志；退款不走审批流程。
+func (s *Service) Refund(ctx context.Context, input RefundInput) (*billing.RefundResult, error) {
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+	refID := strings.TrimSpace(input.RefID)
+	refundID := strings.TrimSpace(input.RefundID)
+	if refID == "" || refundID == "" {
+		return nil, ErrRefIDRequired
+	}
+
+	var result *billing.RefundResult
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		refunded, err := s.billing.WithTx(tx).Refund(ctx, input.UserID, input.Amount, input.Currency, refundID, refID, map[string]any{
+			"source":   "admin_refund",
+			"reason":   reason,
+			"admin_id": input.AdminID,
+		})
+		if err != nil {
+			return err
+		}
+		// 重放的退款不重复写审计日志。
+		if refunded.Ref != nil {
+			if err := s.writeAudit(ctx, tx, input.UserID, input.TraceID, ActionRefund, map[string]any{
+				"admin_id":       input.AdminID,
+				"reason":         reason,
+				"ref_id":         refID,
+				"refund_id":      refundID,
+				"amount":         refunded.Transaction.Amount,
+				"currency":       refunded.Transaction.Currency,
+				"transaction_id": refunded.Transaction.ID,
+				"refunded_total": refunded.Ref.RefundedAmount,
+				"captured":       refunded.Ref.CapturedAmount,
+			}); err != nil {
+				return err
+			}
+		}
+		result = refunded
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Request 创建调账申请。换算后未超过审批阈值的申请立即入账，否则保持 pending 等待另一名管理员审批。
+func (s *Service) Request(ctx context.Context, input RequestInput) (*AdjustmentResult, error) {
+	typ, err := normalizeType(input.Type)
+	if err != nil {
+		return nil, err
+	}
+	if input.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+	wallet, err := s.billing.GetWallet(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	currency := fx.NormalizeCurrency(input.Currency)
+	if strings.TrimSpace(input.Currency) == "" {
+		currency = wallet.Currency
+	}
+	if currency == "" {
+		return nil, fx.ErrInvalidCurrency
+	}
+	refID := strings.TrimSpace(input.RefID)
+	if refID == "" {
+		refID, err = generateRefID()
+		if err != nil {
+			return nil, err
+		}
+	}
+	needsApproval, err := s.needsApproval(ctx, input.Amount, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *AdjustmentResult
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
+		existing, err := repoTx.GetByRefID(ctx, refID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			if existing.UserID != input.UserID || existing.Type != typ || existing.Amount != input.Amount || existing.Currency != currency {
+				return ErrRefConflict
+			}
+			result = &AdjustmentResult{Adjustment: existing}
+			return nil
+		}
+
+		item := &model.BillingAdjustment{
+			UserID:      input.UserID,
+			Type:        typ,
+			Amount:      input.Amount,
+			Currency:    currency,
+			Reason:      reason,
+			RefID:       refID,
+			Status:      StatusPending,
+			RequestedBy: input.AdminID,
+		}
+		if err := repoTx.Create(ctx, item); err != nil {
+			return err
+		}
+		if err := s.writeAudit(ctx, tx, item.UserID, input.TraceID, ActionAdjustmentRequest, map[string]any{
+			"admin_id":       input.AdminID,
+			"adjustment_id":  item.ID,
+			"type":           item.Type,
+			"amount":         item.Amount,
+			"currency":       item.Currency,
+			"reason":         item.Reason,
+			"ref_id":         item.RefID,
+			"needs_approval": needsApproval,
+		}); err != nil {
+			return err
+		}
+		if needsApproval {
+			result = &AdjustmentResult{Adjustment: item}
+			return nil
+		}
+		result, err = s.apply(ctx, tx, item, nil, "", input.TraceID, ActionAdjustmentApply)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Approve 由申请人以外的管理员批准并入账；入账失败（如余额不足）时申请保持 pending。
+func (s *Service) Approve(ctx context.Context, id int64, input ReviewInput) (*AdjustmentResult, error) {
+	var result *AdjustmentResult
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		item, err := s.lockPending(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if item.RequestedBy == input.AdminID {
+			return ErrSelfApproval
+		}
+		result, err = s.apply(ctx, tx, item, &input.AdminID, strings.TrimSpace(input.Note), input.TraceID, ActionAdjustmentApprove)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Reject 驳回待审批的调账申请，申请人也可以驳回自己的申请。
+func (s *Service) Reject(ctx context.Context, id int64, input ReviewInput) (*AdjustmentResult, error) {
+	var result *AdjustmentResult
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		item, err := s.lockPending(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		note := strings.TrimSpace(input.Note)
+		updated, err := s.repo.WithTx(tx).Update(ctx, item.ID, map[string]any{
+			"status":      StatusRejected,
+			"reviewed_by": input.AdminID,
+			"reviewed_at": time.Now().UTC(),
+			"review_note": note,
+		})
+		if err != nil {
+			return err
+		}
+		if err := s.writeAudit(ctx, tx, item.UserID, input.TraceID, ActionAdjustmentReject, map[string]any{
+			"admin_id":      input.AdminID,
+			"adjustment_id": item.ID,
+			"requested_by":  item.RequestedBy,
+			"note":          note,
+		}); err != nil {
+			return err
+		}
+		result = &AdjustmentResult{Adjustment: updated}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Service) List(ctx context.Context, input ListInput) ([]model.BillingAdjustment, int64, error) {
+	page := input.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := input.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	return s.repo.List(ctx, repo.BillingAdjustmentFilter{
+		UserID: input.UserID,
+		Status: strings.ToLower(strings.TrimSpace(input.Status)),
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	})
+}
+
+func (s *Service) lockPending(ctx context.Context, tx *gorm.DB, id int64) (*model.BillingAdjustment, error) {
+	item, err := s.repo.WithTx(tx).GetByIDForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrAdjustmentNotFound
+	}
+	if item.Status != StatusPending {
+		return nil, ErrAdjustmentNotPending
+	}
+	return item, nil
+}
+
+// apply 入账并把申请标记为 applied；reviewer 为 nil 表示未超过阈值、无需审批。
+func (s *Service) apply(ctx context.Context, tx *gorm.DB, item *model.BillingAdjustment, reviewer *int64, note, traceID, action string) (*AdjustmentResult, error) {
+	meta := map[string]any{
+		"source":        "admin_adjustment",
+		"adjustment_id": item.ID,
+		"reason":        item.Reason,
+		"requested_by":  item.RequestedBy,
+	}
+	if reviewer != nil {
+		meta["approved_by"] = *reviewer
+	}
+	adjusted, err := s.billing.WithTx(tx).Adjust(ctx, item.UserID, item.Type, item.Amount, item.Currency, item.RefID, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]any{
+		"status":         StatusApplied,
+		"transaction_id": adjusted.Transaction.ID,
+	}
+	if reviewer != nil {
+		updates["reviewed_by"] = *reviewer
+		updates["reviewed_at"] = time.Now().UTC()
+		updates["review_note"] = note
+	}
+	updated, err := s.repo.WithTx(tx).Update(ctx, item.ID, updates)
+	if err != nil {
+		return nil, err
+	}
+
+	auditMeta := map[string]any{
+		"adjustment_id":  item.ID,
+		"type":           item.Type,
+		"amount":         adjusted.Transaction.Amount,
+		"currency":       adjusted.Transaction.Currency,
+		"transaction_id": adjusted.Transaction.ID,
+		"requested_by":   item.RequestedBy,
+		"reason":         item.Reason,
+	}
+	if reviewer != nil {
+		auditMeta["admin_id"] = *reviewer
+		auditMeta["note"] = note
+	} else {
+		auditMeta["admin_id"] = item.RequestedBy
+	}
+	if err := s.writeAudit(ctx, tx, item.UserID, traceID, action, auditMeta); err != nil {
+		return nil, err
+	}
+	return &AdjustmentResult{Adjustment: updated, Wallet: adjusted.Wallet, Transaction: adjusted.Transaction}, nil
+}
+
+// needsApproval 把调账金额换算为阈值币种后与审批阈值比较。
+func (s *Service) needsApproval(ctx context.Context, amount money.Amount, currency string) (bool, error) {
+	if s.opts.ApprovalThreshold <= 0 {
+		return true, nil
+	}
+	thresholdCurrency := s.opts.ThresholdCurrency
+	if thresholdCurrency == "" {
+		thresholdCurrency = currency
+	}
+	conv, err := s.fx.Convert(ctx, amount, currency, thresholdCurrency, money.RoundHalfUp)
+	if err != nil {
+		return false, err
+	}
+	return conv.Amount > s.opts.ApprovalThreshold, nil
+}
+
+func (s *Service) writeAudit(ctx context.Context, tx *gorm.DB, userID int64, traceID, action string, metadata map[string]any) error {
+	meta, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	return s.audit.WithTx(tx).Create(ctx, &model.AuditLog{
+		UserID:   &userID,
+		TraceID:  traceID,
+		Action:   action,
+		Metadata: meta,
+	})
+}
+
+func normalizeType(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "credit", billing.TransactionTypeManualCredit:
+		return billing.TransactionTypeManualCredit, nil
+	case "debit", billing.TransactionTypeManualDebit:
+		return billing.TransactionTypeManualDebit, nil
+	default:
+		return "", ErrInvalidType
+	}
+}
+
+func generateRefID() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "adj_" + hex.EncodeToString(buf), nil
+}
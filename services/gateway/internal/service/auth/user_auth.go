@@ -6,7 +6,7 @@ import (
 	"strings"
 	"time"
 
-	"deepspace/internal/model"
+	"deepspace-shared/model"
 	"deepspace/internal/repo"
 
 	"golang.org/x/crypto/bcrypt"
@@ -0,0 +1,183 @@
+package billing
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"deepspace-shared/model"
+	"deepspace-shared/money"
+	"deepspace/internal/repo"
+)
+
+// 退款与人工调账的流水类型，金额均为正数，方向由类型决定。
+const (
+	TransactionTypeRefund       = model.TransactionTypeRefund
+	TransactionTypeManualCredit = model.TransactionTypeManualCredit
+	TransactionTypeManualDebit  = model.TransactionTypeManualDebit
+	// TransactionTypeSubscription 是从钱包支付套餐订阅费用的流水类型，金额为正数，扣减余额。
+	TransactionTypeSubscription = model.TransactionTypeSubscription
+)
+
+// TransactionTypes 列出流水列表可筛选的类型。adjustment 由 Worker 对账写入。
+var TransactionTypes = []string{
+	model.TransactionTypeHold,
+	model.TransactionTypeCapture,
+	model.TransactionTypeRelease,
+	TransactionTypeExpire,
+	model.TransactionTypeTopUp,
+	TransactionTypeVoucher,
+	TransactionTypeVoucherExpire,
+	TransactionTypeRefund,
+	TransactionTypeManualCredit,
+	TransactionTypeManualDebit,
+	TransactionTypeSubscription,
+	model.TransactionTypeAdjustment,
+}
+
+func IsTransactionType(value string) bool {
+	return slices.Contains(TransactionTypes, value)
+}
+
+type RefundResult = HoldResult
+
+// Refund 把 captureRef 已扣款的金额部分或全部退回余额。refundID 是本次退款的幂等键，
+// 同一 captureRef 可多次部分退款，累计不超过已扣款金额；退款流水的 ParentRefID 指向 captureRef。
+func (s *Service) Refund(ctx context.Context, userID int64, amount money.Amount, currency string, refundID, captureRef string, metadata map[string]any) (*RefundResult, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	return s.withTx(ctx, func(repoTx *repo.BillingRepo) (*HoldResult, error) {
+		wallet, err := s.ensureWallet(ctx, repoTx, userID)
+		if err != nil {
+			return nil, err
+		}
+		conv, err := s.convert(ctx, wallet, amount, currency, money.RoundHalfUp)
+		if err != nil {
+			return nil, err
+		}
+		if existing, err := s.findExisting(ctx, repoTx, userID, refundID, TransactionTypeRefund, conv); err != nil {
+			return nil, err
+		} else if existing != nil {
+			if existing.ParentRefID != captureRef {
+				return nil, ErrRefConflict
+			}
+			return &HoldResult{Wallet: wallet, Transaction: existing}, nil
+		}
+
+		ref, err := s.lockRef(ctx, repoTx, userID, captureRef)
+		if err != nil {
+			return nil, err
+		}
+		if conv.Amount > ref.CapturedAmount-ref.RefundedAmount {
+			return nil, ErrRefundExceedsCapture
+		}
+		ref.RefundedAmount += conv.Amount
+		if err := repoTx.UpdateRef(ctx, ref.ID, map[string]any{"refunded_amount": ref.RefundedAmount}); err != nil {
+			return nil, err
+		}
+
+		wallet.Balance += conv.Amount
+		if err := repoTx.UpdateWallet(ctx, userID, wallet.Balance, wallet.FrozenBalance); err != nil {
+			return nil, err
+		}
+
+		meta, err := json.Marshal(metadata)
+		if err != nil {
+			return nil, err
+		}
+		tr, err := repoTx.CreateTransaction(ctx, &model.Transaction{
+			UserID:           userID,
+			Type:             TransactionTypeRefund,
+			Amount:           conv.Amount,
+			Currency:         conv.Currency,
+			OriginalAmount:   conv.Original,
+			OriginalCurrency: conv.OriginalCurrency,
+			FXRate:           conv.Rate,
+			RefID:            refundID,
+			ParentRefID:      captureRef,
+			Metadata:         meta,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &HoldResult{Wallet: wallet, Transaction: tr, Ref: ref}, nil
+	})
+}
+
+// Adjust 记入一笔人工调账：manual_credit 增加余额，manual_debit 扣减可用余额且不允许透支。
+// 审批等流程由调用方负责，这里只负责入账。
+func (s *Service) Adjust(ctx context.Context, userID int64, typ string, amount money.Amount, currency string, refID string, metadata map[string]any) (*HoldResult, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	switch typ {
+	case TransactionTypeManualCredit:
+		return s.credit(ctx, typ, userID, amount, currency, refID, metadata)
+	case TransactionTypeManualDebit:
+		return s.debit(ctx, typ, userID, amount, currency, refID, metadata, false)
+	default:
+		return nil, ErrInvalidType
+	}
+}
+
+// Charge 从钱包扣除套餐订阅等固定费用，流水类型为 subscription；后付费账户可使用信用额度，停用的钱包不能付费。
+// 同一 ref 重复扣款返回已有流水。
+func (s *Service) Charge(ctx context.Context, userID int64, amount money.Amount, currency string, refID string, metadata map[string]any) (*HoldResult, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	return s.debit(ctx, TransactionTypeSubscription, userID, amount, currency, refID, metadata, true)
+}
+
+// LockWallet 锁定（不存在时创建）钱包，需在 WithTx 返回的服务上调用，用于串行化同一钱包上的复合操作。
+func (s *Service) LockWallet(ctx context.Context, userID int64) (*model.Wallet, error) {
+	return s.ensureWallet(ctx, s.repo, userID)
+}
+
+// debit 扣减余额；allowCredit 为 true 时可透支到信用额度，否则不允许余额为负。
+func (s *Service) debit(ctx context.Context, typ string, userID int64, amount money.Amount, currency string, refID string, metadata map[string]any, allowCredit bool) (*HoldResult, error) {
+	return s.withTx(ctx, func(repoTx *repo.BillingRepo) (*HoldResult, error) {
+		wallet, err := s.ensureWallet(ctx, repoTx, userID)
+		if err != nil {
+			return nil, err
+		}
+		conv, err := s.convert(ctx, wallet, amount, currency, money.RoundHalfUp)
+		if err != nil {
+			return nil, err
+		}
+		if existing, err := s.findExisting(ctx, repoTx, userID, refID, typ, conv); err != nil {
+			return nil, err
+		} else if existing != nil {
+			return &HoldResult{Wallet: wallet, Transaction: existing}, nil
+		}
+		if conv.Amount == 0 {
+			return nil, ErrInvalidAmount
+		}
+		if allowCredit {
+			if wallet.SuspendedAt != nil {
+				return nil, ErrWalletSuspended
+			}
+			if Available(wallet) < conv.Amount {
+				return nil, ErrInsufficientBalance
+			}
+		} else if wallet.Balance < conv.Amount {
+			return nil, ErrInsufficientBalance
+		}
+
+		wallet.Balance -= conv.Amount
+		if err := repoTx.UpdateWallet(ctx, userID, wallet.Balance, wallet.FrozenBalance); err != nil {
+			return nil, err
+		}
+
+		meta, err := json.Marshal(metadata)
+		if err != nil {
+			return nil, err
+		}
+		tr, err := createTransaction(ctx, repoTx, userID, typ, refID, conv, meta)
+		if err != nil {
+			return nil, err
+		}
+		return &HoldResult{Wallet: wallet, Transaction: tr}, nil
+	})
+}
@@ -0,0 +1,85 @@
+package billing
+
+import (
+	"context"
+	"time"
+
+	"deepspace-shared/model"
+	"deepspace-shared/money"
+
+	"gorm.io/gorm"
+)
+
+// 钱包停用原因。credit_limit 由 Worker 催缴任务在额度用尽时设置，欠款结清后自动恢复；
+// admin 由管理员设置，只能由管理员恢复。
+const (
+	SuspendReasonCreditLimit = model.SuspendReasonCreditLimit
+	SuspendReasonAdmin       = model.SuspendReasonAdmin
+)
+
+// Available 返回钱包可用于预扣的金额，后付费账户包含尚未用完的信用额度。
+func Available(wallet *model.Wallet) money.Amount {
+	return wallet.Balance + wallet.CreditLimit
+}
+
+// CheckSpendable 判断钱包能否发起新的用量。
+func CheckSpendable(wallet *model.Wallet) error {
+	if wallet.SuspendedAt != nil {
+		return ErrWalletSuspended
+	}
+	if Available(wallet) <= 0 {
+		return ErrInsufficientBalance
+	}
+	return nil
+}
+
+// SetCreditLimit 设置以钱包币种计的信用额度，0 表示恢复为预付费。
+// 额度低于当前欠款时不会立即停用，由 Worker 催缴任务处理。
+func (s *Service) SetCreditLimit(ctx context.Context, userID int64, limit money.Amount) (*model.Wallet, error) {
+	if limit < 0 {
+		return nil, ErrInvalidCreditLimit
+	}
+	return s.updateWallet(ctx, userID, func(wallet *model.Wallet) map[string]any {
+		wallet.CreditLimit = limit
+		return map[string]any{"credit_limit": limit}
+	})
+}
+
+// SetSuspended 由管理员停用或恢复钱包。恢复时同时清除催缴阶段，仍超出额度的钱包会在下次催缴时重新停用。
+func (s *Service) SetSuspended(ctx context.Context, userID int64, suspended bool) (*model.Wallet, error) {
+	return s.updateWallet(ctx, userID, func(wallet *model.Wallet) map[string]any {
+		if suspended {
+			if wallet.SuspendedAt == nil {
+				now := time.Now().UTC()
+				wallet.SuspendedAt = &now
+			}
+			wallet.SuspendReason = SuspendReasonAdmin
+			return map[string]any{"suspended_at": wallet.SuspendedAt, "suspend_reason": wallet.SuspendReason}
+		}
+		wallet.SuspendedAt = nil
+		wallet.SuspendReason = ""
+		wallet.DunningStage = 0
+		wallet.DunningAt = nil
+		return map[string]any{"suspended_at": nil, "suspend_reason": "", "dunning_stage": 0, "dunning_at": nil}
+	})
+}
+
+func (s *Service) updateWallet(ctx context.Context, userID int64, apply func(wallet *model.Wallet) map[string]any) (*model.Wallet, error) {
+	var result *model.Wallet
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
+		wallet, err := s.ensureWallet(ctx, repoTx, userID)
+		if err != nil {
+			return err
+		}
+		if err := repoTx.UpdateWalletFields(ctx, userID, apply(wallet)); err != nil {
+			return err
+		}
+		result = wallet
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,123 @@
+package billing
+
+import (
+	"context"
+	"slices"
+
+	"deepspace-shared/model"
+	"deepspace-shared/money"
+	"deepspace/internal/repo"
+)
+
+// BillingRef 生命周期状态。
+const (
+	RefStateHeld              = model.RefStateHeld
+	RefStateCaptured          = model.RefStateCaptured
+	RefStatePartiallyCaptured = model.RefStatePartiallyCaptured
+	RefStateReleased          = model.RefStateReleased
+	RefStateExpired           = model.RefStateExpired
+)
+
+// TransactionTypeExpire 是 Worker 回收过期预扣时写入的流水类型。
+const TransactionTypeExpire = model.TransactionTypeExpire
+
+// refTransitions 列出每个状态允许迁移到的状态；未列出的状态均为终态。
+// held→held 对应部分释放；partially_captured→partially_captured 对应扣款后释放剩余预扣。
+var refTransitions = map[string][]string{
+	RefStateHeld: {
+		RefStateHeld,
+		RefStateCaptured,
+		RefStatePartiallyCaptured,
+		RefStateReleased,
+		RefStateExpired,
+	},
+	RefStatePartiallyCaptured: {
+		RefStatePartiallyCaptured,
+		RefStateExpired,
+	},
+}
+
+func canTransition(from, to string) bool {
+	return slices.Contains(refTransitions[from], to)
+}
+
+// refRemaining 返回 ref 仍处于冻结中的金额。
+func refRemaining(ref *model.BillingRef) money.Amount {
+	return max(ref.HeldAmount-ref.CapturedAmount-ref.ReleasedAmount, 0)
+}
+
+// nextRefState 根据累计金额推导状态；expired 表示剩余预扣由过期回收释放。
+func nextRefState(ref *model.BillingRef, expired bool) string {
+	remaining := refRemaining(ref)
+	switch {
+	case remaining > 0 && ref.CapturedAmount > 0:
+		return RefStatePartiallyCaptured
+	case remaining > 0:
+		return RefStateHeld
+	case expired:
+		return RefStateExpired
+	case ref.CapturedAmount == 0:
+		return RefStateReleased
+	case ref.ReleasedAmount == 0:
+		return RefStateCaptured
+	default:
+		return RefStatePartiallyCaptured
+	}
+}
+
+// lockRef 锁定并返回 ref；引入 billing_refs 之前创建的预扣会按已有流水补建。
+func (s *Service) lockRef(ctx context.Context, repoTx *repo.BillingRepo, userID int64, refID string) (*model.BillingRef, error) {
+	ref, err := repoTx.GetRefForUpdate(ctx, userID, refID)
+	if err != nil || ref != nil {
+		return ref, err
+	}
+
+	hold, err := repoTx.GetTransactionByRef(ctx, userID, refID, "hold")
+	if err != nil {
+		return nil, err
+	}
+	if hold == nil {
+		return nil, ErrHoldNotFound
+	}
+	ref = &model.BillingRef{
+		UserID:     userID,
+		RefID:      refID,
+		HeldAmount: hold.Amount,
+		CreatedAt:  hold.CreatedAt,
+	}
+	if capture, err := repoTx.GetTransactionByRef(ctx, userID, refID, "capture"); err != nil {
+		return nil, err
+	} else if capture != nil {
+		ref.CapturedAmount = capture.Amount
+	}
+	if release, err := repoTx.GetTransactionByRef(ctx, userID, refID, "release"); err != nil {
+		return nil, err
+	} else if release != nil {
+		ref.ReleasedAmount = release.Amount
+	}
+	ref.State = nextRefState(ref, false)
+	if err := repoTx.CreateRef(ctx, ref); err != nil {
+		return nil, err
+	}
+	return ref, nil
+}
+
+// advanceRef 累加扣款/释放金额并校验状态迁移。
+func (s *Service) advanceRef(ctx context.Context, repoTx *repo.BillingRepo, ref *model.BillingRef, captured, released money.Amount, expired bool) error {
+	next := *ref
+	next.CapturedAmount = ref.CapturedAmount + captured
+	next.ReleasedAmount = ref.ReleasedAmount + released
+	next.State = nextRefState(&next, expired)
+	if !canTransition(ref.State, next.State) {
+		return ErrInvalidRefTransition
+	}
+	if err := repoTx.UpdateRef(ctx, ref.ID, map[string]any{
+		"state":           next.State,
+		"captured_amount": next.CapturedAmount,
+		"released_amount": next.ReleasedAmount,
+	}); err != nil {
+		return err
+	}
+	*ref = next
+	return nil
+}
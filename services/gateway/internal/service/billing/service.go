@@ -5,12 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math"
 	"strings"
 	"time"
 
-	"deepspace/internal/model"
+	"deepspace-shared/model"
+	"deepspace-shared/money"
 	"deepspace/internal/repo"
+	"deepspace/internal/service/fx"
 
 	"gorm.io/gorm"
 )
@@ -20,20 +21,45 @@ var (
 	ErrInsufficientFrozen  = errors.New("insufficient frozen balance")
 	ErrInvalidAmount       = errors.New("invalid amount")
 	ErrRefConflict         = errors.New("ref_id already used with different transaction")
+	ErrHoldNotFound        = errors.New("hold not found for ref_id")
+	// ErrInvalidRefTransition 表示 ref 已处于终态或目标状态不可达。
+	ErrInvalidRefTransition = errors.New("invalid billing ref transition")
+	// ErrWalletNotEmpty 表示钱包仍有余额或冻结金额，不能切换币种。
+	ErrWalletNotEmpty = errors.New("wallet is not empty")
+	// ErrRefundExceedsCapture 表示累计退款将超过原 ref 的已扣款金额。
+	ErrRefundExceedsCapture = errors.New("refund exceeds captured amount")
+	ErrInvalidType          = errors.New("invalid transaction type")
+	// ErrWalletSuspended 表示钱包已被停用（额度用尽或管理员停用），不能发起新的预扣。
+	ErrWalletSuspended    = errors.New("wallet suspended")
+	ErrInvalidCreditLimit = errors.New("invalid credit limit")
+)
+
+// 代金券入账与过期回收的流水类型；过期回收由 Worker 写入。
+const (
+	TransactionTypeVoucher       = model.TransactionTypeVoucher
+	TransactionTypeVoucherExpire = model.TransactionTypeVoucherExpire
 )
 
 type Service struct {
-	db   *gorm.DB
-	repo *repo.BillingRepo
+	db              *gorm.DB
+	repo            *repo.BillingRepo
+	fx              *fx.Service
+	defaultCurrency string
 }
 
-func New(db *gorm.DB, repo *repo.BillingRepo) *Service {
-	return &Service{db: db, repo: repo}
+// New 创建计费服务；defaultCurrency 为新建钱包的币种。
+func New(db *gorm.DB, repo *repo.BillingRepo, fxSvc *fx.Service, defaultCurrency string) *Service {
+	currency := fx.NormalizeCurrency(defaultCurrency)
+	if currency == "" {
+		currency = "CNY"
+	}
+	return &Service{db: db, repo: repo, fx: fxSvc, defaultCurrency: currency}
 }
 
 type HoldResult struct {
 	Wallet      *model.Wallet      `json:"wallet"`
 	Transaction *model.Transaction `json:"transaction"`
+	Ref         *model.BillingRef  `json:"ref,omitempty"`
 }
 
 type CaptureResult = HoldResult
@@ -42,19 +68,46 @@ type ReleaseResult = HoldResult
 
 type TopUpResult = HoldResult
 
+type SettleResult struct {
+	Wallet  *model.Wallet      `json:"wallet"`
+	Ref     *model.BillingRef  `json:"ref"`
+	Hold    *model.Transaction `json:"hold"`
+	Capture *model.Transaction `json:"capture"`
+	Release *model.Transaction `json:"release"`
+	// Actual 是换算为钱包币种的实际费用，包含余额不足未扣到的部分。
+	Actual   money.Amount `json:"actual"`
+	Currency string       `json:"currency"`
+}
+
+// BillingEvent 汇总同一 ref_id 下关联的 hold/capture/release 流水及指向该 ref 的退款，Status 取自 BillingRef 状态。
+type BillingEvent struct {
+	RefID        string              `json:"ref_id"`
+	UserID       int64               `json:"user_id"`
+	Status       string              `json:"status"`
+	Currency     string              `json:"currency"`
+	Held         money.Amount        `json:"held"`
+	Captured     money.Amount        `json:"captured"`
+	Released     money.Amount        `json:"released"`
+	Refunded     money.Amount        `json:"refunded"`
+	Transactions []model.Transaction `json:"transactions"`
+}
+
 type WalletListInput struct {
 	UserID   *int64
 	Page     int
 	PageSize int
 }
 
+// TransactionListInput 中 Types 为空表示不按类型筛选；ParentRefID 用于查找某个扣款 ref 的退款。
 type TransactionListInput struct {
-	UserID   *int64
-	Type     string
-	Start    *time.Time
-	End      *time.Time
-	Page     int
-	PageSize int
+	UserID      *int64
+	Types       []string
+	RefID       string
+	ParentRefID string
+	Start       *time.Time
+	End         *time.Time
+	Page        int
+	PageSize    int
 }
 
 func (s *Service) GetWallet(ctx context.Context, userID int64) (*model.Wallet, error) {
@@ -65,35 +118,77 @@ func (s *Service) GetWallet(ctx context.Context, userID int64) (*model.Wallet, e
 	if wallet != nil {
 		return wallet, nil
 	}
-	return s.repo.CreateWallet(ctx, userID)
+	return s.repo.CreateWallet(ctx, userID, s.defaultCurrency)
+}
+
+// SetWalletCurrency 切换钱包币种；钱包必须没有余额和冻结金额。
+func (s *Service) SetWalletCurrency(ctx context.Context, userID int64, currency string) (*model.Wallet, error) {
+	currency = fx.NormalizeCurrency(currency)
+	if currency == "" {
+		return nil, fx.ErrInvalidCurrency
+	}
+	var result *model.Wallet
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
+		wallet, err := s.ensureWallet(ctx, repoTx, userID)
+		if err != nil {
+			return err
+		}
+		if wallet.Currency != currency {
+			if wallet.Balance != 0 || wallet.FrozenBalance != 0 {
+				return ErrWalletNotEmpty
+			}
+			if err := repoTx.UpdateWalletCurrency(ctx, userID, currency); err != nil {
+				return err
+			}
+			wallet.Currency = currency
+		}
+		result = wallet
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
-func (s *Service) Hold(ctx context.Context, userID int64, amount float64, refID string, metadata map[string]any) (*HoldResult, error) {
+// SumInCurrency 把按币种分组的金额换算为 currency 后求和；currency 为空时使用默认币种。
+func (s *Service) SumInCurrency(ctx context.Context, amounts map[string]money.Amount, currency string) (money.Amount, error) {
+	if strings.TrimSpace(currency) == "" {
+		currency = s.defaultCurrency
+	}
+	return s.fx.Sum(ctx, amounts, currency)
+}
+
+// Hold 预扣余额；currency 为空表示与钱包币种相同，否则按当前汇率换算并向上取整。
+func (s *Service) Hold(ctx context.Context, userID int64, amount money.Amount, currency string, refID string, metadata map[string]any) (*HoldResult, error) {
 	if amount <= 0 {
 		return nil, ErrInvalidAmount
 	}
 	return s.withTx(ctx, func(repoTx *repo.BillingRepo) (*HoldResult, error) {
-		if existing, err := s.findExisting(ctx, repoTx, userID, refID, "hold", amount); err != nil {
+		wallet, err := s.ensureWallet(ctx, repoTx, userID)
+		if err != nil {
 			return nil, err
-		} else if existing != nil {
-			wallet, err := s.ensureWallet(ctx, repoTx, userID)
-			if err != nil {
-				return nil, err
-			}
-			return &HoldResult{Wallet: wallet, Transaction: existing}, nil
 		}
-
-		wallet, err := s.ensureWallet(ctx, repoTx, userID)
+		conv, err := s.convert(ctx, wallet, amount, currency, money.RoundUp)
 		if err != nil {
 			return nil, err
 		}
+		if existing, err := s.findExisting(ctx, repoTx, userID, refID, "hold", conv); err != nil {
+			return nil, err
+		} else if existing != nil {
+			return &HoldResult{Wallet: wallet, Transaction: existing}, nil
+		}
 
-		if wallet.Balance < amount {
+		if wallet.SuspendedAt != nil {
+			return nil, ErrWalletSuspended
+		}
+		if Available(wallet) < conv.Amount {
 			return nil, ErrInsufficientBalance
 		}
 
-		wallet.Balance -= amount
-		wallet.FrozenBalance += amount
+		wallet.Balance -= conv.Amount
+		wallet.FrozenBalance += conv.Amount
 		if err := repoTx.UpdateWallet(ctx, userID, wallet.Balance, wallet.FrozenBalance); err != nil {
 			return nil, err
 		}
@@ -102,40 +197,55 @@ func (s *Service) Hold(ctx context.Context, userID int64, amount float64, refID
 		if err != nil {
 			return nil, err
 		}
-		tr, err := repoTx.CreateTransaction(ctx, userID, "hold", amount, refID, meta)
+		tr, err := createTransaction(ctx, repoTx, userID, "hold", refID, conv, meta)
 		if err != nil {
 			return nil, err
 		}
+		ref := &model.BillingRef{
+			UserID:     userID,
+			RefID:      refID,
+			State:      RefStateHeld,
+			HeldAmount: conv.Amount,
+		}
+		if err := repoTx.CreateRef(ctx, ref); err != nil {
+			return nil, err
+		}
 
-		return &HoldResult{Wallet: wallet, Transaction: tr}, nil
+		return &HoldResult{Wallet: wallet, Transaction: tr, Ref: ref}, nil
 	})
 }
 
-func (s *Service) Capture(ctx context.Context, userID int64, amount float64, refID string, metadata map[string]any) (*CaptureResult, error) {
+func (s *Service) Capture(ctx context.Context, userID int64, amount money.Amount, currency string, refID string, metadata map[string]any) (*CaptureResult, error) {
 	if amount <= 0 {
 		return nil, ErrInvalidAmount
 	}
 	return s.withTx(ctx, func(repoTx *repo.BillingRepo) (*CaptureResult, error) {
-		if existing, err := s.findExisting(ctx, repoTx, userID, refID, "capture", amount); err != nil {
+		wallet, err := s.ensureWallet(ctx, repoTx, userID)
+		if err != nil {
+			return nil, err
+		}
+		conv, err := s.convert(ctx, wallet, amount, currency, money.RoundHalfUp)
+		if err != nil {
+			return nil, err
+		}
+		if existing, err := s.findExisting(ctx, repoTx, userID, refID, "capture", conv); err != nil {
 			return nil, err
 		} else if existing != nil {
-			wallet, err := s.ensureWallet(ctx, repoTx, userID)
-			if err != nil {
-				return nil, err
-			}
 			return &HoldResult{Wallet: wallet, Transaction: existing}, nil
 		}
 
-		wallet, err := s.ensureWallet(ctx, repoTx, userID)
+		ref, err := s.lockRef(ctx, repoTx, userID, refID)
 		if err != nil {
 			return nil, err
 		}
-
-		if wallet.FrozenBalance < amount {
+		if conv.Amount > refRemaining(ref) || wallet.FrozenBalance < conv.Amount {
 			return nil, ErrInsufficientFrozen
 		}
+		if err := s.advanceRef(ctx, repoTx, ref, conv.Amount, 0, false); err != nil {
+			return nil, err
+		}
 
-		wallet.FrozenBalance -= amount
+		wallet.FrozenBalance -= conv.Amount
 		if err := repoTx.UpdateWallet(ctx, userID, wallet.Balance, wallet.FrozenBalance); err != nil {
 			return nil, err
 		}
@@ -144,50 +254,68 @@ func (s *Service) Capture(ctx context.Context, userID int64, amount float64, ref
 		if err != nil {
 			return nil, err
 		}
-		tr, err := repoTx.CreateTransaction(ctx, userID, "capture", amount, refID, meta)
+		tr, err := createTransaction(ctx, repoTx, userID, "capture", refID, conv, meta)
 		if err != nil {
 			return nil, err
 		}
 
-		return &HoldResult{Wallet: wallet, Transaction: tr}, nil
+		return &HoldResult{Wallet: wallet, Transaction: tr, Ref: ref}, nil
 	})
 }
 
-func (s *Service) TopUp(ctx context.Context, userID int64, amount float64, currency string, refID string, metadata map[string]any) (*TopUpResult, error) {
-	if amount == 0 || math.IsNaN(amount) || math.IsInf(amount, 0) {
+// TopUp 充值或冲正（amount 为负）；currency 为空表示与钱包币种相同。
+func (s *Service) TopUp(ctx context.Context, userID int64, amount money.Amount, currency string, refID string, metadata map[string]any) (*TopUpResult, error) {
+	if amount == 0 {
+		return nil, ErrInvalidAmount
+	}
+	return s.credit(ctx, "topup", userID, amount, currency, refID, metadata)
+}
+
+// Credit 入账代金券等赠送额度，流水类型为 voucher，与充值区分；同一 ref 重复入账返回已有流水。
+func (s *Service) Credit(ctx context.Context, userID int64, amount money.Amount, currency string, refID string, metadata map[string]any) (*TopUpResult, error) {
+	if amount <= 0 {
 		return nil, ErrInvalidAmount
 	}
+	return s.credit(ctx, TransactionTypeVoucher, userID, amount, currency, refID, metadata)
+}
+
+// WithTx 返回在 tx 内执行的计费服务，供需要与其他表在同一事务内提交的调用方使用。
+func (s *Service) WithTx(tx *gorm.DB) *Service {
+	clone := *s
+	clone.db = tx
+	clone.repo = s.repo.WithTx(tx)
+	return &clone
+}
+
+func (s *Service) credit(ctx context.Context, typ string, userID int64, amount money.Amount, currency string, refID string, metadata map[string]any) (*TopUpResult, error) {
 	return s.withTx(ctx, func(repoTx *repo.BillingRepo) (*HoldResult, error) {
-		if existing, err := s.findExisting(ctx, repoTx, userID, refID, "topup", amount); err != nil {
+		wallet, err := s.ensureWallet(ctx, repoTx, userID)
+		if err != nil {
 			return nil, err
-		} else if existing != nil {
-			wallet, err := s.ensureWallet(ctx, repoTx, userID)
-			if err != nil {
-				return nil, err
-			}
-			return &HoldResult{Wallet: wallet, Transaction: existing}, nil
 		}
-
-		wallet, err := s.ensureWallet(ctx, repoTx, userID)
+		conv, err := s.convert(ctx, wallet, amount, currency, money.RoundHalfUp)
 		if err != nil {
 			return nil, err
 		}
-
-		wallet.Balance += amount
-		if err := repoTx.UpdateWallet(ctx, userID, wallet.Balance, wallet.FrozenBalance); err != nil {
+		if existing, err := s.findExisting(ctx, repoTx, userID, refID, typ, conv); err != nil {
 			return nil, err
+		} else if existing != nil {
+			return &HoldResult{Wallet: wallet, Transaction: existing}, nil
+		}
+		if conv.Amount == 0 {
+			return nil, ErrInvalidAmount
 		}
 
-		if metadata == nil {
-			metadata = map[string]any{}
+		wallet.Balance += conv.Amount
+		if err := repoTx.UpdateWallet(ctx, userID, wallet.Balance, wallet.FrozenBalance); err != nil {
+			return nil, err
 		}
-		metadata["currency"] = normalizeCurrency(currency)
 
 		meta, err := json.Marshal(metadata)
 		if err != nil {
 			return nil, err
 		}
-		tr, err := repoTx.CreateTransaction(ctx, userID, "topup", amount, refID, meta)
+		tr, err := createTransaction(ctx, repoTx, userID, typ, refID, conv, meta)
 		if err != nil {
 			return nil, err
 		}
@@ -196,32 +324,38 @@ func (s *Service) TopUp(ctx context.Context, userID int64, amount float64, curre
 	})
 }
 
-func (s *Service) Release(ctx context.Context, userID int64, amount float64, refID string, metadata map[string]any) (*ReleaseResult, error) {
+func (s *Service) Release(ctx context.Context, userID int64, amount money.Amount, currency string, refID string, metadata map[string]any) (*ReleaseResult, error) {
 	if amount <= 0 {
 		return nil, ErrInvalidAmount
 	}
 	return s.withTx(ctx, func(repoTx *repo.BillingRepo) (*ReleaseResult, error) {
-		if existing, err := s.findExisting(ctx, repoTx, userID, refID, "release", amount); err != nil {
+		wallet, err := s.ensureWallet(ctx, repoTx, userID)
+		if err != nil {
+			return nil, err
+		}
+		conv, err := s.convert(ctx, wallet, amount, currency, money.RoundHalfUp)
+		if err != nil {
+			return nil, err
+		}
+		if existing, err := s.findExisting(ctx, repoTx, userID, refID, "release", conv); err != nil {
 			return nil, err
 		} else if existing != nil {
-			wallet, err := s.ensureWallet(ctx, repoTx, userID)
-			if err != nil {
-				return nil, err
-			}
 			return &HoldResult{Wallet: wallet, Transaction: existing}, nil
 		}
 
-		wallet, err := s.ensureWallet(ctx, repoTx, userID)
+		ref, err := s.lockRef(ctx, repoTx, userID, refID)
 		if err != nil {
 			return nil, err
 		}
-
-		if wallet.FrozenBalance < amount {
+		if conv.Amount > refRemaining(ref) || wallet.FrozenBalance < conv.Amount {
 			return nil, ErrInsufficientFrozen
 		}
+		if err := s.advanceRef(ctx, repoTx, ref, 0, conv.Amount, false); err != nil {
+			return nil, err
+		}
 
-		wallet.FrozenBalance -= amount
-		wallet.Balance += amount
+		wallet.FrozenBalance -= conv.Amount
+		wallet.Balance += conv.Amount
 		if err := repoTx.UpdateWallet(ctx, userID, wallet.Balance, wallet.FrozenBalance); err != nil {
 			return nil, err
 		}
@@ -230,13 +364,270 @@ func (s *Service) Release(ctx context.Context, userID int64, amount float64, ref
 		if err != nil {
 			return nil, err
 		}
-		tr, err := repoTx.CreateTransaction(ctx, userID, "release", amount, refID, meta)
+		tr, err := createTransaction(ctx, repoTx, userID, "release", refID, conv, meta)
 		if err != nil {
 			return nil, err
 		}
 
-		return &HoldResult{Wallet: wallet, Transaction: tr}, nil
+		return &HoldResult{Wallet: wallet, Transaction: tr, Ref: ref}, nil
+	})
+}
+
+// Settle 在同一事务内结算预扣：按实际费用扣款并释放剩余预扣。
+// 实际费用按结算时的汇率换算为钱包币种；超出预扣时，超出部分从可用余额（含信用额度）中扣除，不足的部分记为 uncollected。
+// 同一 ref 重复结算且金额、币种一致时返回已有结果。
+// 预扣已被 Worker 过期回收时，实际费用全部从可用余额中扣除，ref 保持 expired。
+func (s *Service) Settle(ctx context.Context, userID int64, actual money.Amount, currency string, refID string, metadata map[string]any) (*SettleResult, error) {
+	if actual < 0 {
+		return nil, ErrInvalidAmount
+	}
+	if refID == "" {
+		return nil, fmt.Errorf("ref_id is required")
+	}
+
+	var result *SettleResult
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
+		// 先锁钱包，保证同一用户的结算串行执行。
+		wallet, err := s.ensureWallet(ctx, repoTx, userID)
+		if err != nil {
+			return err
+		}
+		conv, err := s.convert(ctx, wallet, actual, currency, money.RoundHalfUp)
+		if err != nil {
+			return err
+		}
+		ref, err := s.lockRef(ctx, repoTx, userID, refID)
+		if err != nil {
+			return err
+		}
+		hold, err := repoTx.GetTransactionByRef(ctx, userID, refID, "hold")
+		if err != nil {
+			return err
+		}
+		if hold == nil {
+			return ErrHoldNotFound
+		}
+		if ref.CapturedAmount > 0 || ref.ReleasedAmount > 0 {
+			capture, err := repoTx.GetTransactionByRef(ctx, userID, refID, "capture")
+			if err != nil {
+				return err
+			}
+			release, err := repoTx.GetTransactionByRef(ctx, userID, refID, "release")
+			if err != nil {
+				return err
+			}
+			// 金额为定点数，重复结算按请求金额与币种精确相等判断。
+			if settled, ok := settledActual(wallet.Currency, capture, release); ok &&
+				settled.Actual == conv.Original && settled.Currency == conv.OriginalCurrency {
+				result = &SettleResult{
+					Wallet:   wallet,
+					Ref:      ref,
+					Hold:     hold,
+					Capture:  capture,
+					Release:  release,
+					Actual:   settled.Charged,
+					Currency: wallet.Currency,
+				}
+				return nil
+			}
+			if ref.State == RefStateExpired && ref.CapturedAmount == 0 && capture == nil {
+				result, err = s.settleExpired(ctx, repoTx, wallet, ref, hold, conv, refID, metadata)
+				return err
+			}
+			if ref.State == RefStateExpired {
+				return ErrInvalidRefTransition
+			}
+			return ErrRefConflict
+		}
+
+		held := ref.HeldAmount
+		if wallet.FrozenBalance < held {
+			return ErrInsufficientFrozen
+		}
+		fromHold := min(conv.Amount, held)
+		released := held - fromHold
+		excess := conv.Amount - fromHold
+		collected := min(excess, max(Available(wallet), 0))
+		uncollected := excess - collected
+		captured := fromHold + collected
+		if err := s.advanceRef(ctx, repoTx, ref, captured, released, false); err != nil {
+			return err
+		}
+
+		wallet.FrozenBalance -= held
+		wallet.Balance += released - collected
+		if err := repoTx.UpdateWallet(ctx, userID, wallet.Balance, wallet.FrozenBalance); err != nil {
+			return err
+		}
+
+		merged := map[string]any{}
+		for key, value := range metadata {
+			merged[key] = value
+		}
+		merged["settled_actual"] = conv.Original
+		merged["settled_currency"] = conv.OriginalCurrency
+		merged["settled_charged"] = conv.Amount
+		merged["held"] = held
+		if uncollected > 0 {
+			merged["uncollected"] = uncollected
+		}
+		meta, err := json.Marshal(merged)
+		if err != nil {
+			return err
+		}
+
+		result = &SettleResult{Wallet: wallet, Ref: ref, Hold: hold, Actual: conv.Amount, Currency: wallet.Currency}
+		if captured > 0 {
+			result.Capture, err = createTransaction(ctx, repoTx, userID, "capture", refID, withAmount(conv, captured), meta)
+			if err != nil {
+				return err
+			}
+		}
+		if released > 0 {
+			result.Release, err = createTransaction(ctx, repoTx, userID, "release", refID, withAmount(conv, released), meta)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// settleExpired 结算已被过期回收的预扣：预扣已退回余额，实际费用从可用余额（含信用额度）中扣除，不足的部分记为 uncollected。
+// 即使一分未扣也写入 capture 流水，重复结算据此识别。
+func (s *Service) settleExpired(ctx context.Context, repoTx *repo.BillingRepo, wallet *model.Wallet, ref *model.BillingRef, hold *model.Transaction, conv fx.Conversion, refID string, metadata map[string]any) (*SettleResult, error) {
+	collected := min(conv.Amount, max(Available(wallet), 0))
+	uncollected := conv.Amount - collected
+	if err := repoTx.UpdateRef(ctx, ref.ID, map[string]any{
+		"captured_amount": ref.CapturedAmount + collected,
+	}); err != nil {
+		return nil, err
+	}
+	ref.CapturedAmount += collected
+
+	wallet.Balance -= collected
+	if err := repoTx.UpdateWallet(ctx, ref.UserID, wallet.Balance, wallet.FrozenBalance); err != nil {
+		return nil, err
+	}
+
+	merged := map[string]any{}
+	for key, value := range metadata {
+		merged[key] = value
+	}
+	merged["settled_actual"] = conv.Original
+	merged["settled_currency"] = conv.OriginalCurrency
+	merged["settled_charged"] = conv.Amount
+	merged["held"] = ref.HeldAmount
+	merged["settled_after_expire"] = true
+	if uncollected > 0 {
+		merged["uncollected"] = uncollected
+	}
+	meta, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	capture, err := createTransaction(ctx, repoTx, ref.UserID, "capture", refID, withAmount(conv, collected), meta)
+	if err != nil {
+		return nil, err
+	}
+	return &SettleResult{Wallet: wallet, Ref: ref, Hold: hold, Capture: capture, Actual: conv.Amount, Currency: wallet.Currency}, nil
+}
+
+// ListBillingEvents 按 ref_id 返回关联流水；userID 为空时按用户分组返回所有匹配的事件。
+func (s *Service) ListBillingEvents(ctx context.Context, refID string, userID *int64) ([]BillingEvent, error) {
+	refID = strings.TrimSpace(refID)
+	if refID == "" {
+		return []BillingEvent{}, nil
+	}
+	items, err := s.repo.ListTransactionsByRef(ctx, refID, userID)
+	if err != nil {
+		return nil, err
+	}
+	events := []BillingEvent{}
+	index := map[int64]int{}
+	for _, item := range items {
+		pos, ok := index[item.UserID]
+		if !ok {
+			pos = len(events)
+			index[item.UserID] = pos
+			events = append(events, BillingEvent{RefID: refID, UserID: item.UserID, Transactions: []model.Transaction{}})
+		}
+		event := &events[pos]
+		event.Transactions = append(event.Transactions, item)
+		if event.Currency == "" {
+			event.Currency = item.Currency
+		}
+		switch item.Type {
+		case "hold":
+			event.Held += item.Amount
+		case "capture":
+			event.Captured += item.Amount
+		case "release", TransactionTypeExpire:
+			event.Released += item.Amount
+		case TransactionTypeRefund:
+			event.Refunded += item.Amount
+		}
+	}
+	refs, err := s.repo.ListRefs(ctx, refID, userID)
+	if err != nil {
+		return nil, err
+	}
+	states := map[int64]string{}
+	for _, ref := range refs {
+		states[ref.UserID] = ref.State
+	}
+	for i := range events {
+		if state, ok := states[events[i].UserID]; ok {
+			events[i].Status = state
+		} else {
+			events[i].Status = eventStatus(&events[i])
+		}
+	}
+	return events, nil
+}
+
+// ReclaimedReport 汇总 Worker 回收的过期预扣。
+type ReclaimedReport struct {
+	Items []model.Transaction `json:"items"`
+	Total int64               `json:"total"`
+	// Totals 按钱包币种汇总回收金额。
+	Totals   map[string]money.Amount `json:"totals"`
+	Page     int                     `json:"page"`
+	PageSize int                     `json:"page_size"`
+}
+
+// ListReclaimed 列出过期回收流水及回收总额，input.Types 会被忽略。
+func (s *Service) ListReclaimed(ctx context.Context, input TransactionListInput) (*ReclaimedReport, error) {
+	page, pageSize := normalizePage(input.Page, input.PageSize)
+	filter := repo.TransactionListFilter{
+		UserID: input.UserID,
+		Types:  []string{TransactionTypeExpire},
+		RefID:  input.RefID,
+		Start:  input.Start,
+		End:    input.End,
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	}
+	items, total, err := s.repo.ListTransactions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.repo.SumTransactionsByCurrency(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &ReclaimedReport{
+		Items:    items,
+		Total:    total,
+		Totals:   totals,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
 }
 
 func (s *Service) ListWallets(ctx context.Context, input WalletListInput) ([]repo.WalletWithUser, int64, error) {
@@ -251,12 +642,14 @@ func (s *Service) ListWallets(ctx context.Context, input WalletListInput) ([]rep
 func (s *Service) ListTransactions(ctx context.Context, input TransactionListInput) ([]model.Transaction, int64, error) {
 	page, pageSize := normalizePage(input.Page, input.PageSize)
 	return s.repo.ListTransactions(ctx, repo.TransactionListFilter{
-		UserID: input.UserID,
-		Type:   input.Type,
-		Start:  input.Start,
-		End:    input.End,
-		Limit:  pageSize,
-		Offset: (page - 1) * pageSize,
+		UserID:      input.UserID,
+		Types:       input.Types,
+		RefID:       input.RefID,
+		ParentRefID: input.ParentRefID,
+		Start:       input.Start,
+		End:         input.End,
+		Limit:       pageSize,
+		Offset:      (page - 1) * pageSize,
 	})
 }
 
@@ -283,7 +676,7 @@ func (s *Service) ensureWallet(ctx context.Context, repoTx *repo.BillingRepo, us
 		return nil, err
 	}
 	if wallet == nil {
-		wallet, err = repoTx.CreateWallet(ctx, userID)
+		wallet, err = repoTx.CreateWallet(ctx, userID, s.defaultCurrency)
 		if err != nil {
 			return nil, err
 		}
@@ -291,35 +684,108 @@ func (s *Service) ensureWallet(ctx context.Context, repoTx *repo.BillingRepo, us
 	return wallet, nil
 }
 
-func (s *Service) findExisting(ctx context.Context, repoTx *repo.BillingRepo, userID int64, refID, typ string, amount float64) (*model.Transaction, error) {
+func (s *Service) findExisting(ctx context.Context, repoTx *repo.BillingRepo, userID int64, refID, typ string, conv fx.Conversion) (*model.Transaction, error) {
 	if refID == "" {
 		return nil, fmt.Errorf("ref_id is required")
 	}
-	existing, err := repoTx.GetTransactionByRef(ctx, refID)
+	existing, err := repoTx.GetTransactionByRef(ctx, userID, refID, typ)
 	if err != nil {
 		return nil, err
 	}
 	if existing == nil {
 		return nil, nil
 	}
-	if existing.UserID != userID {
+	// 定点金额精确比较请求金额与币种，避免汇率变动或浮点误差把同一请求的重放误判为冲突。
+	// 多币种之前的流水没有 OriginalCurrency，按钱包币种金额比较。
+	if existing.OriginalCurrency != "" {
+		if existing.OriginalAmount != conv.Original || existing.OriginalCurrency != conv.OriginalCurrency {
+			return nil, ErrRefConflict
+		}
+	} else if existing.Amount != conv.Original || conv.OriginalCurrency != conv.Currency {
 		return nil, ErrRefConflict
 	}
-	if existing.Type == typ {
-		if existing.Amount != amount {
-			return nil, ErrRefConflict
+	return existing, nil
+}
+
+// convert 把请求金额换算为钱包币种；currency 为空表示与钱包币种相同。
+func (s *Service) convert(ctx context.Context, wallet *model.Wallet, amount money.Amount, currency string, mode money.Rounding) (fx.Conversion, error) {
+	walletCurrency := wallet.Currency
+	if walletCurrency == "" {
+		walletCurrency = s.defaultCurrency
+	}
+	if strings.TrimSpace(currency) == "" {
+		currency = walletCurrency
+	}
+	return s.fx.Convert(ctx, amount, currency, walletCurrency, mode)
+}
+
+func createTransaction(ctx context.Context, repoTx *repo.BillingRepo, userID int64, typ, refID string, conv fx.Conversion, meta []byte) (*model.Transaction, error) {
+	return repoTx.CreateTransaction(ctx, &model.Transaction{
+		UserID:           userID,
+		Type:             typ,
+		Amount:           conv.Amount,
+		Currency:         conv.Currency,
+		OriginalAmount:   conv.Original,
+		OriginalCurrency: conv.OriginalCurrency,
+		FXRate:           conv.Rate,
+		RefID:            refID,
+		Metadata:         meta,
+	})
+}
+
+// withAmount 用于 Settle 拆分出的 capture/release：保留请求金额与汇率，替换记账金额。
+func withAmount(conv fx.Conversion, amount money.Amount) fx.Conversion {
+	conv.Amount = amount
+	return conv
+}
+
+type settleRecord struct {
+	Actual   money.Amount
+	Currency string
+	Charged  money.Amount
+}
+
+// settledActual 读取 Settle 写入的请求金额、币种与换算后金额，用于判断重复结算是否一致。
+// 多币种之前写入的记录没有币种，视为钱包币种。
+func settledActual(walletCurrency string, items ...*model.Transaction) (settleRecord, bool) {
+	for _, item := range items {
+		if item == nil || len(item.Metadata) == 0 {
+			continue
+		}
+		var meta struct {
+			SettledActual   *money.Amount `json:"settled_actual"`
+			SettledCurrency string        `json:"settled_currency"`
+			SettledCharged  *money.Amount `json:"settled_charged"`
+		}
+		if err := json.Unmarshal(item.Metadata, &meta); err != nil || meta.SettledActual == nil {
+			continue
+		}
+		record := settleRecord{Actual: *meta.SettledActual, Currency: meta.SettledCurrency, Charged: *meta.SettledActual}
+		if record.Currency == "" {
+			record.Currency = walletCurrency
+		}
+		if meta.SettledCharged != nil {
+			record.Charged = *meta.SettledCharged
 		}
-		return existing, nil
+		return record, true
 	}
-	return nil, ErrRefConflict
+	return settleRecord{}, false
 }
 
-func normalizeCurrency(value string) string {
-	value = strings.TrimSpace(value)
-	if value == "" {
-		return "CNY"
+// eventStatus 为没有 BillingRef 记录的流水（如充值）推导状态。
+func eventStatus(event *BillingEvent) string {
+	switch {
+	case event.Held == 0 && len(event.Transactions) > 0:
+		return event.Transactions[0].Type
+	case event.Captured == 0 && event.Released == 0:
+		return RefStateHeld
+	case event.Released == 0:
+		return RefStateCaptured
+	case event.Captured == 0:
+		return RefStateReleased
+	default:
+		return RefStatePartiallyCaptured
 	}
-	return strings.ToUpper(value)
 }
 
 func normalizePage(page, pageSize int) (int, int) {
@@ -36,6 +36,14 @@ var (
 const (
 	EmailTypeWelcome       = "welcome"
 	EmailTypeResetPassword = "reset_password"
+	EmailTypeInvoiceIssued = "invoice_issued"
+	EmailTypeCreditWarning = "credit_warning"
+	EmailTypeSuspended     = "account_suspended"
+	// EmailTypeRenewalFailed 在套餐自动续费失败、订阅失效时发送。
+	EmailTypeRenewalFailed = "subscription_renewal_failed"
+	// EmailTypeSubscriptionExpiring 在不续费的订阅到期前提醒，EmailTypeSubscriptionExpired 在订阅失效时发送。
+	EmailTypeSubscriptionExpiring = "subscription_expiring"
+	EmailTypeSubscriptionExpired  = "subscription_expired"
 )
 
 type Service struct {
@@ -139,7 +147,8 @@ func (s *Service) Send(ctx context.Context, input EmailInput) error {
 
 func isValidEmailType(value string) bool {
 	switch strings.TrimSpace(value) {
-	case EmailTypeWelcome, EmailTypeResetPassword:
+	case EmailTypeWelcome, EmailTypeResetPassword, EmailTypeInvoiceIssued, EmailTypeCreditWarning, EmailTypeSuspended, EmailTypeRenewalFailed,
+		EmailTypeSubscriptionExpiring, EmailTypeSubscriptionExpired:
 		return true
 	default:
 		return false
@@ -152,6 +161,18 @@ func templateNameForType(value string) string {
 		return "welcome.html"
 	case EmailTypeResetPassword:
 		return "reset-password.html"
+	case EmailTypeInvoiceIssued:
+		return "invoice-issued.html"
+	case EmailTypeCreditWarning:
+		return "credit-warning.html"
+	case EmailTypeSuspended:
+		return "account-suspended.html"
+	case EmailTypeRenewalFailed:
+		return "subscription-renewal-failed.html"
+	case EmailTypeSubscriptionExpiring:
+		return "subscription-expiring.html"
+	case EmailTypeSubscriptionExpired:
+		return "subscription-expired.html"
 	default:
 		return ""
 	}
@@ -0,0 +1,160 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"deepspace-shared/model"
+	"deepspace-shared/money"
+	"deepspace/internal/repo"
+)
+
+var (
+	ErrInvalidCurrency = errors.New("invalid currency")
+	ErrInvalidRate     = errors.New("invalid fx rate")
+	ErrRateNotFound    = errors.New("fx rate not found")
+)
+
+type Service struct {
+	repo *repo.FXRateRepo
+}
+
+func New(repo *repo.FXRateRepo) *Service {
+	return &Service{repo: repo}
+}
+
+// Conversion 记录一次换算：Original 以 OriginalCurrency 计，Amount 以 Currency 计。
+type Conversion struct {
+	Amount           money.Amount `json:"amount"`
+	Currency         string       `json:"currency"`
+	Original         money.Amount `json:"original_amount"`
+	OriginalCurrency string       `json:"original_currency"`
+	Rate             money.Rate   `json:"rate"`
+}
+
+type RateInput struct {
+	BaseCurrency  string
+	QuoteCurrency string
+	Rate          money.Rate
+}
+
+type ListInput struct {
+	Currency string
+	Page     int
+	PageSize int
+}
+
+// Rate 返回 1 单位 from 折合多少 to：同币种为 1，优先使用直接汇率，其次使用反向汇率的倒数。
+func (s *Service) Rate(ctx context.Context, from, to string) (money.Rate, error) {
+	from = NormalizeCurrency(from)
+	to = NormalizeCurrency(to)
+	if from == "" || to == "" {
+		return 0, ErrInvalidCurrency
+	}
+	if from == to {
+		return money.OneRate, nil
+	}
+	if s == nil || s.repo == nil {
+		return 0, ErrRateNotFound
+	}
+	item, err := s.repo.GetPair(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+	if item != nil && item.Rate > 0 {
+		return item.Rate, nil
+	}
+	item, err = s.repo.GetPair(ctx, to, from)
+	if err != nil {
+		return 0, err
+	}
+	if item != nil && item.Rate > 0 {
+		return item.Rate.Inverse(), nil
+	}
+	return 0, ErrRateNotFound
+}
+
+// Convert 将 amount 从 from 换算为 to，按 mode 取整到钱包精度。
+func (s *Service) Convert(ctx context.Context, amount money.Amount, from, to string, mode money.Rounding) (Conversion, error) {
+	rate, err := s.Rate(ctx, from, to)
+	if err != nil {
+		return Conversion{}, err
+	}
+	return Conversion{
+		Amount:           amount.Convert(rate, mode),
+		Currency:         NormalizeCurrency(to),
+		Original:         amount,
+		OriginalCurrency: NormalizeCurrency(from),
+		Rate:             rate,
+	}, nil
+}
+
+// Sum 将按币种分组的金额逐项换算为 to 后求和，每项四舍五入。
+func (s *Service) Sum(ctx context.Context, amounts map[string]money.Amount, to string) (money.Amount, error) {
+	var total money.Amount
+	for currency, amount := range amounts {
+		if amount == 0 {
+			continue
+		}
+		converted, err := s.Convert(ctx, amount, currency, to, money.RoundHalfUp)
+		if err != nil {
+			return 0, err
+		}
+		total += converted.Amount
+	}
+	return total, nil
+}
+
+func (s *Service) UpsertRate(ctx context.Context, input RateInput) (*model.FXRate, error) {
+	base := NormalizeCurrency(input.BaseCurrency)
+	quote := NormalizeCurrency(input.QuoteCurrency)
+	if base == "" || quote == "" || base == quote {
+		return nil, ErrInvalidCurrency
+	}
+	if input.Rate <= 0 {
+		return nil, ErrInvalidRate
+	}
+	item := &model.FXRate{BaseCurrency: base, QuoteCurrency: quote, Rate: input.Rate}
+	if err := s.repo.Upsert(ctx, item); err != nil {
+		return nil, err
+	}
+	return s.repo.GetPair(ctx, base, quote)
+}
+
+func (s *Service) DeleteRate(ctx context.Context, id int64) (bool, error) {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *Service) ListRates(ctx context.Context, input ListInput) ([]model.FXRate, int64, error) {
+	page := input.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := input.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	return s.repo.List(ctx, repo.FXRateFilter{
+		Currency: NormalizeCurrency(input.Currency),
+		Limit:    pageSize,
+		Offset:   (page - 1) * pageSize,
+	})
+}
+
+// NormalizeCurrency 返回大写的三位字母币种代码，不合法时返回空串。
+func NormalizeCurrency(value string) string {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if len(value) != 3 {
+		return ""
+	}
+	for _, r := range value {
+		if r < 'A' || r > 'Z' {
+			return ""
+		}
+	}
+	return value
+}
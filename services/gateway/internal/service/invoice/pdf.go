@@ -0,0 +1,132 @@
+package invoice
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"deepspace/internal/pkg/pdf"
+)
+
+// PDF 版式参数（点）。
+const (
+	pdfLeft     = 50.0
+	pdfRight    = pdf.PageWidth - 50
+	pdfBottom   = pdf.PageHeight - 60
+	pdfRowStep  = 18.0
+	pdfBodySize = 10.0
+)
+
+// 明细表各列位置：模型、项目左对齐，其余右对齐。
+const (
+	colModel    = pdfLeft
+	colProject  = 210.0
+	colRequests = 390.0
+	colTokens   = 470.0
+	colAmount   = pdfRight
+)
+
+func renderPDF(detail *Detail) []byte {
+	item := detail.Invoice
+	doc := pdf.New()
+	doc.AddPage()
+
+	doc.Text(pdfLeft, 50, 18, "DeepSpace 月度账单")
+	y := 86.0
+	for _, row := range [][2]string{
+		{"账单编号", item.Number},
+		{"账期", fmt.Sprintf("%s 至 %s", item.PeriodStart.Format(time.DateOnly), lastDay(item).Format(time.DateOnly))},
+		{"账单邮箱", item.Email},
+		{"出具时间", item.IssuedAt.UTC().Format(time.DateTime) + " UTC"},
+		{"币种", item.Currency},
+	} {
+		doc.Text(pdfLeft, y, pdfBodySize, row[0]+"："+row[1])
+		y += 16
+	}
+
+	y += 12
+	doc.Text(pdfLeft, y, 13, "账户汇总")
+	y += 22
+	doc.Line(pdfLeft, y-4, pdfRight, y-4, 0.5)
+	for _, row := range [][2]string{
+		{"期初余额", item.OpeningBalance.String()},
+		{"充值及赠送", item.Credited.String()},
+		{"用量扣费", item.Charged.String()},
+		{"退款", item.Refunded.String()},
+		{"其他扣减", item.Debited.String()},
+		{"期末余额", item.ClosingBalance.String()},
+	} {
+		doc.Text(pdfLeft, y, pdfBodySize, row[0])
+		doc.TextRight(pdfRight, y, pdfBodySize, row[1])
+		y += pdfRowStep
+	}
+	doc.Line(pdfLeft, y-4, pdfRight, y-4, 0.5)
+	doc.Text(pdfLeft, y, 12, "本期应付")
+	doc.TextRight(pdfRight, y, 12, item.Total.String()+" "+item.Currency)
+	y += pdfRowStep + 4
+	if item.AmountDue > 0 {
+		doc.Text(pdfLeft, y, 12, "待结清欠款")
+		doc.TextRight(pdfRight, y, 12, item.AmountDue.String()+" "+item.Currency)
+		y += pdfRowStep + 4
+	}
+	y += 14
+
+	doc.Text(pdfLeft, y, 13, "用量明细")
+	y += 22
+	y = pdfTableHeader(doc, y)
+	if len(detail.Lines) == 0 {
+		doc.Text(pdfLeft, y, pdfBodySize, "本账期没有用量记录。")
+		y += pdfRowStep
+	}
+	for _, line := range detail.Lines {
+		if y > pdfBottom {
+			doc.AddPage()
+			y = pdfTableHeader(doc, 50)
+		}
+		doc.Text(colModel, y, pdfBodySize, truncate(line.Model, colProject-colModel-10))
+		doc.Text(colProject, y, pdfBodySize, truncate(projectLabel(line), colRequests-colProject-60))
+		doc.TextRight(colRequests, y, pdfBodySize, strconv.FormatInt(line.Requests, 10))
+		doc.TextRight(colTokens, y, pdfBodySize, strconv.FormatInt(line.TotalTokens, 10))
+		doc.TextRight(colAmount, y, pdfBodySize, line.Amount.String()+" "+line.Currency)
+		y += pdfRowStep
+	}
+	doc.Line(pdfLeft, y-4, pdfRight, y-4, 0.5)
+	doc.Text(pdfLeft, y, pdfBodySize, "合计")
+	doc.TextRight(colRequests, y, pdfBodySize, strconv.FormatInt(item.Requests, 10))
+	doc.TextRight(colTokens, y, pdfBodySize, strconv.FormatInt(item.TotalTokens, 10))
+	doc.TextRight(colAmount, y, pdfBodySize, item.UsageAmount.String()+" "+item.Currency)
+
+	// 合计行已占用页脚位置时，页脚放到新的一页。
+	if y+pdfRowStep > pdf.PageHeight-40 {
+		doc.AddPage()
+	}
+	doc.Text(pdfLeft, pdf.PageHeight-40, 8, "本账单由系统自动生成，金额以钱包流水为准。")
+	return doc.Bytes()
+}
+
+func pdfTableHeader(doc *pdf.Document, y float64) float64 {
+	doc.Text(colModel, y, pdfBodySize, "模型")
+	doc.Text(colProject, y, pdfBodySize, "项目")
+	doc.TextRight(colRequests, y, pdfBodySize, "请求数")
+	doc.TextRight(colTokens, y, pdfBodySize, "Tokens")
+	doc.TextRight(colAmount, y, pdfBodySize, "金额")
+	y += pdfRowStep
+	doc.Line(pdfLeft, y-4, pdfRight, y-4, 0.5)
+	return y
+}
+
+// truncate 把文本截断到 width 以内，超出部分以 "..." 结尾。
+func truncate(s string, width float64) string {
+	if pdf.TextWidth(s, pdfBodySize) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := string(runes) + "..."
+		if pdf.TextWidth(candidate, pdfBodySize) <= width {
+			return candidate
+		}
+	}
+	return ""
+}
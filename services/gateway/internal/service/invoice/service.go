@@ -0,0 +1,179 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"deepspace-shared/model"
+	"deepspace/internal/repo"
+	"deepspace/internal/service/email"
+)
+
+var (
+	ErrInvoiceNotFound = errors.New("invoice not found")
+	ErrInvalidFormat   = errors.New("invalid invoice format")
+)
+
+// 账单由 Worker 生成，Gateway 只负责查询与下载。
+const (
+	StatusIssued = model.InvoiceStatusIssued
+
+	FormatHTML = "html"
+	FormatPDF  = "pdf"
+
+	// templateName 位于模板目录的 invoice/invoice.html，与邮件模板一同加载。
+	templateName = "invoice.html"
+)
+
+type Service struct {
+	repo  *repo.InvoiceRepo
+	email *email.Service
+}
+
+// New 的 emailSvc 用于按模板目录渲染 HTML 账单，为 nil 时只能下载 PDF。
+func New(repo *repo.InvoiceRepo, emailSvc *email.Service) *Service {
+	return &Service{repo: repo, email: emailSvc}
+}
+
+type ListInput struct {
+	UserID   *int64
+	Status   string
+	Start    *time.Time
+	End      *time.Time
+	Page     int
+	PageSize int
+}
+
+type Detail struct {
+	Invoice *model.Invoice      `json:"invoice"`
+	Lines   []model.InvoiceLine `json:"lines"`
+}
+
+// Document 是渲染后的可下载账单。
+type Document struct {
+	Filename    string
+	ContentType string
+	Body        []byte
+}
+
+func (s *Service) List(ctx context.Context, input ListInput) ([]model.Invoice, int64, error) {
+	page := input.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := input.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	return s.repo.List(ctx, repo.InvoiceFilter{
+		UserID: input.UserID,
+		Status: strings.ToLower(strings.TrimSpace(input.Status)),
+		Start:  input.Start,
+		End:    input.End,
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	})
+}
+
+// Get 返回账单与明细；userID 不为 nil 时其他用户的账单视为不存在。
+func (s *Service) Get(ctx context.Context, id int64, userID *int64) (*Detail, error) {
+	item, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil || (userID != nil && item.UserID != *userID) {
+		return nil, ErrInvoiceNotFound
+	}
+	lines, err := s.repo.ListLines(ctx, item.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &Detail{Invoice: item, Lines: lines}, nil
+}
+
+// Render 按 format 渲染账单，format 为空时输出 PDF。
+func (s *Service) Render(detail *Detail, format string) (*Document, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case FormatHTML:
+		body, err := s.email.RenderTemplate(templateName, templateData(detail))
+		if err != nil {
+			return nil, err
+		}
+		return &Document{
+			Filename:    detail.Invoice.Number + ".html",
+			ContentType: "text/html; charset=utf-8",
+			Body:        []byte(body),
+		}, nil
+	case "", FormatPDF:
+		return &Document{
+			Filename:    detail.Invoice.Number + ".pdf",
+			ContentType: "application/pdf",
+			Body:        renderPDF(detail),
+		}, nil
+	default:
+		return nil, ErrInvalidFormat
+	}
+}
+
+func templateData(detail *Detail) map[string]any {
+	item := detail.Invoice
+	lines := make([]map[string]any, 0, len(detail.Lines))
+	for _, line := range detail.Lines {
+		lines = append(lines, map[string]any{
+			"model":             line.Model,
+			"project":           projectLabel(line),
+			"requests":          line.Requests,
+			"prompt_tokens":     line.PromptTokens,
+			"completion_tokens": line.CompletionTokens,
+			"total_tokens":      line.TotalTokens,
+			"amount":            line.Amount.String(),
+			"currency":          line.Currency,
+		})
+	}
+	return map[string]any{
+		"number":          item.Number,
+		"email":           item.Email,
+		"period":          periodLabel(item),
+		"period_start":    item.PeriodStart.Format(time.DateOnly),
+		"period_end":      lastDay(item).Format(time.DateOnly),
+		"issued_at":       item.IssuedAt.Format(time.DateTime),
+		"currency":        item.Currency,
+		"requests":        item.Requests,
+		"total_tokens":    item.TotalTokens,
+		"usage_amount":    item.UsageAmount.String(),
+		"charged":         item.Charged.String(),
+		"refunded":        item.Refunded.String(),
+		"credited":        item.Credited.String(),
+		"debited":         item.Debited.String(),
+		"total":           item.Total.String(),
+		"opening_balance": item.OpeningBalance.String(),
+		"closing_balance": item.ClosingBalance.String(),
+		"amount_due":      item.AmountDue.String(),
+		"has_amount_due":  item.AmountDue > 0,
+		"lines":           lines,
+	}
+}
+
+func projectLabel(line model.InvoiceLine) string {
+	if line.ProjectID == nil {
+		return "未关联项目"
+	}
+	if line.ProjectName != "" {
+		return line.ProjectName
+	}
+	return "已删除项目"
+}
+
+func periodLabel(item *model.Invoice) string {
+	return item.PeriodStart.Format("2006年01月")
+}
+
+// lastDay 返回账期最后一天；PeriodEnd 为下一账期起点，不含在本账期内。
+func lastDay(item *model.Invoice) time.Time {
+	return item.PeriodEnd.AddDate(0, 0, -1)
+}
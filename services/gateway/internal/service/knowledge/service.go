@@ -10,7 +10,7 @@ import (
 	"strings"
 	"time"
 
-	"deepspace/internal/model"
+	"deepspace-shared/model"
 	"deepspace/internal/repo"
 
 	"github.com/google/uuid"
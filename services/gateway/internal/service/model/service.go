@@ -6,7 +6,8 @@ import (
 	"errors"
 	"strings"
 
-	"deepspace/internal/model"
+	"deepspace-shared/model"
+	"deepspace-shared/money"
 	"deepspace/internal/repo"
 
 	"github.com/google/uuid"
@@ -54,8 +55,8 @@ func New(repo *repo.ModelRepo) *Service {
 type CreateInput struct {
 	Name         string
 	Provider     string
-	PriceInput   float64
-	PriceOutput  float64
+	PriceInput   money.Amount
+	PriceOutput  money.Amount
 	Currency     string
 	Capabilities []string
 	Status       string
@@ -64,8 +65,8 @@ type CreateInput struct {
 
 type UpdateInput struct {
 	Provider     *string
-	PriceInput   *float64
-	PriceOutput  *float64
+	PriceInput   *money.Amount
+	PriceOutput  *money.Amount
 	Currency     *string
 	Capabilities *[]string
 	Status       *string
@@ -77,8 +78,8 @@ type ModelItem struct {
 	ID           string         `json:"id"`
 	Name         string         `json:"name"`
 	Provider     string         `json:"provider"`
-	PriceInput   float64        `json:"price_input"`
-	PriceOutput  float64        `json:"price_output"`
+	PriceInput   money.Amount   `json:"price_input"`
+	PriceOutput  money.Amount   `json:"price_output"`
 	Currency     string         `json:"currency"`
 	Capabilities []string       `json:"capabilities"`
 	Status       string         `json:"status"`
@@ -100,8 +101,8 @@ type ConfirmResult struct {
 
 type BatchPricingItem struct {
 	ID           string
-	PriceInput   *float64
-	PriceOutput  *float64
+	PriceInput   *money.Amount
+	PriceOutput  *money.Amount
 	Currency     *string
 	Status       *string
 	Capabilities *[]string
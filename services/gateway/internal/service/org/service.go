@@ -0,0 +1,386 @@
+package org
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"strings"
+
+	"deepspace-shared/model"
+	"deepspace/internal/repo"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOrgNotFound     = errors.New("organization not found")
+	ErrNotMember       = errors.New("not a member of organization")
+	ErrForbidden       = errors.New("organization permission denied")
+	ErrInvalidName     = errors.New("invalid organization name")
+	ErrInvalidEmail    = errors.New("invalid billing email")
+	ErrInvalidRole     = errors.New("invalid member role")
+	ErrUserNotFound    = errors.New("user not found")
+	ErrMemberNotFound  = errors.New("member not found")
+	ErrAlreadyMember   = errors.New("user is already a member")
+	ErrLastOwner       = errors.New("organization must keep at least one owner")
+	ErrPersonalOrg     = errors.New("personal organization members cannot be changed")
+	ErrUserUnavailable = errors.New("user unavailable")
+)
+
+// 成员角色：owner 拥有全部权限；admin 管理成员与资源；member 使用组织资源与钱包；
+// billing 只管理充值、账单与订阅，不能修改项目与知识库。
+const (
+	RoleOwner   = "owner"
+	RoleAdmin   = "admin"
+	RoleMember  = "member"
+	RoleBilling = "billing"
+)
+
+const maxNameLength = 100
+
+type Service struct {
+	db    *gorm.DB
+	repo  *repo.OrgRepo
+	users *repo.UserRepo
+}
+
+func New(db *gorm.DB, repo *repo.OrgRepo, users *repo.UserRepo) *Service {
+	return &Service{db: db, repo: repo, users: users}
+}
+
+// Current 是一次请求所使用的组织及请求者在其中的角色。
+type Current struct {
+	Org  *model.Organization
+	Role string
+}
+
+type UpdateInput struct {
+	Name         *string
+	BillingEmail *string
+}
+
+type ListInput struct {
+	Search   string
+	Personal *bool
+	Page     int
+	PageSize int
+}
+
+func ValidRole(role string) bool {
+	switch role {
+	case RoleOwner, RoleAdmin, RoleMember, RoleBilling:
+		return true
+	default:
+		return false
+	}
+}
+
+func canManageMembers(role string) bool {
+	return role == RoleOwner || role == RoleAdmin
+}
+
+// Resolve 返回用户在 orgID 中的身份；orgID 为 0 时使用用户的个人组织，不存在时自动创建。
+func (s *Service) Resolve(ctx context.Context, userID, orgID int64) (*Current, error) {
+	if orgID == 0 {
+		item, err := s.repo.EnsurePersonal(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			return nil, ErrUserUnavailable
+		}
+		return &Current{Org: item, Role: RoleOwner}, nil
+	}
+
+	item, err := s.repo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrOrgNotFound
+	}
+	membership, err := s.repo.GetMembership(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if membership == nil {
+		return nil, ErrNotMember
+	}
+	return &Current{Org: item, Role: membership.Role}, nil
+}
+
+func (s *Service) ListForUser(ctx context.Context, userID int64) ([]repo.OrgWithRole, error) {
+	if _, err := s.repo.EnsurePersonal(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListForUser(ctx, userID)
+}
+
+// Create 创建团队组织，创建者成为 owner。
+func (s *Service) Create(ctx context.Context, userID int64, name string) (*model.Organization, error) {
+	name, err := normalizeName(name)
+	if err != nil {
+		return nil, err
+	}
+	item := &model.Organization{Name: name, OwnerID: userID}
+	if err := s.repo.Create(ctx, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *Service) Get(ctx context.Context, userID, orgID int64) (*Current, error) {
+	return s.Resolve(ctx, userID, orgID)
+}
+
+func (s *Service) Update(ctx context.Context, userID, orgID int64, input UpdateInput) (*model.Organization, error) {
+	current, err := s.Resolve(ctx, userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManageMembers(current.Role) {
+		return nil, ErrForbidden
+	}
+
+	updates := map[string]any{}
+	if input.Name != nil {
+		name, err := normalizeName(*input.Name)
+		if err != nil {
+			return nil, err
+		}
+		updates["name"] = name
+	}
+	if input.BillingEmail != nil {
+		address := strings.TrimSpace(*input.BillingEmail)
+		if address != "" {
+			if _, err := mail.ParseAddress(address); err != nil {
+				return nil, ErrInvalidEmail
+			}
+		}
+		updates["billing_email"] = address
+	}
+	return s.repo.Update(ctx, orgID, updates)
+}
+
+func (s *Service) ListMembers(ctx context.Context, userID, orgID int64) ([]repo.MemberWithUser, error) {
+	if _, err := s.Resolve(ctx, userID, orgID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListMembers(ctx, orgID)
+}
+
+// AddMember 按邮箱添加已注册用户。只有 owner 可以添加 owner。
+func (s *Service) AddMember(ctx context.Context, actorID, orgID int64, email, role string) (*model.Membership, error) {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if role == "" {
+		role = RoleMember
+	}
+	if !ValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil, ErrUserNotFound
+	}
+
+	var result *model.Membership
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
+		actorRole, err := s.lockForChange(ctx, repoTx, actorID, orgID)
+		if err != nil {
+			return err
+		}
+		if role == RoleOwner && actorRole != RoleOwner {
+			return ErrForbidden
+		}
+		user, err := s.users.GetByEmail(ctx, email)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return ErrUserNotFound
+		}
+		existing, err := repoTx.GetMembership(ctx, orgID, user.ID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return ErrAlreadyMember
+		}
+		item := &model.Membership{OrgID: orgID, UserID: user.ID, Role: role}
+		if err := repoTx.CreateMembership(ctx, item); err != nil {
+			return err
+		}
+		result = item
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UpdateMember 修改成员角色。只有 owner 可以授予或变更 owner 角色，且组织至少保留一名 owner。
+func (s *Service) UpdateMember(ctx context.Context, actorID, orgID, userID int64, role string) error {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if !ValidRole(role) {
+		return ErrInvalidRole
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
+		actorRole, err := s.lockForChange(ctx, repoTx, actorID, orgID)
+		if err != nil {
+			return err
+		}
+		target, err := repoTx.GetMembership(ctx, orgID, userID)
+		if err != nil {
+			return err
+		}
+		if target == nil {
+			return ErrMemberNotFound
+		}
+		if (role == RoleOwner || target.Role == RoleOwner) && actorRole != RoleOwner {
+			return ErrForbidden
+		}
+		if target.Role == RoleOwner && role != RoleOwner {
+			if err := ensureOtherOwner(ctx, repoTx, orgID); err != nil {
+				return err
+			}
+		}
+		return repoTx.UpdateMembershipRole(ctx, orgID, userID, role)
+	})
+}
+
+// RemoveMember 移除成员；成员也可以移除自己以退出组织。admin 不能移除 owner。
+func (s *Service) RemoveMember(ctx context.Context, actorID, orgID, userID int64) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
+		item, err := repoTx.GetByIDForUpdate(ctx, orgID)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return ErrOrgNotFound
+		}
+		actor, err := repoTx.GetMembership(ctx, orgID, actorID)
+		if err != nil {
+			return err
+		}
+		if actor == nil {
+			return ErrNotMember
+		}
+		if item.Personal {
+			return ErrPersonalOrg
+		}
+		target, err := repoTx.GetMembership(ctx, orgID, userID)
+		if err != nil {
+			return err
+		}
+		if target == nil {
+			return ErrMemberNotFound
+		}
+		if actorID != userID {
+			if !canManageMembers(actor.Role) || (target.Role == RoleOwner && actor.Role != RoleOwner) {
+				return ErrForbidden
+			}
+		}
+		if target.Role == RoleOwner {
+			if err := ensureOtherOwner(ctx, repoTx, orgID); err != nil {
+				return err
+			}
+		}
+		return repoTx.DeleteMembership(ctx, orgID, userID)
+	})
+}
+
+func (s *Service) List(ctx context.Context, input ListInput) ([]model.Organization, int64, error) {
+	page := input.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := input.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	return s.repo.List(ctx, repo.OrgFilter{
+		Search:   strings.TrimSpace(input.Search),
+		Personal: input.Personal,
+		Limit:    pageSize,
+		Offset:   (page - 1) * pageSize,
+	})
+}
+
+// AdminGet 返回组织及其成员，不校验成员身份。
+func (s *Service) AdminGet(ctx context.Context, orgID int64) (*model.Organization, []repo.MemberWithUser, error) {
+	item, err := s.repo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if item == nil {
+		return nil, nil, ErrOrgNotFound
+	}
+	members, err := s.repo.ListMembers(ctx, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return item, members, nil
+}
+
+// AdminGetPersonal 返回用户的个人组织，不校验成员身份。
+func (s *Service) AdminGetPersonal(ctx context.Context, userID int64) (*model.Organization, error) {
+	item, err := s.repo.GetPersonal(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrOrgNotFound
+	}
+	return item, nil
+}
+
+// lockForChange 锁定组织并确认请求者可以管理成员，返回请求者的角色。
+func (s *Service) lockForChange(ctx context.Context, repoTx *repo.OrgRepo, actorID, orgID int64) (string, error) {
+	item, err := repoTx.GetByIDForUpdate(ctx, orgID)
+	if err != nil {
+		return "", err
+	}
+	if item == nil {
+		return "", ErrOrgNotFound
+	}
+	actor, err := repoTx.GetMembership(ctx, orgID, actorID)
+	if err != nil {
+		return "", err
+	}
+	if actor == nil {
+		return "", ErrNotMember
+	}
+	if !canManageMembers(actor.Role) {
+		return "", ErrForbidden
+	}
+	if item.Personal {
+		return "", ErrPersonalOrg
+	}
+	return actor.Role, nil
+}
+
+func ensureOtherOwner(ctx context.Context, repoTx *repo.OrgRepo, orgID int64) error {
+	owners, err := repoTx.CountOwners(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+func normalizeName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > maxNameLength {
+		return "", ErrInvalidName
+	}
+	return name, nil
+}
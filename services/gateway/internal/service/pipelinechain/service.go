@@ -0,0 +1,494 @@
+package pipelinechain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"deepspace-shared/model"
+	"deepspace/internal/pipeline"
+	"deepspace/internal/repo"
+
+	"gorm.io/datatypes"
+)
+
+var (
+	ErrInvalidName   = errors.New("invalid name")
+	ErrInvalidScope  = errors.New("invalid scope")
+	ErrInvalidStage  = errors.New("invalid stage")
+	ErrInvalidStatus = errors.New("invalid status")
+	ErrInvalidSteps  = errors.New("invalid steps")
+	ErrChainNotFound = errors.New("chain not found")
+
+	ErrRequiredStepMissing = errors.New("required step missing")
+	ErrHoldStepNotLast     = errors.New("hold step not last")
+)
+
+const (
+	ScopeGlobal  = "global"
+	ScopeProject = "project"
+	ScopeModel   = "model"
+
+	StagePre  = "pre"
+	StagePost = "post"
+
+	// CallStep 是调用上游的步骤，固定在前置与后置链路之间执行，不能配置到链路中。
+	CallStep = "newapi_call"
+
+	// HoldStep 是预扣步骤，必须位于前置链路末尾：前置链路失败时不执行后置链路，
+	// 排在它之后的步骤拒绝请求会使预扣一直冻结到回收任务释放。
+	HoldStep = "budget_hold"
+)
+
+// 未配置任何链路时使用的默认步骤，与历史硬编码行为保持一致。
+var (
+	DefaultPreSteps  = []string{"auth", "policy", "budget_hold"}
+	DefaultPostSteps = []string{"usage_capture"}
+)
+
+// RequiredSteps 是各阶段链路必须包含的步骤：缺少鉴权、预扣或结算时 /v1 请求会绕过计费，
+// 缺少 policy 时套餐额度、项目预算与风控检查会被跳过。
+var RequiredSteps = map[string][]string{
+	StagePre:  {"auth", "policy", HoldStep},
+	StagePost: {"usage_capture"},
+}
+
+// 启用链路的本地缓存有效期；本实例修改链路时立即失效，其他实例最迟在有效期后生效。
+const chainCacheTTL = 30 * time.Second
+
+type Service struct {
+	repo     *repo.PipelineChainRepo
+	registry *pipeline.Registry
+
+	mu       sync.RWMutex
+	active   []model.PipelineChain
+	loadedAt time.Time
+}
+
+func New(repo *repo.PipelineChainRepo, registry *pipeline.Registry) *Service {
+	return &Service{repo: repo, registry: registry}
+}
+
+type ChainCreateInput struct {
+	Name      string
+	Scope     string
+	Stage     string
+	ProjectID *int64
+	Model     *string
+	Steps     []string
+	Status    string
+	Priority  int
+}
+
+type ChainUpdateInput struct {
+	Name      *string
+	Scope     *string
+	Stage     *string
+	ProjectID *int64
+	Model     *string
+	Steps     *[]string
+	Status    *string
+	Priority  *int
+}
+
+// Resolved 为一次请求解析出的前置链路、上游调用与后置链路。
+type Resolved struct {
+	Pre  *pipeline.Pipeline
+	Call pipeline.Step
+	Post *pipeline.Pipeline
+}
+
+func (s *Service) Create(ctx context.Context, input ChainCreateInput) (*model.PipelineChain, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, ErrInvalidName
+	}
+	scope := normalizeScope(input.Scope)
+	if scope == "" {
+		return nil, ErrInvalidScope
+	}
+	modelName := normalizeModel(input.Model)
+	if !validScopeTarget(scope, input.ProjectID, modelName) {
+		return nil, ErrInvalidScope
+	}
+	stage := normalizeStage(input.Stage)
+	if stage == "" {
+		return nil, ErrInvalidStage
+	}
+	steps, err := s.encodeSteps(stage, input.Steps)
+	if err != nil {
+		return nil, err
+	}
+	status := normalizeStatus(input.Status)
+	if status == "" {
+		status = "active"
+	}
+	item := &model.PipelineChain{
+		Name:      name,
+		Scope:     scope,
+		Stage:     stage,
+		ProjectID: input.ProjectID,
+		Model:     modelName,
+		Steps:     steps,
+		Status:    status,
+		Priority:  input.Priority,
+	}
+	if err := s.repo.Create(ctx, item); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return item, nil
+}
+
+func (s *Service) Update(ctx context.Context, id int64, input ChainUpdateInput) (*model.PipelineChain, error) {
+	if id <= 0 {
+		return nil, ErrChainNotFound
+	}
+	item, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrChainNotFound
+	}
+	updates := map[string]any{}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, ErrInvalidName
+		}
+		updates["name"] = name
+	}
+	scope := item.Scope
+	if input.Scope != nil {
+		scope = normalizeScope(*input.Scope)
+		if scope == "" {
+			return nil, ErrInvalidScope
+		}
+		updates["scope"] = scope
+	}
+	// ProjectID、Model 为 nil 表示不修改；作用域改为不再需要它们时自动清空，便于把项目链路改为模型或全局链路。
+	projectID := item.ProjectID
+	if input.ProjectID != nil {
+		projectID = input.ProjectID
+		updates["project_id"] = input.ProjectID
+	} else if scope != ScopeProject && projectID != nil {
+		projectID = nil
+		updates["project_id"] = nil
+	}
+	modelName := item.Model
+	if input.Model != nil {
+		modelName = normalizeModel(input.Model)
+		updates["model"] = modelName
+	} else if scope == ScopeGlobal && modelName != nil {
+		modelName = nil
+		updates["model"] = nil
+	}
+	if !validScopeTarget(scope, projectID, modelName) {
+		return nil, ErrInvalidScope
+	}
+	stage := item.Stage
+	if input.Stage != nil {
+		stage = normalizeStage(*input.Stage)
+		if stage == "" {
+			return nil, ErrInvalidStage
+		}
+		updates["stage"] = stage
+	}
+	if input.Steps != nil || stage != item.Stage {
+		// 阶段变化时按新阶段重新校验必需步骤。
+		var names []string
+		if input.Steps != nil {
+			names = *input.Steps
+		} else if err := json.Unmarshal(item.Steps, &names); err != nil {
+			return nil, ErrInvalidSteps
+		}
+		steps, err := s.encodeSteps(stage, names)
+		if err != nil {
+			return nil, err
+		}
+		updates["steps"] = steps
+	}
+	if input.Status != nil {
+		status := normalizeStatus(*input.Status)
+		if status == "" {
+			return nil, ErrInvalidStatus
+		}
+		updates["status"] = status
+	}
+	if input.Priority != nil {
+		updates["priority"] = *input.Priority
+	}
+	updated, err := s.repo.Update(ctx, id, updates)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return updated, nil
+}
+
+func (s *Service) Delete(ctx context.Context, id int64) (bool, error) {
+	if id <= 0 {
+		return false, ErrChainNotFound
+	}
+	deleted, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	s.invalidate()
+	return deleted, nil
+}
+
+func (s *Service) List(ctx context.Context, filter repo.PipelineChainFilter) ([]model.PipelineChain, int64, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// AvailableSteps 返回已注册、可用于配置的步骤名称。
+func (s *Service) AvailableSteps() []string {
+	if s == nil || s.registry == nil {
+		return []string{}
+	}
+	names := make([]string, 0, len(s.registry.Names()))
+	for _, name := range s.registry.Names() {
+		if name != CallStep {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Resolve 按 项目+模型 > 项目 > 模型 > 全局 的优先级为每个阶段挑选链路，
+// 未配置的阶段回落到默认步骤。
+func (s *Service) Resolve(ctx context.Context, projectID *int64, modelName string) (*Resolved, error) {
+	modelName = strings.TrimSpace(modelName)
+	candidates, err := s.activeChains(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	preNames, err := pickSteps(candidates, StagePre, projectID, modelName)
+	if err != nil {
+		return nil, err
+	}
+	if preNames == nil {
+		preNames = DefaultPreSteps
+	}
+	postNames, err := pickSteps(candidates, StagePost, projectID, modelName)
+	if err != nil {
+		return nil, err
+	}
+	if postNames == nil {
+		postNames = DefaultPostSteps
+	}
+
+	pre, err := s.registry.Build(preNames)
+	if err != nil {
+		return nil, err
+	}
+	post, err := s.registry.Build(postNames)
+	if err != nil {
+		return nil, err
+	}
+	call, ok := s.registry.Get(CallStep)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", pipeline.ErrStepNotFound, CallStep)
+	}
+	return &Resolved{Pre: pre, Call: call, Post: post}, nil
+}
+
+// activeChains 返回全部启用链路，按 priority ASC 排序；优先读本地缓存，避免每次代理请求都查询数据库。
+func (s *Service) activeChains(ctx context.Context) ([]model.PipelineChain, error) {
+	s.mu.RLock()
+	items, loadedAt := s.active, s.loadedAt
+	s.mu.RUnlock()
+	if !loadedAt.IsZero() && time.Since(loadedAt) < chainCacheTTL {
+		return items, nil
+	}
+
+	items, err := s.repo.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	warnNonconforming(items)
+	s.mu.Lock()
+	s.active, s.loadedAt = items, time.Now()
+	s.mu.Unlock()
+	return items, nil
+}
+
+// warnNonconforming 记录早于当前校验规则保存、缺少必需步骤或预扣不在末尾的启用链路，
+// 这些链路仍按原样执行，需要管理员重新保存。
+func warnNonconforming(items []model.PipelineChain) {
+	for _, item := range items {
+		var names []string
+		if err := json.Unmarshal(item.Steps, &names); err != nil {
+			continue
+		}
+		for _, required := range RequiredSteps[item.Stage] {
+			if !containsStep(names, required) {
+				log.Printf("pipeline chain %d (%s) is missing required step %s", item.ID, item.Stage, required)
+			}
+		}
+		if item.Stage == StagePre && len(names) > 0 && names[len(names)-1] != HoldStep {
+			log.Printf("pipeline chain %d (%s) does not end with %s", item.ID, item.Stage, HoldStep)
+		}
+	}
+}
+
+func (s *Service) invalidate() {
+	s.mu.Lock()
+	s.active, s.loadedAt = nil, time.Time{}
+	s.mu.Unlock()
+}
+
+func (s *Service) encodeSteps(stage string, names []string) (datatypes.JSON, error) {
+	cleaned := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || name == CallStep {
+			return nil, ErrInvalidSteps
+		}
+		cleaned = append(cleaned, name)
+	}
+	if s.registry != nil {
+		if err := s.registry.Validate(cleaned); err != nil {
+			return nil, ErrInvalidSteps
+		}
+	}
+	for _, required := range RequiredSteps[stage] {
+		if !containsStep(cleaned, required) {
+			return nil, ErrRequiredStepMissing
+		}
+	}
+	if stage == StagePre && cleaned[len(cleaned)-1] != HoldStep {
+		return nil, ErrHoldStepNotLast
+	}
+	raw, err := json.Marshal(cleaned)
+	if err != nil {
+		return nil, err
+	}
+	return datatypes.JSON(raw), nil
+}
+
+// pickSteps 返回命中链路的步骤，没有命中时返回 nil；命中链路的步骤无法解析时返回错误，不回落到默认步骤。
+func pickSteps(items []model.PipelineChain, stage string, projectID *int64, modelName string) ([]string, error) {
+	var picked *model.PipelineChain
+	pickedRank := -1
+	for i := range items {
+		item := &items[i]
+		if item.Stage != stage {
+			continue
+		}
+		rank := matchRank(item, projectID, modelName)
+		if rank < 0 {
+			continue
+		}
+		// 候选已按 priority ASC 排序，同级别只保留第一条。
+		if picked == nil || rank < pickedRank {
+			picked = item
+			pickedRank = rank
+		}
+	}
+	if picked == nil {
+		return nil, nil
+	}
+	var names []string
+	if err := json.Unmarshal(picked.Steps, &names); err != nil {
+		return nil, fmt.Errorf("pipeline chain %d: %w", picked.ID, ErrInvalidSteps)
+	}
+	if names == nil {
+		names = []string{}
+	}
+	return names, nil
+}
+
+func containsStep(names []string, name string) bool {
+	for _, item := range names {
+		if item == name {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRank 数值越小越具体，-1 表示不匹配。
+func matchRank(item *model.PipelineChain, projectID *int64, modelName string) int {
+	switch item.Scope {
+	case ScopeProject:
+		if projectID == nil || item.ProjectID == nil || *item.ProjectID != *projectID {
+			return -1
+		}
+		if item.Model == nil {
+			return 1
+		}
+		if *item.Model == modelName {
+			return 0
+		}
+		return -1
+	case ScopeModel:
+		if item.Model != nil && *item.Model == modelName {
+			return 2
+		}
+		return -1
+	case ScopeGlobal:
+		return 3
+	default:
+		return -1
+	}
+}
+
+func normalizeScope(value string) string {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case ScopeGlobal, ScopeProject, ScopeModel:
+		return strings.ToLower(strings.TrimSpace(value))
+	default:
+		return ""
+	}
+}
+
+func normalizeStage(value string) string {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case StagePre, StagePost:
+		return strings.ToLower(strings.TrimSpace(value))
+	default:
+		return ""
+	}
+}
+
+func normalizeStatus(value string) string {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "active", "disabled":
+		return strings.ToLower(strings.TrimSpace(value))
+	default:
+		return ""
+	}
+}
+
+func normalizeModel(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+func validScopeTarget(scope string, projectID *int64, modelName *string) bool {
+	switch scope {
+	case ScopeGlobal:
+		return projectID == nil && modelName == nil
+	case ScopeModel:
+		return projectID == nil && modelName != nil
+	case ScopeProject:
+		return projectID != nil
+	default:
+		return false
+	}
+}
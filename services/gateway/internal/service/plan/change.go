@@ -0,0 +1,260 @@
+package plan
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"deepspace-shared/model"
+	"deepspace-shared/money"
+	"deepspace/internal/repo"
+
+	"gorm.io/gorm"
+)
+
+// 套餐变更方式：upgrade 立即生效，downgrade 在当前周期结束时生效。
+const (
+	ChangeUpgrade   = "upgrade"
+	ChangeDowngrade = "downgrade"
+)
+
+// SubscriptionChange 是套餐变更的结果。升级时 Credit 为原套餐剩余时长折算的抵扣金额，
+// Charged 为新套餐剩余时长的费用减去抵扣后实际扣除的金额；降级不产生费用。
+type SubscriptionChange struct {
+	Kind         string            `json:"kind"`
+	Subscription *SubscriptionView `json:"subscription"`
+	Credit       money.Amount      `json:"credit"`
+	Charged      money.Amount      `json:"charged"`
+	Currency     string            `json:"currency"`
+	EffectiveAt  time.Time         `json:"effective_at"`
+}
+
+// ChangePlan 把组织的自助订阅切换到 planID。按钱包币种的日均价格比较：新套餐更贵时为升级，
+// 立即结束原订阅并创建沿用原 EndAt 的新订阅，按剩余时长补差价，本周期已用的用量计入新订阅；
+// 否则为降级，记录在原订阅上，周期结束续费时切换到新套餐，用量从零开始；已关闭自动续费的订阅不能降级。
+func (s *Service) ChangePlan(ctx context.Context, orgID, planID int64) (*SubscriptionChange, error) {
+	if orgID <= 0 || planID <= 0 {
+		return nil, ErrPlanNotFound
+	}
+	target, err := s.planRepo.GetByID(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, ErrPlanNotFound
+	}
+	if normalizeStatus(target.Status) != "active" {
+		return nil, ErrPlanUnavailable
+	}
+
+	now := time.Now().UTC()
+	var result *SubscriptionChange
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		billingTx := s.billing.WithTx(tx)
+		subscriptionTx := s.subscriptionRepo.WithTx(tx)
+		// 与 Subscribe 相同，先锁定钱包串行化同一组织的订阅变更。
+		wallet, err := billingTx.LockWallet(ctx, orgID)
+		if err != nil {
+			return err
+		}
+		current, err := subscriptionTx.GetActiveByOrg(ctx, orgID, now)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return ErrSubscriptionNotFound
+		}
+		if current.Source != SubscriptionSourceWallet || current.EndAt == nil {
+			return ErrNotSelfService
+		}
+		if !current.EndAt.After(now) {
+			return ErrSubscriptionEnded
+		}
+		if current.PlanID == target.ID {
+			return ErrPlanUnchanged
+		}
+		if wallet.Currency != current.Currency {
+			return ErrSubscriptionCurrencyChanged
+		}
+		currentPlan, err := s.planRepo.GetByID(ctx, current.PlanID)
+		if err != nil {
+			return err
+		}
+		if currentPlan == nil {
+			return ErrPlanNotFound
+		}
+		price, err := s.billing.SumInCurrency(ctx, map[string]money.Amount{target.Currency: target.Price}, current.Currency)
+		if err != nil {
+			return err
+		}
+
+		currentDays := int64(normalizeResetInterval(currentPlan.ResetIntervalDays))
+		targetDays := int64(normalizeResetInterval(target.ResetIntervalDays))
+		if price.Micros()*currentDays <= current.Price.Micros()*targetDays {
+			if !current.AutoRenew {
+				return ErrSubscriptionCanceled
+			}
+			result, err = s.scheduleDowngrade(ctx, subscriptionTx, current, target, price)
+			return err
+		}
+		result, err = s.upgrade(ctx, tx, current, currentPlan, target, price, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CancelScheduledChange 撤销尚未生效的降级，续费时继续使用当前套餐。
+func (s *Service) CancelScheduledChange(ctx context.Context, orgID int64) (*SubscriptionView, error) {
+	item, err := s.subscriptionRepo.GetActiveByOrg(ctx, orgID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+	if item.ScheduledPlanID == nil {
+		return nil, ErrNoScheduledChange
+	}
+	updated, err := s.subscriptionRepo.Update(ctx, item.ID, map[string]any{
+		"scheduled_plan_id": nil,
+		"scheduled_price":   money.Amount(0),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+	return s.loadSubscriptionView(ctx, updated)
+}
+
+func (s *Service) scheduleDowngrade(ctx context.Context, subscriptionTx *repo.PlanSubscriptionRepo, current *model.PlanSubscription, target *model.Plan, price money.Amount) (*SubscriptionChange, error) {
+	updated, err := subscriptionTx.Update(ctx, current.ID, map[string]any{
+		"scheduled_plan_id": target.ID,
+		"scheduled_price":   price,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+	view, err := s.loadSubscriptionView(ctx, updated)
+	if err != nil {
+		return nil, err
+	}
+	return &SubscriptionChange{
+		Kind:         ChangeDowngrade,
+		Subscription: view,
+		Currency:     current.Currency,
+		EffectiveAt:  current.EndAt.UTC(),
+	}, nil
+}
+
+func (s *Service) upgrade(ctx context.Context, tx *gorm.DB, current *model.PlanSubscription, currentPlan, target *model.Plan, price money.Amount, now time.Time) (*SubscriptionChange, error) {
+	subscriptionTx := s.subscriptionRepo.WithTx(tx)
+	usageTx := s.usageRepo.WithTx(tx)
+	endAt := current.EndAt.UTC()
+	remaining := int64(endAt.Sub(now) / time.Second)
+	currentSeconds := int64(normalizeResetInterval(currentPlan.ResetIntervalDays)) * 86400
+	targetSeconds := int64(normalizeResetInterval(target.ResetIntervalDays)) * 86400
+	credit := current.Price.MulDiv(remaining, currentSeconds, money.RoundDown)
+	charge := max(price.MulDiv(remaining, targetSeconds, money.RoundHalfUp)-credit, 0)
+
+	// 原订阅本周期的用量，升级后计入新订阅当前周期中范围相同的额度（通用额度或相同的模型/能力）。
+	currentStart, currentEnd := subscriptionPeriod(current, currentPlan.ResetIntervalDays, now)
+	used, err := usageTx.ListBySubscriptionPeriod(ctx, current.ID, currentStart, currentEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := subscriptionTx.Update(ctx, current.ID, map[string]any{
+		"status":            "expired",
+		"end_at":            now,
+		"auto_renew":        false,
+		"scheduled_plan_id": nil,
+		"scheduled_price":   money.Amount(0),
+	}); err != nil {
+		return nil, err
+	}
+	previousID := current.ID
+	item := &model.PlanSubscription{
+		UserID:                 current.UserID,
+		PlanID:                 target.ID,
+		Status:                 "active",
+		StartAt:                now,
+		EndAt:                  &endAt,
+		Source:                 SubscriptionSourceWallet,
+		AutoRenew:              current.AutoRenew,
+		Price:                  price,
+		Currency:               current.Currency,
+		CanceledAt:             current.CanceledAt,
+		PreviousSubscriptionID: &previousID,
+	}
+	if err := subscriptionTx.Create(ctx, item); err != nil {
+		return nil, err
+	}
+	if len(used) > 0 {
+		periodStart, periodEnd := subscriptionPeriod(item, target.ResetIntervalDays, now)
+		targets := allowanceKeys(target)
+		for _, usageItem := range used {
+			key, ok := allowanceKey(currentPlan, usageItem.AllowanceID)
+			if !ok {
+				continue
+			}
+			allowanceID, ok := targets[key]
+			if !ok {
+				continue
+			}
+			if _, err := usageTx.AddUsage(ctx, item.ID, allowanceID, item.UserID, periodStart, periodEnd, usageItem.UsedTokens, usageItem.UsedRequests); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if charge > 0 {
+		if _, err := s.billing.WithTx(tx).Charge(ctx, item.UserID, charge, item.Currency, SubscriptionRefID(item.ID, now), map[string]any{
+			"source":                   "plan_upgrade",
+			"subscription_id":          item.ID,
+			"previous_subscription_id": previousID,
+			"plan_id":                  target.ID,
+			"previous_plan_id":         currentPlan.ID,
+			"credit":                   credit,
+			"period_start":             now,
+			"period_end":               endAt,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return &SubscriptionChange{
+		Kind:         ChangeUpgrade,
+		Subscription: subscriptionView(item, target),
+		Credit:       credit,
+		Charged:      charge,
+		Currency:     item.Currency,
+		EffectiveAt:  now,
+	}, nil
+}
+
+// allowanceKeys 把套餐的额度按范围映射到 AllowanceID，通用额度的键为空串。
+func allowanceKeys(plan *model.Plan) map[string]int64 {
+	keys := map[string]int64{"": 0}
+	for _, item := range plan.Allowances {
+		keys[item.Scope+":"+strings.ToLower(item.Match)] = item.ID
+	}
+	return keys
+}
+
+func allowanceKey(plan *model.Plan, allowanceID int64) (string, bool) {
+	if allowanceID == 0 {
+		return "", true
+	}
+	for _, item := range plan.Allowances {
+		if item.ID == allowanceID {
+			return item.Scope + ":" + strings.ToLower(item.Match), true
+		}
+	}
+	return "", false
+}
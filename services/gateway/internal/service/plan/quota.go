@@ -0,0 +1,156 @@
+package plan
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"deepspace-shared/model"
+	"deepspace-shared/money"
+)
+
+// QuotaCheck 是调用上游前对请求所匹配额度的检查结果。
+type QuotaCheck struct {
+	Allowance         AllowanceQuota
+	Enforcement       string
+	Exhausted         bool
+	OverageMultiplier money.Rate
+}
+
+// Blocks 表示额度已用完且套餐设置为 block，请求应被拒绝。
+func (c *QuotaCheck) Blocks() bool {
+	return c != nil && c.Exhausted && c.Enforcement == QuotaEnforcementBlock
+}
+
+// EstimateOverage 按调用前的剩余额度预估一次请求超出额度的部分，不记录用量；
+// 结果的 BillableCost 即该请求预计需要从钱包支付的费用，c 为 nil 时按全额计。
+func (c *QuotaCheck) EstimateOverage(tokens, requests int64) *QuotaApplyResult {
+	if c == nil {
+		return nil
+	}
+	result := &QuotaApplyResult{
+		Applied:           true,
+		AllowanceID:       c.Allowance.AllowanceID,
+		Tokens:            max(tokens, 0),
+		Requests:          max(requests, 0),
+		OverageMultiplier: c.OverageMultiplier,
+	}
+	if c.Allowance.IncludedTokens > 0 {
+		result.TokenOverage = max(result.Tokens-c.Allowance.RemainingTokens, 0)
+	}
+	if c.Allowance.IncludedRequests > 0 {
+		result.RequestOverage = max(result.Requests-c.Allowance.RemainingRequests, 0)
+	}
+	return result
+}
+
+// CheckQuota 在调用上游前检查请求匹配的额度，不记录用量；组织没有订阅或请求不匹配任何额度时返回 nil。
+func (s *Service) CheckQuota(ctx context.Context, orgID int64, now time.Time, modelName string, capabilities []string) (*QuotaCheck, error) {
+	quota, ok, err := s.GetActivePlanQuota(ctx, orgID, now)
+	if err != nil || !ok {
+		return nil, err
+	}
+	allowance := quota.Match(modelName, capabilities)
+	if allowance == nil {
+		return nil, nil
+	}
+	return &QuotaCheck{
+		Allowance:         *allowance,
+		Enforcement:       quota.QuotaEnforcement,
+		Exhausted:         allowance.Exhausted(),
+		OverageMultiplier: quota.OverageMultiplier,
+	}, nil
+}
+
+// QuotaPeriod 是一个历史周期的额度用量。
+type QuotaPeriod struct {
+	SubscriptionID int64            `json:"subscription_id"`
+	PlanID         int64            `json:"plan_id"`
+	PlanName       string           `json:"plan_name"`
+	PeriodStart    time.Time        `json:"period_start"`
+	PeriodEnd      *time.Time       `json:"period_end"`
+	Allowances     []AllowanceQuota `json:"allowances"`
+}
+
+// QuotaHistory 按周期开始时间倒序返回组织最近 limit 个周期的额度用量（含当前周期）。
+// 套餐之后修改过额度时，已删除的限定额度只保留 AllowanceID 与用量。
+func (s *Service) QuotaHistory(ctx context.Context, orgID int64, limit int) ([]QuotaPeriod, error) {
+	periods, err := s.usageRepo.ListPeriodsByOrg(ctx, orgID, limit)
+	if err != nil || len(periods) == 0 {
+		return []QuotaPeriod{}, err
+	}
+	items, err := s.usageRepo.ListByOrgSince(ctx, orgID, periods[len(periods)-1].PeriodStart)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	result := make([]QuotaPeriod, 0, len(periods))
+	index := map[string]int{}
+	plans := map[int64]*model.Plan{}
+	for _, period := range periods {
+		entry := QuotaPeriod{
+			SubscriptionID: period.SubscriptionID,
+			PeriodStart:    period.PeriodStart,
+			PeriodEnd:      period.PeriodEnd,
+			Allowances:     []AllowanceQuota{},
+		}
+		subscription, err := s.subscriptionRepo.GetByID(ctx, period.SubscriptionID)
+		if err != nil {
+			return nil, err
+		}
+		if subscription != nil {
+			entry.PlanID = subscription.PlanID
+			plan, ok := plans[subscription.PlanID]
+			if !ok {
+				if plan, err = s.planRepo.GetByID(ctx, subscription.PlanID); err != nil {
+					return nil, err
+				}
+				plans[subscription.PlanID] = plan
+			}
+			if plan != nil {
+				entry.PlanName = plan.Name
+			}
+		}
+		index[periodKey(period.SubscriptionID, period.PeriodStart, period.PeriodEnd)] = len(result)
+		result = append(result, entry)
+	}
+
+	for _, item := range items {
+		pos, ok := index[periodKey(item.SubscriptionID, item.PeriodStart, item.PeriodEnd)]
+		if !ok {
+			continue
+		}
+		entry := &result[pos]
+		allowance := AllowanceQuota{
+			AllowanceID:  item.AllowanceID,
+			UsedTokens:   item.UsedTokens,
+			UsedRequests: item.UsedRequests,
+			Rollover:     rolloverQuota(item, now),
+		}
+		rolloverTokens, rolloverRequests := allowance.Rollover.remaining()
+		if plan := plans[entry.PlanID]; plan != nil {
+			for _, candidate := range resolveAllowances(plan) {
+				if candidate.AllowanceID == item.AllowanceID {
+					allowance.Scope = candidate.Scope
+					allowance.Match = candidate.Match
+					allowance.IncludedTokens = candidate.IncludedTokens
+					allowance.IncludedRequests = candidate.IncludedRequests
+					allowance.RemainingTokens = max(candidate.IncludedTokens-item.UsedTokens, 0) + rolloverTokens
+					allowance.RemainingRequests = max(candidate.IncludedRequests-item.UsedRequests, 0) + rolloverRequests
+					break
+				}
+			}
+		}
+		entry.Allowances = append(entry.Allowances, allowance)
+	}
+	return result, nil
+}
+
+func periodKey(subscriptionID int64, start time.Time, end *time.Time) string {
+	var endUnix int64
+	if end != nil {
+		endUnix = end.UTC().UnixNano()
+	}
+	return fmt.Sprintf("%d:%d:%d", subscriptionID, start.UTC().UnixNano(), endUnix)
+}
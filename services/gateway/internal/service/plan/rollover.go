@@ -0,0 +1,193 @@
+package plan
+
+import (
+	"context"
+	"time"
+
+	"deepspace-shared/model"
+
+	"gorm.io/gorm"
+)
+
+// RolloverQuota 是从上一周期结转到本周期的额度，优先于套餐额度消耗；过了 ExpiresAt 后剩余部分失效。
+type RolloverQuota struct {
+	Tokens            int64      `json:"tokens"`
+	Requests          int64      `json:"requests"`
+	UsedTokens        int64      `json:"used_tokens"`
+	UsedRequests      int64      `json:"used_requests"`
+	RemainingTokens   int64      `json:"remaining_tokens"`
+	RemainingRequests int64      `json:"remaining_requests"`
+	ExpiresAt         *time.Time `json:"expires_at"`
+	Expired           bool       `json:"expired"`
+}
+
+func (r *RolloverQuota) remaining() (int64, int64) {
+	if r == nil {
+		return 0, 0
+	}
+	return r.RemainingTokens, r.RemainingRequests
+}
+
+// rolloverQuota 返回用量记录中的结转额度，没有结转时返回 nil。
+func rolloverQuota(item model.PlanUsage, now time.Time) *RolloverQuota {
+	if item.RolloverTokens <= 0 && item.RolloverRequests <= 0 {
+		return nil
+	}
+	result := &RolloverQuota{
+		Tokens:       item.RolloverTokens,
+		Requests:     item.RolloverRequests,
+		UsedTokens:   item.RolloverUsedTokens,
+		UsedRequests: item.RolloverUsedRequests,
+	}
+	result.ExpiresAt = item.RolloverExpiresAt
+	if result.ExpiresAt == nil {
+		result.ExpiresAt = item.PeriodEnd
+	}
+	result.Expired = result.ExpiresAt != nil && !now.Before(*result.ExpiresAt)
+	if !result.Expired {
+		result.RemainingTokens = max(item.RolloverTokens-item.RolloverUsedTokens, 0)
+		result.RemainingRequests = max(item.RolloverRequests-item.RolloverUsedRequests, 0)
+	}
+	return result
+}
+
+// rolloverCaps 返回一项额度每周期最多结转的 token 与请求次数，AllowanceID 为 0 时为通用额度。
+func rolloverCaps(plan *model.Plan, allowanceID int64) (int64, int64) {
+	if allowanceID == 0 {
+		return max(plan.RolloverTokens, 0), max(plan.RolloverRequests, 0)
+	}
+	for _, item := range plan.Allowances {
+		if item.ID == allowanceID {
+			return max(item.RolloverTokens, 0), max(item.RolloverRequests, 0)
+		}
+	}
+	return 0, 0
+}
+
+// needsRolloverStart 表示套餐有可结转的额度，而本周期还没有为其建立用量记录。
+func needsRolloverStart(plan *model.Plan, items []model.PlanUsage) bool {
+	started := make(map[int64]struct{}, len(items))
+	for _, item := range items {
+		started[item.AllowanceID] = struct{}{}
+	}
+	for _, allowance := range resolveAllowances(plan) {
+		tokens, requests := rolloverCaps(plan, allowance.AllowanceID)
+		if tokens == 0 && requests == 0 {
+			continue
+		}
+		if _, ok := started[allowance.AllowanceID]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// startRolloverPeriod 在周期开始时为可结转的额度建立用量记录：上一周期同一额度未用完的部分按上限结转到本周期，
+// 结转额度本身不会再次结转。锁定订阅串行化并发请求，返回本周期的全部用量记录。
+func (s *Service) startRolloverPeriod(ctx context.Context, subscription *model.PlanSubscription, plan *model.Plan, periodStart time.Time, periodEnd *time.Time) ([]model.PlanUsage, error) {
+	var result []model.PlanUsage
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := s.subscriptionRepo.WithTx(tx).GetByIDForUpdate(ctx, subscription.ID); err != nil {
+			return err
+		}
+		usageTx := s.usageRepo.WithTx(tx)
+		current, err := usageTx.ListBySubscriptionPeriod(ctx, subscription.ID, periodStart, periodEnd)
+		if err != nil {
+			return err
+		}
+		started := make(map[int64]struct{}, len(current))
+		for _, item := range current {
+			started[item.AllowanceID] = struct{}{}
+		}
+
+		previous := map[int64]model.PlanUsage{}
+		previousStart, previousEnd, hasPrevious := previousPeriod(subscription, plan.ResetIntervalDays, periodStart)
+		if hasPrevious {
+			items, err := usageTx.ListBySubscriptionPeriod(ctx, subscription.ID, previousStart, previousEnd)
+			if err != nil {
+				return err
+			}
+			for _, item := range items {
+				previous[item.AllowanceID] = item
+			}
+		}
+		expiresAt := rolloverExpiry(plan.RolloverDays, periodStart, periodEnd)
+
+		for _, allowance := range resolveAllowances(plan) {
+			capTokens, capRequests := rolloverCaps(plan, allowance.AllowanceID)
+			if capTokens == 0 && capRequests == 0 {
+				continue
+			}
+			if _, ok := started[allowance.AllowanceID]; ok {
+				continue
+			}
+			item := model.PlanUsage{
+				SubscriptionID: subscription.ID,
+				UserID:         subscription.UserID,
+				AllowanceID:    allowance.AllowanceID,
+				PeriodStart:    periodStart,
+				PeriodEnd:      periodEnd,
+			}
+			used, ok := previous[allowance.AllowanceID]
+			// 上一周期没有用量记录时视为未使用，但额度须在上一周期开始前就已存在。
+			if hasPrevious && (ok || allowanceExistedAt(plan, allowance.AllowanceID, previousStart)) {
+				if allowance.IncludedTokens > 0 {
+					item.RolloverTokens = min(max(allowance.IncludedTokens-used.UsedTokens, 0), capTokens)
+				}
+				if allowance.IncludedRequests > 0 {
+					item.RolloverRequests = min(max(allowance.IncludedRequests-used.UsedRequests, 0), capRequests)
+				}
+				if item.RolloverTokens > 0 || item.RolloverRequests > 0 {
+					item.RolloverExpiresAt = expiresAt
+				}
+			}
+			if err := usageTx.Create(ctx, &item); err != nil {
+				return err
+			}
+			current = append(current, item)
+		}
+		result = current
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// previousPeriod 返回同一订阅紧挨着 periodStart 之前的周期；periodStart 是订阅的首个周期时返回 false。
+func previousPeriod(subscription *model.PlanSubscription, intervalDays int, periodStart time.Time) (time.Time, *time.Time, bool) {
+	if !periodStart.After(subscription.StartAt) {
+		return time.Time{}, nil, false
+	}
+	start, end := subscriptionPeriod(subscription, intervalDays, periodStart.Add(-time.Nanosecond))
+	if end == nil || !end.Equal(periodStart) {
+		return time.Time{}, nil, false
+	}
+	return start, end, true
+}
+
+// allowanceExistedAt 表示额度在 at 之前已存在；限定额度在套餐中新增时才会创建，通用额度始终视为存在。
+func allowanceExistedAt(plan *model.Plan, allowanceID int64, at time.Time) bool {
+	if allowanceID == 0 {
+		return true
+	}
+	for _, item := range plan.Allowances {
+		if item.ID == allowanceID {
+			return !item.CreatedAt.After(at)
+		}
+	}
+	return false
+}
+
+// rolloverExpiry 返回结转额度的失效时间，为 nil 时在周期结束时失效。
+func rolloverExpiry(days int, periodStart time.Time, periodEnd *time.Time) *time.Time {
+	if days <= 0 {
+		return nil
+	}
+	expiresAt := periodStart.AddDate(0, 0, days)
+	if periodEnd != nil && !expiresAt.Before(*periodEnd) {
+		return nil
+	}
+	return &expiresAt
+}
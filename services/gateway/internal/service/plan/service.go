@@ -7,8 +7,12 @@ import (
 	"strings"
 	"time"
 
-	"deepspace/internal/model"
+	"deepspace-shared/model"
+	"deepspace-shared/money"
 	"deepspace/internal/repo"
+	"deepspace/internal/service/billing"
+
+	"gorm.io/gorm"
 )
 
 var (
@@ -17,22 +21,61 @@ var (
 	ErrInvalidPlanCycle         = errors.New("invalid plan cycle")
 	ErrInvalidPlanPrice         = errors.New("invalid plan price")
 	ErrInvalidPlanCurrency      = errors.New("invalid plan currency")
+	ErrInvalidPlanAllowance     = errors.New("invalid plan allowance")
+	ErrInvalidOverageMultiplier = errors.New("invalid overage multiplier")
+	ErrInvalidQuotaEnforcement  = errors.New("invalid quota enforcement")
+	ErrInvalidGracePeriod       = errors.New("invalid grace period")
+	ErrInvalidPlanRollover      = errors.New("invalid plan rollover")
 	ErrPlanNotFound             = errors.New("plan not found")
 	ErrInvalidSubscriptionTime  = errors.New("invalid subscription time")
 	ErrActiveSubscriptionExists = errors.New("active subscription exists")
+	ErrPlanUnavailable          = errors.New("plan unavailable")
+	ErrSubscriptionNotFound     = errors.New("subscription not found")
+	// ErrNotSelfService 表示订阅由管理员创建，不能由用户取消或恢复自动续费。
+	ErrNotSelfService              = errors.New("subscription is not self-service")
+	ErrPlanUnchanged               = errors.New("plan unchanged")
+	ErrSubscriptionCurrencyChanged = errors.New("wallet currency differs from subscription")
+	ErrNoScheduledChange           = errors.New("no scheduled plan change")
+	// ErrSubscriptionEnded 表示订阅已过 EndAt、处于宽限期，只能恢复自动续费或等待失效后重新订阅。
+	ErrSubscriptionEnded = errors.New("subscription has ended")
+	// ErrSubscriptionCanceled 表示订阅已关闭自动续费，周期结束后不会续费，无法安排降级。
+	ErrSubscriptionCanceled = errors.New("subscription is canceled")
+)
+
+// 限定额度的匹配范围：model 按模型名匹配，capability 按模型能力匹配。
+const (
+	AllowanceScopeModel      = "model"
+	AllowanceScopeCapability = "capability"
+)
+
+// 额度用完后的处理方式：block 在调用上游前拒绝请求，overage 转为钱包计费。
+const (
+	QuotaEnforcementBlock   = "block"
+	QuotaEnforcementOverage = "overage"
+)
+
+// 订阅来源：admin 由管理员创建，wallet 由用户从组织钱包付费订阅。
+const (
+	SubscriptionSourceAdmin  = "admin"
+	SubscriptionSourceWallet = "wallet"
 )
 
 type Service struct {
+	db               *gorm.DB
 	planRepo         *repo.PlanRepo
 	subscriptionRepo *repo.PlanSubscriptionRepo
 	usageRepo        *repo.PlanUsageRepo
+	billing          *billing.Service
 }
 
-func New(planRepo *repo.PlanRepo, subscriptionRepo *repo.PlanSubscriptionRepo, usageRepo *repo.PlanUsageRepo) *Service {
+// New 创建套餐服务；billingSvc 用于自助订阅时从组织钱包扣款。
+func New(db *gorm.DB, planRepo *repo.PlanRepo, subscriptionRepo *repo.PlanSubscriptionRepo, usageRepo *repo.PlanUsageRepo, billingSvc *billing.Service) *Service {
 	return &Service{
+		db:               db,
 		planRepo:         planRepo,
 		subscriptionRepo: subscriptionRepo,
 		usageRepo:        usageRepo,
+		billing:          billingSvc,
 	}
 }
 
@@ -42,8 +85,18 @@ type PlanCreateInput struct {
 	IncludedTokens    int64
 	IncludedRequests  int64
 	ResetIntervalDays int
-	Price             float64
+	Price             money.Amount
 	Currency          string
+	// OverageMultiplier 为 0 时按 1 倍计费。
+	OverageMultiplier money.Rate
+	// QuotaEnforcement 为空时为 overage。
+	QuotaEnforcement string
+	GracePeriodDays  int
+	// RolloverTokens/RolloverRequests 为通用额度每周期最多结转的未用量，RolloverDays 为结转额度的有效天数。
+	RolloverTokens   int64
+	RolloverRequests int64
+	RolloverDays     int
+	Allowances       []AllowanceInput
 }
 
 type PlanUpdateInput struct {
@@ -52,8 +105,25 @@ type PlanUpdateInput struct {
 	IncludedTokens    *int64
 	IncludedRequests  *int64
 	ResetIntervalDays *int
-	Price             *float64
+	Price             *money.Amount
 	Currency          *string
+	OverageMultiplier *money.Rate
+	QuotaEnforcement  *string
+	GracePeriodDays   *int
+	RolloverTokens    *int64
+	RolloverRequests  *int64
+	RolloverDays      *int
+	// Allowances 不为 nil 时替换套餐的全部限定额度。
+	Allowances *[]AllowanceInput
+}
+
+type AllowanceInput struct {
+	Scope            string
+	Match            string
+	IncludedTokens   int64
+	IncludedRequests int64
+	RolloverTokens   int64
+	RolloverRequests int64
 }
 
 type SubscriptionCreateInput struct {
@@ -70,15 +140,36 @@ type SubscriptionUpdateInput struct {
 	EndAt   *time.Time
 }
 
+// ActivePlanQuota 是组织当前订阅在本周期的额度，Allowances 中通用额度（AllowanceID 为 0）排在最前。
 type ActivePlanQuota struct {
-	PlanID         int64
-	SubscriptionID int64
-	Type           string
-	Included       int64
-	Used           int64
-	Remaining      int64
-	PeriodStart    time.Time
-	PeriodEnd      *time.Time
+	PlanID            int64            `json:"plan_id"`
+	PlanName          string           `json:"plan_name"`
+	SubscriptionID    int64            `json:"subscription_id"`
+	OverageMultiplier money.Rate       `json:"overage_multiplier"`
+	QuotaEnforcement  string           `json:"quota_enforcement"`
+	Allowances        []AllowanceQuota `json:"allowances"`
+	PeriodStart       time.Time        `json:"period_start"`
+	PeriodEnd         *time.Time       `json:"period_end"`
+}
+
+// AllowanceQuota 是一项额度的用量，Included 为 0 的维度不计量。
+// Remaining 包含未失效的结转额度，Rollover 为从上一周期结转的部分，没有结转时为 nil。
+type AllowanceQuota struct {
+	AllowanceID       int64          `json:"allowance_id"`
+	Scope             string         `json:"scope,omitempty"`
+	Match             string         `json:"match,omitempty"`
+	IncludedTokens    int64          `json:"included_tokens"`
+	IncludedRequests  int64          `json:"included_requests"`
+	UsedTokens        int64          `json:"used_tokens"`
+	UsedRequests      int64          `json:"used_requests"`
+	RemainingTokens   int64          `json:"remaining_tokens"`
+	RemainingRequests int64          `json:"remaining_requests"`
+	Rollover          *RolloverQuota `json:"rollover,omitempty"`
+}
+
+// Exhausted 表示任一计量维度的额度已用完。
+func (a *AllowanceQuota) Exhausted() bool {
+	return (a.IncludedTokens > 0 && a.RemainingTokens <= 0) || (a.IncludedRequests > 0 && a.RemainingRequests <= 0)
 }
 
 func (s *Service) CreatePlan(ctx context.Context, input PlanCreateInput) (*model.Plan, error) {
@@ -86,13 +177,34 @@ func (s *Service) CreatePlan(ctx context.Context, input PlanCreateInput) (*model
 	if name == "" {
 		return nil, ErrInvalidPlanName
 	}
-	if !isValidQuota(input.IncludedTokens, input.IncludedRequests) {
+	allowances, err := normalizeAllowances(input.Allowances)
+	if err != nil {
+		return nil, err
+	}
+	if !isValidQuota(input.IncludedTokens, input.IncludedRequests, len(allowances)) {
 		return nil, ErrInvalidPlanQuota
 	}
+	multiplier := input.OverageMultiplier
+	if multiplier == 0 {
+		multiplier = money.OneRate
+	}
+	if multiplier < 0 {
+		return nil, ErrInvalidOverageMultiplier
+	}
+	enforcement := normalizeQuotaEnforcement(input.QuotaEnforcement)
+	if enforcement == "" {
+		return nil, ErrInvalidQuotaEnforcement
+	}
 	resetInterval := normalizeResetInterval(input.ResetIntervalDays)
 	if resetInterval == 0 {
 		return nil, ErrInvalidPlanCycle
 	}
+	if !isValidGracePeriod(input.GracePeriodDays) {
+		return nil, ErrInvalidGracePeriod
+	}
+	if !isValidRollover(input.RolloverTokens, input.RolloverRequests, input.RolloverDays) {
+		return nil, ErrInvalidPlanRollover
+	}
 	if input.Price < 0 {
 		return nil, ErrInvalidPlanPrice
 	}
@@ -113,6 +225,13 @@ func (s *Service) CreatePlan(ctx context.Context, input PlanCreateInput) (*model
 		ResetIntervalDays: resetInterval,
 		Price:             input.Price,
 		Currency:          currency,
+		OverageMultiplier: multiplier,
+		QuotaEnforcement:  enforcement,
+		GracePeriodDays:   input.GracePeriodDays,
+		RolloverTokens:    input.RolloverTokens,
+		RolloverRequests:  input.RolloverRequests,
+		RolloverDays:      input.RolloverDays,
+		Allowances:        allowances,
 	}
 	if err := s.planRepo.Create(ctx, plan); err != nil {
 		return nil, err
@@ -152,7 +271,15 @@ func (s *Service) UpdatePlan(ctx context.Context, id int64, input PlanUpdateInpu
 	if input.IncludedRequests != nil {
 		updates["included_requests"] = *input.IncludedRequests
 	}
-	if input.IncludedTokens != nil || input.IncludedRequests != nil {
+	allowances := plan.Allowances
+	if input.Allowances != nil {
+		normalized, err := normalizeAllowances(*input.Allowances)
+		if err != nil {
+			return nil, err
+		}
+		allowances = normalized
+	}
+	if input.IncludedTokens != nil || input.IncludedRequests != nil || input.Allowances != nil {
 		finalTokens := plan.IncludedTokens
 		finalRequests := plan.IncludedRequests
 		if input.IncludedTokens != nil {
@@ -161,16 +288,53 @@ func (s *Service) UpdatePlan(ctx context.Context, id int64, input PlanUpdateInpu
 		if input.IncludedRequests != nil {
 			finalRequests = *input.IncludedRequests
 		}
-		if !isValidQuota(finalTokens, finalRequests) {
+		if !isValidQuota(finalTokens, finalRequests, len(allowances)) {
 			return nil, ErrInvalidPlanQuota
 		}
 	}
+	if input.OverageMultiplier != nil {
+		if *input.OverageMultiplier <= 0 {
+			return nil, ErrInvalidOverageMultiplier
+		}
+		updates["overage_multiplier"] = *input.OverageMultiplier
+	}
+	if input.QuotaEnforcement != nil {
+		enforcement := normalizeQuotaEnforcement(*input.QuotaEnforcement)
+		if enforcement == "" {
+			return nil, ErrInvalidQuotaEnforcement
+		}
+		updates["quota_enforcement"] = enforcement
+	}
 	if input.ResetIntervalDays != nil {
 		if normalizeResetInterval(*input.ResetIntervalDays) == 0 {
 			return nil, ErrInvalidPlanCycle
 		}
 		updates["reset_interval_days"] = *input.ResetIntervalDays
 	}
+	if input.GracePeriodDays != nil {
+		if !isValidGracePeriod(*input.GracePeriodDays) {
+			return nil, ErrInvalidGracePeriod
+		}
+		updates["grace_period_days"] = *input.GracePeriodDays
+	}
+	if input.RolloverTokens != nil {
+		if !isValidRollover(*input.RolloverTokens, 0, 0) {
+			return nil, ErrInvalidPlanRollover
+		}
+		updates["rollover_tokens"] = *input.RolloverTokens
+	}
+	if input.RolloverRequests != nil {
+		if !isValidRollover(0, *input.RolloverRequests, 0) {
+			return nil, ErrInvalidPlanRollover
+		}
+		updates["rollover_requests"] = *input.RolloverRequests
+	}
+	if input.RolloverDays != nil {
+		if !isValidRollover(0, 0, *input.RolloverDays) {
+			return nil, ErrInvalidPlanRollover
+		}
+		updates["rollover_days"] = *input.RolloverDays
+	}
 	if input.Price != nil {
 		if *input.Price < 0 {
 			return nil, ErrInvalidPlanPrice
@@ -184,7 +348,25 @@ func (s *Service) UpdatePlan(ctx context.Context, id int64, input PlanUpdateInpu
 		}
 		updates["currency"] = currency
 	}
-	return s.planRepo.Update(ctx, id, updates)
+	var updated *model.Plan
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		planRepo := s.planRepo.WithTx(tx)
+		if input.Allowances != nil {
+			if err := planRepo.ReplaceAllowances(ctx, id, allowances); err != nil {
+				return err
+			}
+		}
+		item, err := planRepo.Update(ctx, id, updates)
+		if err != nil {
+			return err
+		}
+		updated = item
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
 }
 
 func (s *Service) ListPlans(ctx context.Context) ([]model.Plan, error) {
@@ -229,6 +411,7 @@ func (s *Service) CreateSubscription(ctx context.Context, input SubscriptionCrea
 		Status:  status,
 		StartAt: input.StartAt,
 		EndAt:   input.EndAt,
+		Source:  SubscriptionSourceAdmin,
 	}
 	if err := s.subscriptionRepo.Create(ctx, item); err != nil {
 		return nil, err
@@ -253,6 +436,7 @@ func (s *Service) UpdateSubscription(ctx context.Context, id int64, input Subscr
 	}
 	if input.EndAt != nil {
 		updates["end_at"] = *input.EndAt
+		updates["expiry_reminded_at"] = nil
 	}
 	return s.subscriptionRepo.Update(ctx, id, updates)
 }
@@ -279,93 +463,186 @@ func (s *Service) GetActivePlanQuota(ctx context.Context, userID int64, now time
 	if plan == nil {
 		return nil, false, ErrPlanNotFound
 	}
-	quotaType, included := resolveQuota(plan)
-	if quotaType == "" || included <= 0 {
+	allowances := resolveAllowances(plan)
+	if len(allowances) == 0 {
 		return nil, false, nil
 	}
-	periodStart, periodEnd := resolveUsagePeriod(subscription.StartAt, subscription.EndAt, plan.ResetIntervalDays, now)
-	usageItem, err := s.usageRepo.GetBySubscriptionPeriod(ctx, subscription.ID, periodStart, periodEnd)
+	periodStart, periodEnd := subscriptionPeriod(subscription, plan.ResetIntervalDays, now)
+	usageItems, err := s.usageRepo.ListBySubscriptionPeriod(ctx, subscription.ID, periodStart, periodEnd)
 	if err != nil {
 		return nil, false, err
 	}
-	var used int64
-	if usageItem != nil {
-		if quotaType == "token" {
-			used = usageItem.UsedTokens
-		} else {
-			used = usageItem.UsedRequests
+	if needsRolloverStart(plan, usageItems) {
+		if usageItems, err = s.startRolloverPeriod(ctx, subscription, plan, periodStart, periodEnd); err != nil {
+			return nil, false, err
 		}
 	}
-	remaining := included - used
-	if remaining < 0 {
-		remaining = 0
+	used := make(map[int64]model.PlanUsage, len(usageItems))
+	for _, item := range usageItems {
+		used[item.AllowanceID] = item
+	}
+	for i := range allowances {
+		item := &allowances[i]
+		if usageItem, ok := used[item.AllowanceID]; ok {
+			item.UsedTokens = usageItem.UsedTokens
+			item.UsedRequests = usageItem.UsedRequests
+			item.Rollover = rolloverQuota(usageItem, now)
+		}
+		rolloverTokens, rolloverRequests := item.Rollover.remaining()
+		item.RemainingTokens = max(item.IncludedTokens-item.UsedTokens, 0) + rolloverTokens
+		item.RemainingRequests = max(item.IncludedRequests-item.UsedRequests, 0) + rolloverRequests
+	}
+	multiplier := plan.OverageMultiplier
+	if multiplier <= 0 {
+		multiplier = money.OneRate
 	}
 	return &ActivePlanQuota{
-		PlanID:         plan.ID,
-		SubscriptionID: subscription.ID,
-		Type:           quotaType,
-		Included:       included,
-		Used:           used,
-		Remaining:      remaining,
-		PeriodStart:    periodStart,
-		PeriodEnd:      periodEnd,
+		PlanID:            plan.ID,
+		PlanName:          plan.Name,
+		SubscriptionID:    subscription.ID,
+		OverageMultiplier: multiplier,
+		QuotaEnforcement:  normalizeQuotaEnforcement(plan.QuotaEnforcement),
+		Allowances:        allowances,
+		PeriodStart:       periodStart,
+		PeriodEnd:         periodEnd,
 	}, true, nil
 }
 
+// Match 返回请求应消耗的额度：先按模型名匹配，再按模型能力匹配，最后使用通用额度；都不匹配时返回 nil。
+func (q *ActivePlanQuota) Match(modelName string, capabilities []string) *AllowanceQuota {
+	if q == nil {
+		return nil
+	}
+	modelName = strings.TrimSpace(modelName)
+	for i := range q.Allowances {
+		item := &q.Allowances[i]
+		if item.Scope == AllowanceScopeModel && modelName != "" && strings.EqualFold(item.Match, modelName) {
+			return item
+		}
+	}
+	for i := range q.Allowances {
+		item := &q.Allowances[i]
+		if item.Scope != AllowanceScopeCapability {
+			continue
+		}
+		for _, capability := range capabilities {
+			if strings.EqualFold(item.Match, strings.TrimSpace(capability)) {
+				return item
+			}
+		}
+	}
+	for i := range q.Allowances {
+		if q.Allowances[i].AllowanceID == 0 {
+			return &q.Allowances[i]
+		}
+	}
+	return nil
+}
+
+// QuotaUsage 是一次请求的用量及用于匹配限定额度的模型信息。
+type QuotaUsage struct {
+	Model        string
+	Capabilities []string
+	Tokens       int64
+	Requests     int64
+}
+
 type QuotaApplyResult struct {
 	Applied     bool
-	Type        string
-	Included    int64
-	UsedBefore  int64
-	UsedAfter   int64
-	Overage     int64
-	Remaining   int64
-	PeriodStart time.Time
-	PeriodEnd   *time.Time
+	AllowanceID int64
+	Tokens      int64
+	Requests    int64
+	// RolloverTokens/RolloverRequests 是其中由结转额度承担的部分。
+	RolloverTokens    int64
+	RolloverRequests  int64
+	TokenOverage      int64
+	RequestOverage    int64
+	OverageMultiplier money.Rate
+	PeriodStart       time.Time
+	PeriodEnd         *time.Time
+}
+
+// BillableCost 把按模型价格计算的 cost 折算为额度外应付的部分：请求次数超额时整次计费，
+// 否则按超出 token 额度的比例计费，再乘以套餐的超额倍率；未使用套餐额度时原样返回。
+func (r *QuotaApplyResult) BillableCost(cost money.Amount) money.Amount {
+	if r == nil || !r.Applied {
+		return cost
+	}
+	switch {
+	case r.RequestOverage > 0:
+	case r.TokenOverage > 0 && r.Tokens > 0:
+		cost = cost.MulDiv(min(r.TokenOverage, r.Tokens), r.Tokens, money.RoundHalfUp)
+	default:
+		return 0
+	}
+	return cost.Convert(r.OverageMultiplier, money.RoundHalfUp)
 }
 
-func (s *Service) ApplyQuota(ctx context.Context, userID int64, now time.Time, tokenUnits int64, requestUnits int64) (*QuotaApplyResult, error) {
+// ApplyQuota 把一次请求的用量记入匹配的额度，只记录该额度计量的维度；先消耗未失效的结转额度，再计入本周期额度。
+func (s *Service) ApplyQuota(ctx context.Context, userID int64, now time.Time, input QuotaUsage) (*QuotaApplyResult, error) {
 	quota, ok, err := s.GetActivePlanQuota(ctx, userID, now)
 	if err != nil || !ok || quota == nil {
 		return &QuotaApplyResult{Applied: false}, err
 	}
-	var units int64
-	if quota.Type == "token" {
-		units = tokenUnits
-	} else {
-		units = requestUnits
-	}
-	if units < 0 {
-		units = 0
-	}
-	usedBefore := quota.Used
-	remainingBefore := quota.Remaining
-	overage := int64(0)
-	if units > remainingBefore {
-		overage = units - remainingBefore
-	}
-	usedAfter := usedBefore + units
-	remainingAfter := quota.Included - usedAfter
-	if remainingAfter < 0 {
-		remainingAfter = 0
-	}
-	if units > 0 {
-		_, err = s.usageRepo.AddUsage(ctx, quota.SubscriptionID, userID, quota.PeriodStart, quota.PeriodEnd, applyTokenDelta(quota.Type, units), applyRequestDelta(quota.Type, units))
+	allowance := quota.Match(input.Model, input.Capabilities)
+	if allowance == nil {
+		return &QuotaApplyResult{Applied: false}, nil
+	}
+	result := &QuotaApplyResult{
+		Applied:           true,
+		AllowanceID:       allowance.AllowanceID,
+		Tokens:            max(input.Tokens, 0),
+		Requests:          max(input.Requests, 0),
+		OverageMultiplier: quota.OverageMultiplier,
+		PeriodStart:       quota.PeriodStart,
+		PeriodEnd:         quota.PeriodEnd,
+	}
+	// 在事务内锁定用量行后再按行内的最新用量拆分结转与超额，并发请求不会按同一剩余额度判断。
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		usageTx := s.usageRepo.WithTx(tx)
+		current, err := usageTx.LockUsage(ctx, quota.SubscriptionID, allowance.AllowanceID, userID, quota.PeriodStart, quota.PeriodEnd)
 		if err != nil {
-			return nil, err
+			return err
 		}
+		var tokenDelta, requestDelta int64
+		rolloverTokens, rolloverRequests := rolloverQuota(*current, now).remaining()
+		if allowance.IncludedTokens > 0 {
+			result.RolloverTokens = min(result.Tokens, rolloverTokens)
+			tokenDelta = result.Tokens - result.RolloverTokens
+		}
+		if allowance.IncludedRequests > 0 {
+			result.RolloverRequests = min(result.Requests, rolloverRequests)
+			requestDelta = result.Requests - result.RolloverRequests
+		}
+		if result.RolloverTokens > 0 || result.RolloverRequests > 0 {
+			if err := usageTx.AddRolloverUsage(ctx, quota.SubscriptionID, allowance.AllowanceID, quota.PeriodStart, quota.PeriodEnd, result.RolloverTokens, result.RolloverRequests); err != nil {
+				return err
+			}
+		}
+		updated := current
+		if tokenDelta > 0 || requestDelta > 0 {
+			if updated, err = usageTx.AddUsage(ctx, quota.SubscriptionID, allowance.AllowanceID, userID, quota.PeriodStart, quota.PeriodEnd, tokenDelta, requestDelta); err != nil {
+				return err
+			}
+		}
+		// 超额为本次累加使已用量超出包含额度的部分，按累加后的行计算。
+		if allowance.IncludedTokens > 0 {
+			result.TokenOverage = overage(updated.UsedTokens, tokenDelta, allowance.IncludedTokens)
+		}
+		if allowance.IncludedRequests > 0 {
+			result.RequestOverage = overage(updated.UsedRequests, requestDelta, allowance.IncludedRequests)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return &QuotaApplyResult{
-		Applied:     true,
-		Type:        quota.Type,
-		Included:    quota.Included,
-		UsedBefore:  usedBefore,
-		UsedAfter:   usedAfter,
-		Overage:     overage,
-		Remaining:   remainingAfter,
-		PeriodStart: quota.PeriodStart,
-		PeriodEnd:   quota.PeriodEnd,
-	}, nil
+	return result, nil
+}
+
+// overage 返回用量从 used-delta 增加到 used 时超出 included 的部分。
+func overage(used, delta, included int64) int64 {
+	return max(used-included, 0) - max(used-delta-included, 0)
 }
 
 func normalizeStatus(value string) string {
@@ -381,6 +658,18 @@ func normalizeStatus(value string) string {
 	}
 }
 
+// normalizeQuotaEnforcement 返回规范化的处理方式，空值为 overage，无法识别时返回空串。
+func normalizeQuotaEnforcement(value string) string {
+	switch value = strings.ToLower(strings.TrimSpace(value)); value {
+	case "":
+		return QuotaEnforcementOverage
+	case QuotaEnforcementBlock, QuotaEnforcementOverage:
+		return value
+	default:
+		return ""
+	}
+}
+
 func normalizeResetInterval(value int) int {
 	if value == 0 {
 		return 30
@@ -391,6 +680,16 @@ func normalizeResetInterval(value int) int {
 	return value
 }
 
+// isValidRollover 要求结转上限不为负，结转有效天数为 0 到 365 天。
+func isValidRollover(tokens, requests int64, days int) bool {
+	return tokens >= 0 && requests >= 0 && days >= 0 && days <= 365
+}
+
+// isValidGracePeriod 限制宽限期为 0 到 90 天。
+func isValidGracePeriod(days int) bool {
+	return days >= 0 && days <= 90
+}
+
 func normalizeCurrency(value string) string {
 	value = strings.TrimSpace(value)
 	if value == "" {
@@ -399,27 +698,72 @@ func normalizeCurrency(value string) string {
 	return strings.ToUpper(value)
 }
 
-func isValidQuota(tokens, requests int64) bool {
-	if tokens > 0 && requests > 0 {
+// isValidQuota 要求额度不为负，且通用额度或限定额度至少有一项。
+func isValidQuota(tokens, requests int64, allowances int) bool {
+	if tokens < 0 || requests < 0 {
 		return false
 	}
-	if tokens <= 0 && requests <= 0 {
-		return false
+	return tokens > 0 || requests > 0 || allowances > 0
+}
+
+// normalizeAllowances 校验限定额度，同一范围下的匹配值不能重复。
+func normalizeAllowances(items []AllowanceInput) ([]model.PlanAllowance, error) {
+	result := make([]model.PlanAllowance, 0, len(items))
+	seen := map[string]struct{}{}
+	for _, item := range items {
+		scope := strings.ToLower(strings.TrimSpace(item.Scope))
+		match := strings.TrimSpace(item.Match)
+		if scope == AllowanceScopeCapability {
+			match = strings.ToLower(match)
+		}
+		if (scope != AllowanceScopeModel && scope != AllowanceScopeCapability) || match == "" {
+			return nil, ErrInvalidPlanAllowance
+		}
+		if item.IncludedTokens < 0 || item.IncludedRequests < 0 || (item.IncludedTokens == 0 && item.IncludedRequests == 0) {
+			return nil, ErrInvalidPlanAllowance
+		}
+		if !isValidRollover(item.RolloverTokens, item.RolloverRequests, 0) {
+			return nil, ErrInvalidPlanRollover
+		}
+		key := scope + ":" + strings.ToLower(match)
+		if _, ok := seen[key]; ok {
+			return nil, ErrInvalidPlanAllowance
+		}
+		seen[key] = struct{}{}
+		result = append(result, model.PlanAllowance{
+			Scope:            scope,
+			Match:            match,
+			IncludedTokens:   item.IncludedTokens,
+			IncludedRequests: item.IncludedRequests,
+			RolloverTokens:   item.RolloverTokens,
+			RolloverRequests: item.RolloverRequests,
+		})
 	}
-	return true
+	return result, nil
 }
 
-func resolveQuota(plan *model.Plan) (string, int64) {
+// resolveAllowances 返回套餐的全部额度，设置了通用额度时排在最前。
+func resolveAllowances(plan *model.Plan) []AllowanceQuota {
 	if plan == nil {
-		return "", 0
-	}
-	if plan.IncludedTokens > 0 && plan.IncludedRequests == 0 {
-		return "token", plan.IncludedTokens
-	}
-	if plan.IncludedRequests > 0 && plan.IncludedTokens == 0 {
-		return "request", plan.IncludedRequests
-	}
-	return "", 0
+		return nil
+	}
+	result := make([]AllowanceQuota, 0, len(plan.Allowances)+1)
+	if plan.IncludedTokens > 0 || plan.IncludedRequests > 0 {
+		result = append(result, AllowanceQuota{
+			IncludedTokens:   max(plan.IncludedTokens, 0),
+			IncludedRequests: max(plan.IncludedRequests, 0),
+		})
+	}
+	for _, item := range plan.Allowances {
+		result = append(result, AllowanceQuota{
+			AllowanceID:      item.ID,
+			Scope:            item.Scope,
+			Match:            item.Match,
+			IncludedTokens:   item.IncludedTokens,
+			IncludedRequests: item.IncludedRequests,
+		})
+	}
+	return result
 }
 
 func resolveUsagePeriod(start time.Time, end *time.Time, intervalDays int, now time.Time) (time.Time, *time.Time) {
@@ -444,6 +788,33 @@ func resolveUsagePeriod(start time.Time, end *time.Time, intervalDays int, now t
 	return periodStart, &periodEnd
 }
 
+// subscriptionPeriod 返回订阅在 now 所在的用量周期。自助订阅的周期以续费日 EndAt 为锚点向前划分，
+// 升级后从中途开始的首个周期较短，之后与续费周期对齐；管理员创建的订阅从 StartAt 向后划分。
+// 宽限期内 now 已过 EndAt，仍返回 EndAt 前的最后一个周期。
+func subscriptionPeriod(subscription *model.PlanSubscription, intervalDays int, now time.Time) (time.Time, *time.Time) {
+	if subscription.EndAt != nil && !now.Before(*subscription.EndAt) {
+		now = subscription.EndAt.Add(-time.Nanosecond)
+	}
+	if subscription.Source != SubscriptionSourceWallet || subscription.EndAt == nil {
+		return resolveUsagePeriod(subscription.StartAt, subscription.EndAt, intervalDays, now)
+	}
+	intervalDays = normalizeResetInterval(intervalDays)
+	if intervalDays == 0 {
+		intervalDays = 30
+	}
+	startUTC := subscription.StartAt.UTC()
+	periodEnd := subscription.EndAt.UTC()
+	periodStart := periodEnd.AddDate(0, 0, -intervalDays)
+	for periodStart.After(now.UTC()) && periodStart.After(startUTC) {
+		periodEnd = periodStart
+		periodStart = periodEnd.AddDate(0, 0, -intervalDays)
+	}
+	if periodStart.Before(startUTC) {
+		periodStart = startUTC
+	}
+	return periodStart, &periodEnd
+}
+
 func clampPeriodEnd(periodEnd time.Time, end *time.Time) time.Time {
 	if end == nil {
 		return periodEnd
@@ -454,17 +825,3 @@ func clampPeriodEnd(periodEnd time.Time, end *time.Time) time.Time {
 	}
 	return periodEnd
 }
-
-func applyTokenDelta(quotaType string, units int64) int64 {
-	if quotaType == "token" {
-		return units
-	}
-	return 0
-}
-
-func applyRequestDelta(quotaType string, units int64) int64 {
-	if quotaType == "request" {
-		return units
-	}
-	return 0
-}
@@ -0,0 +1,203 @@
+package plan
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"deepspace-shared/model"
+	"deepspace-shared/money"
+
+	"gorm.io/gorm"
+)
+
+// SubscriptionView 是用户可见的订阅信息，Price/Currency 为每周期按钱包币种扣除的金额。
+type SubscriptionView struct {
+	ID         int64        `json:"id"`
+	PlanID     int64        `json:"plan_id"`
+	PlanName   string       `json:"plan_name"`
+	Status     string       `json:"status"`
+	Source     string       `json:"source"`
+	StartAt    time.Time    `json:"start_at"`
+	EndAt      *time.Time   `json:"end_at"`
+	AutoRenew  bool         `json:"auto_renew"`
+	Price      money.Amount `json:"price"`
+	Currency   string       `json:"currency"`
+	CanceledAt *time.Time   `json:"canceled_at"`
+	// GraceUntil 是套餐设置了宽限期时，到期后仍按生效处理的截止时间。
+	GraceUntil *time.Time `json:"grace_until,omitempty"`
+	// ScheduledPlanID 是已安排在 EndAt 生效的降级套餐，ScheduledPrice 为其每周期价格。
+	ScheduledPlanID   *int64       `json:"scheduled_plan_id"`
+	ScheduledPlanName string       `json:"scheduled_plan_name,omitempty"`
+	ScheduledPrice    money.Amount `json:"scheduled_price"`
+}
+
+// Subscribe 为组织订阅一个公开套餐，从组织钱包扣除首个周期的费用，周期长度为套餐的 ResetIntervalDays。
+// 扣款与订阅在同一事务内提交；组织已有生效订阅时返回 ErrActiveSubscriptionExists。
+func (s *Service) Subscribe(ctx context.Context, orgID, planID int64, autoRenew bool) (*SubscriptionView, error) {
+	if orgID <= 0 || planID <= 0 {
+		return nil, ErrPlanNotFound
+	}
+	plan, err := s.planRepo.GetByID(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, ErrPlanNotFound
+	}
+	if strings.ToLower(strings.TrimSpace(plan.Status)) != "active" {
+		return nil, ErrPlanUnavailable
+	}
+
+	now := time.Now().UTC()
+	endAt := now.AddDate(0, 0, normalizeResetInterval(plan.ResetIntervalDays))
+	var result *model.PlanSubscription
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		billingTx := s.billing.WithTx(tx)
+		subscriptionTx := s.subscriptionRepo.WithTx(tx)
+		// 先锁定钱包，串行化同一组织的并发订阅。
+		wallet, err := billingTx.LockWallet(ctx, orgID)
+		if err != nil {
+			return err
+		}
+		existing, err := subscriptionTx.GetActiveByOrg(ctx, orgID, now)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return ErrActiveSubscriptionExists
+		}
+
+		item := &model.PlanSubscription{
+			UserID:    orgID,
+			PlanID:    plan.ID,
+			Status:    "active",
+			StartAt:   now,
+			EndAt:     &endAt,
+			Source:    SubscriptionSourceWallet,
+			AutoRenew: autoRenew,
+			Currency:  wallet.Currency,
+		}
+		if err := subscriptionTx.Create(ctx, item); err != nil {
+			return err
+		}
+		if plan.Price > 0 {
+			charged, err := billingTx.Charge(ctx, orgID, plan.Price, plan.Currency, SubscriptionRefID(item.ID, now), map[string]any{
+				"source":          "plan_subscription",
+				"subscription_id": item.ID,
+				"plan_id":         plan.ID,
+				"period_start":    now,
+				"period_end":      endAt,
+			})
+			if err != nil {
+				return err
+			}
+			item.Price = charged.Transaction.Amount
+			item.Currency = charged.Transaction.Currency
+			if _, err := subscriptionTx.Update(ctx, item.ID, map[string]any{
+				"price":    item.Price,
+				"currency": item.Currency,
+			}); err != nil {
+				return err
+			}
+		}
+		result = item
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subscriptionView(result, plan), nil
+}
+
+// GetSubscriptionView 返回组织当前生效的订阅，没有时返回 nil。
+func (s *Service) GetSubscriptionView(ctx context.Context, orgID int64) (*SubscriptionView, error) {
+	item, err := s.subscriptionRepo.GetActiveByOrg(ctx, orgID, time.Now().UTC())
+	if err != nil || item == nil {
+		return nil, err
+	}
+	return s.loadSubscriptionView(ctx, item)
+}
+
+// CancelSubscription 关闭自动续费，订阅保持生效到当前周期结束。
+func (s *Service) CancelSubscription(ctx context.Context, orgID int64) (*SubscriptionView, error) {
+	now := time.Now().UTC()
+	return s.setAutoRenew(ctx, orgID, map[string]any{"auto_renew": false, "canceled_at": now})
+}
+
+// ResumeSubscription 在周期结束前恢复自动续费。
+func (s *Service) ResumeSubscription(ctx context.Context, orgID int64) (*SubscriptionView, error) {
+	return s.setAutoRenew(ctx, orgID, map[string]any{"auto_renew": true, "canceled_at": nil})
+}
+
+func (s *Service) setAutoRenew(ctx context.Context, orgID int64, updates map[string]any) (*SubscriptionView, error) {
+	item, err := s.subscriptionRepo.GetActiveByOrg(ctx, orgID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+	if item.Source != SubscriptionSourceWallet || item.EndAt == nil {
+		return nil, ErrNotSelfService
+	}
+	updated, err := s.subscriptionRepo.Update(ctx, item.ID, updates)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+	return s.loadSubscriptionView(ctx, updated)
+}
+
+// SubscriptionRefID 返回订阅某个周期扣款流水的 ref_id，Worker 续费使用相同格式保证幂等。
+func SubscriptionRefID(subscriptionID int64, periodStart time.Time) string {
+	return fmt.Sprintf("subscription:%d:%d", subscriptionID, periodStart.UTC().Unix())
+}
+
+// loadSubscriptionView 查询订阅及已安排降级的套餐名称。
+func (s *Service) loadSubscriptionView(ctx context.Context, item *model.PlanSubscription) (*SubscriptionView, error) {
+	plan, err := s.planRepo.GetByID(ctx, item.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	view := subscriptionView(item, plan)
+	if item.ScheduledPlanID != nil {
+		scheduled, err := s.planRepo.GetByID(ctx, *item.ScheduledPlanID)
+		if err != nil {
+			return nil, err
+		}
+		if scheduled != nil {
+			view.ScheduledPlanName = scheduled.Name
+		}
+	}
+	return view, nil
+}
+
+func subscriptionView(item *model.PlanSubscription, plan *model.Plan) *SubscriptionView {
+	view := &SubscriptionView{
+		ID:         item.ID,
+		PlanID:     item.PlanID,
+		Status:     item.Status,
+		Source:     item.Source,
+		StartAt:    item.StartAt,
+		EndAt:      item.EndAt,
+		AutoRenew:  item.AutoRenew,
+		Price:      item.Price,
+		Currency:   item.Currency,
+		CanceledAt: item.CanceledAt,
+
+		ScheduledPlanID: item.ScheduledPlanID,
+		ScheduledPrice:  item.ScheduledPrice,
+	}
+	if plan != nil {
+		view.PlanName = plan.Name
+		if item.EndAt != nil && plan.GracePeriodDays > 0 {
+			graceUntil := item.EndAt.AddDate(0, 0, plan.GracePeriodDays)
+			view.GraceUntil = &graceUntil
+		}
+	}
+	return view
+}
@@ -0,0 +1,239 @@
+package projectbudget
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"deepspace-shared/model"
+	"deepspace-shared/money"
+	"deepspace/internal/repo"
+	"deepspace/internal/service/billing"
+	"deepspace/internal/service/fx"
+	"deepspace/internal/service/usage"
+)
+
+var (
+	ErrProjectNotFound    = errors.New("project not found")
+	ErrBudgetNotFound     = errors.New("project budget not found")
+	ErrInvalidCycle       = errors.New("invalid budget cycle")
+	ErrInvalidAmount      = errors.New("invalid budget amount")
+	ErrInvalidCurrency    = errors.New("invalid budget currency")
+	ErrInvalidEnforcement = errors.New("invalid budget enforcement")
+)
+
+// 执行方式：hard 用完后拒绝请求，soft 只提示超额。
+const (
+	EnforcementHard = "hard"
+	EnforcementSoft = "soft"
+)
+
+const (
+	CycleDaily   = "daily"
+	CycleWeekly  = "weekly"
+	CycleMonthly = "monthly"
+)
+
+type Service struct {
+	repo     *repo.ProjectBudgetRepo
+	projects *repo.ProjectRepo
+	usage    *usage.Service
+	fx       *fx.Service
+	billing  *billing.Service
+}
+
+// New 创建项目预算服务；billingSvc 用于在未指定币种时取组织钱包的币种。
+func New(repo *repo.ProjectBudgetRepo, projects *repo.ProjectRepo, usageSvc *usage.Service, fxSvc *fx.Service, billingSvc *billing.Service) *Service {
+	return &Service{repo: repo, projects: projects, usage: usageSvc, fx: fxSvc, billing: billingSvc}
+}
+
+type SetInput struct {
+	Cycle       string
+	Amount      money.Amount
+	Currency    string
+	Enforcement string
+}
+
+// Status 是项目预算在当前周期的使用情况，Spent 为周期内项目全部成员的用量换算为预算币种后的合计。
+type Status struct {
+	ProjectID   int64        `json:"project_id"`
+	Cycle       string       `json:"cycle"`
+	Amount      money.Amount `json:"amount"`
+	Currency    string       `json:"currency"`
+	Enforcement string       `json:"enforcement"`
+	Spent       money.Amount `json:"spent"`
+	Remaining   money.Amount `json:"remaining"`
+	Exceeded    bool         `json:"exceeded"`
+	PeriodStart time.Time    `json:"period_start"`
+	PeriodEnd   time.Time    `json:"period_end"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// Blocks 表示预算已用完且为 hard 执行方式，请求应被拒绝。
+func (s *Status) Blocks() bool {
+	return s != nil && s.Exceeded && s.Enforcement == EnforcementHard
+}
+
+func (s *Service) Get(ctx context.Context, orgID, projectID int64) (*Status, error) {
+	if err := s.ensureProject(ctx, orgID, projectID); err != nil {
+		return nil, err
+	}
+	item, err := s.repo.Get(ctx, orgID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrBudgetNotFound
+	}
+	return s.status(ctx, item, time.Now().UTC())
+}
+
+// Set 创建或覆盖项目预算。币种为空时使用组织钱包的币种。
+func (s *Service) Set(ctx context.Context, orgID, projectID int64, input SetInput) (*Status, error) {
+	cycle := strings.ToLower(strings.TrimSpace(input.Cycle))
+	if cycle == "" {
+		cycle = CycleMonthly
+	}
+	if _, _, ok := CycleBounds(cycle, time.Now().UTC()); !ok {
+		return nil, ErrInvalidCycle
+	}
+	if input.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	enforcement := strings.ToLower(strings.TrimSpace(input.Enforcement))
+	if enforcement == "" {
+		enforcement = EnforcementHard
+	}
+	if enforcement != EnforcementHard && enforcement != EnforcementSoft {
+		return nil, ErrInvalidEnforcement
+	}
+	currency := ""
+	if strings.TrimSpace(input.Currency) != "" {
+		currency = fx.NormalizeCurrency(input.Currency)
+		if currency == "" {
+			return nil, ErrInvalidCurrency
+		}
+	}
+
+	if err := s.ensureProject(ctx, orgID, projectID); err != nil {
+		return nil, err
+	}
+	if currency == "" {
+		currency = "CNY"
+		if s.billing != nil {
+			wallet, err := s.billing.GetWallet(ctx, orgID)
+			if err != nil {
+				return nil, err
+			}
+			if wallet != nil && wallet.Currency != "" {
+				currency = wallet.Currency
+			}
+		}
+	}
+
+	item := &model.ProjectBudget{
+		UserID:      orgID,
+		ProjectID:   projectID,
+		Cycle:       cycle,
+		Amount:      input.Amount,
+		Currency:    currency,
+		Enforcement: enforcement,
+	}
+	if err := s.repo.Upsert(ctx, item); err != nil {
+		return nil, err
+	}
+	saved, err := s.repo.Get(ctx, orgID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if saved == nil {
+		return nil, ErrBudgetNotFound
+	}
+	return s.status(ctx, saved, time.Now().UTC())
+}
+
+func (s *Service) Delete(ctx context.Context, orgID, projectID int64) error {
+	if err := s.ensureProject(ctx, orgID, projectID); err != nil {
+		return err
+	}
+	deleted, err := s.repo.Delete(ctx, orgID, projectID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrBudgetNotFound
+	}
+	return nil
+}
+
+// Check 返回项目预算在 now 所在周期的状态，项目未设置预算时返回 nil。
+func (s *Service) Check(ctx context.Context, orgID, projectID int64, now time.Time) (*Status, error) {
+	item, err := s.repo.Get(ctx, orgID, projectID)
+	if err != nil || item == nil {
+		return nil, err
+	}
+	return s.status(ctx, item, now)
+}
+
+func (s *Service) status(ctx context.Context, item *model.ProjectBudget, now time.Time) (*Status, error) {
+	start, end, ok := CycleBounds(item.Cycle, now)
+	if !ok {
+		return nil, ErrInvalidCycle
+	}
+	projectID := item.ProjectID
+	agg, err := s.usage.AggregateSince(ctx, usage.AggregateInput{
+		OrgID:     item.UserID,
+		ProjectID: &projectID,
+		Start:     &start,
+	})
+	if err != nil {
+		return nil, err
+	}
+	spent, err := s.fx.Sum(ctx, agg.Costs, item.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return &Status{
+		ProjectID:   item.ProjectID,
+		Cycle:       item.Cycle,
+		Amount:      item.Amount,
+		Currency:    item.Currency,
+		Enforcement: item.Enforcement,
+		Spent:       spent,
+		Remaining:   max(item.Amount-spent, 0),
+		Exceeded:    spent >= item.Amount,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		UpdatedAt:   item.UpdatedAt,
+	}, nil
+}
+
+func (s *Service) ensureProject(ctx context.Context, orgID, projectID int64) error {
+	project, err := s.projects.Get(ctx, orgID, projectID)
+	if err != nil {
+		return err
+	}
+	if project == nil {
+		return ErrProjectNotFound
+	}
+	return nil
+}
+
+// CycleBounds 返回 now 所在周期的起止时间（UTC），周从周一开始。
+func CycleBounds(cycle string, now time.Time) (time.Time, time.Time, bool) {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch strings.ToLower(strings.TrimSpace(cycle)) {
+	case CycleDaily:
+		return day, day.AddDate(0, 0, 1), true
+	case CycleWeekly:
+		start := day.AddDate(0, 0, -((int(now.Weekday()) + 6) % 7))
+		return start, start.AddDate(0, 0, 7), true
+	case CycleMonthly:
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0), true
+	default:
+		return time.Time{}, time.Time{}, false
+	}
+}
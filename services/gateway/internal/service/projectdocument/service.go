@@ -6,7 +6,7 @@ import (
 	"errors"
 	"strings"
 
-	"deepspace/internal/model"
+	"deepspace-shared/model"
 	"deepspace/internal/repo"
 )
 
@@ -5,7 +5,8 @@ import (
 	"errors"
 	"strings"
 
-	"deepspace/internal/model"
+	"deepspace-shared/model"
+	"deepspace-shared/money"
 	"deepspace/internal/repo"
 )
 
@@ -87,14 +88,14 @@ type IPRuleUpdateInput struct {
 type BudgetCapInput struct {
 	PolicyID int64
 	Cycle    string
-	MaxCost  float64
+	MaxCost  money.Amount
 	Currency string
 	Status   string
 }
 
 type BudgetCapUpdateInput struct {
 	Cycle    *string
-	MaxCost  *float64
+	MaxCost  *money.Amount
 	Currency *string
 	Status   *string
 }
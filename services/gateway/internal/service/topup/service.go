@@ -0,0 +1,170 @@
+package topup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"deepspace-shared/model"
+	"deepspace-shared/money"
+	"deepspace/internal/integrations/payment"
+	"deepspace/internal/repo"
+	"deepspace/internal/service/billing"
+)
+
+var (
+	ErrProviderNotConfigured = errors.New("payment provider not configured")
+	ErrInvalidAmount         = errors.New("invalid top-up amount")
+	ErrOrderNotFound         = errors.New("top-up order not found")
+	ErrEventMismatch         = errors.New("payment event does not match order")
+)
+
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+type Options struct {
+	MinAmount money.Amount
+	MaxAmount money.Amount
+}
+
+type Service struct {
+	repo     *repo.TopUpOrderRepo
+	billing  *billing.Service
+	provider payment.Provider
+	opts     Options
+}
+
+// New 创建自助充值服务；provider 为 nil 时下单与回调均返回 ErrProviderNotConfigured。
+func New(repo *repo.TopUpOrderRepo, billingSvc *billing.Service, provider payment.Provider, opts Options) *Service {
+	return &Service{repo: repo, billing: billingSvc, provider: provider, opts: opts}
+}
+
+type ListInput struct {
+	UserID   *int64
+	Status   string
+	Page     int
+	PageSize int
+}
+
+// CreateCheckout 以钱包币种创建充值订单并返回支付渠道的收银台地址。
+func (s *Service) CreateCheckout(ctx context.Context, userID int64, amount money.Amount) (*model.TopUpOrder, error) {
+	if s == nil || s.provider == nil {
+		return nil, ErrProviderNotConfigured
+	}
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if s.opts.MinAmount > 0 && amount < s.opts.MinAmount {
+		return nil, ErrInvalidAmount
+	}
+	if s.opts.MaxAmount > 0 && amount > s.opts.MaxAmount {
+		return nil, ErrInvalidAmount
+	}
+
+	wallet, err := s.billing.GetWallet(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	checkout, err := s.provider.CreateCheckout(ctx, payment.CheckoutRequest{
+		UserID:   userID,
+		Amount:   amount,
+		Currency: wallet.Currency,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	item := &model.TopUpOrder{
+		UserID:      userID,
+		Provider:    s.provider.Name(),
+		PaymentID:   checkout.PaymentID,
+		Amount:      amount,
+		Currency:    wallet.Currency,
+		Status:      StatusPending,
+		CheckoutURL: checkout.CheckoutURL,
+	}
+	if err := s.repo.Create(ctx, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// HandleWebhook 校验回调签名并处理支付结果。支付成功时以渠道 payment id 作为 ref_id 调用 TopUp，
+// 重复投递命中同一 ref 的幂等记录，不会重复入账。
+func (s *Service) HandleWebhook(ctx context.Context, providerName string, header http.Header, body []byte) (*model.TopUpOrder, error) {
+	if s == nil || s.provider == nil || !strings.EqualFold(strings.TrimSpace(providerName), s.provider.Name()) {
+		return nil, ErrProviderNotConfigured
+	}
+	event, err := s.provider.ParseWebhook(header, body)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.repo.GetByPayment(ctx, s.provider.Name(), event.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, ErrOrderNotFound
+	}
+
+	switch event.Status {
+	case payment.StatusSucceeded:
+		if event.Amount != order.Amount || (event.Currency != "" && event.Currency != order.Currency) {
+			return nil, ErrEventMismatch
+		}
+		_, err := s.billing.TopUp(ctx, order.UserID, order.Amount, order.Currency, order.PaymentID, map[string]any{
+			"source":   "payment",
+			"provider": order.Provider,
+			"order_id": order.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := s.repo.MarkPaid(ctx, order.ID, time.Now().UTC()); err != nil {
+			return nil, err
+		}
+	case payment.StatusFailed:
+		if err := s.repo.MarkFailed(ctx, order.ID); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.GetByID(ctx, order.ID)
+}
+
+// Get 返回属于 userID 的订单，不存在或不属于该用户时返回 nil。
+func (s *Service) Get(ctx context.Context, userID, id int64) (*model.TopUpOrder, error) {
+	item, err := s.repo.GetByID(ctx, id)
+	if err != nil || item == nil {
+		return nil, err
+	}
+	if item.UserID != userID {
+		return nil, nil
+	}
+	return item, nil
+}
+
+func (s *Service) List(ctx context.Context, input ListInput) ([]model.TopUpOrder, int64, error) {
+	page := input.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := input.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	return s.repo.List(ctx, repo.TopUpOrderFilter{
+		UserID: input.UserID,
+		Status: strings.TrimSpace(input.Status),
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	})
+}
@@ -0,0 +1,232 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"deepspace-shared/money"
+	"deepspace/internal/repo"
+)
+
+const (
+	GranularityHour = "hour"
+	GranularityDay  = "day"
+)
+
+var (
+	ErrInvalidGranularity = errors.New("invalid granularity")
+	ErrInvalidGroupBy     = errors.New("invalid group_by")
+	ErrInvalidTimeRange   = errors.New("invalid time range")
+)
+
+// analyticsDimensions 是可分组的维度及其在 usage_rollups 中的列名。
+var analyticsDimensions = map[string]string{
+	"org":     "org_id",
+	"user":    "user_id",
+	"project": "project_id",
+	"model":   "model",
+}
+
+// 各粒度未指定起止时间时的默认跨度，以及单次查询允许的最大跨度。
+var (
+	analyticsDefaultSpan = map[string]time.Duration{
+		GranularityHour: 24 * time.Hour,
+		GranularityDay:  30 * 24 * time.Hour,
+	}
+	analyticsMaxSpan = map[string]time.Duration{
+		GranularityHour: 31 * 24 * time.Hour,
+		GranularityDay:  366 * 24 * time.Hour,
+	}
+)
+
+// AnalyticsInput 的 OrgID 为 nil 时查询全部组织（仅管理员）；GroupBy 取值为 org、user、project、model。
+type AnalyticsInput struct {
+	Granularity string
+	OrgID       *int64
+	UserID      *int64
+	ProjectID   *int64
+	Model       string
+	Start       *time.Time
+	End         *time.Time
+	GroupBy     []string
+	Limit       int
+}
+
+// AnalyticsPoint 是一个时间桶（或整个时间范围）内某组维度的用量；未参与分组的维度不返回。
+// project_id 为 0 表示不属于任何项目；平均耗时只统计记录了耗时的请求，没有时为 null。
+type AnalyticsPoint struct {
+	BucketStart      *time.Time   `json:"bucket_start,omitempty"`
+	OrgID            *int64       `json:"org_id,omitempty"`
+	UserID           *int64       `json:"user_id,omitempty"`
+	ProjectID        *int64       `json:"project_id,omitempty"`
+	Model            *string      `json:"model,omitempty"`
+	Currency         string       `json:"currency"`
+	Requests         int64        `json:"requests"`
+	FailedRequests   int64        `json:"failed_requests"`
+	PromptTokens     int64        `json:"prompt_tokens"`
+	CompletionTokens int64        `json:"completion_tokens"`
+	TotalTokens      int64        `json:"total_tokens"`
+	Cost             money.Amount `json:"cost"`
+	AvgLatencyMs     *int64       `json:"avg_latency_ms"`
+	AvgFirstTokenMs  *int64       `json:"avg_first_token_ms"`
+}
+
+// Analytics 中 Series 按时间升序，没有用量的时间桶不返回；Totals 为整个范围的汇总，按费用倒序取前 Limit 组。
+type Analytics struct {
+	Granularity string           `json:"granularity"`
+	Start       time.Time        `json:"start"`
+	End         time.Time        `json:"end"`
+	GroupBy     []string         `json:"group_by"`
+	Series      []AnalyticsPoint `json:"series"`
+	Totals      []AnalyticsPoint `json:"totals"`
+}
+
+// Analytics 从 usage_rollups 读取按小时或按天（UTC）的用量时间序列，起止时间按时间桶对齐。
+// 聚合由 Worker 定时累加，最近几分钟的用量可能尚未计入。
+func (s *Service) Analytics(ctx context.Context, in AnalyticsInput) (*Analytics, error) {
+	granularity := strings.ToLower(strings.TrimSpace(in.Granularity))
+	if granularity == "" {
+		granularity = GranularityDay
+	}
+	if _, ok := analyticsMaxSpan[granularity]; !ok {
+		return nil, ErrInvalidGranularity
+	}
+
+	groupBy := make([]string, 0, len(in.GroupBy))
+	columns := make([]string, 0, len(in.GroupBy))
+	seen := map[string]struct{}{}
+	for _, item := range in.GroupBy {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		column, ok := analyticsDimensions[item]
+		if !ok {
+			return nil, ErrInvalidGroupBy
+		}
+		if _, ok := seen[item]; ok {
+			continue
+		}
+		seen[item] = struct{}{}
+		groupBy = append(groupBy, item)
+		columns = append(columns, column)
+	}
+
+	var end time.Time
+	if in.End != nil {
+		end = ceilBucket(*in.End, granularity)
+	} else {
+		end = ceilBucket(time.Now(), granularity)
+	}
+	var start time.Time
+	if in.Start != nil {
+		start = truncateBucket(*in.Start, granularity)
+	} else {
+		start = truncateBucket(end.Add(-analyticsDefaultSpan[granularity]), granularity)
+	}
+	if !start.Before(end) || end.Sub(start) > analyticsMaxSpan[granularity] {
+		return nil, ErrInvalidTimeRange
+	}
+
+	limit := in.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	filter := repo.UsageRollupFilter{
+		Granularity: granularity,
+		OrgID:       in.OrgID,
+		UserID:      in.UserID,
+		ProjectID:   in.ProjectID,
+		Model:       strings.TrimSpace(in.Model),
+		Start:       start,
+		End:         end,
+		GroupBy:     columns,
+	}
+	series, err := s.rollupRepo.Series(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.rollupRepo.Totals(ctx, filter, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Analytics{
+		Granularity: granularity,
+		Start:       start,
+		End:         end,
+		GroupBy:     groupBy,
+		Series:      make([]AnalyticsPoint, 0, len(series)),
+		Totals:      make([]AnalyticsPoint, 0, len(totals)),
+	}
+	for _, row := range series {
+		point := analyticsPoint(row, seen)
+		bucket := row.BucketStart.UTC()
+		point.BucketStart = &bucket
+		result.Series = append(result.Series, point)
+	}
+	for _, row := range totals {
+		result.Totals = append(result.Totals, analyticsPoint(row, seen))
+	}
+	return result, nil
+}
+
+func analyticsPoint(row repo.UsageRollupRow, dimensions map[string]struct{}) AnalyticsPoint {
+	point := AnalyticsPoint{
+		Currency:         row.Currency,
+		Requests:         row.Requests,
+		FailedRequests:   row.FailedRequests,
+		PromptTokens:     row.PromptTokens,
+		CompletionTokens: row.CompletionTokens,
+		TotalTokens:      row.TotalTokens,
+		Cost:             row.Cost,
+	}
+	if _, ok := dimensions["org"]; ok {
+		point.OrgID = &row.OrgID
+	}
+	if _, ok := dimensions["user"]; ok {
+		point.UserID = &row.UserID
+	}
+	if _, ok := dimensions["project"]; ok {
+		point.ProjectID = &row.ProjectID
+	}
+	if _, ok := dimensions["model"]; ok {
+		point.Model = &row.Model
+	}
+	if row.LatencyCount > 0 {
+		avg := row.LatencyMsSum / row.LatencyCount
+		point.AvgLatencyMs = &avg
+	}
+	if row.FirstTokenCount > 0 {
+		avg := row.FirstTokenMsSum / row.FirstTokenCount
+		point.AvgFirstTokenMs = &avg
+	}
+	return point
+}
+
+// truncateBucket 返回 t 所在时间桶（UTC）的开始时间。
+func truncateBucket(t time.Time, granularity string) time.Time {
+	t = t.UTC()
+	if granularity == GranularityHour {
+		return t.Truncate(time.Hour)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ceilBucket 把 t 向后对齐到时间桶边界，t 本身在边界上时不变。
+func ceilBucket(t time.Time, granularity string) time.Time {
+	start := truncateBucket(t, granularity)
+	if start.Equal(t) {
+		return start
+	}
+	if granularity == GranularityHour {
+		return start.Add(time.Hour)
+	}
+	return start.AddDate(0, 0, 1)
+}
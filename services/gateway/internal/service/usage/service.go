@@ -5,20 +5,23 @@ import (
 	"strings"
 	"time"
 
-	"deepspace/internal/model"
+	"deepspace-shared/model"
+	"deepspace-shared/money"
 	"deepspace/internal/repo"
 )
 
 type Service struct {
-	repo *repo.UsageRepo
+	repo       *repo.UsageRepo
+	rollupRepo *repo.UsageRollupRepo
 }
 
-func New(repo *repo.UsageRepo) *Service {
-	return &Service{repo: repo}
+func New(repo *repo.UsageRepo, rollupRepo *repo.UsageRollupRepo) *Service {
+	return &Service{repo: repo, rollupRepo: rollupRepo}
 }
 
+// ListInput 按组织列出用量，组织内所有成员的请求都会列出。
 type ListInput struct {
-	UserID   int64
+	OrgID    int64
 	Start    *time.Time
 	End      *time.Time
 	Page     int
@@ -27,14 +30,17 @@ type ListInput struct {
 
 type AdminListInput struct {
 	UserID   *int64
+	OrgID    *int64
 	Start    *time.Time
 	End      *time.Time
 	Page     int
 	PageSize int
 }
 
+// AggregateInput 的 OrgID 非 0 时汇总整个组织的用量（如项目预算），否则只汇总 UserID 本人的用量。
 type AggregateInput struct {
 	UserID    int64
+	OrgID     int64
 	ProjectID *int64
 	Start     *time.Time
 	End       *time.Time
@@ -42,15 +48,34 @@ type AggregateInput struct {
 
 type RecordInput struct {
 	UserID           int64
+	OrgID            int64
 	ProjectID        *int64
 	Model            string
 	PromptTokens     int
 	CompletionTokens int
 	TotalTokens      int
-	Cost             float64
+	Cost             money.Amount
+	Currency         string
 	TraceID          string
+	Path             string
+	StatusCode       int
+	Error            string
+	Streamed         bool
+	Upstream         string
+	Latency          time.Duration
+	FirstTokenDelay  time.Duration
+	AuthMethod       string
+	UserAgent        string
+	BillingError     string
 }
 
+// 用量记录中错误信息与 User-Agent 的最大长度。
+const (
+	maxRecordErrorLength     = 500
+	maxRecordUserAgentLength = 255
+)
+
+// Record 写入一条用量记录；FirstTokenDelay 为 0（非流式或未读到响应体）时不记录首 token 耗时。
 func (s *Service) Record(ctx context.Context, in RecordInput) error {
 	modelName := strings.TrimSpace(in.Model)
 	if modelName == "" {
@@ -59,13 +84,28 @@ func (s *Service) Record(ctx context.Context, in RecordInput) error {
 
 	rec := model.UsageRecord{
 		UserID:           in.UserID,
+		OrgID:            in.OrgID,
 		ProjectID:        in.ProjectID,
 		Model:            modelName,
 		PromptTokens:     in.PromptTokens,
 		CompletionTokens: in.CompletionTokens,
 		TotalTokens:      in.TotalTokens,
 		Cost:             in.Cost,
+		Currency:         strings.ToUpper(strings.TrimSpace(in.Currency)),
 		TraceID:          in.TraceID,
+		Path:             in.Path,
+		StatusCode:       in.StatusCode,
+		Error:            truncate(strings.TrimSpace(in.Error), maxRecordErrorLength),
+		Streamed:         in.Streamed,
+		Upstream:         in.Upstream,
+		LatencyMs:        in.Latency.Milliseconds(),
+		AuthMethod:       in.AuthMethod,
+		UserAgent:        truncate(strings.TrimSpace(in.UserAgent), maxRecordUserAgentLength),
+		BillingError:     truncate(strings.TrimSpace(in.BillingError), maxRecordErrorLength),
+	}
+	if in.FirstTokenDelay > 0 {
+		ms := in.FirstTokenDelay.Milliseconds()
+		rec.FirstTokenMs = &ms
 	}
 
 	return s.repo.Create(ctx, rec)
@@ -85,7 +125,7 @@ func (s *Service) List(ctx context.Context, in ListInput) ([]model.UsageRecord,
 	}
 
 	records, err := s.repo.List(ctx, repo.UsageListFilter{
-		UserID: in.UserID,
+		OrgID:  in.OrgID,
 		Start:  in.Start,
 		End:    in.End,
 		Limit:  pageSize,
@@ -95,7 +135,7 @@ func (s *Service) List(ctx context.Context, in ListInput) ([]model.UsageRecord,
 		return nil, 0, err
 	}
 
-	total, err := s.repo.Count(ctx, in.UserID, in.Start, in.End)
+	total, err := s.repo.Count(ctx, in.OrgID, in.Start, in.End)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -103,8 +143,9 @@ func (s *Service) List(ctx context.Context, in ListInput) ([]model.UsageRecord,
 	return records, total, nil
 }
 
-func (s *Service) SumCost(ctx context.Context, userID int64, start, end *time.Time) (float64, error) {
-	return s.repo.SumCost(ctx, userID, start, end)
+// SumCostByCurrency 按币种汇总组织在时间范围内的费用。
+func (s *Service) SumCostByCurrency(ctx context.Context, orgID int64, start, end *time.Time) (map[string]money.Amount, error) {
+	return s.repo.SumCostByCurrency(ctx, orgID, start, end)
 }
 
 func (s *Service) ListAdmin(ctx context.Context, in AdminListInput) ([]model.UsageRecord, int64, error) {
@@ -122,6 +163,7 @@ func (s *Service) ListAdmin(ctx context.Context, in AdminListInput) ([]model.Usa
 
 	filter := repo.AdminUsageListFilter{
 		UserID: in.UserID,
+		OrgID:  in.OrgID,
 		Start:  in.Start,
 		End:    in.End,
 		Limit:  pageSize,
@@ -143,6 +185,7 @@ func (s *Service) ListAdmin(ctx context.Context, in AdminListInput) ([]model.Usa
 func (s *Service) CountByScope(ctx context.Context, in AggregateInput) (int64, error) {
 	return s.repo.CountByScope(ctx, repo.UsageAggregateFilter{
 		UserID:    in.UserID,
+		OrgID:     in.OrgID,
 		ProjectID: in.ProjectID,
 		Start:     in.Start,
 		End:       in.End,
@@ -152,8 +195,31 @@ func (s *Service) CountByScope(ctx context.Context, in AggregateInput) (int64, e
 func (s *Service) AggregateByScope(ctx context.Context, in AggregateInput) (repo.UsageAggregate, error) {
 	return s.repo.AggregateByScope(ctx, repo.UsageAggregateFilter{
 		UserID:    in.UserID,
+		OrgID:     in.OrgID,
 		ProjectID: in.ProjectID,
 		Start:     in.Start,
 		End:       in.End,
 	})
 }
+
+// AggregateSince 汇总 in.Start 至今的用量，Start 须按天（UTC）对齐；读取预聚合加上尚未聚合的最新记录，不扫描整个周期的 usage_records。
+func (s *Service) AggregateSince(ctx context.Context, in AggregateInput) (repo.UsageAggregate, error) {
+	if in.Start == nil || !truncateBucket(*in.Start, GranularityDay).Equal(*in.Start) {
+		return repo.UsageAggregate{}, ErrInvalidTimeRange
+	}
+	return s.rollupRepo.AggregateSince(ctx, repo.UsageAggregateFilter{
+		UserID:    in.UserID,
+		OrgID:     in.OrgID,
+		ProjectID: in.ProjectID,
+		Start:     in.Start,
+	})
+}
+
+// truncate 按字符截断 s，避免超长内容写入用量记录。
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit])
+}
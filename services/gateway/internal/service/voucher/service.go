@@ -0,0 +1,341 @@
+package voucher
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"deepspace-shared/model"
+	"deepspace-shared/money"
+	"deepspace/internal/repo"
+	"deepspace/internal/service/billing"
+	"deepspace/internal/service/fx"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCode           = errors.New("invalid voucher code")
+	ErrInvalidAmount         = errors.New("invalid voucher amount")
+	ErrInvalidCurrency       = errors.New("invalid voucher currency")
+	ErrInvalidMaxRedemptions = errors.New("invalid voucher max redemptions")
+	ErrInvalidCount          = errors.New("invalid voucher count")
+	ErrInvalidStatus         = errors.New("invalid voucher status")
+	ErrInvalidExpiry         = errors.New("invalid voucher expiry")
+	ErrCodeExists            = errors.New("voucher code exists")
+	ErrVoucherNotFound       = errors.New("voucher not found")
+	ErrVoucherUnavailable    = errors.New("voucher disabled or expired")
+	ErrVoucherExhausted      = errors.New("voucher fully redeemed")
+	ErrAlreadyRedeemed       = errors.New("voucher already redeemed")
+)
+
+const (
+	StatusActive   = "active"
+	StatusDisabled = "disabled"
+
+	RedemptionActive  = model.RedemptionStatusActive
+	RedemptionExpired = model.RedemptionStatusExpired
+
+	// maxBatchCount 限制单次批量生成的兑换码数量。
+	maxBatchCount = 1000
+	// codeAlphabet 去掉了易混淆的 0/O/1/I。
+	codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+type Service struct {
+	db      *gorm.DB
+	repo    *repo.VoucherRepo
+	billing *billing.Service
+}
+
+func New(db *gorm.DB, repo *repo.VoucherRepo, billingSvc *billing.Service) *Service {
+	return &Service{db: db, repo: repo, billing: billingSvc}
+}
+
+// CreateInput 中 Code 为空时自动生成；Count 大于 1 时批量生成 Count 个参数相同的兑换码。
+type CreateInput struct {
+	Code           string
+	Count          int
+	Amount         money.Amount
+	Currency       string
+	MaxRedemptions int
+	CreditDays     int
+	ExpiresAt      *time.Time
+	Note           string
+	CreatedBy      *int64
+}
+
+type UpdateInput struct {
+	Status         *string
+	MaxRedemptions *int
+	ExpiresAt      *time.Time
+	Note           *string
+}
+
+type ListInput struct {
+	Status   string
+	Code     string
+	Page     int
+	PageSize int
+}
+
+type RedemptionListInput struct {
+	VoucherID *int64
+	UserID    *int64
+	Status    string
+	Page      int
+	PageSize  int
+}
+
+type RedeemResult struct {
+	Redemption  *model.VoucherRedemption `json:"redemption"`
+	Wallet      *model.Wallet            `json:"wallet"`
+	Transaction *model.Transaction       `json:"transaction"`
+}
+
+func (s *Service) Create(ctx context.Context, input CreateInput) ([]model.Voucher, error) {
+	if input.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	currency := fx.NormalizeCurrency(input.Currency)
+	if currency == "" {
+		return nil, ErrInvalidCurrency
+	}
+	maxRedemptions := input.MaxRedemptions
+	if maxRedemptions == 0 {
+		maxRedemptions = 1
+	}
+	if maxRedemptions < 0 {
+		return nil, ErrInvalidMaxRedemptions
+	}
+	if input.CreditDays < 0 {
+		return nil, ErrInvalidExpiry
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidExpiry
+	}
+	count := input.Count
+	if count == 0 {
+		count = 1
+	}
+	code := normalizeCode(input.Code)
+	if count < 1 || count > maxBatchCount || (count > 1 && code != "") {
+		return nil, ErrInvalidCount
+	}
+	if input.Code != "" && code == "" {
+		return nil, ErrInvalidCode
+	}
+	if code != "" {
+		if _, total, err := s.repo.List(ctx, repo.VoucherFilter{Code: code, Limit: 1}); err != nil {
+			return nil, err
+		} else if total > 0 {
+			return nil, ErrCodeExists
+		}
+	}
+
+	items := make([]model.Voucher, 0, count)
+	for range count {
+		value := code
+		if value == "" {
+			generated, err := generateCode()
+			if err != nil {
+				return nil, err
+			}
+			value = generated
+		}
+		items = append(items, model.Voucher{
+			Code:           value,
+			Amount:         input.Amount,
+			Currency:       currency,
+			MaxRedemptions: maxRedemptions,
+			CreditDays:     input.CreditDays,
+			ExpiresAt:      input.ExpiresAt,
+			Status:         StatusActive,
+			Note:           strings.TrimSpace(input.Note),
+			CreatedBy:      input.CreatedBy,
+		})
+	}
+	if err := s.repo.CreateBatch(ctx, items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *Service) Update(ctx context.Context, id int64, input UpdateInput) (*model.Voucher, error) {
+	item, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrVoucherNotFound
+	}
+	updates := map[string]any{}
+	if input.Status != nil {
+		status := strings.ToLower(strings.TrimSpace(*input.Status))
+		if status != StatusActive && status != StatusDisabled {
+			return nil, ErrInvalidStatus
+		}
+		updates["status"] = status
+	}
+	if input.MaxRedemptions != nil {
+		if *input.MaxRedemptions < item.RedeemedCount || *input.MaxRedemptions <= 0 {
+			return nil, ErrInvalidMaxRedemptions
+		}
+		updates["max_redemptions"] = *input.MaxRedemptions
+	}
+	if input.ExpiresAt != nil {
+		updates["expires_at"] = *input.ExpiresAt
+	}
+	if input.Note != nil {
+		updates["note"] = strings.TrimSpace(*input.Note)
+	}
+	return s.repo.Update(ctx, id, updates)
+}
+
+func (s *Service) List(ctx context.Context, input ListInput) ([]model.Voucher, int64, error) {
+	page, pageSize := normalizePage(input.Page, input.PageSize)
+	return s.repo.List(ctx, repo.VoucherFilter{
+		Status: strings.ToLower(strings.TrimSpace(input.Status)),
+		Code:   normalizeCode(input.Code),
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	})
+}
+
+func (s *Service) ListRedemptions(ctx context.Context, input RedemptionListInput) ([]model.VoucherRedemption, int64, error) {
+	page, pageSize := normalizePage(input.Page, input.PageSize)
+	return s.repo.ListRedemptions(ctx, repo.VoucherRedemptionFilter{
+		VoucherID: input.VoucherID,
+		UserID:    input.UserID,
+		Status:    strings.ToLower(strings.TrimSpace(input.Status)),
+		Limit:     pageSize,
+		Offset:    (page - 1) * pageSize,
+	})
+}
+
+// Redeem 兑换代金券：锁定代金券行校验状态与次数，记录兑换并以 voucher 流水入账，三者在同一事务内提交。
+// 每个用户对同一代金券只能兑换一次；入账 ref_id 为 "voucher:<code>"。
+func (s *Service) Redeem(ctx context.Context, userID int64, code string) (*RedeemResult, error) {
+	code = normalizeCode(code)
+	if code == "" {
+		return nil, ErrInvalidCode
+	}
+
+	var result *RedeemResult
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
+		item, err := repoTx.GetByCodeForUpdate(ctx, code)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return ErrVoucherNotFound
+		}
+		now := time.Now().UTC()
+		if item.Status != StatusActive || (item.ExpiresAt != nil && !item.ExpiresAt.After(now)) {
+			return ErrVoucherUnavailable
+		}
+		existing, err := repoTx.GetRedemption(ctx, item.ID, userID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return ErrAlreadyRedeemed
+		}
+		if item.RedeemedCount >= item.MaxRedemptions {
+			return ErrVoucherExhausted
+		}
+
+		refID := "voucher:" + item.Code
+		credited, err := s.billing.WithTx(tx).Credit(ctx, userID, item.Amount, item.Currency, refID, map[string]any{
+			"source":     "voucher",
+			"voucher_id": item.ID,
+			"code":       item.Code,
+		})
+		if err != nil {
+			return err
+		}
+
+		redemption := &model.VoucherRedemption{
+			VoucherID: item.ID,
+			UserID:    userID,
+			RefID:     refID,
+			Amount:    credited.Transaction.Amount,
+			Currency:  credited.Transaction.Currency,
+			Status:    RedemptionActive,
+			ExpiresAt: creditExpiry(item, now),
+		}
+		if err := repoTx.CreateRedemption(ctx, redemption); err != nil {
+			return err
+		}
+		if err := repoTx.IncrementRedeemed(ctx, item.ID); err != nil {
+			return err
+		}
+		result = &RedeemResult{Redemption: redemption, Wallet: credited.Wallet, Transaction: credited.Transaction}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// creditExpiry 取代金券过期时间与兑换后 CreditDays 天中较早者，均未设置时额度不过期。
+func creditExpiry(item *model.Voucher, redeemedAt time.Time) *time.Time {
+	var expiry *time.Time
+	if item.ExpiresAt != nil {
+		value := item.ExpiresAt.UTC()
+		expiry = &value
+	}
+	if item.CreditDays > 0 {
+		value := redeemedAt.AddDate(0, 0, item.CreditDays)
+		if expiry == nil || value.Before(*expiry) {
+			expiry = &value
+		}
+	}
+	return expiry
+}
+
+func normalizeCode(value string) string {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if len(value) < 4 || len(value) > 64 {
+		return ""
+	}
+	for _, r := range value {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return ""
+		}
+	}
+	return value
+}
+
+// generateCode 生成 XXXX-XXXX-XXXX-XXXX 形式的随机兑换码。
+func generateCode() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for i, v := range buf {
+		if i > 0 && i%4 == 0 {
+			b.WriteByte('-')
+		}
+		b.WriteByte(codeAlphabet[int(v)%len(codeAlphabet)])
+	}
+	return b.String(), nil
+}
+
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	return page, pageSize
+}
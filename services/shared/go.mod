@@ -0,0 +1,18 @@
+module deepspace-shared
+
+go 1.25.6
+
+require (
+	github.com/google/uuid v1.6.0
+	gorm.io/datatypes v1.2.7
+)
+
+require (
+	filippo.io/edwards25519 v1.1.0 // indirect
+	github.com/go-sql-driver/mysql v1.8.1 // indirect
+	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/jinzhu/now v1.1.5 // indirect
+	golang.org/x/text v0.20.0 // indirect
+	gorm.io/driver/mysql v1.5.6 // indirect
+	gorm.io/gorm v1.30.0 // indirect
+)
@@ -0,0 +1,58 @@
+package model
+
+// 以下取值由 Gateway 与 Worker 共同读写，两个服务都引用这里的常量，不再各自维护副本。
+
+// BillingRef.State 的取值。
+const (
+	RefStateHeld              = "held"
+	RefStateCaptured          = "captured"
+	RefStatePartiallyCaptured = "partially_captured"
+	RefStateReleased          = "released"
+	RefStateExpired           = "expired"
+)
+
+// Transaction.Type 的取值。
+const (
+	TransactionTypeHold    = "hold"
+	TransactionTypeCapture = "capture"
+	TransactionTypeRelease = "release"
+	TransactionTypeTopUp   = "topup"
+	// TransactionTypeExpire 是 Worker 回收过期预扣时写入的流水类型。
+	TransactionTypeExpire = "expire"
+	// TransactionTypeVoucher/TransactionTypeVoucherExpire 是代金券入账与过期回收的流水类型；过期回收由 Worker 写入。
+	TransactionTypeVoucher       = "voucher"
+	TransactionTypeVoucherExpire = "voucher_expire"
+	// 退款与人工调账的流水类型，金额均为正数，方向由类型决定。
+	TransactionTypeRefund       = "refund"
+	TransactionTypeManualCredit = "manual_credit"
+	TransactionTypeManualDebit  = "manual_debit"
+	// TransactionTypeSubscription 是从钱包支付套餐订阅费用的流水类型，金额为正数，扣减余额。
+	TransactionTypeSubscription = "subscription"
+	// TransactionTypeAdjustment 是 Worker 对账修正钱包余额时写入的流水类型。
+	TransactionTypeAdjustment = "adjustment"
+)
+
+// Wallet.SuspendReason 的取值。credit_limit 由 Worker 催缴任务在额度用尽时设置，欠款结清后自动恢复；
+// admin 由管理员设置，只能由管理员恢复。
+const (
+	SuspendReasonCreditLimit = "credit_limit"
+	SuspendReasonAdmin       = "admin"
+)
+
+// PlanSubscription.Status 中由 Worker 维护的取值。
+const (
+	SubscriptionStatusActive  = "active"
+	SubscriptionStatusExpired = "expired"
+)
+
+// VoucherRedemption.Status 的取值。
+const (
+	RedemptionStatusActive  = "active"
+	RedemptionStatusExpired = "expired"
+)
+
+// InvoiceStatusIssued 是 Worker 生成账单后的状态，Gateway 只负责查询与下载。
+const InvoiceStatusIssued = "issued"
+
+// UsageRollupCursorName 是用量聚合游标的名称；项目预算据此读取尚未累加到聚合表的用量记录。
+const UsageRollupCursorName = "usage_rollups"
@@ -0,0 +1,579 @@
+package model
+
+import (
+	"time"
+
+	"deepspace-shared/money"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+type User struct {
+	ID           int64  `gorm:"primaryKey;autoIncrement"`
+	Email        string `gorm:"uniqueIndex"`
+	PasswordHash string
+	Role         string     `gorm:"default:user;index"` // admin, user, developer
+	Status       string     `gorm:"index"`
+	LastLoginAt  *time.Time `gorm:"index"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime"`
+
+	Profile *UserProfile `gorm:"foreignKey:UserID"`
+}
+
+type UserProfile struct {
+	UserID      int64 `gorm:"primaryKey"`
+	DisplayName *string
+	FullName    *string
+	Title       *string
+	AvatarURL   *string
+	Bio         *string
+	Phone       *string
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+type UserSettings struct {
+	UserID    int64     `gorm:"primaryKey"`
+	Theme     string    `gorm:"default:system"`
+	Locale    string    `gorm:"default:zh-CN"`
+	Timezone  string    `gorm:"default:Asia/Shanghai"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// Organization 是项目、知识库、钱包与套餐订阅的所有者。这些表沿用 user_id 列保存组织 ID；
+// 迁移时每个已有用户获得一个与用户 ID 相同的个人组织（Personal），原有数据无需改写。
+// BillingEmail 为空时账单与催缴邮件发送给 Owner。
+type Organization struct {
+	ID           int64 `gorm:"primaryKey;autoIncrement"`
+	Name         string
+	Personal     bool  `gorm:"default:false;index:idx_organizations_owner_personal,priority:2"`
+	OwnerID      int64 `gorm:"index:idx_organizations_owner_personal,priority:1"`
+	BillingEmail string
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+}
+
+// Membership 的 Role 为 owner、admin、member 或 billing。
+type Membership struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement"`
+	OrgID     int64     `gorm:"uniqueIndex:idx_memberships_org_user,priority:1"`
+	UserID    int64     `gorm:"uniqueIndex:idx_memberships_org_user,priority:2;index"`
+	Role      string    `gorm:"default:member"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+type Project struct {
+	ID          int64 `gorm:"primaryKey;autoIncrement"`
+	UserID      int64 `gorm:"index"`
+	Name        string
+	Type        string `gorm:"index"`
+	Description *string
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+type ProjectDocument struct {
+	ID        int64 `gorm:"primaryKey;autoIncrement"`
+	UserID    int64 `gorm:"index:idx_project_documents_user_project_updated,priority:1"`
+	ProjectID int64 `gorm:"index:idx_project_documents_user_project_updated,priority:2"`
+	Title     string
+	Content   string         `gorm:"type:text"`
+	Tags      datatypes.JSON `gorm:"type:jsonb"`
+	Status    string         `gorm:"index"`
+	CreatedAt time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime;index:idx_project_documents_user_project_updated,priority:3"`
+}
+
+type ProjectSkill struct {
+	ID          int64 `gorm:"primaryKey;autoIncrement"`
+	UserID      int64 `gorm:"index:idx_project_skills_user_project_updated,priority:1"`
+	ProjectID   int64 `gorm:"index:idx_project_skills_user_project_updated,priority:2"`
+	Name        string
+	Description *string
+	Prompt      *string   `gorm:"type:text"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime;index:idx_project_skills_user_project_updated,priority:3"`
+}
+
+type ProjectWorkflow struct {
+	ID          int64 `gorm:"primaryKey;autoIncrement"`
+	UserID      int64 `gorm:"index:idx_project_workflows_user_project_updated,priority:1"`
+	ProjectID   int64 `gorm:"index:idx_project_workflows_user_project_updated,priority:2"`
+	Name        string
+	Description *string
+	Steps       datatypes.JSON `gorm:"type:jsonb"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime;index:idx_project_workflows_user_project_updated,priority:3"`
+}
+
+// ProjectBudget 是项目每个周期可从组织钱包花费的额度，UserID 为组织 ID，每个项目最多一条。
+// Enforcement 为 hard 时用完后拒绝请求；为 soft 时只在响应头中提示已超额。
+type ProjectBudget struct {
+	ID          int64        `gorm:"primaryKey;autoIncrement"`
+	UserID      int64        `gorm:"index"`
+	ProjectID   int64        `gorm:"uniqueIndex"`
+	Cycle       string       `gorm:"default:monthly"`
+	Amount      money.Amount `gorm:"type:numeric(20,6)"`
+	Currency    string       `gorm:"default:CNY"`
+	Enforcement string       `gorm:"default:hard"`
+	CreatedAt   time.Time    `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time    `gorm:"autoUpdateTime"`
+}
+
+// Wallet 的 CreditLimit 大于 0 时为后付费账户，可用余额为 Balance + CreditLimit，余额最低可透支到 -CreditLimit。
+// SuspendedAt 不为空时停止新的预扣；SuspendReason 为 credit_limit（Worker 催缴自动停用）或 admin（管理员停用）。
+// DunningStage 记录已发送的催缴阶段：0 未催缴、1 已发额度预警、2 已因额度用尽停用。
+type Wallet struct {
+	UserID        int64        `gorm:"primaryKey"`
+	Balance       money.Amount `gorm:"type:numeric(20,6)"`
+	FrozenBalance money.Amount `gorm:"type:numeric(20,6)"`
+	Currency      string       `gorm:"default:CNY"`
+	CreditLimit   money.Amount `gorm:"type:numeric(20,6);default:0"`
+	SuspendedAt   *time.Time
+	SuspendReason string
+	DunningStage  int `gorm:"default:0"`
+	DunningAt     *time.Time
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+}
+
+// Transaction 的 Amount 以钱包币种记账；请求币种不同时，OriginalAmount/OriginalCurrency/FXRate 记录换算前金额与所用汇率。
+// ParentRefID 仅用于退款流水，指向被退款的扣款 ref_id。
+type Transaction struct {
+	ID               int64        `gorm:"primaryKey;autoIncrement"`
+	UserID           int64        `gorm:"uniqueIndex:idx_transactions_user_ref_type,priority:1;index:idx_transactions_user_parent_ref,priority:1"`
+	Type             string       `gorm:"uniqueIndex:idx_transactions_user_ref_type,priority:3"`
+	Amount           money.Amount `gorm:"type:numeric(20,6)"`
+	Currency         string       `gorm:"default:CNY"`
+	OriginalAmount   money.Amount `gorm:"type:numeric(20,6)"`
+	OriginalCurrency string
+	FXRate           money.Rate     `gorm:"type:numeric(20,10)"`
+	RefID            string         `gorm:"uniqueIndex:idx_transactions_user_ref_type,priority:2"`
+	ParentRefID      string         `gorm:"index:idx_transactions_user_parent_ref,priority:2"`
+	Metadata         datatypes.JSON `gorm:"type:jsonb"`
+	CreatedAt        time.Time      `gorm:"autoCreateTime"`
+}
+
+// BillingRef 记录一次预扣在 hold/capture/release 之间的生命周期。
+type BillingRef struct {
+	ID             int64        `gorm:"primaryKey;autoIncrement"`
+	UserID         int64        `gorm:"uniqueIndex:idx_billing_refs_user_ref,priority:1"`
+	RefID          string       `gorm:"uniqueIndex:idx_billing_refs_user_ref,priority:2"`
+	State          string       `gorm:"index:idx_billing_refs_state_created,priority:1"`
+	HeldAmount     money.Amount `gorm:"type:numeric(20,6)"`
+	CapturedAmount money.Amount `gorm:"type:numeric(20,6)"`
+	ReleasedAmount money.Amount `gorm:"type:numeric(20,6)"`
+	RefundedAmount money.Amount `gorm:"type:numeric(20,6)"`
+	CreatedAt      time.Time    `gorm:"autoCreateTime;index:idx_billing_refs_state_created,priority:2"`
+	UpdatedAt      time.Time    `gorm:"autoUpdateTime"`
+}
+
+// UsageRecord 的 UserID 为发起请求的成员，OrgID 为付费的组织。
+type UsageRecord struct {
+	ID               int64  `gorm:"primaryKey;autoIncrement"`
+	UserID           int64  `gorm:"index:idx_usage_records_user_created,priority:1;index:idx_usage_records_user_project,priority:1"`
+	OrgID            int64  `gorm:"index:idx_usage_records_org_created,priority:1"`
+	ProjectID        *int64 `gorm:"index:idx_usage_records_user_project,priority:2"`
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	Cost             money.Amount `gorm:"type:numeric(20,6)"`
+	Currency         string       `gorm:"default:CNY"`
+	TraceID          string       `gorm:"index:idx_usage_records_trace"`
+	CreatedAt        time.Time    `gorm:"autoCreateTime;index:idx_usage_records_user_created,priority:2;index:idx_usage_records_org_created,priority:2"`
+
+	// 请求元数据：Path 为不含查询串的 /v1 路径，StatusCode 为返回给客户端的状态码，失败请求在 Error 中记录原因且不计费。
+	// LatencyMs 为上游调用总耗时（含重试），FirstTokenMs 为流式响应读到首个字节的耗时；
+	// AuthMethod 为请求使用的凭证（cookie 或 bearer），UserAgent 标识调用的客户端。
+	// BillingError 不为空表示扣款失败，Cost 记为 0。
+	Path         string
+	StatusCode   int
+	Error        string
+	Streamed     bool
+	Upstream     string
+	LatencyMs    int64
+	FirstTokenMs *int64
+	AuthMethod   string
+	UserAgent    string
+	BillingError string
+}
+
+// UsageRollup 是按小时（hour）或按天（day，UTC）预聚合的用量，由 Worker 从 usage_records 增量累加。
+// ProjectID 为 0 表示请求不属于任何项目；费用按记录币种分别累计。
+// 耗时只统计记录了耗时的请求，平均值为 LatencyMsSum / LatencyCount 与 FirstTokenMsSum / FirstTokenCount。
+type UsageRollup struct {
+	ID               int64        `gorm:"primaryKey;autoIncrement"`
+	Granularity      string       `gorm:"uniqueIndex:idx_usage_rollups_key,priority:1;index:idx_usage_rollups_org_bucket,priority:1"`
+	BucketStart      time.Time    `gorm:"uniqueIndex:idx_usage_rollups_key,priority:2;index:idx_usage_rollups_org_bucket,priority:3"`
+	OrgID            int64        `gorm:"uniqueIndex:idx_usage_rollups_key,priority:3;index:idx_usage_rollups_org_bucket,priority:2"`
+	UserID           int64        `gorm:"uniqueIndex:idx_usage_rollups_key,priority:4"`
+	ProjectID        int64        `gorm:"uniqueIndex:idx_usage_rollups_key,priority:5"`
+	Model            string       `gorm:"uniqueIndex:idx_usage_rollups_key,priority:6"`
+	Currency         string       `gorm:"uniqueIndex:idx_usage_rollups_key,priority:7"`
+	Requests         int64        `gorm:"default:0"`
+	FailedRequests   int64        `gorm:"default:0"`
+	PromptTokens     int64        `gorm:"default:0"`
+	CompletionTokens int64        `gorm:"default:0"`
+	TotalTokens      int64        `gorm:"default:0"`
+	Cost             money.Amount `gorm:"type:numeric(20,6);default:0"`
+	LatencyMsSum     int64        `gorm:"default:0"`
+	LatencyCount     int64        `gorm:"default:0"`
+	FirstTokenMsSum  int64        `gorm:"default:0"`
+	FirstTokenCount  int64        `gorm:"default:0"`
+	UpdatedAt        time.Time    `gorm:"autoUpdateTime"`
+}
+
+// UsageRollupCursor 记录 Worker 已累加到 usage_rollups 的最大 usage_records.id。
+type UsageRollupCursor struct {
+	Name      string    `gorm:"primaryKey"`
+	LastID    int64     `gorm:"default:0"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+type Conversation struct {
+	ID        int64  `gorm:"primaryKey;autoIncrement"`
+	UserID    int64  `gorm:"index:idx_conversations_user_project_updated,priority:1"`
+	ProjectID *int64 `gorm:"index:idx_conversations_user_project_updated,priority:2"`
+	Title     *string
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime;index:idx_conversations_user_project_updated,priority:3"`
+}
+
+type Message struct {
+	ID             int64 `gorm:"primaryKey;autoIncrement"`
+	ConversationID int64 `gorm:"index:idx_messages_conversation_created,priority:1"`
+	Role           string
+	Content        string
+	Model          *string
+	TraceID        *string
+	CreatedAt      time.Time `gorm:"autoCreateTime;index:idx_messages_conversation_created,priority:2"`
+}
+
+type AuditLog struct {
+	ID            int64  `gorm:"primaryKey;autoIncrement"`
+	UserID        *int64 `gorm:"index:idx_audit_logs_user_created,priority:1"`
+	TraceID       string `gorm:"index:idx_audit_logs_trace"`
+	Action        string
+	RequestPath   *string
+	RequestMethod *string
+	StatusCode    *int
+	Metadata      datatypes.JSON `gorm:"type:jsonb"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime;index:idx_audit_logs_user_created,priority:2"`
+}
+
+type KnowledgeBase struct {
+	ID          int64 `gorm:"primaryKey;autoIncrement"`
+	UserID      int64 `gorm:"index:idx_knowledge_bases_user_scope_project_created,priority:1"`
+	ProjectID   *int64
+	Scope       string `gorm:"index:idx_knowledge_bases_user_scope_project_created,priority:2"`
+	Name        string
+	Description *string
+	CreatedAt   time.Time `gorm:"autoCreateTime;index:idx_knowledge_bases_user_scope_project_created,priority:4"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+type KnowledgeDocument struct {
+	ID              int64 `gorm:"primaryKey;autoIncrement"`
+	UserID          int64 `gorm:"index:idx_knowledge_documents_user_project,priority:1"`
+	ProjectID       *int64
+	KnowledgeBaseID int64 `gorm:"index:idx_knowledge_documents_kb_created,priority:1"`
+	FileName        string
+	ContentType     *string
+	SizeBytes       *int64
+	StoragePath     string
+	Status          string
+	Metadata        datatypes.JSON `gorm:"type:jsonb"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime;index:idx_knowledge_documents_kb_created,priority:2"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime"`
+}
+
+type Model struct {
+	ID           uuid.UUID      `gorm:"type:uuid;primaryKey"`
+	Name         string         `gorm:"uniqueIndex:idx_models_name_provider,priority:1"`
+	Provider     string         `gorm:"uniqueIndex:idx_models_name_provider,priority:2;index"`
+	PriceInput   money.Amount   `gorm:"type:numeric(20,6)"`
+	PriceOutput  money.Amount   `gorm:"type:numeric(20,6)"`
+	Currency     string         `gorm:"default:USD"`
+	Capabilities datatypes.JSON `gorm:"type:jsonb"`
+	Status       string         `gorm:"default:active;index"`
+	Metadata     datatypes.JSON `gorm:"type:jsonb"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time      `gorm:"autoUpdateTime"`
+}
+
+// Plan 的 IncludedTokens/IncludedRequests 是套餐通用额度，可同时设置，为 0 的一项不计量；
+// Allowances 中限定模型或能力的额度优先匹配。超出额度的部分按模型价格乘以 OverageMultiplier 从钱包扣费。
+// QuotaEnforcement 为 block 时额度用完后在调用上游前拒绝请求，为 overage 时转为钱包计费。
+// GracePeriodDays 是订阅到期后仍按生效处理的天数，宽限期内继续使用最后一个周期的额度。
+// RolloverTokens/RolloverRequests 是通用额度每周期最多结转到下一周期的未用量，为 0 时不结转；
+// 结转的额度在下一周期开始 RolloverDays 天后失效，RolloverDays 为 0 时在下一周期结束时失效。
+type Plan struct {
+	ID                int64 `gorm:"primaryKey;autoIncrement"`
+	Name              string
+	Status            string          `gorm:"default:active;index"`
+	IncludedTokens    int64           `gorm:"default:0"`
+	IncludedRequests  int64           `gorm:"default:0"`
+	ResetIntervalDays int             `gorm:"default:30"`
+	Price             money.Amount    `gorm:"type:numeric(20,6)"`
+	Currency          string          `gorm:"default:CNY"`
+	OverageMultiplier money.Rate      `gorm:"type:numeric(20,10);default:1"`
+	QuotaEnforcement  string          `gorm:"default:overage"`
+	GracePeriodDays   int             `gorm:"default:0"`
+	RolloverTokens    int64           `gorm:"default:0"`
+	RolloverRequests  int64           `gorm:"default:0"`
+	RolloverDays      int             `gorm:"default:0"`
+	Allowances        []PlanAllowance `gorm:"foreignKey:PlanID"`
+	CreatedAt         time.Time       `gorm:"autoCreateTime"`
+	UpdatedAt         time.Time       `gorm:"autoUpdateTime"`
+}
+
+// PlanAllowance 是套餐中限定模型（Scope 为 model，Match 为模型名）或能力（Scope 为 capability，Match 为能力名）的额度。
+// 命中的请求只消耗该额度，不占用套餐通用额度。RolloverTokens/RolloverRequests 是该额度每周期最多结转的未用量。
+type PlanAllowance struct {
+	ID               int64 `gorm:"primaryKey;autoIncrement"`
+	PlanID           int64 `gorm:"index"`
+	Scope            string
+	Match            string
+	IncludedTokens   int64     `gorm:"default:0"`
+	IncludedRequests int64     `gorm:"default:0"`
+	RolloverTokens   int64     `gorm:"default:0"`
+	RolloverRequests int64     `gorm:"default:0"`
+	CreatedAt        time.Time `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+}
+
+// PlanSubscription 的 UserID 为组织 ID。Source 为 admin 时由管理员创建，不收费；
+// 为 wallet 时由组织钱包付费，Price/Currency 是订阅时换算为钱包币种的每周期价格，续费按此价格扣款。
+// AutoRenew 为 true 时由 Worker 在 EndAt 到期时扣款并顺延一个 ResetIntervalDays 周期；
+// 取消后 AutoRenew 置为 false 并记录 CanceledAt，订阅在 EndAt 结束。
+// 升级时原订阅在当前时刻结束，新订阅从当前时刻开始、沿用原 EndAt，并通过 PreviousSubscriptionID 关联原订阅；
+// 降级记录在 ScheduledPlanID/ScheduledPrice 上，续费时以新套餐创建下一周期的订阅。
+// 不续费的订阅在 EndAt 加套餐宽限期后由 Worker 标记为 expired；ExpiryRemindedAt 记录到期提醒的发送时间，续费后清空。
+type PlanSubscription struct {
+	ID         int64        `gorm:"primaryKey;autoIncrement"`
+	UserID     int64        `gorm:"index:idx_plan_subscriptions_user_status,priority:1;index:idx_plan_subscriptions_user_start_end,priority:1"`
+	PlanID     int64        `gorm:"index"`
+	Status     string       `gorm:"default:active;index:idx_plan_subscriptions_user_status,priority:2"`
+	StartAt    time.Time    `gorm:"index:idx_plan_subscriptions_user_start_end,priority:2"`
+	EndAt      *time.Time   `gorm:"index:idx_plan_subscriptions_user_start_end,priority:3"`
+	Source     string       `gorm:"default:admin"`
+	AutoRenew  bool         `gorm:"default:false;index"`
+	Price      money.Amount `gorm:"type:numeric(20,6);default:0"`
+	Currency   string
+	CanceledAt *time.Time
+	// PreviousSubscriptionID 是升级或降级前的订阅。
+	PreviousSubscriptionID *int64 `gorm:"index"`
+	ScheduledPlanID        *int64
+	ExpiryRemindedAt       *time.Time
+	ScheduledPrice         money.Amount `gorm:"type:numeric(20,6);default:0"`
+	CreatedAt              time.Time    `gorm:"autoCreateTime"`
+	UpdatedAt              time.Time    `gorm:"autoUpdateTime"`
+}
+
+type PlanUsage struct {
+	ID             int64      `gorm:"primaryKey;autoIncrement"`
+	SubscriptionID int64      `gorm:"index:idx_plan_usages_subscription_period,priority:1;uniqueIndex:idx_plan_usages_subscription_allowance_period,priority:1"`
+	UserID         int64      `gorm:"index:idx_plan_usages_user_period,priority:1"`
+	PeriodStart    time.Time  `gorm:"index:idx_plan_usages_subscription_period,priority:2;index:idx_plan_usages_user_period,priority:2;uniqueIndex:idx_plan_usages_subscription_allowance_period,priority:3"`
+	PeriodEnd      *time.Time `gorm:"index:idx_plan_usages_subscription_period,priority:3;index:idx_plan_usages_user_period,priority:3"`
+	// ClosedAt 是 Worker 在周期结束或订阅失效后关闭该用量的时间。
+	ClosedAt *time.Time
+	// RolloverTokens/RolloverRequests 是周期开始时从上一周期结转的额度，优先于 Used* 消耗，RolloverUsed* 为已消耗的部分；
+	// RolloverExpiresAt 之后未用完的结转额度失效，为空时在周期结束时失效。
+	RolloverTokens       int64 `gorm:"default:0"`
+	RolloverRequests     int64 `gorm:"default:0"`
+	RolloverUsedTokens   int64 `gorm:"default:0"`
+	RolloverUsedRequests int64 `gorm:"default:0"`
+	RolloverExpiresAt    *time.Time
+	// AllowanceID 为 0 时是套餐通用额度的用量，否则为对应 PlanAllowance 的用量；每个订阅周期每项额度只有一条记录。
+	AllowanceID  int64     `gorm:"default:0;uniqueIndex:idx_plan_usages_subscription_allowance_period,priority:2"`
+	UsedTokens   int64     `gorm:"default:0"`
+	UsedRequests int64     `gorm:"default:0"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+}
+
+type RiskPolicy struct {
+	ID        int64 `gorm:"primaryKey;autoIncrement"`
+	Name      string
+	Scope     string    `gorm:"index"`
+	UserID    *int64    `gorm:"index"`
+	ProjectID *int64    `gorm:"index"`
+	Status    string    `gorm:"default:active;index"`
+	Priority  int       `gorm:"default:0;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+type RateLimit struct {
+	ID            int64     `gorm:"primaryKey;autoIncrement"`
+	PolicyID      int64     `gorm:"index"`
+	WindowSeconds int       `gorm:"index"`
+	MaxRequests   int       `gorm:"default:0"`
+	MaxTokens     int       `gorm:"default:0"`
+	Status        string    `gorm:"default:active;index"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+}
+
+type IPRule struct {
+	ID        int64  `gorm:"primaryKey;autoIncrement"`
+	PolicyID  int64  `gorm:"index"`
+	Type      string `gorm:"index"`
+	IP        *string
+	CIDR      *string
+	Status    string    `gorm:"default:active;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+type BudgetCap struct {
+	ID        int64        `gorm:"primaryKey;autoIncrement"`
+	PolicyID  int64        `gorm:"index"`
+	Cycle     string       `gorm:"index"`
+	MaxCost   money.Amount `gorm:"type:numeric(20,6)"`
+	Currency  string       `gorm:"default:CNY"`
+	Status    string       `gorm:"default:active;index"`
+	CreatedAt time.Time    `gorm:"autoCreateTime"`
+	UpdatedAt time.Time    `gorm:"autoUpdateTime"`
+}
+
+// FXRate 表示 1 单位 BaseCurrency 折合 Rate 单位 QuoteCurrency。
+type FXRate struct {
+	ID            int64      `gorm:"primaryKey;autoIncrement"`
+	BaseCurrency  string     `gorm:"uniqueIndex:idx_fx_rates_pair,priority:1"`
+	QuoteCurrency string     `gorm:"uniqueIndex:idx_fx_rates_pair,priority:2"`
+	Rate          money.Rate `gorm:"type:numeric(20,10)"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime"`
+}
+
+// TopUpOrder 记录一次自助充值；支付渠道回调成功后以 PaymentID 作为 ref_id 入账。
+type TopUpOrder struct {
+	ID          int64        `gorm:"primaryKey;autoIncrement"`
+	UserID      int64        `gorm:"index:idx_topup_orders_user_created,priority:1"`
+	Provider    string       `gorm:"uniqueIndex:idx_topup_orders_provider_payment,priority:1"`
+	PaymentID   string       `gorm:"uniqueIndex:idx_topup_orders_provider_payment,priority:2"`
+	Amount      money.Amount `gorm:"type:numeric(20,6)"`
+	Currency    string       `gorm:"default:CNY"`
+	Status      string       `gorm:"default:pending"`
+	CheckoutURL string
+	PaidAt      *time.Time
+	CreatedAt   time.Time `gorm:"autoCreateTime;index:idx_topup_orders_user_created,priority:2"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+// BillingAdjustment 是管理员发起的人工调账。金额超过审批阈值时先以 pending 保存，
+// 由另一名管理员批准后才入账；TransactionID 指向入账后的流水。
+type BillingAdjustment struct {
+	ID            int64 `gorm:"primaryKey;autoIncrement"`
+	UserID        int64 `gorm:"index"`
+	Type          string
+	Amount        money.Amount `gorm:"type:numeric(20,6)"`
+	Currency      string
+	Reason        string
+	RefID         string `gorm:"uniqueIndex"`
+	Status        string `gorm:"default:pending;index:idx_billing_adjustments_status_created,priority:1"`
+	RequestedBy   int64
+	ReviewedBy    *int64
+	ReviewedAt    *time.Time
+	ReviewNote    string
+	TransactionID *int64
+	CreatedAt     time.Time `gorm:"autoCreateTime;index:idx_billing_adjustments_status_created,priority:2"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+}
+
+// Voucher 是可兑换的赠送额度。ExpiresAt 之后不可兑换；兑换所得额度在 ExpiresAt
+// 或兑换后 CreditDays 天（取较早者）过期，由 Worker 回收未使用部分。
+type Voucher struct {
+	ID             int64        `gorm:"primaryKey;autoIncrement"`
+	Code           string       `gorm:"uniqueIndex"`
+	Amount         money.Amount `gorm:"type:numeric(20,6)"`
+	Currency       string       `gorm:"default:CNY"`
+	MaxRedemptions int          `gorm:"default:1"`
+	RedeemedCount  int          `gorm:"default:0"`
+	CreditDays     int
+	ExpiresAt      *time.Time
+	Status         string `gorm:"default:active"`
+	Note           string
+	CreatedBy      *int64
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+// VoucherRedemption 记录一次兑换；Amount 为入账的钱包币种金额，RefID 与 voucher 流水一致。
+type VoucherRedemption struct {
+	ID         int64 `gorm:"primaryKey;autoIncrement"`
+	VoucherID  int64 `gorm:"uniqueIndex:idx_voucher_redemptions_voucher_user,priority:1"`
+	UserID     int64 `gorm:"uniqueIndex:idx_voucher_redemptions_voucher_user,priority:2;index"`
+	RefID      string
+	Amount     money.Amount `gorm:"type:numeric(20,6)"`
+	Currency   string       `gorm:"default:CNY"`
+	Status     string       `gorm:"default:active;index:idx_voucher_redemptions_status_expires,priority:1"`
+	ExpiresAt  *time.Time   `gorm:"index:idx_voucher_redemptions_status_expires,priority:2"`
+	ClawedBack money.Amount `gorm:"type:numeric(20,6)"`
+	CreatedAt  time.Time    `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time    `gorm:"autoUpdateTime"`
+}
+
+// Invoice 是 Worker 按账期（自然月，UTC）汇总生成的月度账单，生成后不再修改。
+// Charged/Refunded/Credited/Debited 来自账期内的流水，Total = Charged - Refunded；
+// OpeningBalance/ClosingBalance 为按流水重放得到的账期起止可用余额；后付费账户期末余额为负时，
+// AmountDue 为需要支付的欠款。
+type Invoice struct {
+	ID             int64  `gorm:"primaryKey;autoIncrement"`
+	UserID         int64  `gorm:"uniqueIndex:idx_invoices_user_period,priority:1"`
+	Number         string `gorm:"uniqueIndex"`
+	Email          string
+	PeriodStart    time.Time `gorm:"uniqueIndex:idx_invoices_user_period,priority:2;index"`
+	PeriodEnd      time.Time
+	Currency       string `gorm:"default:CNY"`
+	Requests       int64
+	TotalTokens    int64
+	UsageAmount    money.Amount `gorm:"type:numeric(20,6)"`
+	Charged        money.Amount `gorm:"type:numeric(20,6)"`
+	Refunded       money.Amount `gorm:"type:numeric(20,6)"`
+	Credited       money.Amount `gorm:"type:numeric(20,6)"`
+	Debited        money.Amount `gorm:"type:numeric(20,6)"`
+	Total          money.Amount `gorm:"type:numeric(20,6)"`
+	OpeningBalance money.Amount `gorm:"type:numeric(20,6)"`
+	ClosingBalance money.Amount `gorm:"type:numeric(20,6)"`
+	AmountDue      money.Amount `gorm:"type:numeric(20,6)"`
+	Status         string       `gorm:"default:issued"`
+	IssuedAt       time.Time
+	NotifiedAt     *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+}
+
+// InvoiceLine 是账单按模型与项目汇总的用量明细，ProjectName 为生成时的项目名称快照。
+type InvoiceLine struct {
+	ID               int64 `gorm:"primaryKey;autoIncrement"`
+	InvoiceID        int64 `gorm:"index"`
+	Model            string
+	ProjectID        *int64
+	ProjectName      string
+	Requests         int64
+	PromptTokens     int64
+	CompletionTokens int64
+	TotalTokens      int64
+	Amount           money.Amount `gorm:"type:numeric(20,6)"`
+	Currency         string       `gorm:"default:CNY"`
+}
+
+type PipelineChain struct {
+	ID        int64 `gorm:"primaryKey;autoIncrement"`
+	Name      string
+	Scope     string         `gorm:"index:idx_pipeline_chains_stage_scope,priority:2"`
+	Stage     string         `gorm:"index:idx_pipeline_chains_stage_scope,priority:1"`
+	ProjectID *int64         `gorm:"index"`
+	Model     *string        `gorm:"index"`
+	Steps     datatypes.JSON `gorm:"type:jsonb"`
+	Status    string         `gorm:"default:active;index"`
+	Priority  int            `gorm:"default:0"`
+	CreatedAt time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime"`
+}
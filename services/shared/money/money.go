@@ -0,0 +1,266 @@
+// Package money 提供定点金额类型。金额以 10^-6 为最小单位保存为 int64，
+// 与数据库 numeric(20,6) 精度一致；运算全部在整数上完成，只在显式指定的位置取整：
+//   - 实际费用（按 token 结算、按比例折算）使用 RoundHalfUp；
+//   - 预扣估算使用 RoundUp，保证预扣不少于估算值；
+//   - 外部输入（JSON、请求头、数据库）超过 6 位小数的部分使用 RoundHalfUp。
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Scale 是金额保留的小数位数。
+const Scale = 6
+
+const unit = 1_000_000
+
+// Amount 是定点金额，值为金额乘以 10^6。可以直接使用 + - 与比较运算符。
+type Amount int64
+
+// Rounding 指定舍入方式。
+type Rounding int
+
+const (
+	// RoundHalfUp 四舍五入，.5 远离零。
+	RoundHalfUp Rounding = iota
+	// RoundUp 向正无穷取整。
+	RoundUp
+	// RoundDown 向负无穷取整。
+	RoundDown
+)
+
+var ErrInvalid = errors.New("invalid money amount")
+
+var (
+	bigUnit    = big.NewInt(unit)
+	bigMillion = big.NewInt(1_000_000)
+	bigMax     = big.NewInt(math.MaxInt64)
+	bigMin     = big.NewInt(math.MinInt64)
+)
+
+// FromMicros 由最小单位（10^-6）构造金额。
+func FromMicros(v int64) Amount {
+	return Amount(v)
+}
+
+// FromInt 由整数金额构造。
+func FromInt(v int64) Amount {
+	return Amount(v * unit)
+}
+
+// Micros 返回最小单位（10^-6）下的整数值。
+func (a Amount) Micros() int64 {
+	return int64(a)
+}
+
+// Parse 精确解析十进制字符串（支持指数形式），超过 6 位的小数四舍五入。
+func Parse(s string) (Amount, error) {
+	return ParseRound(s, RoundHalfUp)
+}
+
+// ParseRound 与 Parse 相同，但超过 6 位的小数按 mode 取整。
+func ParseRound(s string, mode Rounding) (Amount, error) {
+	v, err := parseScaled(s, bigUnit, mode)
+	return Amount(v), err
+}
+
+// MustParse 用于常量金额，解析失败时 panic。
+func MustParse(s string) Amount {
+	a, err := Parse(s)
+	if err != nil {
+		panic(fmt.Sprintf("money: %q: %v", s, err))
+	}
+	return a
+}
+
+// String 返回去掉末尾 0 的十进制表示，如 "12.5"、"0.000001"、"-3"。
+func (a Amount) String() string {
+	return formatScaled(int64(a), Scale)
+}
+
+// Float64 返回近似的浮点值，仅用于展示和日志，不得参与金额运算。
+func (a Amount) Float64() float64 {
+	return float64(a) / unit
+}
+
+// MulDiv 返回 a*num/den，按 mode 取整。den 必须大于 0。
+func (a Amount) MulDiv(num, den int64, mode Rounding) Amount {
+	if den <= 0 {
+		panic("money: MulDiv with non-positive denominator")
+	}
+	n := new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(num))
+	return clamp(divRound(n, big.NewInt(den), mode))
+}
+
+// TokenPrice 是一项按“每百万 token 单价”计费的用量。
+type TokenPrice struct {
+	Price  Amount
+	Tokens int64
+}
+
+// PerMillion 计算按每百万 token 单价计费的总费用：各项先精确累加，再按 mode 取整一次。
+func PerMillion(mode Rounding, items ...TokenPrice) Amount {
+	sum := new(big.Int)
+	for _, item := range items {
+		if item.Price == 0 || item.Tokens == 0 {
+			continue
+		}
+		sum.Add(sum, new(big.Int).Mul(big.NewInt(int64(item.Price)), big.NewInt(item.Tokens)))
+	}
+	return clamp(divRound(sum, bigMillion, mode))
+}
+
+// MarshalJSON 输出 JSON 数字字面量，不经过浮点转换。
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON 接受数字或数字字符串，null 保持原值。
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	v, ok, err := unmarshalScaled(data, bigUnit)
+	if err != nil || !ok {
+		return err
+	}
+	*a = Amount(v)
+	return nil
+}
+
+// Value 以十进制字符串写入数据库，由 numeric 列精确接收。
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan 读取 numeric/整数/字符串列；NULL 视为 0。
+func (a *Amount) Scan(src any) error {
+	v, err := scanScaled(src, bigUnit)
+	if err != nil {
+		return err
+	}
+	*a = Amount(v)
+	return nil
+}
+
+func parseScaled(s string, scale *big.Int, mode Rounding) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s, "/xXpP_") {
+		return 0, ErrInvalid
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, ErrInvalid
+	}
+	num := new(big.Int).Mul(r.Num(), scale)
+	v, ok := fromBig(divRound(num, r.Denom(), mode))
+	if !ok {
+		return 0, ErrInvalid
+	}
+	return int64(v), nil
+}
+
+func formatScaled(v int64, scale int) string {
+	neg := v < 0
+	var abs uint64
+	if neg {
+		abs = uint64(-(v + 1)) + 1
+	} else {
+		abs = uint64(v)
+	}
+	pow := uint64(1)
+	for range scale {
+		pow *= 10
+	}
+	intPart := abs / pow
+	frac := abs % pow
+
+	var b strings.Builder
+	if neg {
+		b.WriteByte('-')
+	}
+	b.WriteString(strconv.FormatUint(intPart, 10))
+	if frac != 0 {
+		digits := fmt.Sprintf("%0*d", scale, frac)
+		b.WriteByte('.')
+		b.WriteString(strings.TrimRight(digits, "0"))
+	}
+	return b.String()
+}
+
+func unmarshalScaled(data []byte, scale *big.Int) (int64, bool, error) {
+	s := strings.TrimSpace(string(data))
+	if s == "null" {
+		return 0, false, nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	v, err := parseScaled(s, scale, RoundHalfUp)
+	if err != nil {
+		return 0, false, fmt.Errorf("money: invalid amount %s", string(data))
+	}
+	return v, true, nil
+}
+
+func scanScaled(src any, scale *big.Int) (int64, error) {
+	switch v := src.(type) {
+	case nil:
+		return 0, nil
+	case string:
+		return parseScaled(v, scale, RoundHalfUp)
+	case []byte:
+		return parseScaled(string(v), scale, RoundHalfUp)
+	case int64:
+		return parseScaled(strconv.FormatInt(v, 10), scale, RoundHalfUp)
+	case float64:
+		return parseScaled(strconv.FormatFloat(v, 'f', -1, 64), scale, RoundHalfUp)
+	default:
+		return 0, fmt.Errorf("money: cannot scan %T", src)
+	}
+}
+
+// divRound 计算 num/den（den > 0）并按 mode 取整。
+func divRound(num, den *big.Int, mode Rounding) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	switch mode {
+	case RoundUp:
+		if r.Sign() > 0 {
+			q.Add(q, big.NewInt(1))
+		}
+	case RoundDown:
+		if r.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		}
+	default:
+		twice := new(big.Int).Abs(r)
+		twice.Lsh(twice, 1)
+		if twice.Cmp(den) >= 0 {
+			q.Add(q, big.NewInt(int64(r.Sign())))
+		}
+	}
+	return q
+}
+
+func fromBig(v *big.Int) (Amount, bool) {
+	if v.Cmp(bigMax) > 0 || v.Cmp(bigMin) < 0 {
+		return 0, false
+	}
+	return Amount(v.Int64()), true
+}
+
+func clamp(v *big.Int) Amount {
+	if v.Cmp(bigMax) > 0 {
+		return Amount(math.MaxInt64)
+	}
+	if v.Cmp(bigMin) < 0 {
+		return Amount(math.MinInt64)
+	}
+	return Amount(v.Int64())
+}
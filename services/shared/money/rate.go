@@ -0,0 +1,75 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"math/big"
+)
+
+// RateScale 是汇率保留的小数位数，与数据库 numeric(20,10) 一致。
+const RateScale = 10
+
+const rateUnit = 10_000_000_000
+
+var bigRateUnit = big.NewInt(rateUnit)
+
+var ErrInvalidRate = errors.New("invalid exchange rate")
+
+// Rate 是定点汇率，值为汇率乘以 10^10：1 单位源币种折合 Rate 单位目标币种。
+type Rate int64
+
+// OneRate 表示同币种换算。
+const OneRate = Rate(rateUnit)
+
+// ParseRate 精确解析汇率，超过 10 位的小数四舍五入；汇率必须为正。
+func ParseRate(s string) (Rate, error) {
+	v, err := parseScaled(s, bigRateUnit, RoundHalfUp)
+	if err != nil || v <= 0 {
+		return 0, ErrInvalidRate
+	}
+	return Rate(v), nil
+}
+
+func (r Rate) String() string {
+	return formatScaled(int64(r), RateScale)
+}
+
+// Inverse 返回反向汇率，四舍五入到 10 位小数。
+func (r Rate) Inverse() Rate {
+	if r <= 0 {
+		return 0
+	}
+	n := new(big.Int).Mul(bigRateUnit, bigRateUnit)
+	return Rate(clamp(divRound(n, big.NewInt(int64(r)), RoundHalfUp)))
+}
+
+// Convert 按汇率换算金额并按 mode 取整到 6 位小数。
+func (a Amount) Convert(rate Rate, mode Rounding) Amount {
+	return a.MulDiv(int64(rate), rateUnit, mode)
+}
+
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	v, ok, err := unmarshalScaled(data, bigRateUnit)
+	if err != nil || !ok {
+		return err
+	}
+	*r = Rate(v)
+	return nil
+}
+
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+func (r *Rate) Scan(src any) error {
+	v, err := scanScaled(src, bigRateUnit)
+	if err != nil {
+		return err
+	}
+	*r = Rate(v)
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"deepspace-worker/internal/config"
+	"deepspace-worker/internal/job"
+	"deepspace-worker/internal/pkg/db"
+)
+
+// invoice 手动为指定账期生成月度账单，用于补生成历史账期；已生成的账单不会重复生成，
+// 通知邮件由定时任务统一发送。
+func main() {
+	cfg := config.Load()
+
+	period := flag.String("period", job.InvoicePeriod(time.Now().AddDate(0, -1, 0)).Format("2006-01"), "账期，格式为 YYYY-MM")
+	flag.Parse()
+
+	start, err := time.Parse("2006-01", *period)
+	if err != nil {
+		log.Fatalf("账期格式不正确: %v", err)
+	}
+	if !start.Before(job.InvoicePeriod(time.Now())) {
+		log.Fatalf("账期 %s 尚未结束", *period)
+	}
+
+	dbConn, err := db.New(cfg)
+	if err != nil {
+		log.Fatalf("连接数据库失败: %v", err)
+	}
+
+	count, err := job.NewInvoiceGenerator(dbConn, job.InvoiceOptions{
+		BatchSize: cfg.InvoiceBatchSize,
+	}).Generate(context.Background(), start)
+	if err != nil {
+		log.Fatalf("生成账单失败: %v", err)
+	}
+	log.Printf("账期 %s 新生成账单 %d 份", *period, count)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"deepspace-worker/internal/config"
+	"deepspace-worker/internal/job"
+	"deepspace-worker/internal/pkg/db"
+)
+
+// reconcile 手动执行一次钱包对账，报告以 JSON 输出到标准输出。
+// 退出码：0 表示账目一致，1 表示执行失败，2 表示发现不一致。
+func main() {
+	cfg := config.Load()
+
+	fix := flag.Bool("fix", cfg.ReconcileFix, "为不一致的钱包写入 adjustment 流水")
+	userID := flag.Int64("user", 0, "只核对指定组织（钱包 user_id）")
+	window := flag.Duration("usage-window", cfg.ReconcileUsageWindow, "核对最近多长时间内的用量记录")
+	flag.Parse()
+
+	dbConn, err := db.New(cfg)
+	if err != nil {
+		log.Fatalf("连接数据库失败: %v", err)
+	}
+
+	opts := job.ReconcileOptions{
+		Fix:         *fix,
+		UsageWindow: *window,
+		BatchSize:   cfg.ReconcileBatchSize,
+	}
+	if *userID > 0 {
+		opts.UserID = userID
+	}
+
+	report, err := job.NewReconciler(dbConn, opts).Reconcile(context.Background())
+	if err != nil {
+		log.Fatalf("对账失败: %v", err)
+	}
+	report.Log()
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatalf("输出报告失败: %v", err)
+	}
+	if report.HasIssues() {
+		os.Exit(2)
+	}
+}
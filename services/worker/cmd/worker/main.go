@@ -8,19 +8,119 @@ import (
 	"time"
 
 	"deepspace-worker/internal/config"
+	"deepspace-worker/internal/job"
+	"deepspace-worker/internal/pkg/db"
 	"deepspace-worker/internal/service/email"
 
 	"github.com/redis/go-redis/v9"
 )
 
 func main() {
-	log.Println("启动邮件队列 Worker...")
+	log.Println("启动 Worker...")
 
 	cfg := config.Load()
 	if err := cfg.Validate(); err != nil {
 		log.Fatalf("配置校验失败: %v", err)
 	}
 
+	ctx := context.Background()
+	if cfg.JobsEnabled {
+		scheduler := newScheduler(cfg)
+		if !cfg.EmailEnabled {
+			scheduler.Run(ctx)
+			return
+		}
+		go scheduler.Run(ctx)
+	}
+
+	runEmailQueue(ctx, cfg)
+}
+
+func newScheduler(cfg *config.Config) *job.Scheduler {
+	dbConn, err := db.New(cfg)
+	if err != nil {
+		log.Fatalf("连接数据库失败: %v", err)
+	}
+
+	// 邮件未启用时账单与催缴照常执行，只是不发送通知。
+	var notifier *email.Service
+	if cfg.EmailEnabled {
+		notifier, err = email.New(cfg)
+		if err != nil {
+			log.Fatalf("初始化邮件服务失败: %v", err)
+		}
+	}
+
+	return job.NewScheduler(
+		job.Entry{
+			Job: job.NewHoldReaper(dbConn, job.HoldReaperOptions{
+				TTL:       cfg.HoldReaperTTL,
+				BatchSize: cfg.HoldReaperBatchSize,
+			}),
+			Interval: cfg.HoldReaperInterval,
+		},
+		job.Entry{
+			Job: job.NewReconciler(dbConn, job.ReconcileOptions{
+				Fix:         cfg.ReconcileFix,
+				UsageWindow: cfg.ReconcileUsageWindow,
+				BatchSize:   cfg.ReconcileBatchSize,
+			}),
+			// 间隔为 0 时不定时执行，仅通过 cmd/reconcile 手动运行。
+			Interval: cfg.ReconcileInterval,
+		},
+		job.Entry{
+			Job: job.NewVoucherExpiry(dbConn, job.VoucherExpiryOptions{
+				BatchSize: cfg.VoucherExpiryBatchSize,
+			}),
+			Interval: cfg.VoucherExpiryInterval,
+		},
+		job.Entry{
+			Job: job.NewInvoiceGenerator(dbConn, job.InvoiceOptions{
+				BatchSize:  cfg.InvoiceBatchSize,
+				Notifier:   notifier,
+				WebBaseURL: cfg.WebBaseURL,
+			}),
+			Interval: cfg.InvoiceInterval,
+		},
+		job.Entry{
+			Job: job.NewDunning(dbConn, job.DunningOptions{
+				BatchSize:   cfg.DunningBatchSize,
+				WarnPercent: cfg.DunningWarnPercent,
+				Notifier:    notifier,
+				WebBaseURL:  cfg.WebBaseURL,
+			}),
+			Interval: cfg.DunningInterval,
+		},
+		job.Entry{
+			Job: job.NewSubscriptionRenewal(dbConn, job.SubscriptionRenewalOptions{
+				BatchSize:  cfg.SubscriptionRenewalBatchSize,
+				Notifier:   notifier,
+				WebBaseURL: cfg.WebBaseURL,
+			}),
+			Interval: cfg.SubscriptionRenewalInterval,
+		},
+		job.Entry{
+			Job: job.NewSubscriptionLifecycle(dbConn, job.SubscriptionLifecycleOptions{
+				BatchSize:    cfg.SubscriptionLifecycleBatchSize,
+				ReminderDays: cfg.SubscriptionReminderDays,
+				Notifier:     notifier,
+				WebBaseURL:   cfg.WebBaseURL,
+			}),
+			Interval: cfg.SubscriptionLifecycleInterval,
+		},
+		job.Entry{
+			Job: job.NewUsageAggregate(dbConn, job.UsageAggregateOptions{
+				BatchSize: cfg.UsageAggregateBatchSize,
+				Delay:     cfg.UsageAggregateDelay,
+			}),
+			Interval: cfg.UsageAggregateInterval,
+		},
+	)
+}
+
+func runEmailQueue(ctx context.Context, cfg *config.Config) {
+	log.Println("启动邮件队列消费...")
+
 	emailService, err := email.New(cfg)
 	if err != nil {
 		log.Fatalf("初始化邮件服务失败: %v", err)
@@ -31,7 +131,6 @@ func main() {
 		log.Fatal("Redis 客户端不可用")
 	}
 
-	ctx := context.Background()
 	for {
 		result, err := client.BRPop(ctx, cfg.PollTimeout, cfg.RedisQueueKey).Result()
 		if err != nil {
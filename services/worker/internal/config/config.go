@@ -20,6 +20,7 @@ type Config struct {
 	SMTPPassword     string
 	SMTPUseTLS       bool
 	EmailTemplateDir string
+	WebBaseURL       string
 
 	RedisURL      string
 	RedisQueueKey string
@@ -27,6 +28,44 @@ type Config struct {
 
 	PollTimeout time.Duration
 	RetryMax    int
+
+	DBHost     string
+	DBPort     string
+	DBUser     string
+	DBPassword string
+	DBName     string
+	DBSSLMode  string
+
+	JobsEnabled         bool
+	HoldReaperInterval  time.Duration
+	HoldReaperTTL       time.Duration
+	HoldReaperBatchSize int
+
+	ReconcileInterval    time.Duration
+	ReconcileFix         bool
+	ReconcileUsageWindow time.Duration
+	ReconcileBatchSize   int
+
+	VoucherExpiryInterval  time.Duration
+	VoucherExpiryBatchSize int
+
+	InvoiceInterval  time.Duration
+	InvoiceBatchSize int
+
+	DunningInterval    time.Duration
+	DunningBatchSize   int
+	DunningWarnPercent int
+
+	SubscriptionRenewalInterval  time.Duration
+	SubscriptionRenewalBatchSize int
+
+	SubscriptionLifecycleInterval  time.Duration
+	SubscriptionLifecycleBatchSize int
+	SubscriptionReminderDays       int
+
+	UsageAggregateInterval  time.Duration
+	UsageAggregateBatchSize int
+	UsageAggregateDelay     time.Duration
 }
 
 func Load() *Config {
@@ -43,6 +82,7 @@ func Load() *Config {
 		SMTPPassword:     getEnv("SMTP_PASSWORD", ""),
 		SMTPUseTLS:       getEnvBool("SMTP_USE_TLS", true),
 		EmailTemplateDir: getEnv("EMAIL_TEMPLATE_DIR", "../../templates"),
+		WebBaseURL:       getEnv("WEB_BASE_URL", ""),
 
 		RedisURL:      getEnv("REDIS_URL", ""),
 		RedisQueueKey: getEnv("REDIS_QUEUE_KEY", "email:queue"),
@@ -50,13 +90,77 @@ func Load() *Config {
 
 		PollTimeout: time.Duration(getEnvInt("EMAIL_QUEUE_TIMEOUT", 10)) * time.Second,
 		RetryMax:    getEnvInt("EMAIL_RETRY_MAX", 5),
+
+		DBHost:     getEnv("DB_HOST", "localhost"),
+		DBPort:     getEnv("DB_PORT", "5432"),
+		DBUser:     getEnv("DB_USER", "postgres"),
+		DBPassword: getEnv("DB_PASSWORD", ""),
+		DBName:     getEnv("DB_NAME", "deepspace"),
+		DBSSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+		JobsEnabled:         getEnvBool("WORKER_JOBS_ENABLED", true),
+		HoldReaperInterval:  time.Duration(getEnvInt("HOLD_REAPER_INTERVAL_SECONDS", 300)) * time.Second,
+		HoldReaperTTL:       time.Duration(getEnvInt("HOLD_REAPER_TTL_MINUTES", 60)) * time.Minute,
+		HoldReaperBatchSize: getEnvInt("HOLD_REAPER_BATCH_SIZE", 200),
+
+		ReconcileInterval:    time.Duration(getEnvInt("RECONCILE_INTERVAL_MINUTES", 1440)) * time.Minute,
+		ReconcileFix:         getEnvBool("RECONCILE_FIX", false),
+		ReconcileUsageWindow: time.Duration(getEnvInt("RECONCILE_USAGE_WINDOW_HOURS", 24)) * time.Hour,
+		ReconcileBatchSize:   getEnvInt("RECONCILE_BATCH_SIZE", 500),
+
+		VoucherExpiryInterval:  time.Duration(getEnvInt("VOUCHER_EXPIRY_INTERVAL_MINUTES", 10)) * time.Minute,
+		VoucherExpiryBatchSize: getEnvInt("VOUCHER_EXPIRY_BATCH_SIZE", 200),
+
+		InvoiceInterval:  time.Duration(getEnvInt("INVOICE_INTERVAL_MINUTES", 60)) * time.Minute,
+		InvoiceBatchSize: getEnvInt("INVOICE_BATCH_SIZE", 200),
+
+		DunningInterval:    time.Duration(getEnvInt("DUNNING_INTERVAL_MINUTES", 5)) * time.Minute,
+		DunningBatchSize:   getEnvInt("DUNNING_BATCH_SIZE", 200),
+		DunningWarnPercent: getEnvInt("DUNNING_WARN_PERCENT", 80),
+
+		SubscriptionRenewalInterval:  time.Duration(getEnvInt("SUBSCRIPTION_RENEWAL_INTERVAL_MINUTES", 5)) * time.Minute,
+		SubscriptionRenewalBatchSize: getEnvInt("SUBSCRIPTION_RENEWAL_BATCH_SIZE", 200),
+
+		SubscriptionLifecycleInterval:  time.Duration(getEnvInt("SUBSCRIPTION_LIFECYCLE_INTERVAL_MINUTES", 15)) * time.Minute,
+		SubscriptionLifecycleBatchSize: getEnvInt("SUBSCRIPTION_LIFECYCLE_BATCH_SIZE", 200),
+		SubscriptionReminderDays:       getEnvInt("SUBSCRIPTION_REMINDER_DAYS", 3),
+
+		UsageAggregateInterval:  time.Duration(getEnvInt("USAGE_AGGREGATE_INTERVAL_SECONDS", 60)) * time.Second,
+		UsageAggregateBatchSize: getEnvInt("USAGE_AGGREGATE_BATCH_SIZE", 5000),
+		UsageAggregateDelay:     time.Duration(getEnvInt("USAGE_AGGREGATE_DELAY_SECONDS", 120)) * time.Second,
 	}
 }
 
+func (c *Config) PostgresDSN() string {
+	return fmt.Sprintf(
+		"postgres://%s:%s@%s:%s/%s?sslmode=%s",
+		c.DBUser,
+		c.DBPassword,
+		c.DBHost,
+		c.DBPort,
+		c.DBName,
+		c.DBSSLMode,
+	)
+}
+
 func (c *Config) Validate() error {
-	if !c.EmailEnabled {
-		return fmt.Errorf("EMAIL_ENABLED must be true")
+	if !c.EmailEnabled && !c.JobsEnabled {
+		return fmt.Errorf("EMAIL_ENABLED or WORKER_JOBS_ENABLED must be true")
 	}
+	if c.EmailEnabled {
+		if err := c.validateEmail(); err != nil {
+			return err
+		}
+	}
+	if c.JobsEnabled {
+		if err := c.validateJobs(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateEmail() error {
 	if strings.TrimSpace(c.EmailFromAddress) == "" {
 		return fmt.Errorf("EMAIL_FROM_ADDRESS is required")
 	}
@@ -84,6 +188,82 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+func (c *Config) validateJobs() error {
+	if strings.TrimSpace(c.DBHost) == "" {
+		return fmt.Errorf("DB_HOST is required")
+	}
+	if strings.TrimSpace(c.DBUser) == "" {
+		return fmt.Errorf("DB_USER is required")
+	}
+	if strings.TrimSpace(c.DBName) == "" {
+		return fmt.Errorf("DB_NAME is required")
+	}
+	if c.HoldReaperInterval <= 0 {
+		return fmt.Errorf("HOLD_REAPER_INTERVAL_SECONDS must be positive")
+	}
+	if c.HoldReaperTTL <= 0 {
+		return fmt.Errorf("HOLD_REAPER_TTL_MINUTES must be positive")
+	}
+	if c.HoldReaperBatchSize <= 0 {
+		return fmt.Errorf("HOLD_REAPER_BATCH_SIZE must be positive")
+	}
+	if c.ReconcileInterval < 0 {
+		return fmt.Errorf("RECONCILE_INTERVAL_MINUTES must not be negative")
+	}
+	if c.ReconcileUsageWindow <= 0 {
+		return fmt.Errorf("RECONCILE_USAGE_WINDOW_HOURS must be positive")
+	}
+	if c.ReconcileBatchSize <= 0 {
+		return fmt.Errorf("RECONCILE_BATCH_SIZE must be positive")
+	}
+	if c.VoucherExpiryInterval < 0 {
+		return fmt.Errorf("VOUCHER_EXPIRY_INTERVAL_MINUTES must not be negative")
+	}
+	if c.VoucherExpiryBatchSize <= 0 {
+		return fmt.Errorf("VOUCHER_EXPIRY_BATCH_SIZE must be positive")
+	}
+	if c.InvoiceInterval < 0 {
+		return fmt.Errorf("INVOICE_INTERVAL_MINUTES must not be negative")
+	}
+	if c.InvoiceBatchSize <= 0 {
+		return fmt.Errorf("INVOICE_BATCH_SIZE must be positive")
+	}
+	if c.DunningInterval < 0 {
+		return fmt.Errorf("DUNNING_INTERVAL_MINUTES must not be negative")
+	}
+	if c.DunningBatchSize <= 0 {
+		return fmt.Errorf("DUNNING_BATCH_SIZE must be positive")
+	}
+	if c.DunningWarnPercent <= 0 || c.DunningWarnPercent > 100 {
+		return fmt.Errorf("DUNNING_WARN_PERCENT must be between 1 and 100")
+	}
+	if c.SubscriptionRenewalInterval < 0 {
+		return fmt.Errorf("SUBSCRIPTION_RENEWAL_INTERVAL_MINUTES must not be negative")
+	}
+	if c.SubscriptionRenewalBatchSize <= 0 {
+		return fmt.Errorf("SUBSCRIPTION_RENEWAL_BATCH_SIZE must be positive")
+	}
+	if c.SubscriptionLifecycleInterval < 0 {
+		return fmt.Errorf("SUBSCRIPTION_LIFECYCLE_INTERVAL_MINUTES must not be negative")
+	}
+	if c.SubscriptionLifecycleBatchSize <= 0 {
+		return fmt.Errorf("SUBSCRIPTION_LIFECYCLE_BATCH_SIZE must be positive")
+	}
+	if c.SubscriptionReminderDays < 0 {
+		return fmt.Errorf("SUBSCRIPTION_REMINDER_DAYS must not be negative")
+	}
+	if c.UsageAggregateInterval < 0 {
+		return fmt.Errorf("USAGE_AGGREGATE_INTERVAL_SECONDS must not be negative")
+	}
+	if c.UsageAggregateBatchSize <= 0 {
+		return fmt.Errorf("USAGE_AGGREGATE_BATCH_SIZE must be positive")
+	}
+	if c.UsageAggregateDelay < 0 {
+		return fmt.Errorf("USAGE_AGGREGATE_DELAY_SECONDS must not be negative")
+	}
+	return nil
+}
+
 func getEnv(key, fallback string) string {
 	if value, ok := os.LookupEnv(key); ok {
 		return value
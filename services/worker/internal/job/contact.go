@@ -0,0 +1,38 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"deepspace-shared/model"
+
+	"gorm.io/gorm"
+)
+
+// orgContact 返回组织接收账单与催缴邮件的邮箱：优先使用账单邮箱，否则使用 owner 的邮箱。
+// 没有对应组织的历史数据按用户 ID 查找。
+func orgContact(ctx context.Context, db *gorm.DB, orgID int64) (string, error) {
+	var address string
+	if err := db.WithContext(ctx).
+		Raw(`SELECT COALESCE(NULLIF(o.billing_email, ''), u.email, '')
+			FROM users u
+			LEFT JOIN organizations o ON o.id = ?
+			WHERE u.id = COALESCE(o.owner_id, ?)`, orgID, orgID).
+		Scan(&address).Error; err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(address), nil
+}
+
+// orgDisplayName 返回邮件中展示的组织名称，组织不存在或未命名时为“你的账户”。
+func orgDisplayName(ctx context.Context, db *gorm.DB, orgID int64) (string, error) {
+	var org model.Organization
+	if err := db.WithContext(ctx).Where("id = ?", orgID).First(&org).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+	if name := strings.TrimSpace(org.Name); name != "" {
+		return name, nil
+	}
+	return "你的账户", nil
+}
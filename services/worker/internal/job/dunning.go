@@ -0,0 +1,228 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"deepspace-shared/model"
+	"deepspace-shared/money"
+	"deepspace-worker/internal/service/email"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 催缴阶段：0 正常，1 已发送额度预警，2 已因超出信用额度停用。
+const (
+	dunningStageNone    = 0
+	dunningStageWarned  = 1
+	dunningStageSuspend = 2
+)
+
+type DunningOptions struct {
+	BatchSize int
+	// WarnPercent 为欠款占信用额度的预警比例（1-100）。
+	WarnPercent int
+	// Notifier 不为 nil 时发送预警与停用邮件。
+	Notifier   *email.Service
+	WebBaseURL string
+}
+
+// Dunning 巡检后付费钱包：欠款达到预警比例时提醒用户，用尽信用额度时停用账户，
+// 充值结清欠款后自动恢复因额度停用的账户。管理员手动停用的账户不会被自动恢复。
+type Dunning struct {
+	db   *gorm.DB
+	opts DunningOptions
+}
+
+func NewDunning(db *gorm.DB, opts DunningOptions) *Dunning {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 200
+	}
+	if opts.WarnPercent <= 0 || opts.WarnPercent > 100 {
+		opts.WarnPercent = 80
+	}
+	return &Dunning{db: db, opts: opts}
+}
+
+func (d *Dunning) Name() string {
+	return "dunning"
+}
+
+func (d *Dunning) Run(ctx context.Context) error {
+	var warned, suspended, resumed int
+	var lastID int64
+	for {
+		// 只扫描欠款中或仍处于催缴状态的钱包，冻结中的预扣不计入欠款。
+		var userIDs []int64
+		if err := d.db.WithContext(ctx).
+			Model(&model.Wallet{}).
+			Where("user_id > ?", lastID).
+			Where("(credit_limit > 0 AND balance + frozen_balance < 0) OR dunning_stage > 0 OR suspend_reason = ?", model.SuspendReasonCreditLimit).
+			Order("user_id ASC").
+			Limit(d.opts.BatchSize).
+			Pluck("user_id", &userIDs).Error; err != nil {
+			return err
+		}
+		if len(userIDs) == 0 {
+			break
+		}
+		for _, userID := range userIDs {
+			lastID = userID
+			action, err := d.check(ctx, userID)
+			if err != nil {
+				log.Printf("催缴检查失败 user=%d: %v", userID, err)
+				continue
+			}
+			switch action {
+			case dunningActionWarn:
+				warned++
+			case dunningActionSuspend:
+				suspended++
+			case dunningActionResume:
+				resumed++
+			}
+		}
+	}
+	if warned+suspended+resumed > 0 {
+		log.Printf("催缴巡检完成：预警 %d，停用 %d，恢复 %d", warned, suspended, resumed)
+	}
+	return nil
+}
+
+type dunningAction int
+
+const (
+	dunningActionNone dunningAction = iota
+	dunningActionWarn
+	dunningActionSuspend
+	dunningActionResume
+)
+
+// check 锁定钱包后重新判断状态并推进催缴阶段，通知在事务提交后入队。
+func (d *Dunning) check(ctx context.Context, userID int64) (dunningAction, error) {
+	action := dunningActionNone
+	var wallet model.Wallet
+	now := time.Now().UTC()
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		action = dunningActionNone
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).
+			First(&wallet).Error; err != nil {
+			return err
+		}
+
+		position := wallet.Balance + wallet.FrozenBalance
+		updates := map[string]any{}
+		switch {
+		case position >= 0:
+			if wallet.SuspendedAt != nil && wallet.SuspendReason == model.SuspendReasonCreditLimit {
+				updates["suspended_at"] = nil
+				updates["suspend_reason"] = ""
+				action = dunningActionResume
+			}
+			if wallet.DunningStage != dunningStageNone {
+				updates["dunning_stage"] = dunningStageNone
+				updates["dunning_at"] = nil
+			}
+		case wallet.CreditLimit <= 0:
+			// 信用额度被调为 0 的欠款账户由 Gateway 拒绝新请求，这里不再推进催缴。
+		case position+wallet.CreditLimit <= 0:
+			if wallet.SuspendedAt == nil {
+				updates["suspended_at"] = now
+				updates["suspend_reason"] = model.SuspendReasonCreditLimit
+				updates["dunning_stage"] = dunningStageSuspend
+				updates["dunning_at"] = now
+				action = dunningActionSuspend
+			}
+		case wallet.DunningStage < dunningStageWarned &&
+			-position*100 >= wallet.CreditLimit*money.Amount(d.opts.WarnPercent):
+			updates["dunning_stage"] = dunningStageWarned
+			updates["dunning_at"] = now
+			action = dunningActionWarn
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+		if err := tx.Model(&model.Wallet{}).
+			Where("user_id = ?", userID).
+			Updates(updates).Error; err != nil {
+			return err
+		}
+		if action != dunningActionSuspend && action != dunningActionResume {
+			return nil
+		}
+
+		auditAction := "billing.dunning.suspend"
+		if action == dunningActionResume {
+			auditAction = "billing.dunning.resume"
+		}
+		meta, err := json.Marshal(map[string]any{
+			"source":       "dunning",
+			"balance":      wallet.Balance,
+			"frozen":       wallet.FrozenBalance,
+			"credit_limit": wallet.CreditLimit,
+			"currency":     wallet.Currency,
+		})
+		if err != nil {
+			return err
+		}
+		return tx.Create(&model.AuditLog{
+			UserID:   &userID,
+			TraceID:  fmt.Sprintf("dunning:%d:%d", userID, now.Unix()),
+			Action:   auditAction,
+			Metadata: meta,
+		}).Error
+	})
+	if err != nil {
+		return dunningActionNone, err
+	}
+	if action == dunningActionWarn || action == dunningActionSuspend {
+		if err := d.notify(ctx, action, wallet); err != nil {
+			log.Printf("催缴通知入队失败 user=%d: %v", userID, err)
+		}
+	}
+	return action, nil
+}
+
+func (d *Dunning) notify(ctx context.Context, action dunningAction, wallet model.Wallet) error {
+	if d.opts.Notifier == nil {
+		return nil
+	}
+	contact, err := orgContact(ctx, d.db, wallet.UserID)
+	if err != nil {
+		return err
+	}
+	if contact == "" {
+		return nil
+	}
+
+	address := ""
+	if base := strings.TrimRight(strings.TrimSpace(d.opts.WebBaseURL), "/"); base != "" {
+		address = base + "/billing"
+	}
+	position := wallet.Balance + wallet.FrozenBalance
+	input := email.EmailInput{
+		Type:    email.EmailTypeCreditWarning,
+		To:      []string{contact},
+		Subject: "DeepSpace 信用额度即将用尽",
+		TemplateData: map[string]any{
+			"username":     contact,
+			"amount_due":   (-position).String(),
+			"credit_limit": wallet.CreditLimit.String(),
+			"remaining":    max(position+wallet.CreditLimit, 0).String(),
+			"percent":      d.opts.WarnPercent,
+			"currency":     wallet.Currency,
+			"address":      address,
+		},
+	}
+	if action == dunningActionSuspend {
+		input.Type = email.EmailTypeSuspended
+		input.Subject = "DeepSpace 账户已停用"
+	}
+	return d.opts.Notifier.EnqueueBatch(ctx, []email.EmailInput{input})
+}
@@ -0,0 +1,231 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"deepspace-shared/model"
+	"deepspace-shared/money"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type HoldReaperOptions struct {
+	TTL       time.Duration
+	BatchSize int
+}
+
+// HoldReaper 释放超过 TTL 仍未结算的预扣，防止冻结余额永久滞留。
+type HoldReaper struct {
+	db   *gorm.DB
+	opts HoldReaperOptions
+}
+
+func NewHoldReaper(db *gorm.DB, opts HoldReaperOptions) *HoldReaper {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 200
+	}
+	return &HoldReaper{db: db, opts: opts}
+}
+
+func (r *HoldReaper) Name() string {
+	return "hold_reaper"
+}
+
+func (r *HoldReaper) Run(ctx context.Context) error {
+	cutoff := time.Now().UTC().Add(-r.opts.TTL)
+	if err := r.adoptLegacyHolds(ctx, cutoff); err != nil {
+		return err
+	}
+
+	// 按 (created_at, id) 游标分页，本轮处理失败的 ref 不会在下一页重复出现、占满批次。
+	count := 0
+	var total money.Amount
+	var after *model.BillingRef
+	for {
+		query := r.db.WithContext(ctx).
+			Where("state IN ? AND created_at < ?", []string{model.RefStateHeld, model.RefStatePartiallyCaptured}, cutoff).
+			Where("held_amount - captured_amount - released_amount > 0")
+		if after != nil {
+			query = query.Where("(created_at, id) > (?, ?)", after.CreatedAt, after.ID)
+		}
+		var refs []model.BillingRef
+		if err := query.
+			Order("created_at ASC, id ASC").
+			Limit(r.opts.BatchSize).
+			Find(&refs).Error; err != nil {
+			return err
+		}
+
+		for _, item := range refs {
+			amount, err := r.reap(ctx, item)
+			if err != nil {
+				log.Printf("回收预扣失败 user=%d ref=%s: %v", item.UserID, item.RefID, err)
+				continue
+			}
+			if amount > 0 {
+				count++
+				total += amount
+			}
+		}
+		if len(refs) < r.opts.BatchSize || ctx.Err() != nil {
+			break
+		}
+		after = &refs[len(refs)-1]
+	}
+	if count > 0 {
+		log.Printf("已回收过期预扣 %d 笔，共 %s", count, total)
+	}
+	return nil
+}
+
+// reap 在单个事务内释放一个 ref 的剩余预扣。锁顺序与 Gateway 一致：先钱包后 ref。
+func (r *HoldReaper) reap(ctx context.Context, item model.BillingRef) (money.Amount, error) {
+	var released money.Amount
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var wallet model.Wallet
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", item.UserID).
+			First(&wallet).Error; err != nil {
+			return err
+		}
+		var ref model.BillingRef
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", item.ID).
+			First(&ref).Error; err != nil {
+			return err
+		}
+		// 加锁期间可能已被 Gateway 结算。
+		if ref.State != model.RefStateHeld && ref.State != model.RefStatePartiallyCaptured {
+			return nil
+		}
+		remaining := ref.HeldAmount - ref.CapturedAmount - ref.ReleasedAmount
+		if remaining <= 0 {
+			// 预扣已全部结算但状态未推进时补上终态；扣款后释放剩余的 partially_captured 本身已结算完毕。
+			if state := refState(&ref); state != ref.State {
+				return tx.Model(&model.BillingRef{}).
+					Where("id = ?", ref.ID).
+					Update("state", state).Error
+			}
+			return nil
+		}
+		if wallet.FrozenBalance < remaining {
+			return fmt.Errorf("frozen balance %s is less than remaining hold %s", wallet.FrozenBalance, remaining)
+		}
+
+		now := time.Now().UTC()
+		if err := tx.Model(&model.Wallet{}).
+			Where("user_id = ?", ref.UserID).
+			Updates(map[string]any{
+				"balance":        wallet.Balance + remaining,
+				"frozen_balance": wallet.FrozenBalance - remaining,
+			}).Error; err != nil {
+			return err
+		}
+
+		meta, err := json.Marshal(map[string]any{
+			"source":         "hold_reaper",
+			"reason":         "hold not settled within TTL",
+			"ttl_seconds":    int64(r.opts.TTL / time.Second),
+			"held_at":        ref.CreatedAt,
+			"reaped_at":      now,
+			"previous_state": ref.State,
+			"held":           ref.HeldAmount,
+			"captured":       ref.CapturedAmount,
+		})
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(&model.Transaction{
+			UserID:   ref.UserID,
+			Type:     model.TransactionTypeExpire,
+			Amount:   remaining,
+			Currency: wallet.Currency,
+			RefID:    ref.RefID,
+			Metadata: meta,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.BillingRef{}).
+			Where("id = ?", ref.ID).
+			Updates(map[string]any{
+				"state":           model.RefStateExpired,
+				"released_amount": ref.ReleasedAmount + remaining,
+			}).Error; err != nil {
+			return err
+		}
+		released = remaining
+		return nil
+	})
+	return released, err
+}
+
+// adoptLegacyHolds 为 billing_refs 引入前创建、且已超过 TTL 的预扣补建 ref，使其进入回收流程。
+func (r *HoldReaper) adoptLegacyHolds(ctx context.Context, cutoff time.Time) error {
+	var holds []model.Transaction
+	if err := r.db.WithContext(ctx).
+		Where("type = ? AND created_at < ?", model.TransactionTypeHold, cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM billing_refs r WHERE r.user_id = transactions.user_id AND r.ref_id = transactions.ref_id)").
+		Order("created_at ASC").
+		Limit(r.opts.BatchSize).
+		Find(&holds).Error; err != nil {
+		return err
+	}
+
+	for _, hold := range holds {
+		var sums []struct {
+			Type  string
+			Total money.Amount
+		}
+		if err := r.db.WithContext(ctx).
+			Model(&model.Transaction{}).
+			Select("type, COALESCE(SUM(amount), 0) AS total").
+			Where("user_id = ? AND ref_id = ? AND type IN ?", hold.UserID, hold.RefID, []string{model.TransactionTypeCapture, model.TransactionTypeRelease, model.TransactionTypeExpire}).
+			Group("type").
+			Scan(&sums).Error; err != nil {
+			return err
+		}
+		ref := model.BillingRef{
+			UserID:     hold.UserID,
+			RefID:      hold.RefID,
+			HeldAmount: hold.Amount,
+			CreatedAt:  hold.CreatedAt,
+		}
+		for _, item := range sums {
+			if item.Type == model.TransactionTypeCapture {
+				ref.CapturedAmount += item.Total
+			} else {
+				ref.ReleasedAmount += item.Total
+			}
+		}
+		ref.State = refState(&ref)
+		if err := r.db.WithContext(ctx).
+			Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&ref).Error; err != nil && !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return err
+		}
+	}
+	return nil
+}
+
+func refState(ref *model.BillingRef) string {
+	remaining := ref.HeldAmount - ref.CapturedAmount - ref.ReleasedAmount
+	switch {
+	case remaining > 0 && ref.CapturedAmount > 0:
+		return model.RefStatePartiallyCaptured
+	case remaining > 0:
+		return model.RefStateHeld
+	case ref.CapturedAmount == 0:
+		return model.RefStateReleased
+	case ref.ReleasedAmount == 0:
+		return model.RefStateCaptured
+	default:
+		return model.RefStatePartiallyCaptured
+	}
+}
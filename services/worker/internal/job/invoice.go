@@ -0,0 +1,282 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"deepspace-shared/model"
+	"deepspace-shared/money"
+	"deepspace-worker/internal/service/email"
+
+	"gorm.io/gorm"
+)
+
+type InvoiceOptions struct {
+	BatchSize int
+	// Notifier 不为 nil 时，账单生成后通过邮件队列通知用户。
+	Notifier *email.Service
+	// WebBaseURL 用于在通知邮件中生成账单页面链接，为空时不附链接。
+	WebBaseURL string
+}
+
+// InvoiceGenerator 在每个自然月（UTC）结束后，为上月有流水或用量的用户生成月度账单。
+// 同一用户同一账期只生成一次，重复执行是安全的。
+type InvoiceGenerator struct {
+	db   *gorm.DB
+	opts InvoiceOptions
+}
+
+func NewInvoiceGenerator(db *gorm.DB, opts InvoiceOptions) *InvoiceGenerator {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 200
+	}
+	return &InvoiceGenerator{db: db, opts: opts}
+}
+
+func (g *InvoiceGenerator) Name() string {
+	return "invoice"
+}
+
+func (g *InvoiceGenerator) Run(ctx context.Context) error {
+	start := InvoicePeriod(time.Now().AddDate(0, -1, 0))
+	count, err := g.Generate(ctx, start)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		log.Printf("已生成 %s 账单 %d 份", start.Format("2006-01"), count)
+	}
+	if g.opts.Notifier != nil {
+		return g.notify(ctx)
+	}
+	return nil
+}
+
+// InvoicePeriod 返回 t 所在账期的起点，即该月 1 日 00:00 UTC。
+func InvoicePeriod(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Generate 为 start 起的一个月账期生成尚未生成的账单，返回新生成的数量。
+func (g *InvoiceGenerator) Generate(ctx context.Context, start time.Time) (int, error) {
+	start = InvoicePeriod(start)
+	end := start.AddDate(0, 1, 0)
+
+	count := 0
+	var lastID int64
+	for {
+		var userIDs []int64
+		if err := g.db.WithContext(ctx).Raw(`
+			SELECT active.user_id FROM (
+				SELECT user_id FROM transactions WHERE created_at >= ? AND created_at < ?
+				UNION
+				SELECT org_id FROM usage_records WHERE created_at >= ? AND created_at < ?
+			) AS active
+			WHERE active.user_id > ?
+				AND NOT EXISTS (SELECT 1 FROM invoices WHERE invoices.user_id = active.user_id AND invoices.period_start = ?)
+			ORDER BY active.user_id
+			LIMIT ?`,
+			start, end, start, end, lastID, start, g.opts.BatchSize).
+			Scan(&userIDs).Error; err != nil {
+			return count, err
+		}
+		if len(userIDs) == 0 {
+			return count, nil
+		}
+		for _, userID := range userIDs {
+			lastID = userID
+			created, err := g.issue(ctx, userID, start, end)
+			if err != nil {
+				log.Printf("生成账单失败 user=%d period=%s: %v", userID, start.Format("2006-01"), err)
+				continue
+			}
+			if created {
+				count++
+			}
+		}
+	}
+}
+
+// issue 在单个事务内汇总一个组织的账期数据并写入账单与明细。
+func (g *InvoiceGenerator) issue(ctx context.Context, userID int64, start, end time.Time) (bool, error) {
+	created := false
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&model.Invoice{}).
+			Where("user_id = ? AND period_start = ?", userID, start).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return nil
+		}
+
+		currency := "CNY"
+		var wallet model.Wallet
+		if err := tx.Where("user_id = ?", userID).First(&wallet).Error; err == nil {
+			if wallet.Currency != "" {
+				currency = wallet.Currency
+			}
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		contact, err := orgContact(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		var lines []model.InvoiceLine
+		if err := tx.Table("usage_records AS u").
+			Select(`u.model, u.project_id, COALESCE(p.name, '') AS project_name, u.currency,
+				COUNT(*) AS requests,
+				COALESCE(SUM(u.prompt_tokens), 0) AS prompt_tokens,
+				COALESCE(SUM(u.completion_tokens), 0) AS completion_tokens,
+				COALESCE(SUM(u.total_tokens), 0) AS total_tokens,
+				COALESCE(SUM(u.cost), 0) AS amount`).
+			Joins("LEFT JOIN projects p ON p.id = u.project_id").
+			Where("u.org_id = ? AND u.created_at >= ? AND u.created_at < ?", userID, start, end).
+			Group("u.model, u.project_id, p.name, u.currency").
+			Order("amount DESC").
+			Scan(&lines).Error; err != nil {
+			return err
+		}
+
+		var sums []struct {
+			Type  string
+			Total money.Amount
+		}
+		if err := tx.Model(&model.Transaction{}).
+			Select("type, COALESCE(SUM(amount), 0) AS total").
+			Where("user_id = ? AND created_at >= ? AND created_at < ?", userID, start, end).
+			Group("type").
+			Scan(&sums).Error; err != nil {
+			return err
+		}
+
+		opening, _, err := replayLedgerBefore(tx, userID, start)
+		if err != nil {
+			return err
+		}
+		closing, _, err := replayLedgerBefore(tx, userID, end)
+		if err != nil {
+			return err
+		}
+
+		invoice := model.Invoice{
+			UserID:         userID,
+			Number:         fmt.Sprintf("INV-%s-%06d", start.Format("200601"), userID),
+			Email:          contact,
+			PeriodStart:    start,
+			PeriodEnd:      end,
+			Currency:       currency,
+			OpeningBalance: opening,
+			ClosingBalance: closing,
+			Status:         model.InvoiceStatusIssued,
+			IssuedAt:       time.Now().UTC(),
+		}
+		for _, line := range lines {
+			invoice.Requests += line.Requests
+			invoice.TotalTokens += line.TotalTokens
+			// 用量按记录时的币种汇总，账单合计只计入与钱包币种一致的部分。
+			if line.Currency == currency {
+				invoice.UsageAmount += line.Amount
+			}
+		}
+		for _, sum := range sums {
+			switch sum.Type {
+			case model.TransactionTypeCapture, model.TransactionTypeSubscription:
+				invoice.Charged += sum.Total
+			case model.TransactionTypeRefund:
+				invoice.Refunded += sum.Total
+			case model.TransactionTypeTopUp, model.TransactionTypeVoucher, model.TransactionTypeManualCredit:
+				invoice.Credited += sum.Total
+			case model.TransactionTypeManualDebit:
+				invoice.Debited += sum.Total
+			case model.TransactionTypeVoucherExpire:
+				invoice.Debited -= sum.Total
+			}
+		}
+		invoice.Total = invoice.Charged - invoice.Refunded
+		// 后付费账户期末透支的部分即需要结清的欠款。
+		if closing < 0 {
+			invoice.AmountDue = -closing
+		}
+
+		if err := tx.Create(&invoice).Error; err != nil {
+			return err
+		}
+		if len(lines) > 0 {
+			for i := range lines {
+				lines[i].InvoiceID = invoice.ID
+			}
+			if err := tx.Create(&lines).Error; err != nil {
+				return err
+			}
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+// notify 把尚未通知的账单放入邮件队列，入队成功后记录 notified_at。
+func (g *InvoiceGenerator) notify(ctx context.Context) error {
+	var items []model.Invoice
+	if err := g.db.WithContext(ctx).
+		Where("status = ? AND notified_at IS NULL AND email <> ''", model.InvoiceStatusIssued).
+		Order("id ASC").
+		Limit(g.opts.BatchSize).
+		Find(&items).Error; err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	address := ""
+	if base := strings.TrimRight(strings.TrimSpace(g.opts.WebBaseURL), "/"); base != "" {
+		address = base + "/billing"
+	}
+	inputs := make([]email.EmailInput, 0, len(items))
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		period := item.PeriodStart.Format("2006年01月")
+		inputs = append(inputs, email.EmailInput{
+			Type:    email.EmailTypeInvoiceIssued,
+			To:      []string{item.Email},
+			Subject: fmt.Sprintf("DeepSpace %s账单已出具", period),
+			TemplateData: map[string]any{
+				"username":        item.Email,
+				"number":          item.Number,
+				"period":          period,
+				"period_start":    item.PeriodStart.Format(time.DateOnly),
+				"period_end":      item.PeriodEnd.AddDate(0, 0, -1).Format(time.DateOnly),
+				"currency":        item.Currency,
+				"charged":         item.Charged.String(),
+				"refunded":        item.Refunded.String(),
+				"total":           item.Total.String(),
+				"closing_balance": item.ClosingBalance.String(),
+				"amount_due":      item.AmountDue.String(),
+				"has_amount_due":  item.AmountDue > 0,
+				"address":         address,
+			},
+		})
+		ids = append(ids, item.ID)
+	}
+	if err := g.opts.Notifier.EnqueueBatch(ctx, inputs); err != nil {
+		return err
+	}
+	if err := g.db.WithContext(ctx).
+		Model(&model.Invoice{}).
+		Where("id IN ?", ids).
+		Update("notified_at", time.Now().UTC()).Error; err != nil {
+		return err
+	}
+	log.Printf("已发送账单通知 %d 封", len(items))
+	return nil
+}
@@ -0,0 +1,366 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"deepspace-shared/model"
+	"deepspace-shared/money"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 对账修正流水（model.TransactionTypeAdjustment）在 metadata.field 中指明修正的字段，缺省为 balance。
+const (
+	adjustmentFieldBalance = "balance"
+	adjustmentFieldFrozen  = "frozen_balance"
+)
+
+// 用量交叉核对的不一致类型。
+const (
+	UsageMismatchMissingCapture = "missing_capture"
+	UsageMismatchAmount         = "amount_mismatch"
+	UsageMismatchUncollected    = "uncollected"
+)
+
+type ReconcileOptions struct {
+	// Fix 为 true 时为每个不一致的字段写入 adjustment 流水，使账本与钱包一致；钱包本身不会被修改。
+	Fix         bool
+	UsageWindow time.Duration
+	BatchSize   int
+	// UserID 非空时只核对该钱包（组织 ID）。
+	UserID *int64
+}
+
+// Reconciler 按流水重放每个钱包的余额与冻结金额，并核对用量费用与实际扣款。
+type Reconciler struct {
+	db   *gorm.DB
+	opts ReconcileOptions
+}
+
+func NewReconciler(db *gorm.DB, opts ReconcileOptions) *Reconciler {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	if opts.UsageWindow <= 0 {
+		opts.UsageWindow = 24 * time.Hour
+	}
+	return &Reconciler{db: db, opts: opts}
+}
+
+type WalletDrift struct {
+	UserID          int64        `json:"user_id"`
+	Balance         money.Amount `json:"balance"`
+	ExpectedBalance money.Amount `json:"expected_balance"`
+	BalanceDrift    money.Amount `json:"balance_drift"`
+	FrozenBalance   money.Amount `json:"frozen_balance"`
+	ExpectedFrozen  money.Amount `json:"expected_frozen"`
+	FrozenDrift     money.Amount `json:"frozen_drift"`
+	Adjusted        bool         `json:"adjusted"`
+}
+
+type UsageMismatch struct {
+	UserID      int64        `json:"user_id"`
+	TraceID     string       `json:"trace_id"`
+	Reason      string       `json:"reason"`
+	UsageCost   money.Amount `json:"usage_cost"`
+	Captured    money.Amount `json:"captured"`
+	Uncollected money.Amount `json:"uncollected"`
+}
+
+type ReconcileReport struct {
+	RunID           string          `json:"run_id"`
+	Fix             bool            `json:"fix"`
+	StartedAt       time.Time       `json:"started_at"`
+	FinishedAt      time.Time       `json:"finished_at"`
+	WalletsChecked  int             `json:"wallets_checked"`
+	Drifts          []WalletDrift   `json:"drifts"`
+	Adjustments     int             `json:"adjustments"`
+	UsageSince      time.Time       `json:"usage_since"`
+	UsageChecked    int             `json:"usage_checked"`
+	UsageMismatches []UsageMismatch `json:"usage_mismatches"`
+}
+
+// HasIssues 表示存在钱包漂移或用量不一致。
+func (r *ReconcileReport) HasIssues() bool {
+	return len(r.Drifts) > 0 || len(r.UsageMismatches) > 0
+}
+
+func (r *ReconcileReport) Log() {
+	for _, item := range r.Drifts {
+		log.Printf("钱包对账不一致 user=%d balance=%s expected=%s frozen=%s expected=%s adjusted=%t",
+			item.UserID, item.Balance, item.ExpectedBalance, item.FrozenBalance, item.ExpectedFrozen, item.Adjusted)
+	}
+	for _, item := range r.UsageMismatches {
+		log.Printf("用量与扣款不一致 user=%d trace=%s reason=%s cost=%s captured=%s uncollected=%s",
+			item.UserID, item.TraceID, item.Reason, item.UsageCost, item.Captured, item.Uncollected)
+	}
+	log.Printf("对账完成 run=%s 钱包 %d 个，不一致 %d 个，修正流水 %d 笔；用量 %d 条，不一致 %d 条",
+		r.RunID, r.WalletsChecked, len(r.Drifts), r.Adjustments, r.UsageChecked, len(r.UsageMismatches))
+}
+
+func (r *Reconciler) Name() string {
+	return "reconcile"
+}
+
+func (r *Reconciler) Run(ctx context.Context) error {
+	report, err := r.Reconcile(ctx)
+	if err != nil {
+		return err
+	}
+	report.Log()
+	return nil
+}
+
+// Reconcile 执行一次完整对账并返回报告。
+func (r *Reconciler) Reconcile(ctx context.Context) (*ReconcileReport, error) {
+	now := time.Now().UTC()
+	report := &ReconcileReport{
+		RunID:           "reconcile-" + now.Format("20060102T150405.000Z"),
+		Fix:             r.opts.Fix,
+		StartedAt:       now,
+		Drifts:          []WalletDrift{},
+		UsageSince:      now.Add(-r.opts.UsageWindow),
+		UsageMismatches: []UsageMismatch{},
+	}
+
+	var lastID int64
+	for {
+		var userIDs []int64
+		query := r.db.WithContext(ctx).Model(&model.Wallet{}).Where("user_id > ?", lastID)
+		if r.opts.UserID != nil {
+			query = query.Where("user_id = ?", *r.opts.UserID)
+		}
+		if err := query.Order("user_id ASC").Limit(r.opts.BatchSize).Pluck("user_id", &userIDs).Error; err != nil {
+			return nil, err
+		}
+		for _, userID := range userIDs {
+			drift, adjustments, err := r.reconcileWallet(ctx, report.RunID, userID)
+			if err != nil {
+				return nil, fmt.Errorf("reconcile wallet of user %d: %w", userID, err)
+			}
+			report.WalletsChecked++
+			report.Adjustments += adjustments
+			if drift != nil {
+				report.Drifts = append(report.Drifts, *drift)
+			}
+		}
+		if len(userIDs) < r.opts.BatchSize {
+			break
+		}
+		lastID = userIDs[len(userIDs)-1]
+	}
+
+	checked, mismatches, err := r.checkUsage(ctx, report.UsageSince, now)
+	if err != nil {
+		return nil, err
+	}
+	report.UsageChecked = checked
+	report.UsageMismatches = mismatches
+	report.FinishedAt = time.Now().UTC()
+	return report, nil
+}
+
+// reconcileWallet 锁定钱包后重放流水。Gateway 写流水前都会先锁钱包，因此锁内读到的是一致快照。
+func (r *Reconciler) reconcileWallet(ctx context.Context, runID string, userID int64) (*WalletDrift, int, error) {
+	var drift *WalletDrift
+	adjustments := 0
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var wallet model.Wallet
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).
+			First(&wallet).Error; err != nil {
+			return err
+		}
+		balance, frozen, err := replayLedger(tx, userID)
+		if err != nil {
+			return err
+		}
+		if wallet.Balance == balance && wallet.FrozenBalance == frozen {
+			return nil
+		}
+		drift = &WalletDrift{
+			UserID:          userID,
+			Balance:         wallet.Balance,
+			ExpectedBalance: balance,
+			BalanceDrift:    wallet.Balance - balance,
+			FrozenBalance:   wallet.FrozenBalance,
+			ExpectedFrozen:  frozen,
+			FrozenDrift:     wallet.FrozenBalance - frozen,
+		}
+		if !r.opts.Fix {
+			return nil
+		}
+		if drift.BalanceDrift != 0 {
+			if err := writeAdjustment(tx, runID, &wallet, adjustmentFieldBalance, wallet.Balance, balance); err != nil {
+				return err
+			}
+			adjustments++
+		}
+		if drift.FrozenDrift != 0 {
+			if err := writeAdjustment(tx, runID, &wallet, adjustmentFieldFrozen, wallet.FrozenBalance, frozen); err != nil {
+				return err
+			}
+			adjustments++
+		}
+		drift.Adjusted = true
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return drift, adjustments, nil
+}
+
+// replayLedger 由流水推导钱包应有的余额与冻结金额。
+// 每个 ref 的 hold 减去 capture/release/expire 即剩余冻结；结算超出预扣的部分从余额扣除，表现为负的剩余。
+func replayLedger(tx *gorm.DB, userID int64) (money.Amount, money.Amount, error) {
+	return replayLedgerBefore(tx, userID, time.Time{})
+}
+
+// replayLedgerBefore 只重放 before 之前的流水，得到该时刻的余额与冻结金额；before 为零值时重放全部流水。
+func replayLedgerBefore(tx *gorm.DB, userID int64, before time.Time) (money.Amount, money.Amount, error) {
+	var rows []struct {
+		RefID string
+		Type  string
+		Field string
+		Total money.Amount
+	}
+	query := tx.Model(&model.Transaction{}).
+		Select("ref_id, type, COALESCE(metadata->>'field', '') AS field, COALESCE(SUM(amount), 0) AS total").
+		Where("user_id = ?", userID)
+	if !before.IsZero() {
+		query = query.Where("created_at < ?", before)
+	}
+	if err := query.Group("ref_id, type, field").Scan(&rows).Error; err != nil {
+		return 0, 0, err
+	}
+
+	var balance, frozen money.Amount
+	refs := map[string]money.Amount{}
+	for _, row := range rows {
+		switch row.Type {
+		case model.TransactionTypeTopUp, model.TransactionTypeVoucher, model.TransactionTypeVoucherExpire, model.TransactionTypeRefund, model.TransactionTypeManualCredit:
+			balance += row.Total
+		case model.TransactionTypeManualDebit, model.TransactionTypeSubscription:
+			balance -= row.Total
+		case model.TransactionTypeAdjustment:
+			if row.Field == adjustmentFieldFrozen {
+				frozen += row.Total
+			} else {
+				balance += row.Total
+			}
+		case model.TransactionTypeHold:
+			balance -= row.Total
+			refs[row.RefID] += row.Total
+		case model.TransactionTypeRelease, model.TransactionTypeExpire:
+			balance += row.Total
+			refs[row.RefID] -= row.Total
+		case model.TransactionTypeCapture:
+			refs[row.RefID] -= row.Total
+		default:
+			return 0, 0, fmt.Errorf("unknown transaction type %q", row.Type)
+		}
+	}
+	for _, remaining := range refs {
+		if remaining > 0 {
+			frozen += remaining
+		} else {
+			balance += remaining
+		}
+	}
+	return balance, frozen, nil
+}
+
+// writeAdjustment 记入 actual-expected 的差额使账本与钱包一致，并写审计日志。
+func writeAdjustment(tx *gorm.DB, runID string, wallet *model.Wallet, field string, actual, expected money.Amount) error {
+	userID := wallet.UserID
+	meta, err := json.Marshal(map[string]any{
+		"source":   "reconcile",
+		"run_id":   runID,
+		"field":    field,
+		"actual":   actual,
+		"expected": expected,
+		"drift":    actual - expected,
+		"reason":   "wallet does not match ledger replay",
+	})
+	if err != nil {
+		return err
+	}
+	if err := tx.Create(&model.Transaction{
+		UserID:   userID,
+		Type:     model.TransactionTypeAdjustment,
+		Amount:   actual - expected,
+		Currency: wallet.Currency,
+		RefID:    "reconcile:" + runID + ":" + field,
+		Metadata: meta,
+	}).Error; err != nil {
+		return err
+	}
+	return tx.Create(&model.AuditLog{
+		UserID:   &userID,
+		TraceID:  runID,
+		Action:   "billing.reconcile.adjustment",
+		Metadata: meta,
+	}).Error
+}
+
+// checkUsage 核对时间窗口内每个 trace 的 usage_records.cost 与扣款流水。
+// 扣款按 ref_id 或 metadata.trace_id 关联；结算时余额不足未扣到的部分记在 metadata.uncollected。
+func (r *Reconciler) checkUsage(ctx context.Context, start, end time.Time) (int, []UsageMismatch, error) {
+	var rows []struct {
+		UserID      int64
+		TraceID     string
+		UsageCost   money.Amount
+		Captured    money.Amount
+		Uncollected money.Amount
+	}
+	query := r.db.WithContext(ctx).
+		Table("usage_records AS u").
+		Select("u.org_id AS user_id, u.trace_id, COALESCE(SUM(u.cost), 0) AS usage_cost, COALESCE(c.captured, 0) AS captured, COALESCE(c.uncollected, 0) AS uncollected").
+		Joins(`LEFT JOIN LATERAL (
+			SELECT SUM(t.amount) AS captured, SUM(COALESCE((t.metadata->>'uncollected')::numeric, 0)) AS uncollected
+			FROM transactions t
+			WHERE t.user_id = u.org_id AND t.type = ?
+				AND (t.ref_id = u.trace_id OR t.metadata->>'trace_id' = u.trace_id)
+		) c ON true`, model.TransactionTypeCapture).
+		Where("u.trace_id <> '' AND u.created_at >= ? AND u.created_at < ?", start, end)
+	if r.opts.UserID != nil {
+		query = query.Where("u.org_id = ?", *r.opts.UserID)
+	}
+	if err := query.
+		Group("u.org_id, u.trace_id, c.captured, c.uncollected").
+		Order("u.org_id, u.trace_id").
+		Scan(&rows).Error; err != nil {
+		return 0, nil, err
+	}
+
+	mismatches := []UsageMismatch{}
+	for _, row := range rows {
+		reason := ""
+		switch {
+		case row.UsageCost > 0 && row.Captured == 0 && row.Uncollected == 0:
+			reason = UsageMismatchMissingCapture
+		case row.Captured+row.Uncollected != row.UsageCost:
+			reason = UsageMismatchAmount
+		case row.Uncollected > 0:
+			reason = UsageMismatchUncollected
+		}
+		if reason == "" {
+			continue
+		}
+		mismatches = append(mismatches, UsageMismatch{
+			UserID:      row.UserID,
+			TraceID:     row.TraceID,
+			Reason:      reason,
+			UsageCost:   row.UsageCost,
+			Captured:    row.Captured,
+			Uncollected: row.Uncollected,
+		})
+	}
+	return len(rows), mismatches, nil
+}
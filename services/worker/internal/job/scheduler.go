@@ -0,0 +1,67 @@
+package job
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job 是一个可周期执行的后台任务。
+type Job interface {
+	Name() string
+	Run(ctx context.Context) error
+}
+
+type Entry struct {
+	Job      Job
+	Interval time.Duration
+}
+
+type Scheduler struct {
+	entries []Entry
+}
+
+func NewScheduler(entries ...Entry) *Scheduler {
+	return &Scheduler{entries: entries}
+}
+
+// Run 启动后立即执行一次每个任务，之后按各自间隔执行，直到 ctx 结束。
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, entry := range s.entries {
+		if entry.Job == nil || entry.Interval <= 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(entry Entry) {
+			defer wg.Done()
+			s.loop(ctx, entry)
+		}(entry)
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, entry Entry) {
+	ticker := time.NewTicker(entry.Interval)
+	defer ticker.Stop()
+	for {
+		runOnce(ctx, entry.Job)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func runOnce(ctx context.Context, job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("任务 %s 异常退出: %v", job.Name(), r)
+		}
+	}()
+	if err := job.Run(ctx); err != nil {
+		log.Printf("任务 %s 执行失败: %v", job.Name(), err)
+	}
+}
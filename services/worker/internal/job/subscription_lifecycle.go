@@ -0,0 +1,316 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"deepspace-shared/model"
+	"deepspace-worker/internal/service/email"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SubscriptionLifecycleOptions struct {
+	BatchSize int
+	// ReminderDays 为到期前多少天发送到期提醒，0 表示不提醒。
+	ReminderDays int
+	// Notifier 不为 nil 时发送到期提醒与失效邮件。
+	Notifier   *email.Service
+	WebBaseURL string
+}
+
+// SubscriptionLifecycle 维护不续费订阅的状态：到期前 ReminderDays 天提醒组织，
+// 过了 EndAt 加套餐宽限期后把订阅标记为 expired 并关闭其用量，同时关闭已结束周期的用量。
+// 开启自动续费的订阅由 SubscriptionRenewal 处理，续费失败后才进入这里的流程。
+type SubscriptionLifecycle struct {
+	db   *gorm.DB
+	opts SubscriptionLifecycleOptions
+}
+
+func NewSubscriptionLifecycle(db *gorm.DB, opts SubscriptionLifecycleOptions) *SubscriptionLifecycle {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 200
+	}
+	if opts.ReminderDays < 0 {
+		opts.ReminderDays = 0
+	}
+	return &SubscriptionLifecycle{db: db, opts: opts}
+}
+
+func (l *SubscriptionLifecycle) Name() string {
+	return "subscription_lifecycle"
+}
+
+func (l *SubscriptionLifecycle) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+	reminded, err := l.remind(ctx, now)
+	if err != nil {
+		return err
+	}
+	expired, err := l.expire(ctx, now)
+	if err != nil {
+		return err
+	}
+	closed, err := l.closeUsage(ctx, now)
+	if err != nil {
+		return err
+	}
+	if reminded+expired > 0 || closed > 0 {
+		log.Printf("订阅状态维护完成：到期提醒 %d，失效 %d，关闭用量 %d", reminded, expired, closed)
+	}
+	return nil
+}
+
+// remind 为即将到期且不会续费的订阅发送一次到期提醒。先写入 ExpiryRemindedAt 占位，入队失败时清空以便下次重试。
+func (l *SubscriptionLifecycle) remind(ctx context.Context, now time.Time) (int, error) {
+	if l.opts.ReminderDays <= 0 || l.opts.Notifier == nil {
+		return 0, nil
+	}
+	var items []model.PlanSubscription
+	if err := l.db.WithContext(ctx).
+		Where("status = ? AND auto_renew = ? AND expiry_reminded_at IS NULL", model.SubscriptionStatusActive, false).
+		Where("end_at > ? AND end_at <= ?", now, now.AddDate(0, 0, l.opts.ReminderDays)).
+		Order("end_at ASC, id ASC").
+		Limit(l.opts.BatchSize).
+		Find(&items).Error; err != nil {
+		return 0, err
+	}
+
+	var reminded int
+	for _, item := range items {
+		result := l.db.WithContext(ctx).
+			Model(&model.PlanSubscription{}).
+			Where("id = ? AND status = ? AND auto_renew = ? AND expiry_reminded_at IS NULL", item.ID, model.SubscriptionStatusActive, false).
+			Update("expiry_reminded_at", now)
+		if result.Error != nil {
+			return reminded, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		plan, err := loadPlan(ctx, l.db, item.PlanID)
+		if err != nil {
+			return reminded, err
+		}
+		if err := l.notify(ctx, email.EmailTypeSubscriptionExpiring, "DeepSpace 套餐即将到期", item, plan); err != nil {
+			log.Printf("到期提醒入队失败 subscription=%d: %v", item.ID, err)
+			if err := l.db.WithContext(ctx).
+				Model(&model.PlanSubscription{}).
+				Where("id = ?", item.ID).
+				Update("expiry_reminded_at", nil).Error; err != nil {
+				return reminded, err
+			}
+			continue
+		}
+		reminded++
+	}
+	return reminded, nil
+}
+
+// expire 把已过宽限期且不续费的订阅标记为 expired。
+// 按 (end_at, id) 游标分页，本轮处理失败的订阅不会在下一页重复出现、占满批次。
+func (l *SubscriptionLifecycle) expire(ctx context.Context, now time.Time) (int, error) {
+	type candidate struct {
+		ID    int64
+		EndAt time.Time
+	}
+	var expired int
+	var after *candidate
+	for {
+		query := l.db.WithContext(ctx).
+			Model(&model.PlanSubscription{}).
+			Joins("LEFT JOIN plans ON plans.id = plan_subscriptions.plan_id").
+			Where("plan_subscriptions.status = ? AND plan_subscriptions.auto_renew = ? AND plan_subscriptions.end_at IS NOT NULL", model.SubscriptionStatusActive, false).
+			Where("plan_subscriptions.end_at + make_interval(days => COALESCE(plans.grace_period_days, 0)) <= ?", now)
+		if after != nil {
+			query = query.Where("(plan_subscriptions.end_at, plan_subscriptions.id) > (?, ?)", after.EndAt, after.ID)
+		}
+		var items []candidate
+		if err := query.
+			Select("plan_subscriptions.id, plan_subscriptions.end_at").
+			Order("plan_subscriptions.end_at ASC, plan_subscriptions.id ASC").
+			Limit(l.opts.BatchSize).
+			Scan(&items).Error; err != nil {
+			return expired, err
+		}
+
+		for _, item := range items {
+			outcome, err := l.expireOne(ctx, item.ID, now)
+			if err != nil {
+				log.Printf("订阅失效处理失败 subscription=%d: %v", item.ID, err)
+				continue
+			}
+			if outcome == nil {
+				continue
+			}
+			expired++
+			if l.opts.Notifier == nil {
+				continue
+			}
+			if err := l.notify(ctx, email.EmailTypeSubscriptionExpired, "DeepSpace 套餐已失效", outcome.subscription, outcome.plan); err != nil {
+				log.Printf("订阅失效通知入队失败 subscription=%d: %v", item.ID, err)
+			}
+		}
+		if len(items) < l.opts.BatchSize || ctx.Err() != nil {
+			break
+		}
+		after = &items[len(items)-1]
+	}
+	return expired, nil
+}
+
+type lifecycleOutcome struct {
+	subscription model.PlanSubscription
+	plan         *model.Plan
+}
+
+// expireOne 在事务内锁定订阅，确认已过宽限期后标记为 expired、关闭其全部用量并写入审计日志；订阅已被处理时返回 nil。
+func (l *SubscriptionLifecycle) expireOne(ctx context.Context, id int64, now time.Time) (*lifecycleOutcome, error) {
+	var outcome *lifecycleOutcome
+	err := l.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		outcome = nil
+		var sub model.PlanSubscription
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).
+			First(&sub).Error; err != nil {
+			return err
+		}
+		if sub.Status != model.SubscriptionStatusActive || sub.AutoRenew || sub.EndAt == nil {
+			return nil
+		}
+		plan, err := loadPlan(ctx, tx, sub.PlanID)
+		if err != nil {
+			return err
+		}
+		graceUntil := subscriptionGraceUntil(sub, plan)
+		if graceUntil.After(now) {
+			return nil
+		}
+
+		if err := tx.Model(&model.PlanSubscription{}).
+			Where("id = ?", sub.ID).
+			Update("status", model.SubscriptionStatusExpired).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.PlanUsage{}).
+			Where("subscription_id = ? AND closed_at IS NULL", sub.ID).
+			Update("closed_at", now).Error; err != nil {
+			return err
+		}
+		meta, err := json.Marshal(map[string]any{
+			"source":          "subscription_lifecycle",
+			"subscription_id": sub.ID,
+			"plan_id":         sub.PlanID,
+			"end_at":          sub.EndAt.UTC(),
+			"grace_until":     graceUntil,
+		})
+		if err != nil {
+			return err
+		}
+		orgID := sub.UserID
+		if err := tx.Create(&model.AuditLog{
+			UserID:   &orgID,
+			TraceID:  fmt.Sprintf("subscription_lifecycle:%d", sub.ID),
+			Action:   "billing.subscription.expired",
+			Metadata: meta,
+		}).Error; err != nil {
+			return err
+		}
+		sub.Status = model.SubscriptionStatusExpired
+		outcome = &lifecycleOutcome{subscription: sub, plan: plan}
+		return nil
+	})
+	return outcome, err
+}
+
+// closeUsage 关闭已结束周期的用量：订阅已不再生效，或周期已结束且订阅已进入下一周期。
+// 宽限期内的订阅仍使用最后一个周期的额度，其用量在订阅失效时才关闭。
+func (l *SubscriptionLifecycle) closeUsage(ctx context.Context, now time.Time) (int64, error) {
+	result := l.db.WithContext(ctx).
+		Model(&model.PlanUsage{}).
+		Where("closed_at IS NULL").
+		Where(`EXISTS (SELECT 1 FROM plan_subscriptions s
+			WHERE s.id = plan_usages.subscription_id
+			AND (s.status <> ? OR (plan_usages.period_end <= ? AND (s.end_at IS NULL OR s.end_at > plan_usages.period_end))))`,
+			model.SubscriptionStatusActive, now).
+		Update("closed_at", now)
+	return result.RowsAffected, result.Error
+}
+
+func (l *SubscriptionLifecycle) notify(ctx context.Context, emailType, subject string, sub model.PlanSubscription, plan *model.Plan) error {
+	contact, err := orgContact(ctx, l.db, sub.UserID)
+	if err != nil {
+		return err
+	}
+	if contact == "" {
+		return nil
+	}
+	orgName, err := orgDisplayName(ctx, l.db, sub.UserID)
+	if err != nil {
+		return err
+	}
+
+	address := ""
+	if base := strings.TrimRight(strings.TrimSpace(l.opts.WebBaseURL), "/"); base != "" {
+		address = base + "/billing"
+	}
+	planName, periodEnd, graceUntil := "", "", ""
+	if plan != nil {
+		planName = plan.Name
+	}
+	if sub.EndAt != nil {
+		periodEnd = sub.EndAt.UTC().Format("2006-01-02 15:04 UTC")
+		if plan != nil && plan.GracePeriodDays > 0 {
+			graceUntil = subscriptionGraceUntil(sub, plan).Format("2006-01-02 15:04 UTC")
+		}
+	}
+	return l.opts.Notifier.EnqueueBatch(ctx, []email.EmailInput{{
+		Type:    emailType,
+		To:      []string{contact},
+		Subject: subject,
+		TemplateData: map[string]any{
+			"username":    contact,
+			"org_name":    orgName,
+			"plan_name":   planName,
+			"period_end":  periodEnd,
+			"grace_until": graceUntil,
+			"address":     address,
+		},
+	}})
+}
+
+// loadPlan 查询套餐，不存在时返回 nil。
+func loadPlan(ctx context.Context, db *gorm.DB, planID int64) (*model.Plan, error) {
+	var plan model.Plan
+	if err := db.WithContext(ctx).Where("id = ?", planID).First(&plan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// planGraceDays 返回套餐的宽限天数，套餐不存在时为 0。
+func planGraceDays(ctx context.Context, db *gorm.DB, planID int64) (int, error) {
+	plan, err := loadPlan(ctx, db, planID)
+	if err != nil || plan == nil {
+		return 0, err
+	}
+	return max(plan.GracePeriodDays, 0), nil
+}
+
+// subscriptionGraceUntil 返回订阅宽限期的结束时间，sub.EndAt 不能为空。
+func subscriptionGraceUntil(sub model.PlanSubscription, plan *model.Plan) time.Time {
+	endAt := sub.EndAt.UTC()
+	if plan == nil || plan.GracePeriodDays <= 0 {
+		return endAt
+	}
+	return endAt.AddDate(0, 0, plan.GracePeriodDays)
+}
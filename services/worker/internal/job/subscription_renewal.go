@@ -0,0 +1,365 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"deepspace-shared/model"
+	"deepspace-shared/money"
+	"deepspace-worker/internal/service/email"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 续费失败原因，写入审计日志并在邮件中展示对应的说明。
+const (
+	renewalFailInsufficient = "insufficient_balance"
+	renewalFailSuspended    = "wallet_suspended"
+	renewalFailCurrency     = "currency_changed"
+	renewalFailPlan         = "plan_unavailable"
+)
+
+var renewalFailText = map[string]string{
+	renewalFailInsufficient: "钱包余额不足",
+	renewalFailSuspended:    "钱包已停用",
+	renewalFailCurrency:     "钱包币种已变更",
+	renewalFailPlan:         "套餐已下架",
+}
+
+type SubscriptionRenewalOptions struct {
+	BatchSize int
+	// Notifier 不为 nil 时发送续费失败邮件。
+	Notifier   *email.Service
+	WebBaseURL string
+}
+
+// SubscriptionRenewal 为到期且开启自动续费的订阅从组织钱包扣款并顺延一个周期。
+// 扣款按订阅时锁定的 Price/Currency，可使用后付费信用额度；扣款失败时关闭自动续费并通知组织，
+// 套餐设置了宽限期时订阅保持生效到宽限期结束（由 SubscriptionLifecycle 标记为 expired），否则立即标记为 expired。
+type SubscriptionRenewal struct {
+	db   *gorm.DB
+	opts SubscriptionRenewalOptions
+}
+
+func NewSubscriptionRenewal(db *gorm.DB, opts SubscriptionRenewalOptions) *SubscriptionRenewal {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 200
+	}
+	return &SubscriptionRenewal{db: db, opts: opts}
+}
+
+func (r *SubscriptionRenewal) Name() string {
+	return "subscription_renewal"
+}
+
+func (r *SubscriptionRenewal) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+	var ids []int64
+	if err := r.db.WithContext(ctx).
+		Model(&model.PlanSubscription{}).
+		Where("status = ? AND auto_renew = ? AND end_at IS NOT NULL AND end_at <= ?", model.SubscriptionStatusActive, true, now).
+		Order("end_at ASC, id ASC").
+		Limit(r.opts.BatchSize).
+		Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+
+	var renewed, failed int
+	for _, id := range ids {
+		outcome, err := r.renew(ctx, id, now)
+		if err != nil {
+			log.Printf("订阅续费失败 subscription=%d: %v", id, err)
+			continue
+		}
+		switch {
+		case outcome == nil:
+		case outcome.reason != "":
+			failed++
+			if err := r.notify(ctx, outcome); err != nil {
+				log.Printf("续费失败通知入队失败 subscription=%d: %v", id, err)
+			}
+		default:
+			renewed++
+		}
+	}
+	if renewed+failed > 0 {
+		log.Printf("订阅续费完成：续费 %d，失败 %d", renewed, failed)
+	}
+	return nil
+}
+
+// renewalOutcome 描述一次续费结果，reason 不为空表示续费失败；graceUntil 不为空时订阅在宽限期内仍然生效。
+type renewalOutcome struct {
+	subscription model.PlanSubscription
+	plan         model.Plan
+	reason       string
+	graceUntil   *time.Time
+}
+
+// renew 在单个事务内锁定钱包与订阅，扣款并顺延 EndAt；订阅已被处理时返回 nil。
+// 锁顺序与 Gateway 订阅、变更套餐一致：先不加锁读出订阅所属组织并锁定钱包，再锁定订阅并重新检查状态。
+// 订阅安排了降级时，以 ScheduledPlanID/ScheduledPrice 创建下一周期的新订阅，原订阅标记为 expired。
+// 扣款流水的 ref_id 与 Gateway 订阅时的格式一致（subscription:<id>:<周期开始时间戳>），重复执行不会重复扣款。
+func (r *SubscriptionRenewal) renew(ctx context.Context, id int64, now time.Time) (*renewalOutcome, error) {
+	var outcome *renewalOutcome
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		outcome = nil
+		var orgID int64
+		if err := tx.Model(&model.PlanSubscription{}).
+			Where("id = ?", id).
+			Pluck("user_id", &orgID).Error; err != nil {
+			return err
+		}
+		locked, err := lockRenewalWallet(tx, orgID)
+		if err != nil {
+			return err
+		}
+		var sub model.PlanSubscription
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).
+			First(&sub).Error; err != nil {
+			return err
+		}
+		if sub.UserID != orgID || sub.Status != model.SubscriptionStatusActive || !sub.AutoRenew || sub.EndAt == nil || sub.EndAt.After(now) {
+			return nil
+		}
+		result := &renewalOutcome{subscription: sub}
+
+		planID, price := sub.PlanID, sub.Price
+		if sub.ScheduledPlanID != nil {
+			planID, price = *sub.ScheduledPlanID, sub.ScheduledPrice
+		}
+		var plan model.Plan
+		if err := tx.Where("id = ?", planID).First(&plan).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			result.reason = renewalFailPlan
+		} else if plan.Status != model.SubscriptionStatusActive {
+			result.reason = renewalFailPlan
+		}
+		result.plan = plan
+
+		periodStart := sub.EndAt.UTC()
+		interval := plan.ResetIntervalDays
+		if interval <= 0 {
+			interval = 30
+		}
+		periodEnd := periodStart.AddDate(0, 0, interval)
+
+		var wallet *model.Wallet
+		if result.reason == "" && price > 0 {
+			if result.reason = checkRenewalWallet(locked, sub.Currency, price); result.reason == "" {
+				wallet = locked
+			}
+		}
+
+		if result.reason != "" {
+			graceDays, err := planGraceDays(ctx, tx, sub.PlanID)
+			if err != nil {
+				return err
+			}
+			updates := map[string]any{"status": model.SubscriptionStatusExpired, "auto_renew": false}
+			if graceUntil := periodStart.AddDate(0, 0, graceDays); graceUntil.After(now) {
+				// 宽限期内保持生效，恢复自动续费后会重新尝试扣款。
+				updates = map[string]any{"auto_renew": false}
+				result.graceUntil = &graceUntil
+			}
+			if err := tx.Model(&model.PlanSubscription{}).
+				Where("id = ?", sub.ID).
+				Updates(updates).Error; err != nil {
+				return err
+			}
+			meta, err := json.Marshal(map[string]any{
+				"source":          "subscription_renewal",
+				"subscription_id": sub.ID,
+				"plan_id":         planID,
+				"reason":          result.reason,
+				"price":           price,
+				"currency":        sub.Currency,
+				"period_end":      periodStart,
+				"grace_until":     result.graceUntil,
+			})
+			if err != nil {
+				return err
+			}
+			orgID := sub.UserID
+			if err := tx.Create(&model.AuditLog{
+				UserID:   &orgID,
+				TraceID:  fmt.Sprintf("subscription_renewal:%d:%d", sub.ID, periodStart.Unix()),
+				Action:   "billing.subscription.renewal_failed",
+				Metadata: meta,
+			}).Error; err != nil {
+				return err
+			}
+			outcome = result
+			return nil
+		}
+
+		renewed := sub.ID
+		if sub.ScheduledPlanID != nil {
+			previousID := sub.ID
+			next := model.PlanSubscription{
+				UserID:                 sub.UserID,
+				PlanID:                 planID,
+				Status:                 model.SubscriptionStatusActive,
+				StartAt:                periodStart,
+				EndAt:                  &periodEnd,
+				Source:                 sub.Source,
+				AutoRenew:              true,
+				Price:                  price,
+				Currency:               sub.Currency,
+				PreviousSubscriptionID: &previousID,
+			}
+			if err := tx.Create(&next).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&model.PlanSubscription{}).
+				Where("id = ?", sub.ID).
+				Updates(map[string]any{"status": model.SubscriptionStatusExpired, "auto_renew": false}).Error; err != nil {
+				return err
+			}
+			renewed = next.ID
+		} else if err := tx.Model(&model.PlanSubscription{}).
+			Where("id = ?", sub.ID).
+			Updates(map[string]any{"end_at": periodEnd, "expiry_reminded_at": nil}).Error; err != nil {
+			return err
+		}
+
+		if wallet != nil {
+			if err := chargeRenewal(tx, wallet, renewed, planID, price, periodStart, periodEnd); err != nil {
+				return err
+			}
+		}
+		outcome = result
+		return nil
+	})
+	return outcome, err
+}
+
+// lockRenewalWallet 锁定组织钱包，钱包不存在时返回 nil。
+func lockRenewalWallet(tx *gorm.DB, orgID int64) (*model.Wallet, error) {
+	var wallet model.Wallet
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", orgID).
+		First(&wallet).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// checkRenewalWallet 检查已锁定的钱包能否扣除 price，无法扣款时返回失败原因。
+func checkRenewalWallet(wallet *model.Wallet, currency string, price money.Amount) string {
+	switch {
+	case wallet == nil:
+		return renewalFailInsufficient
+	case wallet.SuspendedAt != nil:
+		return renewalFailSuspended
+	case wallet.Currency != currency:
+		return renewalFailCurrency
+	case wallet.Balance+wallet.CreditLimit < price:
+		return renewalFailInsufficient
+	}
+	return ""
+}
+
+// chargeRenewal 从已锁定的钱包扣除订阅 subscriptionID 一个周期的费用，可透支到信用额度。
+func chargeRenewal(tx *gorm.DB, wallet *model.Wallet, subscriptionID, planID int64, price money.Amount, periodStart, periodEnd time.Time) error {
+	refID := fmt.Sprintf("subscription:%d:%d", subscriptionID, periodStart.Unix())
+	var existing int64
+	if err := tx.Model(&model.Transaction{}).
+		Where("user_id = ? AND ref_id = ? AND type = ?", wallet.UserID, refID, model.TransactionTypeSubscription).
+		Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		return nil
+	}
+
+	if err := tx.Model(&model.Wallet{}).
+		Where("user_id = ?", wallet.UserID).
+		Update("balance", wallet.Balance-price).Error; err != nil {
+		return err
+	}
+	meta, err := json.Marshal(map[string]any{
+		"source":          "subscription_renewal",
+		"subscription_id": subscriptionID,
+		"plan_id":         planID,
+		"period_start":    periodStart,
+		"period_end":      periodEnd,
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Create(&model.Transaction{
+		UserID:   wallet.UserID,
+		Type:     model.TransactionTypeSubscription,
+		Amount:   price,
+		Currency: wallet.Currency,
+		RefID:    refID,
+		Metadata: meta,
+	}).Error
+}
+
+func (r *SubscriptionRenewal) notify(ctx context.Context, outcome *renewalOutcome) error {
+	if r.opts.Notifier == nil {
+		return nil
+	}
+	sub := outcome.subscription
+	contact, err := orgContact(ctx, r.db, sub.UserID)
+	if err != nil {
+		return err
+	}
+	if contact == "" {
+		return nil
+	}
+	orgName, err := orgDisplayName(ctx, r.db, sub.UserID)
+	if err != nil {
+		return err
+	}
+
+	address := ""
+	if base := strings.TrimRight(strings.TrimSpace(r.opts.WebBaseURL), "/"); base != "" {
+		address = base + "/billing"
+	}
+	periodEnd, graceUntil := "", ""
+	if sub.EndAt != nil {
+		periodEnd = sub.EndAt.UTC().Format("2006-01-02 15:04 UTC")
+	}
+	if outcome.graceUntil != nil {
+		graceUntil = outcome.graceUntil.UTC().Format("2006-01-02 15:04 UTC")
+	}
+	return r.opts.Notifier.EnqueueBatch(ctx, []email.EmailInput{{
+		Type:    email.EmailTypeRenewalFailed,
+		To:      []string{contact},
+		Subject: "DeepSpace 套餐续费失败",
+		TemplateData: map[string]any{
+			"username":    contact,
+			"org_name":    orgName,
+			"plan_name":   outcome.plan.Name,
+			"period_end":  periodEnd,
+			"grace_until": graceUntil,
+			"reason":      renewalFailText[outcome.reason],
+			"price":       renewalPrice(sub).String(),
+			"currency":    sub.Currency,
+			"address":     address,
+		},
+	}})
+}
+
+// renewalPrice 返回续费应扣的每周期价格，安排了降级时为降级后套餐的价格。
+func renewalPrice(sub model.PlanSubscription) money.Amount {
+	if sub.ScheduledPlanID != nil {
+		return sub.ScheduledPrice
+	}
+	return sub.Price
+}
@@ -0,0 +1,141 @@
+package job
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"deepspace-shared/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 每次执行最多处理的批次数，积压（如首次上线回填历史记录）时分多次执行追上。
+const usageAggregateMaxBatches = 20
+
+type UsageAggregateOptions struct {
+	BatchSize int
+	// Delay 内新写入的用量记录留到下次执行，避免 ID 较小但尚未提交的记录被游标跳过。
+	Delay time.Duration
+}
+
+// UsageAggregate 把 usage_records 按 ID 顺序增量累加到 usage_rollups 的小时与天（UTC）聚合中，
+// 维度为组织、成员、项目、模型与币种。游标与聚合在同一事务内更新，每条记录只会被累加一次。
+type UsageAggregate struct {
+	db   *gorm.DB
+	opts UsageAggregateOptions
+}
+
+func NewUsageAggregate(db *gorm.DB, opts UsageAggregateOptions) *UsageAggregate {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 5000
+	}
+	if opts.Delay < 0 {
+		opts.Delay = 0
+	}
+	return &UsageAggregate{db: db, opts: opts}
+}
+
+func (a *UsageAggregate) Name() string {
+	return "usage_aggregate"
+}
+
+func (a *UsageAggregate) Run(ctx context.Context) error {
+	cutoff := time.Now().UTC().Add(-a.opts.Delay)
+	var total int64
+	for i := 0; i < usageAggregateMaxBatches; i++ {
+		count, err := a.aggregateBatch(ctx, cutoff)
+		if err != nil {
+			return err
+		}
+		total += count
+		if count < int64(a.opts.BatchSize) {
+			break
+		}
+	}
+	if total > 0 {
+		log.Printf("用量聚合完成：累加 %d 条用量记录", total)
+	}
+	return nil
+}
+
+// aggregateBatch 锁定游标，把游标之后至多 BatchSize 条早于 cutoff 的记录累加到聚合表并前移游标，返回处理的记录数。
+func (a *UsageAggregate) aggregateBatch(ctx context.Context, cutoff time.Time) (int64, error) {
+	var count int64
+	err := a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.UsageRollupCursor{Name: model.UsageRollupCursorName}).Error; err != nil {
+			return err
+		}
+		var cursor model.UsageRollupCursor
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("name = ?", model.UsageRollupCursorName).
+			First(&cursor).Error; err != nil {
+			return err
+		}
+
+		var batch struct {
+			Count int64
+			MaxID *int64
+		}
+		if err := tx.Raw(`SELECT COUNT(*) AS count, MAX(id) AS max_id FROM (
+				SELECT id FROM usage_records WHERE id > ? AND created_at < ? ORDER BY id LIMIT ?
+			) AS batch`, cursor.LastID, cutoff, a.opts.BatchSize).
+			Scan(&batch).Error; err != nil {
+			return err
+		}
+		if batch.MaxID == nil {
+			return nil
+		}
+
+		// 按 ID 区间累加而不是按 created_at 过滤，区间内的记录即使时间较晚也不会被游标跳过。
+		if err := tx.Exec(`INSERT INTO usage_rollups (
+				granularity, bucket_start, org_id, user_id, project_id, model, currency,
+				requests, failed_requests, prompt_tokens, completion_tokens, total_tokens, cost,
+				latency_ms_sum, latency_count, first_token_ms_sum, first_token_count, updated_at
+			)
+			SELECT g.granularity,
+				date_trunc(g.granularity, u.created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+				u.org_id, u.user_id, COALESCE(u.project_id, 0), u.model, COALESCE(u.currency, ''),
+				COUNT(*),
+				COUNT(*) FILTER (WHERE u.status_code <> 0 AND (u.status_code < 200 OR u.status_code >= 400)),
+				COALESCE(SUM(u.prompt_tokens), 0),
+				COALESCE(SUM(u.completion_tokens), 0),
+				COALESCE(SUM(u.total_tokens), 0),
+				COALESCE(SUM(u.cost), 0),
+				COALESCE(SUM(u.latency_ms) FILTER (WHERE u.latency_ms > 0), 0),
+				COUNT(*) FILTER (WHERE u.latency_ms > 0),
+				COALESCE(SUM(u.first_token_ms), 0),
+				COUNT(u.first_token_ms),
+				NOW()
+			FROM usage_records u
+			CROSS JOIN (VALUES ('hour'), ('day')) AS g(granularity)
+			WHERE u.id > ? AND u.id <= ?
+			GROUP BY 1, 2, 3, 4, 5, 6, 7
+			ON CONFLICT (granularity, bucket_start, org_id, user_id, project_id, model, currency) DO UPDATE SET
+				requests = usage_rollups.requests + EXCLUDED.requests,
+				failed_requests = usage_rollups.failed_requests + EXCLUDED.failed_requests,
+				prompt_tokens = usage_rollups.prompt_tokens + EXCLUDED.prompt_tokens,
+				completion_tokens = usage_rollups.completion_tokens + EXCLUDED.completion_tokens,
+				total_tokens = usage_rollups.total_tokens + EXCLUDED.total_tokens,
+				cost = usage_rollups.cost + EXCLUDED.cost,
+				latency_ms_sum = usage_rollups.latency_ms_sum + EXCLUDED.latency_ms_sum,
+				latency_count = usage_rollups.latency_count + EXCLUDED.latency_count,
+				first_token_ms_sum = usage_rollups.first_token_ms_sum + EXCLUDED.first_token_ms_sum,
+				first_token_count = usage_rollups.first_token_count + EXCLUDED.first_token_count,
+				updated_at = EXCLUDED.updated_at`,
+			cursor.LastID, *batch.MaxID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.UsageRollupCursor{}).
+			Where("name = ?", model.UsageRollupCursorName).
+			Update("last_id", *batch.MaxID).Error; err != nil {
+			return err
+		}
+		count = batch.Count
+		return nil
+	})
+	return count, err
+}
@@ -0,0 +1,237 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sort"
+	"time"
+
+	"deepspace-shared/model"
+	"deepspace-shared/money"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VoucherExpiryOptions struct {
+	BatchSize int
+}
+
+// VoucherExpiry 回收已过期兑换记录中尚未用完的代金券额度。
+type VoucherExpiry struct {
+	db   *gorm.DB
+	opts VoucherExpiryOptions
+}
+
+func NewVoucherExpiry(db *gorm.DB, opts VoucherExpiryOptions) *VoucherExpiry {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 200
+	}
+	return &VoucherExpiry{db: db, opts: opts}
+}
+
+func (r *VoucherExpiry) Name() string {
+	return "voucher_expiry"
+}
+
+func (r *VoucherExpiry) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+	// 按 (expires_at, id) 游标分页，本轮处理失败的兑换记录不会在下一页重复出现、占满批次。
+	count := 0
+	var total money.Amount
+	var after *model.VoucherRedemption
+	for {
+		query := r.db.WithContext(ctx).
+			Where("status = ? AND expires_at IS NOT NULL AND expires_at <= ?", model.RedemptionStatusActive, now)
+		if after != nil {
+			query = query.Where("(expires_at, id) > (?, ?)", *after.ExpiresAt, after.ID)
+		}
+		var items []model.VoucherRedemption
+		if err := query.
+			Order("expires_at ASC, id ASC").
+			Limit(r.opts.BatchSize).
+			Find(&items).Error; err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			amount, err := r.expire(ctx, item, now)
+			if err != nil {
+				log.Printf("回收代金券额度失败 user=%d redemption=%d: %v", item.UserID, item.ID, err)
+				continue
+			}
+			if amount > 0 {
+				count++
+				total += amount
+			}
+		}
+		if len(items) < r.opts.BatchSize || ctx.Err() != nil {
+			break
+		}
+		after = &items[len(items)-1]
+	}
+	if count > 0 {
+		log.Printf("已回收过期代金券额度 %d 笔，共 %s", count, total)
+	}
+	return nil
+}
+
+// expire 在单个事务内回收一条兑换记录的剩余额度并将其标记为 expired。
+// 每条兑换记录只计入分摊给它的扣款（见 allocateVoucherSpend），剩余部分按当前可用余额封顶回收，不会使余额为负。
+func (r *VoucherExpiry) expire(ctx context.Context, item model.VoucherRedemption, now time.Time) (money.Amount, error) {
+	var clawback money.Amount
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var wallet model.Wallet
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", item.UserID).
+			First(&wallet).Error; err != nil {
+			return err
+		}
+		var redemption model.VoucherRedemption
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", item.ID).
+			First(&redemption).Error; err != nil {
+			return err
+		}
+		if redemption.Status != model.RedemptionStatusActive {
+			return nil
+		}
+
+		// 兑换后钱包切换过币种时原额度已无法对应，只关闭兑换记录。
+		var unused, consumed money.Amount
+		if redemption.Currency == "" || redemption.Currency == wallet.Currency {
+			allocated, err := allocateVoucherSpend(tx, redemption.UserID, wallet.Currency, now)
+			if err != nil {
+				return err
+			}
+			consumed = allocated[redemption.ID]
+			unused = redemption.Amount - consumed
+		}
+		amount := min(unused, wallet.Balance)
+		if amount < 0 {
+			amount = 0
+		}
+
+		if amount > 0 {
+			if err := tx.Model(&model.Wallet{}).
+				Where("user_id = ?", redemption.UserID).
+				Update("balance", wallet.Balance-amount).Error; err != nil {
+				return err
+			}
+			meta, err := json.Marshal(map[string]any{
+				"source":        "voucher_expiry",
+				"reason":        "voucher credit expired",
+				"redemption_id": redemption.ID,
+				"voucher_id":    redemption.VoucherID,
+				"credited":      redemption.Amount,
+				"consumed":      consumed,
+				"expires_at":    redemption.ExpiresAt,
+				"expired_at":    now,
+			})
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(&model.Transaction{
+				UserID:   redemption.UserID,
+				Type:     model.TransactionTypeVoucherExpire,
+				Amount:   -amount,
+				Currency: wallet.Currency,
+				RefID:    redemption.RefID,
+				Metadata: meta,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&model.VoucherRedemption{}).
+			Where("id = ?", redemption.ID).
+			Updates(map[string]any{
+				"status":      model.RedemptionStatusExpired,
+				"clawed_back": amount,
+			}).Error; err != nil {
+			return err
+		}
+		clawback = amount
+		return nil
+	})
+	return clawback, err
+}
+
+// allocateVoucherSpend 按先进先出把用户的扣款分摊到各条兑换记录上，返回每条记录已消耗的额度。
+// 时间轴按兑换与过期时刻切分为若干区间，区间内的净扣款依次计入当时仍有效且未用完的最早兑换记录，
+// 超出全部代金券剩余额度的部分视为由充值等其他余额支付；退款按相反顺序冲减已分摊的消耗。
+// 调用方需已锁定钱包，保证分摊期间不会有新的扣款写入。
+func allocateVoucherSpend(tx *gorm.DB, userID int64, currency string, now time.Time) (map[int64]money.Amount, error) {
+	var redemptions []model.VoucherRedemption
+	if err := tx.Where("user_id = ? AND created_at <= ?", userID, now).
+		Order("created_at ASC, id ASC").
+		Find(&redemptions).Error; err != nil {
+		return nil, err
+	}
+	consumed := make(map[int64]money.Amount, len(redemptions))
+	var eligible []model.VoucherRedemption
+	for _, item := range redemptions {
+		if item.Currency == "" || item.Currency == currency {
+			eligible = append(eligible, item)
+		}
+	}
+	if len(eligible) == 0 {
+		return consumed, nil
+	}
+
+	bounds := []time.Time{now}
+	for _, item := range eligible {
+		bounds = append(bounds, item.CreatedAt)
+		if item.ExpiresAt != nil && item.ExpiresAt.Before(now) {
+			bounds = append(bounds, *item.ExpiresAt)
+		}
+	}
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i].Before(bounds[j]) })
+
+	for i := 0; i+1 < len(bounds); i++ {
+		start, end := bounds[i], bounds[i+1]
+		if !start.Before(end) {
+			continue
+		}
+		var live []model.VoucherRedemption
+		for _, item := range eligible {
+			if !item.CreatedAt.After(start) && (item.ExpiresAt == nil || !item.ExpiresAt.Before(end)) {
+				live = append(live, item)
+			}
+		}
+		if len(live) == 0 {
+			continue
+		}
+
+		var spent money.Amount
+		if err := tx.Model(&model.Transaction{}).
+			Select("COALESCE(SUM(CASE WHEN type = ? THEN -amount ELSE amount END), 0)", model.TransactionTypeRefund).
+			Where("user_id = ? AND type IN ? AND created_at >= ? AND created_at < ?", userID, []string{model.TransactionTypeCapture, model.TransactionTypeSubscription, model.TransactionTypeRefund}, start, end).
+			Scan(&spent).Error; err != nil {
+			return nil, err
+		}
+
+		if spent > 0 {
+			for _, item := range live {
+				if spent <= 0 {
+					break
+				}
+				take := min(item.Amount-consumed[item.ID], spent)
+				if take > 0 {
+					consumed[item.ID] += take
+					spent -= take
+				}
+			}
+		} else if spent < 0 {
+			refund := -spent
+			for j := len(live) - 1; j >= 0 && refund > 0; j-- {
+				id := live[j].ID
+				back := min(consumed[id], refund)
+				consumed[id] -= back
+				refund -= back
+			}
+		}
+	}
+	return consumed, nil
+}